@@ -33,12 +33,15 @@ import (
 	"github.com/horizoncd/horizon/core/config"
 	accessctl "github.com/horizoncd/horizon/core/controller/access"
 	accesstokenctl "github.com/horizoncd/horizon/core/controller/accesstoken"
+	admissionpolicyctl "github.com/horizoncd/horizon/core/controller/admissionpolicy"
 	applicationctl "github.com/horizoncd/horizon/core/controller/application"
 	applicationregionctl "github.com/horizoncd/horizon/core/controller/applicationregion"
 	badgectl "github.com/horizoncd/horizon/core/controller/badge"
 	"github.com/horizoncd/horizon/core/controller/build"
+	checkctl "github.com/horizoncd/horizon/core/controller/check"
 	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
 	codectl "github.com/horizoncd/horizon/core/controller/code"
+	deploywindowctl "github.com/horizoncd/horizon/core/controller/deploywindow"
 	environmentctl "github.com/horizoncd/horizon/core/controller/environment"
 	environmentregionctl "github.com/horizoncd/horizon/core/controller/environmentregion"
 	envtemplatectl "github.com/horizoncd/horizon/core/controller/envtemplate"
@@ -46,6 +49,7 @@ import (
 	groupctl "github.com/horizoncd/horizon/core/controller/group"
 	idpctl "github.com/horizoncd/horizon/core/controller/idp"
 	memberctl "github.com/horizoncd/horizon/core/controller/member"
+	membergrantctl "github.com/horizoncd/horizon/core/controller/membergrant"
 	oauthservicectl "github.com/horizoncd/horizon/core/controller/oauth"
 	oauthappctl "github.com/horizoncd/horizon/core/controller/oauthapp"
 	oauthcheckctl "github.com/horizoncd/horizon/core/controller/oauthcheck"
@@ -84,16 +88,20 @@ import (
 	"github.com/horizoncd/horizon/core/http/api/v1/template"
 	accessv2 "github.com/horizoncd/horizon/core/http/api/v2/access"
 	accesstokenv2 "github.com/horizoncd/horizon/core/http/api/v2/accesstoken"
+	admissionpolicyv2 "github.com/horizoncd/horizon/core/http/api/v2/admissionpolicy"
 	applicationregionv2 "github.com/horizoncd/horizon/core/http/api/v2/applicationregion"
 	"github.com/horizoncd/horizon/core/http/api/v2/badge"
+	checkv2 "github.com/horizoncd/horizon/core/http/api/v2/check"
 	clusterv2 "github.com/horizoncd/horizon/core/http/api/v2/cluster"
 	codev2 "github.com/horizoncd/horizon/core/http/api/v2/code"
+	deploywindowv2 "github.com/horizoncd/horizon/core/http/api/v2/deploywindow"
 	environmentv2 "github.com/horizoncd/horizon/core/http/api/v2/environment"
 	environmentregionv2 "github.com/horizoncd/horizon/core/http/api/v2/environmentregion"
 	eventv2 "github.com/horizoncd/horizon/core/http/api/v2/event"
 	groupv2 "github.com/horizoncd/horizon/core/http/api/v2/group"
 	idpv2 "github.com/horizoncd/horizon/core/http/api/v2/idp"
 	memberv2 "github.com/horizoncd/horizon/core/http/api/v2/member"
+	membergrantv2 "github.com/horizoncd/horizon/core/http/api/v2/membergrant"
 	oauthappv2 "github.com/horizoncd/horizon/core/http/api/v2/oauthapp"
 	pipelinerunv2 "github.com/horizoncd/horizon/core/http/api/v2/pipelinerun"
 	regionv2 "github.com/horizoncd/horizon/core/http/api/v2/region"
@@ -109,19 +117,25 @@ import (
 	"github.com/horizoncd/horizon/core/middleware/auth"
 	"github.com/horizoncd/horizon/core/middleware/requestid"
 	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+	"github.com/horizoncd/horizon/lib/plaingit"
 	"github.com/horizoncd/horizon/pkg/admission"
 	"github.com/horizoncd/horizon/pkg/cd"
 	clustermetrcis "github.com/horizoncd/horizon/pkg/cluster/metrics"
 	admissionconfig "github.com/horizoncd/horizon/pkg/config/admission"
+	gitlabconfig "github.com/horizoncd/horizon/pkg/config/gitlab"
+	deploywindowservice "github.com/horizoncd/horizon/pkg/deploywindow/service"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	"github.com/horizoncd/horizon/pkg/grafana"
 	"github.com/horizoncd/horizon/pkg/jobs"
+	jobapproval "github.com/horizoncd/horizon/pkg/jobs/approval"
 	"github.com/horizoncd/horizon/pkg/jobs/autofree"
 	"github.com/horizoncd/horizon/pkg/jobs/clean"
 	"github.com/horizoncd/horizon/pkg/jobs/eventhandler"
+	"github.com/horizoncd/horizon/pkg/jobs/eventsink"
 	"github.com/horizoncd/horizon/pkg/jobs/grafanasync"
 	"github.com/horizoncd/horizon/pkg/jobs/k8sevent"
+	jobmembergrant "github.com/horizoncd/horizon/pkg/jobs/membergrant"
 	jobwebhook "github.com/horizoncd/horizon/pkg/jobs/webhook"
 	prservice "github.com/horizoncd/horizon/pkg/pr/service"
 	"github.com/horizoncd/horizon/pkg/regioninformers"
@@ -141,6 +155,7 @@ import (
 	templatev2 "github.com/horizoncd/horizon/core/http/api/v2/template"
 	"github.com/horizoncd/horizon/core/http/health"
 	"github.com/horizoncd/horizon/core/http/metrics"
+	templaterepoapi "github.com/horizoncd/horizon/core/http/templaterepo"
 	admissionmiddle "github.com/horizoncd/horizon/core/middleware/admission"
 	ginlogmiddle "github.com/horizoncd/horizon/core/middleware/ginlog"
 	logmiddle "github.com/horizoncd/horizon/core/middleware/log"
@@ -173,6 +188,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/templaterelease/output"
 	templateschemarepo "github.com/horizoncd/horizon/pkg/templaterelease/schema/repo"
 	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/templaterepo/filerepo"
 	userservice "github.com/horizoncd/horizon/pkg/user/service"
 	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
 
@@ -325,8 +341,17 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 
 	// init manager parameter
 	manager := managerparam.InitManager(mysqlDB)
+	// admission policies in the database are evaluated after the webhooks in the config
+	admission.NewPolicyWebhooks(manager.AdmissionPolicyMgr)
 
-	gitlabGitops, err := gitlablib.New(coreConfig.GitopsRepoConfig.Token, coreConfig.GitopsRepoConfig.URL)
+	var gitlabGitops gitlablib.Interface
+	switch coreConfig.GitopsRepoConfig.Kind {
+	case gitlabconfig.GitopsRepoKindGit:
+		gitlabGitops, err = plaingit.New(coreConfig.GitopsRepoConfig.Token, coreConfig.GitopsRepoConfig.URL,
+			coreConfig.GitopsRepoConfig.DefaultBranch)
+	default:
+		gitlabGitops, err = gitlablib.New(coreConfig.GitopsRepoConfig.Token, coreConfig.GitopsRepoConfig.URL)
+	}
 	if err != nil {
 		panic(err)
 	}
@@ -387,7 +412,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		coreConfig.Oauth.AccessTokenExpireIn,
 		coreConfig.Oauth.RefreshTokenExpireIn)
 
-	roleService, err := role.NewFileRoleFrom2(context.TODO(), roleConfig)
+	roleService, err := role.NewDBRole(context.TODO(), roleConfig, manager.RoleMgr)
 	if err != nil {
 		panic(err)
 	}
@@ -471,8 +496,9 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		ScopeService:         scopeService,
 		ApplicationGitRepo:   applicationGitRepo,
 		TemplateSchemaGetter: templateSchemaGetter,
-		CD: cd.NewCD(regionInformers, clusterGitRepo, coreConfig.ArgoCDMapper, coreConfig.RegionArgoCDMapper,
-			coreConfig.GitopsRepoConfig.DefaultBranch),
+		CD: cd.NewCD(regionInformers, clusterGitRepo, templateRepo, manager.TemplateReleaseMgr,
+			manager.RegionMgr, coreConfig.ArgoCDMapper, coreConfig.RegionArgoCDMapper,
+			coreConfig.RegionFluxCDMapper, coreConfig.RegionHelmCDMapper, coreConfig.GitopsRepoConfig.DefaultBranch),
 		K8sUtil:        cd.NewK8sUtil(regionInformers, manager.EventMgr),
 		OutputGetter:   outputGetter,
 		TektonFty:      tektonFty,
@@ -481,13 +507,16 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		GitGetter:      gitGetter,
 		GrafanaService: grafanaService,
 		BuildSchema:    buildSchema,
+
+		DeployWindowSvc: deploywindowservice.NewService(manager, mservice, eventSvc),
 	}
 
 	var (
 		authnSkippers = []middleware.Skipper{
 			middleware.MethodAndPathSkipper("*",
 				regexp.MustCompile("(^/apis/front/.*)|(^/health)|(^/metrics)|(^/apis/login)|"+
-					"(^/apis/core/v[12]/roles)|(^/apis/internal/.*)|(^/login/oauth/authorize)|(^/login/oauth/access_token)")),
+					"(^/apis/internal/.*)|(^/login/oauth/authorize)|(^/login/oauth/access_token)")),
+			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/roles$")),
 			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/idps/endpoints")),
 			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/login/callback")),
 			middleware.MethodAndPathSkipper(http.MethodPost, regexp.MustCompile("^/apis/core/v[12]/logout")),
@@ -497,6 +526,9 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		authzSkippers = []middleware.Skipper{
 			middleware.MethodAndPathSkipper("*",
 				regexp.MustCompile("^/apis/core/v[12]/templates$")),
+			// events in the stream are authorized one by one by the event controller
+			middleware.MethodAndPathSkipper(http.MethodGet,
+				regexp.MustCompile("^/apis/core/v2/events/stream$")),
 		}
 	)
 	authzSkippers = append(authzSkippers, authnSkippers...)
@@ -504,6 +536,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	var (
 		// init controller
 		memberCtl            = memberctl.NewController(parameter)
+		memberGrantCtl       = membergrantctl.NewController(parameter)
 		applicationCtl       = applicationctl.NewController(parameter)
 		envTemplateCtl       = envtemplatectl.NewController(parameter)
 		clusterCtl           = clusterctl.NewController(coreConfig, parameter)
@@ -514,7 +547,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		codeGitCtl           = codectl.NewController(gitGetter)
 		tagCtl               = tagctl.NewController(parameter)
 		templateSchemaTagCtl = templateschematagctl.NewController(parameter)
-		accessCtl            = accessctl.NewController(rbacAuthorizer, authzSkippers...)
+		accessCtl            = accessctl.NewController(parameter, rbacAuthorizer, authzSkippers...)
 		applicationRegionCtl = applicationregionctl.NewController(parameter)
 		groupCtl             = groupctl.NewController(parameter)
 		oauthCheckerCtl      = oauthcheckctl.NewOauthChecker(parameter)
@@ -530,8 +563,11 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		accessTokenCtl       = accesstokenctl.NewController(parameter)
 		scopeCtl             = scopectl.NewController(parameter)
 		webhookCtl           = webhookctl.NewController(parameter)
-		eventCtl             = eventctl.NewController(parameter)
+		eventCtl             = eventctl.NewController(parameter, rbacAuthorizer)
 		badgeCtl             = badgectl.NewController(parameter)
+		deployWindowCtl      = deploywindowctl.NewController(parameter)
+		checkCtl             = checkctl.NewController(parameter)
+		admissionPolicyCtl   = admissionpolicyctl.NewController(parameter)
 	)
 
 	var (
@@ -579,6 +615,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		groupAPIV2             = groupv2.NewAPI(groupCtl)
 		idpAPIV2               = idpv2.NewAPI(idpCtrl, store)
 		memberAPIV2            = memberv2.NewAPI(memberCtl, roleService)
+		memberGrantAPIV2       = membergrantv2.NewAPI(memberGrantCtl)
 		oauthAppAPIV2          = oauthappv2.NewAPI(oauthAppCtl)
 		pipelinerunAPIV2       = pipelinerunv2.NewAPI(prCtl)
 		regionAPIV2            = regionv2.NewAPI(regionCtl, tagCtl)
@@ -592,6 +629,9 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		userAPIV2              = userv2.NewAPI(userCtl, store)
 		webhookAPIV2           = webhookv2.NewAPI(webhookCtl)
 		badgeAPIV2             = badge.NewAPI(badgeCtl)
+		deployWindowAPIV2      = deploywindowv2.NewAPI(deployWindowCtl)
+		checkAPIV2             = checkv2.NewAPI(checkCtl)
+		admissionPolicyAPIV2   = admissionpolicyv2.NewAPI(admissionPolicyCtl)
 	)
 
 	// start jobs
@@ -601,12 +641,19 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	}
 	eventHandlerJob, eventHandlerSvc := eventhandler.New(ctx, coreConfig.EventHandlerConfig, manager)
 	webhookJob, _ := jobwebhook.New(ctx, eventHandlerSvc, coreConfig.WebhookConfig, manager)
+	eventSinkJob := eventsink.New(ctx, coreConfig.EventHandlerConfig, manager)
 	grafanaSyncJob := func(ctx context.Context) {
 		grafanasync.Run(ctx, coreConfig, manager, client)
 	}
 	k8seventJob := k8sevent.New(coreConfig.KubernetesEvent, regionInformers, manager, mysqlDB)
-	go jobs.Run(ctx, &coreConfig.JobConfig, eventHandlerJob, webhookJob,
-		k8seventJob.Run, cleaner.Run, autoFreeJob, grafanaSyncJob)
+	memberGrantJob := func(ctx context.Context) {
+		jobmembergrant.Run(ctx, &coreConfig.MemberGrant, manager.UserMgr, memberGrantCtl)
+	}
+	approvalJob := func(ctx context.Context) {
+		jobapproval.Run(ctx, &coreConfig.Approval, manager.UserMgr, prCtl)
+	}
+	go jobs.Run(ctx, &coreConfig.JobConfig, eventHandlerJob, webhookJob, eventSinkJob,
+		k8seventJob.Run, cleaner.Run, autoFreeJob, grafanaSyncJob, memberGrantJob, approvalJob)
 
 	// init server
 	r := gin.New()
@@ -628,6 +675,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/metrics")),
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/apis/front/v1/terminal")),
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/apis/front/v2/buildschema")),
+			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^"+filerepo.ServePath)),
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/login/oauth/access_token")),
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/apis/internal/v2/.*")),
 			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/idps/endpoints")),
@@ -645,6 +693,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	health.RegisterRoutes(r)
 	clustermetrcis.NewMetrics(manager)
 	metrics.RegisterRoutes(r)
+	templaterepoapi.RegisterRoutes(r, templateRepo)
 
 	// v1
 	registerV1Group := []RegisterRouter{
@@ -692,6 +741,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		eventAPIV2,
 		idpAPIV2,
 		memberAPIV2,
+		memberGrantAPIV2,
 		oauthAppAPIV2,
 		pipelinerunAPIV2,
 		regionAPIV2,
@@ -705,6 +755,9 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		userAPIV2,
 		webhookAPIV2,
 		badgeAPIV2,
+		deployWindowAPIV2,
+		checkAPIV2,
+		admissionPolicyAPIV2,
 	}
 
 	// start cloud event server
@@ -33,6 +33,7 @@ const (
 	ClusterQueryTailLines     = "tailLines"
 	ClusterQueryExtraOwner    = "extraOwner"
 	ClusterQueryHard          = "hard"
+	ClusterQueryPreview       = "preview"
 
 	// ClusterQueryIsFavorite is used to query cluster with favorite for current user only.
 	ClusterQueryIsFavorite = "isFavorite"
@@ -86,6 +86,8 @@ const (
 
 	ResourceRegion = "regions"
 
+	ResourceEnvironment = "environments"
+
 	// ResourceGroup represent the group member entry.
 	ResourceGroup = "groups"
 
@@ -110,6 +112,10 @@ const (
 	ResourceWebhookLog = "webhooklogs"
 
 	ResourceMember = "members"
+
+	ResourceMemberGrant = "membergrants"
+
+	ResourceRole = "roles"
 )
 
 const (
@@ -126,6 +132,7 @@ const (
 	ParamResourceType  = "resourceType"
 	ParamResourceID    = "resourceID"
 	ParamAccessTokenID = "accessTokenID"
+	ParamMemberGrantID = "memberGrantID"
 )
 
 const (
@@ -0,0 +1,10 @@
+package common
+
+const (
+	MemberGrantQueryByResourceType = "resourceType"
+	MemberGrantQueryByResourceID   = "resourceID"
+	MemberGrantQueryByUserID       = "userID"
+	MemberGrantQueryByStatus       = "status"
+	// MemberGrantQueryVisibleTo limits grants by models.Visibility, it's set by the controller
+	MemberGrantQueryVisibleTo = "visibleTo"
+)
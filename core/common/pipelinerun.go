@@ -9,4 +9,7 @@ const (
 	MessagePipelinerunExecuted  = "executed pipelinerun"
 	MessagePipelinerunCancelled = "cancelled pipelinerun"
 	MessagePipelinerunReady     = "marked pipelinerun as ready to execute"
+	MessagePipelinerunApproved  = "approved pipelinerun"
+	MessagePipelinerunRejected  = "rejected pipelinerun"
+	MessageApprovalExpired      = "approval of pipelinerun expired"
 )
@@ -32,7 +32,8 @@ const (
 )
 
 const (
-	contextUserKey = "contextUser"
+	contextUserKey        = "contextUser"
+	contextAccessTokenKey = "contextAccessToken"
 
 	AuthorizationHeaderKey = "Authorization"
 	TokenHeaderValuePrefix = "Bearer"
@@ -59,6 +60,20 @@ func SetUser(c *gin.Context, user user.User) {
 	c.Set(contextUserKey, user)
 }
 
+// AccessTokenFromContext returns the access token the request is authenticated by
+func AccessTokenFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(contextAccessTokenKey).(string)
+	return token, ok && token != ""
+}
+
+func WithContextAccessToken(parent context.Context, token string) context.Context {
+	return context.WithValue(parent, contextAccessTokenKey, token) // nolint
+}
+
+func SetAccessToken(c *gin.Context, token string) {
+	c.Set(contextAccessTokenKey, token)
+}
+
 func GetToken(c *gin.Context) (string, error) {
 	if _, ok := c.Request.Header[AuthorizationHeaderKey]; !ok {
 		return "", perror.Wrap(herror.ErrAuthorizationHeaderNotFound, "")
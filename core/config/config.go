@@ -19,17 +19,21 @@ import (
 	"strings"
 
 	"github.com/horizoncd/horizon/pkg/config/admission"
+	"github.com/horizoncd/horizon/pkg/config/approval"
 	"github.com/horizoncd/horizon/pkg/config/argocd"
 	"github.com/horizoncd/horizon/pkg/config/authenticate"
 	"github.com/horizoncd/horizon/pkg/config/autofree"
 	"github.com/horizoncd/horizon/pkg/config/clean"
 	"github.com/horizoncd/horizon/pkg/config/db"
 	"github.com/horizoncd/horizon/pkg/config/eventhandler"
+	"github.com/horizoncd/horizon/pkg/config/fluxcd"
 	"github.com/horizoncd/horizon/pkg/config/git"
 	"github.com/horizoncd/horizon/pkg/config/gitlab"
 	"github.com/horizoncd/horizon/pkg/config/grafana"
+	"github.com/horizoncd/horizon/pkg/config/helmcd"
 	"github.com/horizoncd/horizon/pkg/config/job"
 	"github.com/horizoncd/horizon/pkg/config/k8sevent"
+	"github.com/horizoncd/horizon/pkg/config/membergrant"
 	"github.com/horizoncd/horizon/pkg/config/oauth"
 	"github.com/horizoncd/horizon/pkg/config/pprof"
 	"github.com/horizoncd/horizon/pkg/config/redis"
@@ -54,6 +58,8 @@ type Config struct {
 	GitopsRepoConfig       gitlab.GitopsRepoConfig `yaml:"gitopsRepoConfig"`
 	ArgoCDMapper           argocd.Mapper           `yaml:"argoCDMapper"`
 	RegionArgoCDMapper     argocd.RegionMapper     `yaml:"regionArgoCDMapper"`
+	RegionFluxCDMapper     fluxcd.RegionMapper     `yaml:"regionFluxCDMapper"`
+	RegionHelmCDMapper     helmcd.RegionMapper     `yaml:"regionHelmCDMapper"`
 	RedisConfig            redis.Redis             `yaml:"redisConfig"`
 	TektonMapper           tekton.Mapper           `yaml:"tektonMapper"`
 	TemplateRepo           templaterepo.Repo       `yaml:"templateRepo"`
@@ -70,6 +76,8 @@ type Config struct {
 	KubernetesEvent        k8sevent.Config         `yaml:"kubernetesEvent"`
 	Clean                  clean.Config            `yaml:"clean"`
 	Admission              admission.Admission     `yaml:"admission"`
+	MemberGrant            membergrant.Config      `yaml:"memberGrant"`
+	Approval               approval.Config         `yaml:"approval"`
 }
 
 func LoadConfig(configFilePath string) (*Config, error) {
@@ -101,6 +109,24 @@ func LoadConfig(configFilePath string) (*Config, error) {
 	}
 	config.RegionArgoCDMapper = newRegionCDMapper
 
+	newRegionFluxCDMapper := fluxcd.RegionMapper{}
+	for key, v := range config.RegionFluxCDMapper {
+		ks := strings.Split(key, ",")
+		for i := 0; i < len(ks); i++ {
+			newRegionFluxCDMapper[ks[i]] = v
+		}
+	}
+	config.RegionFluxCDMapper = newRegionFluxCDMapper
+
+	newRegionHelmCDMapper := helmcd.RegionMapper{}
+	for key, v := range config.RegionHelmCDMapper {
+		ks := strings.Split(key, ",")
+		for i := 0; i < len(ks); i++ {
+			newRegionHelmCDMapper[ks[i]] = v
+		}
+	}
+	config.RegionHelmCDMapper = newRegionHelmCDMapper
+
 	newTektonMapper := tekton.Mapper{}
 	for key, v := range config.TektonMapper {
 		ks := strings.Split(key, ",")
@@ -16,35 +16,52 @@ package access
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/oauthcheck"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/core/middleware"
 	"github.com/horizoncd/horizon/core/middleware/prehandle"
 	hauth "github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/rbac"
+	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 )
 
 type Controller interface {
 	// Review return access review results for apis
 	Review(ctx context.Context, apis []API) (map[string]map[string]*ReviewResult, error)
+	// Explain explains the decision of an api for the user or access token,
+	// including the member, the member inheritance chain and the rule matched
+	Explain(ctx context.Context, request *ExplainRequest) (*Explanation, error)
 }
 
 type controller struct {
 	requestInfoFty hauth.RequestInfoFactory
 	authorizer     rbac.Authorizer
 	skippers       []middleware.Skipper
+	oauthCheckCtl  oauthcheck.Controller
+	tokenMgr       tokenmanager.Manager
+	userMgr        usermanager.Manager
 }
 
 var _ Controller = (*controller)(nil)
 
-func NewController(authorizer rbac.Authorizer,
+func NewController(param *param.Param, authorizer rbac.Authorizer,
 	skippers ...middleware.Skipper) Controller {
 	return &controller{
 		requestInfoFty: prehandle.RequestInfoFty,
 		authorizer:     authorizer,
 		skippers:       skippers,
+		oauthCheckCtl:  oauthcheck.NewOauthChecker(param),
+		tokenMgr:       param.TokenMgr,
+		userMgr:        param.UserMgr,
 	}
 }
 
@@ -66,40 +83,18 @@ func (c *controller) Review(ctx context.Context, apis []API) (map[string]map[str
 		reviewResult := &ReviewResult{}
 		reviewResponse[api.URL][api.Method] = reviewResult
 
-		// 1.new request by api
-		req, err := http.NewRequest(api.Method, api.URL, nil)
+		// 1.new request info by api, and check skippers
+		requestInfo, skipped, err := c.newRequestInfo(ctx, api)
 		if err != nil {
-			return nil, perror.Wrapf(err, "invalid api, url: %s, method: %s", api.URL, api.Method)
+			return nil, err
 		}
-		// 2.check skippers
-		for _, skipper := range c.skippers {
-			if skipper(req) {
-				reviewResult.Allowed = true
-				break
-			}
-		}
-		// 3.if skipped, review next api
-		if reviewResult.Allowed {
+		// 2.if skipped, review next api
+		if skipped {
+			reviewResult.Allowed = true
 			continue
 		}
-		// 4.new request info by request
-		requestInfo, err := c.requestInfoFty.NewRequestInfo(ctx, req)
-		if err != nil {
-			return nil, perror.WithMessagef(err, "invalid api, url: %s, method: %s", api.URL, api.Method)
-		}
-		// 4. do rbac auth
-		authRecord := hauth.AttributesRecord{
-			User:            currentUser,
-			Verb:            requestInfo.Verb,
-			APIGroup:        requestInfo.APIGroup,
-			APIVersion:      requestInfo.APIVersion,
-			Resource:        requestInfo.Resource,
-			SubResource:     requestInfo.Subresource,
-			Name:            requestInfo.Name,
-			Scope:           requestInfo.Scope,
-			ResourceRequest: requestInfo.IsResourceRequest,
-			Path:            requestInfo.Path,
-		}
+		// 3. do rbac auth
+		authRecord := newAttributesRecord(currentUser, requestInfo)
 		decision, reason, err := c.authorizer.Authorize(ctx, authRecord)
 		if err != nil {
 			return nil, perror.WithMessagef(err, "failed to authorize, url: %s, method: %s", api.URL, api.Method)
@@ -110,3 +105,133 @@ func (c *controller) Review(ctx context.Context, apis []API) (map[string]map[str
 
 	return reviewResponse, nil
 }
+
+func (c *controller) Explain(ctx context.Context, request *ExplainRequest) (*Explanation, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, perror.WithMessage(err, "failed to get user info")
+	}
+
+	// 1. get the user to explain for
+	var (
+		subject userauth.User = currentUser
+		token   *tokenmodels.Token
+	)
+	if request.AccessTokenID != 0 {
+		token, err = c.tokenMgr.LoadTokenByID(ctx, request.AccessTokenID)
+		if err != nil {
+			return nil, err
+		}
+		if !currentUser.IsAdmin() && token.UserID != currentUser.GetID() &&
+			token.CreatedBy != currentUser.GetID() {
+			return nil, perror.Wrapf(herrors.ErrForbidden,
+				"no privilege to explain for access token %d", request.AccessTokenID)
+		}
+		subject, err = c.getUser(ctx, token.UserID)
+		if err != nil {
+			return nil, err
+		}
+	} else if request.UserID != 0 && request.UserID != currentUser.GetID() {
+		if !currentUser.IsAdmin() {
+			return nil, perror.Wrapf(herrors.ErrForbidden,
+				"only admins can explain for user %d", request.UserID)
+		}
+		subject, err = c.getUser(ctx, request.UserID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ctx = common.WithContext(ctx, subject)
+
+	// 2. new request info by api, and check skippers
+	requestInfo, skipped, err := c.newRequestInfo(ctx, request.API)
+	if err != nil {
+		return nil, err
+	}
+	if skipped {
+		return &Explanation{
+			Allowed:   true,
+			Reason:    "api is not checked by authorizer",
+			Skipped:   true,
+			RuleIndex: -1,
+		}, nil
+	}
+
+	// 3. check scopes of access token
+	explanation := &Explanation{}
+	if token != nil {
+		allowed, reason, err := c.oauthCheckCtl.CheckScopePermission(ctx, token.Code, *requestInfo)
+		if err != nil {
+			return nil, err
+		}
+		explanation.Scope = &ScopeReview{Allowed: allowed, Reason: reason}
+	}
+
+	// 4. explain rbac auth
+	ret, err := c.authorizer.Explain(ctx, newAttributesRecord(subject, requestInfo))
+	if err != nil {
+		return nil, perror.WithMessagef(err, "failed to explain, url: %s, method: %s",
+			request.URL, request.Method)
+	}
+	explanation.Allowed = ret.Decision == hauth.DecisionAllow
+	explanation.Reason = ret.Reason
+	explanation.Member = ofMember(ret.Member)
+	explanation.Chain = ofChain(ret.Chain)
+	explanation.RuleIndex = ret.RuleIndex
+	explanation.Rule = ret.Rule()
+	if ret.Role != nil {
+		explanation.Role = ret.Role.Name
+	}
+	if explanation.Scope != nil && !explanation.Scope.Allowed {
+		explanation.Allowed = false
+		explanation.Reason = fmt.Sprintf("access token %d denied by scopes", token.ID)
+	}
+	return explanation, nil
+}
+
+func (c *controller) getUser(ctx context.Context, id uint) (userauth.User, error) {
+	usr, err := c.userMgr.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &userauth.DefaultInfo{
+		Name:     usr.Name,
+		FullName: usr.FullName,
+		ID:       usr.ID,
+		Email:    usr.Email,
+		Admin:    usr.Admin,
+	}, nil
+}
+
+// newRequestInfo news request info by api, skipped is true if the api is skipped by authorizer
+func (c *controller) newRequestInfo(ctx context.Context, api API) (_ *hauth.RequestInfo, skipped bool, _ error) {
+	req, err := http.NewRequest(api.Method, api.URL, nil)
+	if err != nil {
+		return nil, false, perror.Wrapf(err, "invalid api, url: %s, method: %s", api.URL, api.Method)
+	}
+	for _, skipper := range c.skippers {
+		if skipper(req) {
+			return nil, true, nil
+		}
+	}
+	requestInfo, err := c.requestInfoFty.NewRequestInfo(ctx, req)
+	if err != nil {
+		return nil, false, perror.WithMessagef(err, "invalid api, url: %s, method: %s", api.URL, api.Method)
+	}
+	return requestInfo, false, nil
+}
+
+func newAttributesRecord(user userauth.User, requestInfo *hauth.RequestInfo) hauth.AttributesRecord {
+	return hauth.AttributesRecord{
+		User:            user,
+		Verb:            requestInfo.Verb,
+		APIGroup:        requestInfo.APIGroup,
+		APIVersion:      requestInfo.APIVersion,
+		Resource:        requestInfo.Resource,
+		SubResource:     requestInfo.Subresource,
+		Name:            requestInfo.Name,
+		Scope:           requestInfo.Scope,
+		ResourceRequest: requestInfo.IsResourceRequest,
+		Path:            requestInfo.Path,
+	}
+}
@@ -25,14 +25,17 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/core/middleware"
 	"github.com/horizoncd/horizon/lib/orm"
 	applicationmodels "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	"github.com/horizoncd/horizon/pkg/rbac"
 	roleservice "github.com/horizoncd/horizon/pkg/rbac/role"
@@ -85,7 +88,7 @@ func TestMain(m *testing.M) {
 	skippers := middleware.MethodAndPathSkipper("*",
 		regexp.MustCompile("(^/apis/front/.*)|(^/health)|(^/metrics)|(^/apis/login)|"+
 			"(^/apis/core/v1/roles)|(^/apis/internal/.*)"))
-	c = NewController(rbacAuthorizer, skippers)
+	c = NewController(&param.Param{Manager: manager}, rbacAuthorizer, skippers)
 
 	group, err = manager.GroupMgr.Create(ctx, &groupmodels.Group{
 		Name:            "group",
@@ -248,6 +251,79 @@ func TestController_GetAccesses_Admin(t *testing.T) {
 	}
 }
 
+func TestController_Explain(t *testing.T) {
+	maintainer, err := manager.UserMgr.Create(ctx, &usermodels.User{
+		Name: "maintainer",
+	})
+	assert.Nil(t, err)
+	_, err = manager.MemberMgr.Create(ctx, &membermodels.Member{
+		ResourceType: "applications",
+		ResourceID:   application.ID,
+		Role:         "maintainer",
+		MemberType:   membermodels.MemberUser,
+		MemberNameID: maintainer.ID,
+	})
+	assert.Nil(t, err)
+
+	maintainerCtx := context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		ID: maintainer.ID,
+	})
+	explanation, err := c.Explain(maintainerCtx, &ExplainRequest{
+		API: API{
+			URL:    fmt.Sprintf("/apis/core/v1/clusters/%d/deploy", cluster.ID),
+			Method: "POST",
+		},
+	})
+	assert.Nil(t, err)
+	assert.True(t, explanation.Allowed)
+	assert.Equal(t, "maintainer", explanation.Role)
+	assert.Equal(t, "applications", explanation.Member.ResourceType)
+	assert.Equal(t, 2, explanation.RuleIndex)
+	assert.Contains(t, explanation.Rule.Resources, "clusters/deploy")
+	assert.Equal(t, []Resource{
+		{ResourceType: "groups", ResourceID: 0},
+		{ResourceType: "groups", ResourceID: group.ID},
+		{ResourceType: "applications", ResourceID: application.ID},
+		{ResourceType: "clusters", ResourceID: cluster.ID},
+	}, explanation.Chain)
+
+	explanation, err = c.Explain(maintainerCtx, &ExplainRequest{
+		API: API{
+			URL:    fmt.Sprintf("/apis/core/v1/clusters/%d", cluster.ID),
+			Method: "DELETE",
+		},
+	})
+	assert.Nil(t, err)
+	assert.False(t, explanation.Allowed)
+	assert.Equal(t, -1, explanation.RuleIndex)
+	assert.Nil(t, explanation.Rule)
+
+	// only admins can explain for others
+	_, err = c.Explain(maintainerCtx, &ExplainRequest{
+		API: API{
+			URL:    fmt.Sprintf("/apis/core/v1/clusters/%d", cluster.ID),
+			Method: "GET",
+		},
+		UserID: maintainer.ID + 1,
+	})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	adminCtx := context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		ID:    maintainer.ID + 1,
+		Admin: true,
+	})
+	explanation, err = c.Explain(adminCtx, &ExplainRequest{
+		API: API{
+			URL:    fmt.Sprintf("/apis/core/v1/clusters/%d/deploy", cluster.ID),
+			Method: "POST",
+		},
+		UserID: maintainer.ID,
+	})
+	assert.Nil(t, err)
+	assert.True(t, explanation.Allowed)
+	assert.Equal(t, "maintainer", explanation.Role)
+}
+
 const roleConfig = `RolePriorityRankDesc:
   - pe
   - owner
@@ -14,6 +14,12 @@
 
 package access
 
+import (
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+)
+
 type API struct {
 	URL    string `json:"url"`
 	Method string `json:"method"`
@@ -28,3 +34,71 @@ type ReviewResult struct {
 type ReviewRequest struct {
 	APIs []API `json:"apis"`
 }
+
+type ExplainRequest struct {
+	API
+	// UserID is the user to explain for, it's the current user by default,
+	// only admins can explain for others
+	UserID uint `json:"userID"`
+	// AccessTokenID is the access token to explain for, scopes of the token are checked as well
+	AccessTokenID uint `json:"accessTokenID"`
+}
+
+type Explanation struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason"`
+	// Skipped means the api is not checked by the authorizer
+	Skipped bool         `json:"skipped"`
+	Scope   *ScopeReview `json:"scope,omitempty"`
+	Member  *Member      `json:"member,omitempty"`
+	// Chain is the resources which the member is inherited from, from the root group down to the resource
+	Chain     []Resource        `json:"chain,omitempty"`
+	Role      string            `json:"role,omitempty"`
+	RuleIndex int               `json:"ruleIndex"`
+	Rule      *types.PolicyRule `json:"rule,omitempty"`
+}
+
+// ScopeReview is the result of checking scopes of access token
+type ScopeReview struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason"`
+}
+
+type Member struct {
+	ID           uint   `json:"id"`
+	ResourceType string `json:"resourceType"`
+	ResourceID   uint   `json:"resourceID"`
+	Role         string `json:"role"`
+	MemberType   uint8  `json:"memberType"`
+	MemberNameID uint   `json:"memberNameID"`
+}
+
+type Resource struct {
+	ResourceType string `json:"resourceType"`
+	ResourceID   uint   `json:"resourceID"`
+}
+
+func ofMember(member *membermodels.Member) *Member {
+	if member == nil {
+		return nil
+	}
+	return &Member{
+		ID:           member.ID,
+		ResourceType: string(member.ResourceType),
+		ResourceID:   member.ResourceID,
+		Role:         member.Role,
+		MemberType:   uint8(member.MemberType),
+		MemberNameID: member.MemberNameID,
+	}
+}
+
+func ofChain(chain []memberservice.Resource) []Resource {
+	resources := make([]Resource, 0, len(chain))
+	for _, resource := range chain {
+		resources = append(resources, Resource{
+			ResourceType: resource.ResourceType,
+			ResourceID:   resource.ResourceID,
+		})
+	}
+	return resources
+}
@@ -0,0 +1,302 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admissionpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "gopkg.in/evanphx/json-patch.v5"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/admission"
+	admissionmodels "github.com/horizoncd/horizon/pkg/admission/models"
+	policymanager "github.com/horizoncd/horizon/pkg/admissionpolicy/manager"
+	"github.com/horizoncd/horizon/pkg/admissionpolicy/models"
+	config "github.com/horizoncd/horizon/pkg/config/admission"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	Create(ctx context.Context, request *CreateOrUpdateRequest) (*AdmissionPolicy, error)
+	List(ctx context.Context) ([]*AdmissionPolicy, error)
+	Get(ctx context.Context, id uint) (*AdmissionPolicy, error)
+	Update(ctx context.Context, id uint, request *CreateOrUpdateRequest) (*AdmissionPolicy, error)
+	Delete(ctx context.Context, id uint) error
+	// Evaluate evaluates the policy against the admission request without saving the policy
+	Evaluate(ctx context.Context, request *EvaluateRequest) (*EvaluateResponse, error)
+	// EvaluateByID evaluates the saved policy against the admission request
+	EvaluateByID(ctx context.Context, id uint, request *admission.Request) (*EvaluateResponse, error)
+}
+
+type controller struct {
+	policyMgr policymanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		policyMgr: param.AdmissionPolicyMgr,
+	}
+}
+
+// checkAdmin checks the current user is an admin, admission policies apply to all the resources
+func checkAdmin(ctx context.Context) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !currentUser.IsAdmin() {
+		return perror.Wrap(herrors.ErrForbidden, "only admins can manage admission policies")
+	}
+	return nil
+}
+
+// setPolicy validates the request and sets it to the policy
+func setPolicy(policy *models.AdmissionPolicy, request *CreateOrUpdateRequest) (*admission.CompiledPolicy, error) {
+	if request.Name == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "name of admission policy is empty")
+	}
+	rules, err := json.Marshal(request.Rules)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	policy.Name = request.Name
+	policy.Description = request.Description
+	policy.Kind = admissionmodels.Kind(request.Kind).String()
+	policy.Language = request.Language
+	if policy.Language == "" {
+		policy.Language = models.LanguageCEL
+	}
+	policy.Rules = string(rules)
+	policy.Expression = request.Expression
+	policy.Message = request.Message
+	policy.FailurePolicy = request.FailurePolicy
+	if policy.FailurePolicy == "" {
+		policy.FailurePolicy = string(config.FailurePolicyFail)
+	}
+	policy.DryRun = request.DryRun
+	return admission.CompilePolicy(policy)
+}
+
+func (c *controller) checkName(ctx context.Context, policy *models.AdmissionPolicy) error {
+	existed, err := c.policyMgr.GetByName(ctx, policy.Name)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil
+		}
+		return err
+	}
+	if existed.ID != policy.ID {
+		return perror.Wrapf(herrors.ErrNameConflict, "admission policy %s already exists", policy.Name)
+	}
+	return nil
+}
+
+func (c *controller) Create(ctx context.Context, request *CreateOrUpdateRequest) (*AdmissionPolicy, error) {
+	const op = "admission policy controller: create"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policy := &models.AdmissionPolicy{
+		CreatedBy: currentUser.GetID(),
+		UpdatedBy: currentUser.GetID(),
+	}
+	if _, err := setPolicy(policy, request); err != nil {
+		return nil, err
+	}
+	if err := c.checkName(ctx, policy); err != nil {
+		return nil, err
+	}
+	policy, err = c.policyMgr.Create(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	return ofAdmissionPolicy(policy), nil
+}
+
+func (c *controller) List(ctx context.Context) ([]*AdmissionPolicy, error) {
+	const op = "admission policy controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	policies, err := c.policyMgr.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*AdmissionPolicy, 0, len(policies))
+	for _, policy := range policies {
+		result = append(result, ofAdmissionPolicy(policy))
+	}
+	return result, nil
+}
+
+func (c *controller) Get(ctx context.Context, id uint) (*AdmissionPolicy, error) {
+	const op = "admission policy controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	policy, err := c.policyMgr.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ofAdmissionPolicy(policy), nil
+}
+
+func (c *controller) Update(ctx context.Context, id uint,
+	request *CreateOrUpdateRequest) (*AdmissionPolicy, error) {
+	const op = "admission policy controller: update"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := c.policyMgr.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := setPolicy(policy, request); err != nil {
+		return nil, err
+	}
+	if err := c.checkName(ctx, policy); err != nil {
+		return nil, err
+	}
+	policy.UpdatedBy = currentUser.GetID()
+	policy, err = c.policyMgr.Update(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	return ofAdmissionPolicy(policy), nil
+}
+
+func (c *controller) Delete(ctx context.Context, id uint) error {
+	const op = "admission policy controller: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+	if _, err := c.policyMgr.Get(ctx, id); err != nil {
+		return err
+	}
+	return c.policyMgr.Delete(ctx, id)
+}
+
+func (c *controller) Evaluate(ctx context.Context, request *EvaluateRequest) (*EvaluateResponse, error) {
+	const op = "admission policy controller: evaluate"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if request.Policy == nil || request.Request == nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "policy and request are required")
+	}
+	compiled, err := setPolicy(&models.AdmissionPolicy{}, request.Policy)
+	if err != nil {
+		return nil, err
+	}
+	return evaluate(ctx, compiled, request.Request)
+}
+
+func (c *controller) EvaluateByID(ctx context.Context, id uint,
+	request *admission.Request) (*EvaluateResponse, error) {
+	const op = "admission policy controller: evaluate by id"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if request == nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "request is required")
+	}
+	policy, err := c.policyMgr.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	compiled, err := admission.CompilePolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	return evaluate(ctx, compiled, request)
+}
+
+// evaluate evaluates the policy as the webhook does, but the errors are returned in the response
+func evaluate(ctx context.Context, policy *admission.CompiledPolicy,
+	request *admission.Request) (*EvaluateResponse, error) {
+	if !policy.Match(request) {
+		return &EvaluateResponse{Matched: false, Allowed: true, Object: request.Object}, nil
+	}
+	result := &EvaluateResponse{Matched: true, Object: request.Object}
+	response, err := policy.Evaluate(ctx, request)
+	if err != nil {
+		result.Allowed = policy.IgnoreError()
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.Allowed = *response.Allowed
+	result.Result = response.Result
+	if response.Patch == nil {
+		return result, nil
+	}
+	result.Patch = response.Patch
+	object, err := applyPatch(request.Object, response.Patch)
+	if err != nil {
+		result.Allowed = policy.IgnoreError()
+		result.Error = fmt.Sprintf("failed to apply patch: %v", err)
+		return result, nil
+	}
+	result.Object = object
+	return result, nil
+}
+
+func applyPatch(object interface{}, patchJSON []byte) (interface{}, error) {
+	objectJSON, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := jsonpatch.DecodePatch(patchJSON)
+	if err != nil {
+		return nil, err
+	}
+	patched, err := patch.Apply(objectJSON)
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	if err := json.Unmarshal(patched, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
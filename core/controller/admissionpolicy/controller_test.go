@@ -0,0 +1,134 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admissionpolicy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/admission"
+	admissionmodels "github.com/horizoncd/horizon/pkg/admission/models"
+	"github.com/horizoncd/horizon/pkg/admissionpolicy/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	config "github.com/horizoncd/horizon/pkg/config/admission"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+)
+
+func TestController(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&models.AdmissionPolicy{}))
+	manager := managerparam.InitManager(db)
+	c := NewController(&param.Param{Manager: manager})
+
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "Tony", ID: 1})
+	adminCtx := common.WithContext(context.Background(),
+		&userauth.DefaultInfo{Name: "admin", ID: 2, Admin: true})
+
+	request := &CreateOrUpdateRequest{
+		Name: "no latest",
+		Kind: "Validating",
+		Rules: []config.Rule{
+			{
+				Resources:  []string{"applications/clusters", "clusters"},
+				Operations: []admissionmodels.Operation{admissionmodels.OperationCreate},
+				Versions:   []string{admissionmodels.MatchAll},
+			},
+		},
+		Expression: `!object.image.endsWith(":latest")`,
+		Message:    "image with the latest tag is not allowed",
+	}
+	_, err := c.Create(ctx, request)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	policy, err := c.Create(adminCtx, request)
+	assert.Nil(t, err)
+	assert.Equal(t, admissionmodels.KindValidating.String(), policy.Kind)
+	assert.Equal(t, models.LanguageCEL, policy.Language)
+	assert.Equal(t, string(config.FailurePolicyFail), policy.FailurePolicy)
+	assert.Equal(t, request.Rules, policy.Rules)
+	assert.Equal(t, uint(2), policy.CreatedBy)
+
+	_, err = c.Create(adminCtx, request)
+	assert.Equal(t, herrors.ErrNameConflict, perror.Cause(err))
+	_, err = c.Create(adminCtx, &CreateOrUpdateRequest{
+		Name:       "invalid",
+		Kind:       admissionmodels.KindValidating.String(),
+		Rules:      request.Rules,
+		Expression: `object.image.endsWith(`,
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	clusterRequest := &admission.Request{
+		Operation:   admissionmodels.OperationCreate,
+		Resource:    "applications",
+		SubResource: "clusters",
+		Version:     "v2",
+		Object:      map[string]interface{}{"image": "nginx:latest"},
+	}
+	result, err := c.EvaluateByID(adminCtx, policy.ID, clusterRequest)
+	assert.Nil(t, err)
+	assert.True(t, result.Matched)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, "denied by admission policy no latest: image with the latest tag is not allowed", result.Result)
+
+	// evaluate a policy not saved
+	result, err = c.Evaluate(adminCtx, &EvaluateRequest{
+		Policy: &CreateOrUpdateRequest{
+			Name:       "pin image",
+			Kind:       admissionmodels.KindMutating.String(),
+			Rules:      request.Rules,
+			Expression: `object.image.endsWith(":latest") ? [{"op": "replace", "path": "/image", "value": "nginx:1.25"}] : []`,
+		},
+		Request: clusterRequest,
+	})
+	assert.Nil(t, err)
+	assert.True(t, result.Matched)
+	assert.True(t, result.Allowed)
+	assert.JSONEq(t, `[{"op": "replace", "path": "/image", "value": "nginx:1.25"}]`, string(result.Patch))
+	assert.Equal(t, map[string]interface{}{"image": "nginx:1.25"}, result.Object)
+
+	// errors of evaluation are returned in the result
+	result, err = c.EvaluateByID(adminCtx, policy.ID, &admission.Request{
+		Operation: admissionmodels.OperationCreate,
+		Resource:  "clusters",
+		Version:   "v2",
+		Object:    map[string]interface{}{},
+	})
+	assert.Nil(t, err)
+	assert.False(t, result.Allowed)
+	assert.NotEmpty(t, result.Error)
+
+	request.FailurePolicy = string(config.FailurePolicyIgnore)
+	request.DryRun = true
+	policy, err = c.Update(adminCtx, policy.ID, request)
+	assert.Nil(t, err)
+	assert.True(t, policy.DryRun)
+	assert.Equal(t, string(config.FailurePolicyIgnore), policy.FailurePolicy)
+
+	policies, err := c.List(adminCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(policies))
+
+	assert.Nil(t, c.Delete(adminCtx, policy.ID))
+	_, err = c.Get(adminCtx, policy.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
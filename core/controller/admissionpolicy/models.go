@@ -0,0 +1,103 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admissionpolicy
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/admission"
+	"github.com/horizoncd/horizon/pkg/admissionpolicy/models"
+	config "github.com/horizoncd/horizon/pkg/config/admission"
+)
+
+type CreateOrUpdateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Kind is validating or mutating
+	Kind string `json:"kind"`
+	// Language of the expression, only cel is supported for now, default to cel
+	Language string        `json:"language"`
+	Rules    []config.Rule `json:"rules"`
+	// Expression of a validating policy returns a bool,
+	// such as `object.image.endsWith(":latest") == false`.
+	// Expression of a mutating policy returns a list of json patch operations,
+	// such as `has(object.replicas) ? [] : [{"op": "add", "path": "/replicas", "value": 1}]`.
+	Expression string `json:"expression"`
+	// Message is returned when the request is denied by a validating policy
+	Message string `json:"message"`
+	// FailurePolicy is ignore or fail, default to fail
+	FailurePolicy string `json:"failurePolicy"`
+	// DryRun policies are evaluated and logged, but never deny or mutate requests
+	DryRun bool `json:"dryRun"`
+}
+
+type AdmissionPolicy struct {
+	ID            uint          `json:"id"`
+	Name          string        `json:"name"`
+	Description   string        `json:"description"`
+	Kind          string        `json:"kind"`
+	Language      string        `json:"language"`
+	Rules         []config.Rule `json:"rules"`
+	Expression    string        `json:"expression"`
+	Message       string        `json:"message"`
+	FailurePolicy string        `json:"failurePolicy"`
+	DryRun        bool          `json:"dryRun"`
+	CreatedAt     time.Time     `json:"createdAt"`
+	UpdatedAt     time.Time     `json:"updatedAt"`
+	CreatedBy     uint          `json:"createdBy"`
+	UpdatedBy     uint          `json:"updatedBy"`
+}
+
+type EvaluateRequest struct {
+	// Policy to evaluate, which is not required to be saved
+	Policy  *CreateOrUpdateRequest `json:"policy"`
+	Request *admission.Request     `json:"request"`
+}
+
+type EvaluateResponse struct {
+	// Matched is false if the request does not match the rules of the policy
+	Matched bool   `json:"matched"`
+	Allowed bool   `json:"allowed"`
+	Result  string `json:"result,omitempty"`
+	// Patch is the json patch returned by a mutating policy
+	Patch json.RawMessage `json:"patch,omitempty"`
+	// Object is the object after patched
+	Object interface{} `json:"object,omitempty"`
+	// Error is the error when evaluating the policy
+	Error string `json:"error,omitempty"`
+}
+
+func ofAdmissionPolicy(policy *models.AdmissionPolicy) *AdmissionPolicy {
+	// rules are validated when saved, so the error can be ignored
+	var rules []config.Rule
+	_ = json.Unmarshal([]byte(policy.Rules), &rules)
+	return &AdmissionPolicy{
+		ID:            policy.ID,
+		Name:          policy.Name,
+		Description:   policy.Description,
+		Kind:          policy.Kind,
+		Language:      policy.Language,
+		Rules:         rules,
+		Expression:    policy.Expression,
+		Message:       policy.Message,
+		FailurePolicy: policy.FailurePolicy,
+		DryRun:        policy.DryRun,
+		CreatedAt:     policy.CreatedAt,
+		UpdatedAt:     policy.UpdatedAt,
+		CreatedBy:     policy.CreatedBy,
+		UpdatedBy:     policy.UpdatedBy,
+	}
+}
@@ -18,12 +18,15 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/controller/build"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/admission"
+	admissionmodels "github.com/horizoncd/horizon/pkg/admission/models"
 	"github.com/horizoncd/horizon/pkg/application/gitrepo"
 	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	"github.com/horizoncd/horizon/pkg/application/models"
@@ -594,6 +597,16 @@ func (c *controller) DeleteApplication(ctx context.Context, id uint, hard bool)
 			"because there are clusters under this application.")
 	}
 
+	if err := admission.Admit(ctx, &admission.Request{
+		Operation: admissionmodels.OperationDelete,
+		Resource:  common.ResourceApplication,
+		Name:      strconv.FormatUint(uint64(app.ID), 10),
+		OldObject: admission.NewApplicationObject(app),
+		Options:   map[string]interface{}{"hard": hard},
+	}); err != nil {
+		return err
+	}
+
 	// 2. delete application in git repo
 	if hard {
 		// delete member
@@ -0,0 +1,270 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	Create(ctx context.Context, resourceType string, resourceID uint,
+		request *CreateOrUpdateRequest) (*Check, error)
+	List(ctx context.Context, resourceType string, resourceID uint) ([]*Check, error)
+	Get(ctx context.Context, resourceType string, resourceID uint, id uint) (*Check, error)
+	Update(ctx context.Context, resourceType string, resourceID uint, id uint,
+		request *CreateOrUpdateRequest) (*Check, error)
+	Delete(ctx context.Context, resourceType string, resourceID uint, id uint) error
+}
+
+type controller struct {
+	prMgr          *prmanager.PRManager
+	envMgr         envmanager.Manager
+	groupMgr       groupmanager.Manager
+	applicationMgr appmanager.Manager
+	clusterMgr     clustermanager.Manager
+	userMgr        usermanager.Manager
+	roleSvc        role.Service
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		prMgr:          param.PRMgr,
+		envMgr:         param.EnvMgr,
+		groupMgr:       param.GroupMgr,
+		applicationMgr: param.ApplicationMgr,
+		clusterMgr:     param.ClusterMgr,
+		userMgr:        param.UserMgr,
+		roleSvc:        param.RoleService,
+	}
+}
+
+// checkResource checks the resource exists, the checks of the root group are managed in the database by admins
+func (c *controller) checkResource(ctx context.Context, resourceType string, resourceID uint) error {
+	switch resourceType {
+	case common.ResourceGroup:
+		_, err := c.groupMgr.GetByID(ctx, resourceID)
+		return err
+	case common.ResourceApplication:
+		_, err := c.applicationMgr.GetByID(ctx, resourceID)
+		return err
+	case common.ResourceCluster:
+		_, err := c.clusterMgr.GetByID(ctx, resourceID)
+		return err
+	default:
+		return perror.Wrapf(herrors.ErrParamInvalid, "unsupported resource type: %s", resourceType)
+	}
+}
+
+// get gets the check and checks it's attached to the resource
+func (c *controller) get(ctx context.Context, resourceType string,
+	resourceID uint, id uint) (*prmodels.Check, error) {
+	check, err := c.prMgr.Check.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if check.Type != resourceType || check.ResourceID != resourceID {
+		return nil, herrors.NewErrNotFound(herrors.CheckInDB,
+			fmt.Sprintf("check %d not found in %s %d", id, resourceType, resourceID))
+	}
+	return check, nil
+}
+
+// validate validates the request and sets it to check
+func (c *controller) validate(ctx context.Context, check *prmodels.Check, request *CreateOrUpdateRequest) error {
+	if request.Environment != "" {
+		if _, err := c.envMgr.GetByName(ctx, request.Environment); err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				return perror.Wrapf(herrors.ErrParamInvalid, "environment %s not found", request.Environment)
+			}
+			return err
+		}
+	}
+	check.Kind = request.Kind
+	check.Environment = request.Environment
+	check.Approval = ""
+
+	switch request.Kind {
+	case "":
+		if request.Approval != nil {
+			return perror.Wrap(herrors.ErrParamInvalid, "approval policy is only for approval checks")
+		}
+		return nil
+	case prmodels.CheckKindApproval:
+	default:
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid kind of check: %s", request.Kind)
+	}
+
+	policy := request.Approval
+	if policy == nil || policy.Required == 0 {
+		return perror.Wrap(herrors.ErrParamInvalid, "approval checks require at least one approval")
+	}
+	if len(policy.Roles) == 0 && len(policy.Users) == 0 {
+		return perror.Wrap(herrors.ErrParamInvalid, "approvers of approval checks are empty")
+	}
+	for _, r := range policy.Roles {
+		if _, err := c.roleSvc.GetRole(ctx, r); err != nil {
+			return perror.Wrapf(herrors.ErrParamInvalid, "role %s not found", r)
+		}
+	}
+	if len(policy.Users) > 0 {
+		users, err := c.userMgr.GetUserMapByIDs(ctx, policy.Users)
+		if err != nil {
+			return err
+		}
+		for _, id := range policy.Users {
+			if _, ok := users[id]; !ok {
+				return perror.Wrapf(herrors.ErrParamInvalid, "user %d not found", id)
+			}
+		}
+		if len(policy.Roles) == 0 && int(policy.Required) > len(users) {
+			return perror.Wrapf(herrors.ErrParamInvalid,
+				"%d approvals are required, but there are only %d approvers", policy.Required, len(users))
+		}
+	}
+	approval, err := json.Marshal(policy)
+	if err != nil {
+		return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	check.Approval = string(approval)
+	return nil
+}
+
+// checkDuplicate checks there is no other check of the same kind and environment on the resource
+func (c *controller) checkDuplicate(ctx context.Context, check *prmodels.Check) error {
+	checks, err := c.prMgr.Check.GetByResource(ctx, check.Resource)
+	if err != nil {
+		return err
+	}
+	for _, other := range checks {
+		if other.ID != check.ID && other.Kind == check.Kind && other.Environment == check.Environment {
+			return perror.Wrapf(herrors.ErrParamInvalid,
+				"check %d of the same kind and environment already exists", other.ID)
+		}
+	}
+	return nil
+}
+
+func (c *controller) Create(ctx context.Context, resourceType string, resourceID uint,
+	request *CreateOrUpdateRequest) (*Check, error) {
+	const op = "check controller: create"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := c.checkResource(ctx, resourceType, resourceID); err != nil {
+		return nil, err
+	}
+	check := &prmodels.Check{
+		Resource: common.Resource{
+			ResourceID: resourceID,
+			Type:       resourceType,
+		},
+	}
+	if err := c.validate(ctx, check, request); err != nil {
+		return nil, err
+	}
+	if err := c.checkDuplicate(ctx, check); err != nil {
+		return nil, err
+	}
+	check, err := c.prMgr.Check.Create(ctx, check)
+	if err != nil {
+		return nil, err
+	}
+	return ofCheck(check)
+}
+
+func (c *controller) List(ctx context.Context, resourceType string, resourceID uint) ([]*Check, error) {
+	const op = "check controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := c.checkResource(ctx, resourceType, resourceID); err != nil {
+		return nil, err
+	}
+	checks, err := c.prMgr.Check.GetByResource(ctx, common.Resource{
+		ResourceID: resourceID,
+		Type:       resourceType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Check, 0, len(checks))
+	for _, check := range checks {
+		item, err := ofCheck(check)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+func (c *controller) Get(ctx context.Context, resourceType string, resourceID uint, id uint) (*Check, error) {
+	const op = "check controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	check, err := c.get(ctx, resourceType, resourceID, id)
+	if err != nil {
+		return nil, err
+	}
+	return ofCheck(check)
+}
+
+func (c *controller) Update(ctx context.Context, resourceType string, resourceID uint, id uint,
+	request *CreateOrUpdateRequest) (*Check, error) {
+	const op = "check controller: update"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	check, err := c.get(ctx, resourceType, resourceID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.validate(ctx, check, request); err != nil {
+		return nil, err
+	}
+	if err := c.checkDuplicate(ctx, check); err != nil {
+		return nil, err
+	}
+	check, err = c.prMgr.Check.Update(ctx, check)
+	if err != nil {
+		return nil, err
+	}
+	return ofCheck(check)
+}
+
+func (c *controller) Delete(ctx context.Context, resourceType string, resourceID uint, id uint) error {
+	const op = "check controller: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.get(ctx, resourceType, resourceID, id); err != nil {
+		return err
+	}
+	return c.prMgr.Check.Delete(ctx, id)
+}
@@ -0,0 +1,119 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	rolemock "github.com/horizoncd/horizon/mock/pkg/rbac/role"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+func TestController(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&prmodels.Check{}, &envmodels.Environment{},
+		&groupmodels.Group{}, &appmodels.Application{}, &usermodels.User{}))
+	manager := managerparam.InitManager(db)
+
+	mockCtl := gomock.NewController(t)
+	roleSvc := rolemock.NewMockService(mockCtl)
+	roleSvc.EXPECT().GetRole(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, name string) (*types.Role, error) {
+			if name == role.Owner {
+				return &types.Role{Name: name}, nil
+			}
+			return nil, errors.New("role not found")
+		}).AnyTimes()
+	c := NewController(&param.Param{Manager: manager, RoleService: roleSvc})
+
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "Tony", ID: 1})
+	user, err := manager.UserMgr.Create(ctx, &usermodels.User{Name: "Tony"})
+	assert.Nil(t, err)
+	env, err := manager.EnvMgr.CreateEnvironment(ctx, &envmodels.Environment{Name: "online"})
+	assert.Nil(t, err)
+	group := &groupmodels.Group{Name: "group", Path: "group"}
+	assert.Nil(t, db.Create(group).Error)
+
+	request := &CreateOrUpdateRequest{
+		Kind:        prmodels.CheckKindApproval,
+		Environment: env.Name,
+		Approval: &prmodels.ApprovalPolicy{
+			Required: 1,
+			Roles:    []string{role.Owner},
+		},
+	}
+	check, err := c.Create(ctx, common.ResourceGroup, group.ID, request)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), check.Approval.Required)
+	assert.Equal(t, []string{role.Owner}, check.Approval.Roles)
+
+	// only one approval check per environment
+	_, err = c.Create(ctx, common.ResourceGroup, group.ID, request)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	// external checks are not limited by approval checks
+	_, err = c.Create(ctx, common.ResourceGroup, group.ID, &CreateOrUpdateRequest{})
+	assert.Nil(t, err)
+
+	for _, invalid := range []*CreateOrUpdateRequest{
+		{Kind: "unknown"},
+		{Kind: prmodels.CheckKindApproval, Environment: "nonexistent",
+			Approval: &prmodels.ApprovalPolicy{Required: 1, Roles: []string{role.Owner}}},
+		{Kind: prmodels.CheckKindApproval},
+		{Kind: prmodels.CheckKindApproval, Approval: &prmodels.ApprovalPolicy{Required: 1}},
+		{Kind: prmodels.CheckKindApproval, Approval: &prmodels.ApprovalPolicy{Required: 1, Roles: []string{"nobody"}}},
+		{Kind: prmodels.CheckKindApproval, Approval: &prmodels.ApprovalPolicy{Required: 1, Users: []uint{100}}},
+		{Kind: prmodels.CheckKindApproval, Approval: &prmodels.ApprovalPolicy{Required: 2, Users: []uint{user.ID}}},
+		{Approval: &prmodels.ApprovalPolicy{Required: 1, Roles: []string{role.Owner}}},
+	} {
+		_, err = c.Create(ctx, common.ResourceGroup, group.ID, invalid)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	}
+	_, err = c.Create(ctx, common.ResourceEnvironment, env.ID, request)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	checks, err := c.List(ctx, common.ResourceGroup, group.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(checks))
+
+	request.Environment = ""
+	request.Approval = &prmodels.ApprovalPolicy{Required: 1, Users: []uint{user.ID}}
+	check, err = c.Update(ctx, common.ResourceGroup, group.ID, check.ID, request)
+	assert.Nil(t, err)
+	assert.Equal(t, "", check.Environment)
+	assert.Equal(t, []uint{user.ID}, check.Approval.Users)
+
+	assert.Nil(t, c.Delete(ctx, common.ResourceGroup, group.ID, check.ID))
+	_, err = c.Get(ctx, common.ResourceGroup, group.ID, check.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
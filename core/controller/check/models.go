@@ -0,0 +1,62 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"time"
+
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	prservice "github.com/horizoncd/horizon/pkg/pr/service"
+)
+
+type CreateOrUpdateRequest struct {
+	// Kind is empty for checks of external systems, or approval
+	Kind string `json:"kind"`
+	// Environment limits the check to clusters of the environment, empty means all the environments
+	Environment string `json:"environment"`
+	// Approval is required for approval checks
+	Approval *prmodels.ApprovalPolicy `json:"approval,omitempty"`
+}
+
+type Check struct {
+	ID           uint                     `json:"id"`
+	ResourceType string                   `json:"resourceType"`
+	ResourceID   uint                     `json:"resourceID"`
+	Kind         string                   `json:"kind"`
+	Environment  string                   `json:"environment"`
+	Approval     *prmodels.ApprovalPolicy `json:"approval,omitempty"`
+	CreatedAt    time.Time                `json:"createdAt"`
+	UpdatedAt    time.Time                `json:"updatedAt"`
+}
+
+func ofCheck(check *prmodels.Check) (*Check, error) {
+	result := &Check{
+		ID:           check.ID,
+		ResourceType: check.Type,
+		ResourceID:   check.ResourceID,
+		Kind:         check.Kind,
+		Environment:  check.Environment,
+		CreatedAt:    check.CreatedAt,
+		UpdatedAt:    check.UpdatedAt,
+	}
+	if check.Kind == prmodels.CheckKindApproval {
+		policy, err := prservice.ParseApprovalPolicy(check)
+		if err != nil {
+			return nil, err
+		}
+		result.Approval = policy
+	}
+	return result, nil
+}
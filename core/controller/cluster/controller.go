@@ -26,6 +26,7 @@ import (
 
 	"github.com/horizoncd/horizon/core/config"
 	"github.com/horizoncd/horizon/core/controller/build"
+	"github.com/horizoncd/horizon/core/controller/oauthcheck"
 	"github.com/horizoncd/horizon/lib/q"
 	appgitrepo "github.com/horizoncd/horizon/pkg/application/gitrepo"
 	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
@@ -40,6 +41,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/config/grafana"
 	"github.com/horizoncd/horizon/pkg/config/template"
 	"github.com/horizoncd/horizon/pkg/config/token"
+	deploywindowservice "github.com/horizoncd/horizon/pkg/deploywindow/service"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	environmentregionmapper "github.com/horizoncd/horizon/pkg/environmentregion/manager"
@@ -52,6 +54,7 @@ import (
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
 	pipelinemanager "github.com/horizoncd/horizon/pkg/pr/pipeline/manager"
 	prservice "github.com/horizoncd/horizon/pkg/pr/service"
+	"github.com/horizoncd/horizon/pkg/rbac"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
@@ -80,7 +83,7 @@ type Controller interface {
 
 	BuildDeploy(ctx context.Context, clusterID uint,
 		request *BuildDeployRequest) (*BuildDeployResponse, error)
-	Restart(ctx context.Context, clusterID uint) (*PipelinerunIDResponse, error)
+	Restart(ctx context.Context, clusterID uint, r *RestartRequest) (*PipelinerunIDResponse, error)
 	Deploy(ctx context.Context, clusterID uint, request *DeployRequest) (*PipelinerunIDResponse, error)
 	Rollback(ctx context.Context, clusterID uint, request *RollbackRequest) (*PipelinerunIDResponse, error)
 
@@ -102,6 +105,8 @@ type Controller interface {
 	Exec(ctx context.Context, clusterID uint, r *ExecRequest) (_ ExecResponse, err error)
 
 	GetDiff(ctx context.Context, clusterID uint, refType, ref string) (*GetDiffResponse, error)
+	// ListImages lists images pushed to the registry for the cluster
+	ListImages(ctx context.Context, clusterID uint) ([]*Image, error)
 	GetContainerLog(ctx context.Context, clusterID uint, podName, containerName string, tailLines int64) (
 		<-chan string, error)
 
@@ -124,10 +129,22 @@ type Controller interface {
 	GetClusterPipelinerunStatus(ctx context.Context, clusterID uint) (*PipelinerunStatusResponse, error)
 	GetResourceTree(ctx context.Context, clusterID uint) (*GetResourceTreeResponse, error)
 	GetStep(ctx context.Context, clusterID uint) (resp *GetStepResponse, err error)
+	// ListAnalysisRuns lists analysis runs of the current step in progressive delivery
+	ListAnalysisRuns(ctx context.Context, clusterID uint) ([]cd.AnalysisRun, error)
 	// Deprecated: for internal usage, v1 to v2
 	Upgrade(ctx context.Context, clusterID uint) error
 	ToggleLikeStatus(ctx context.Context, clusterID uint, like *WhetherLike) (err error)
 	CreatePipelineRun(ctx context.Context, clusterID uint, r *CreatePipelineRunRequest) (*prmodels.PipelineBasic, error)
+
+	// PreviewCloneCluster returns the values of the cluster to be cloned without creating it
+	PreviewCloneCluster(ctx context.Context, clusterID uint,
+		r *CloneClusterRequest) (*CloneClusterPreviewResponse, error)
+	// CloneCluster creates a cluster with the values copied from the cluster and the overrides in request
+	CloneCluster(ctx context.Context, clusterID uint, r *CloneClusterRequest) (*CreateClusterResponseV2, error)
+	// PromoteConfig copies the configs from the source clusters to the target clusters of the application,
+	// only the diffs are returned in preview
+	PromoteConfig(ctx context.Context, applicationID uint,
+		r *PromoteConfigRequest, preview bool) (*PromoteConfigResponse, error)
 }
 
 type controller struct {
@@ -169,6 +186,9 @@ type controller struct {
 	templateUpgradeMapper template.UpgradeMapper
 	collectionManager     collectionmanager.Manager
 	clusterSvc            clusterservice.Service
+	deployWindowSvc       deploywindowservice.Service
+	authorizer            rbac.Authorizer
+	oauthChecker          oauthcheck.Controller
 }
 
 var _ Controller = (*controller)(nil)
@@ -213,5 +233,8 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		templateUpgradeMapper: config.TemplateUpgradeMapper,
 		collectionManager:     param.CollectionMgr,
 		clusterSvc:            param.ClusterSvc,
+		deployWindowSvc:       param.DeployWindowSvc,
+		authorizer:            rbac.NewAuthorizer(param.RoleService, param.MemberService),
+		oauthChecker:          oauthcheck.NewOauthChecker(param),
 	}
 }
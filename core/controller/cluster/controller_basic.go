@@ -27,6 +27,7 @@ import (
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/core/middleware/requestid"
 	"github.com/horizoncd/horizon/lib/q"
+	admissionmodels "github.com/horizoncd/horizon/pkg/admission/models"
 	"github.com/horizoncd/horizon/pkg/application/models"
 	"github.com/horizoncd/horizon/pkg/cd"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
@@ -809,6 +810,11 @@ func (c *controller) DeleteCluster(ctx context.Context, clusterID uint, hard boo
 		return err
 	}
 
+	if err := c.admit(ctx, admissionmodels.OperationDelete, "", application, cluster, nil,
+		map[string]interface{}{"hard": hard}); err != nil {
+		return err
+	}
+
 	// 0. set cluster status
 	cluster.Status = common.ClusterStatusDeleting
 	cluster, err = c.clusterMgr.UpdateByID(ctx, cluster.ID, cluster)
@@ -931,6 +937,14 @@ func (c *controller) FreeCluster(ctx context.Context, clusterID uint) (err error
 		return nil
 	}
 
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return err
+	}
+	if err := c.admit(ctx, admissionmodels.OperationAction, "free", application, cluster, nil, nil); err != nil {
+		return err
+	}
+
 	// 1. set cluster status
 	cluster.Status = common.ClusterStatusFreeing
 	cluster, err = c.clusterMgr.UpdateByID(ctx, cluster.ID, cluster)
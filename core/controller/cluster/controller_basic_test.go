@@ -25,6 +25,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
+	deploywindowservice "github.com/horizoncd/horizon/pkg/deploywindow/service"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 
 	"github.com/horizoncd/horizon/core/common"
@@ -99,13 +100,14 @@ func testListClusterByNameFuzzily(t *testing.T) {
 	}
 
 	c = &controller{
-		clusterMgr:     manager.ClusterMgr,
-		applicationMgr: manager.ApplicationMgr,
-		applicationSvc: applicationservice.NewService(groupservice.NewService(manager), manager),
-		groupManager:   manager.GroupMgr,
-		memberManager:  manager.MemberMgr,
-		eventSvc:       eventservice.New(manager),
-		commitGetter:   commitGetter,
+		clusterMgr:      manager.ClusterMgr,
+		applicationMgr:  manager.ApplicationMgr,
+		applicationSvc:  applicationservice.NewService(groupservice.NewService(manager), manager),
+		groupManager:    manager.GroupMgr,
+		memberManager:   manager.MemberMgr,
+		eventSvc:        eventservice.New(manager),
+		deployWindowSvc: deploywindowservice.NewService(manager, nil, eventservice.New(manager)),
+		commitGetter:    commitGetter,
 	}
 
 	resps, count, err := c.List(ctx, &q.Query{Keywords: q.KeyWords{common.ClusterQueryName: "fuzzilyCluster"}})
@@ -216,13 +218,14 @@ func testListUserClustersByNameFuzzily(t *testing.T) {
 	assert.Nil(t, err)
 
 	c = &controller{
-		clusterMgr:     manager.ClusterMgr,
-		applicationMgr: manager.ApplicationMgr,
-		applicationSvc: applicationservice.NewService(groupservice.NewService(manager), manager),
-		groupManager:   manager.GroupMgr,
-		memberManager:  manager.MemberMgr,
-		eventSvc:       eventservice.New(manager),
-		commitGetter:   commitGetter,
+		clusterMgr:      manager.ClusterMgr,
+		applicationMgr:  manager.ApplicationMgr,
+		applicationSvc:  applicationservice.NewService(groupservice.NewService(manager), manager),
+		groupManager:    manager.GroupMgr,
+		memberManager:   manager.MemberMgr,
+		eventSvc:        eventservice.New(manager),
+		deployWindowSvc: deploywindowservice.NewService(manager, nil, eventservice.New(manager)),
+		commitGetter:    commitGetter,
 	}
 
 	resps, count, err := c.List(ctx,
@@ -302,15 +305,16 @@ func testControllerFreeOrDeleteClusterFailed(t *testing.T) {
 	cd.EXPECT().DeleteCluster(gomock.Any(), gomock.Any()).Return(errors.New("test")).AnyTimes()
 
 	c = &controller{
-		cd:             cd,
-		clusterMgr:     manager.ClusterMgr,
-		applicationMgr: manager.ApplicationMgr,
-		applicationSvc: applicationservice.NewService(groupservice.NewService(manager), manager),
-		groupManager:   manager.GroupMgr,
-		envMgr:         manager.EnvMgr,
-		badgeMgr:       manager.BadgeMgr,
-		regionMgr:      manager.RegionMgr,
-		eventSvc:       eventservice.New(manager),
+		cd:              cd,
+		clusterMgr:      manager.ClusterMgr,
+		applicationMgr:  manager.ApplicationMgr,
+		applicationSvc:  applicationservice.NewService(groupservice.NewService(manager), manager),
+		groupManager:    manager.GroupMgr,
+		envMgr:          manager.EnvMgr,
+		badgeMgr:        manager.BadgeMgr,
+		regionMgr:       manager.RegionMgr,
+		eventSvc:        eventservice.New(manager),
+		deployWindowSvc: deploywindowservice.NewService(manager, nil, eventservice.New(manager)),
 	}
 
 	id, err := registrydao.NewDAO(db).Create(ctx, &registrymodels.Registry{
@@ -22,6 +22,7 @@ import (
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/controller/build"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	admissionmodels "github.com/horizoncd/horizon/pkg/admission/models"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	"github.com/horizoncd/horizon/pkg/cd"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
@@ -669,6 +670,9 @@ func (c *controller) CreatePipelineRun(ctx context.Context, clusterID uint,
 	if pipelineRun, err = c.prMgr.PipelineRun.Create(ctx, pipelineRun); err != nil {
 		return nil, err
 	}
+	if err := c.prSvc.CreateApprovalCheckRuns(ctx, pipelineRun.ID, checks); err != nil {
+		return nil, err
+	}
 
 	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourcePipelinerun, pipelineRun.ID,
 		eventmodels.PipelinerunCreated, nil)
@@ -814,6 +818,18 @@ func (c *controller) createPipelineRun(ctx context.Context, clusterID uint,
 		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported action %v", r.Action)
 	}
 
+	// admitted by the action like the deploy, build deploy, restart and rollback apis
+	if err := c.admit(ctx, admissionmodels.OperationAction, action,
+		application, cluster, r, nil); err != nil {
+		return nil, err
+	}
+
+	if action != prmodels.ActionRollback {
+		if err := c.checkDeployWindow(ctx, application, cluster, action, r.DeployWindowOverride); err != nil {
+			return nil, err
+		}
+	}
+
 	return &prmodels.Pipelinerun{
 		ClusterID:        clusterID,
 		Action:           action,
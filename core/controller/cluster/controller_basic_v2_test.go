@@ -5,17 +5,24 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	deploywindowmodels "github.com/horizoncd/horizon/pkg/deploywindow/models"
+	deploywindowservice "github.com/horizoncd/horizon/pkg/deploywindow/service"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/orm"
 	mock_code "github.com/horizoncd/horizon/mock/pkg/cluster/code"
 	mock_gitrepo "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/admission"
+	admissionmodels "github.com/horizoncd/horizon/pkg/admission/models"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	clustergitrepo "github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	"github.com/horizoncd/horizon/pkg/cluster/models"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/git"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
@@ -33,7 +40,8 @@ func TestCreatePipelineRun(t *testing.T) {
 	if err := db.AutoMigrate(&appmodels.Application{}, &models.Cluster{},
 		&regionmodels.Region{}, &membermodels.Member{}, &registrymodels.Registry{},
 		&prmodels.Pipelinerun{}, &groupmodels.Group{}, &prmodels.Check{},
-		&usermodel.User{}, &eventmodels.Event{}); err != nil {
+		&usermodel.User{}, &eventmodels.Event{}, &envmodels.Environment{},
+		&deploywindowmodels.DeployWindow{}); err != nil {
 		panic(err)
 	}
 	param := managerparam.InitManager(db)
@@ -56,14 +64,15 @@ func TestCreatePipelineRun(t *testing.T) {
 		}, nil).AnyTimes()
 
 	controller := &controller{
-		prSvc:          prservice.NewService(param),
-		prMgr:          param.PRMgr,
-		clusterMgr:     param.ClusterMgr,
-		applicationMgr: param.ApplicationMgr,
-		regionMgr:      param.RegionMgr,
-		clusterGitRepo: mockClusterGitRepo,
-		commitGetter:   mockGitGetter,
-		eventSvc:       eventservice.New(param),
+		prSvc:           prservice.NewService(param),
+		prMgr:           param.PRMgr,
+		clusterMgr:      param.ClusterMgr,
+		applicationMgr:  param.ApplicationMgr,
+		regionMgr:       param.RegionMgr,
+		clusterGitRepo:  mockClusterGitRepo,
+		commitGetter:    mockGitGetter,
+		eventSvc:        eventservice.New(param),
+		deployWindowSvc: deploywindowservice.NewService(param, nil, eventservice.New(param)),
 	}
 
 	_, err := param.UserMgr.Create(ctx, &usermodel.User{
@@ -150,4 +159,21 @@ func TestCreatePipelineRun(t *testing.T) {
 	pipelineBuildDeployPending, err := controller.CreatePipelineRun(ctx, clusterGit.ID, requestBuildDeploy)
 	assert.NoError(t, err)
 	assert.Equal(t, "pending", pipelineBuildDeployPending.Status)
+
+	// the deploy and build deploy apis are admitted by the action as well
+	webhook := &deniedWebhook{}
+	admission.Register(admissionmodels.KindValidating, webhook)
+	webhook.enabled = true
+	_, err = controller.Deploy(ctx, clusterGit.ID, &DeployRequest{Title: "test"})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	_, err = controller.BuildDeploy(ctx, clusterGit.ID, &BuildDeployRequest{Title: "test"})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	_, err = controller.CreatePipelineRun(ctx, clusterGit.ID, requestBuildDeploy)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	webhook.enabled = false
+	assert.Equal(t, 3, len(webhook.requests))
+	for i, action := range []string{prmodels.ActionDeploy, prmodels.ActionBuildDeploy, prmodels.ActionBuildDeploy} {
+		assert.Equal(t, admissionmodels.OperationAction, webhook.requests[i].Operation)
+		assert.Equal(t, action, webhook.requests[i].SubResource)
+	}
 }
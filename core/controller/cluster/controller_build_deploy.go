@@ -25,7 +25,9 @@ import (
 
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	admissionmodels "github.com/horizoncd/horizon/pkg/admission/models"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton"
 	"github.com/horizoncd/horizon/pkg/git"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
@@ -61,6 +63,15 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 		return nil, err
 	}
 
+	if err := c.checkDeployWindow(ctx, application, cluster, prmodels.ActionBuildDeploy,
+		r.DeployWindowOverride); err != nil {
+		return nil, err
+	}
+	if err := c.admit(ctx, admissionmodels.OperationAction, prmodels.ActionBuildDeploy,
+		application, cluster, r, nil); err != nil {
+		return nil, err
+	}
+
 	var gitRef, gitRefType = cluster.GitRef, cluster.GitRefType
 	if r.Git != nil {
 		if r.Git.Commit != "" {
@@ -255,6 +266,40 @@ func (c *controller) GetDiff(ctx context.Context, clusterID uint, refType, ref s
 	return c.ofClusterDiff(cluster.GitURL, refType, ref, commit, diff)
 }
 
+func (c *controller) ListImages(ctx context.Context, clusterID uint) (_ []*Image, err error) {
+	const op = "cluster controller: list images"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return nil, err
+	}
+
+	rg, err := c.registryFty.GetRegistryByConfig(ctx, &registry.Config{
+		Server:             regionEntity.Registry.Server,
+		Token:              regionEntity.Registry.Token,
+		InsecureSkipVerify: regionEntity.Registry.InsecureSkipTLSVerify,
+		Kind:               regionEntity.Registry.Kind,
+		Path:               regionEntity.Registry.Path,
+	})
+	if err != nil {
+		return nil, err
+	}
+	images, err := rg.ListImages(ctx, application.Name, cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	return ofImages(images), nil
+}
+
 func (c *controller) ofClusterDiff(gitURL, refType, ref string, commit *git.Commit, diff string) (
 	*GetDiffResponse, error) {
 	var codeInfo *CodeInfo
@@ -0,0 +1,466 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/mattbaird/jsonpatch"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/admission"
+	admissionmodels "github.com/horizoncd/horizon/pkg/admission/models"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	hauth "github.com/horizoncd/horizon/pkg/auth"
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	"github.com/horizoncd/horizon/pkg/cluster/models"
+	hctx "github.com/horizoncd/horizon/pkg/context"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	"github.com/horizoncd/horizon/pkg/util/mergemap"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) PreviewCloneCluster(ctx context.Context, clusterID uint,
+	r *CloneClusterRequest) (*CloneClusterPreviewResponse, error) {
+	const op = "cluster controller: preview clone cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	params, application, err := c.toCloneClusterParams(ctx, clusterID, r)
+	if err != nil {
+		return nil, err
+	}
+	return &CloneClusterPreviewResponse{
+		Name:        params.Name,
+		Description: params.Description,
+		Application: &Application{
+			ID:   application.ID,
+			Name: application.Name,
+		},
+		Scope: &Scope{
+			Environment: params.Environment,
+			Region:      params.Region,
+		},
+		Git:            params.Git,
+		Image:          params.Image,
+		Tags:           params.Tags,
+		BuildConfig:    params.BuildConfig,
+		TemplateInfo:   params.TemplateInfo,
+		TemplateConfig: params.TemplateConfig,
+	}, nil
+}
+
+func (c *controller) CloneCluster(ctx context.Context, clusterID uint,
+	r *CloneClusterRequest) (*CreateClusterResponseV2, error) {
+	const op = "cluster controller: clone cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	params, application, err := c.toCloneClusterParams(ctx, clusterID, r)
+	if err != nil {
+		return nil, err
+	}
+
+	// the new cluster is admitted as if it's created by the api of creating clusters
+	mutated, err := c.admitObject(ctx, &admission.Request{
+		Operation:   admissionmodels.OperationCreate,
+		Resource:    common.ResourceApplication,
+		Name:        strconv.FormatUint(uint64(application.ID), 10),
+		SubResource: common.ResourceCluster,
+		Version:     "v2",
+		Options: map[string]interface{}{
+			hctx.ParamScope: fmt.Sprintf("%s/%s", params.Environment, params.Region),
+		},
+	}, params.CreateClusterRequestV2)
+	if err != nil {
+		return nil, err
+	}
+	var request CreateClusterRequestV2
+	if err := json.Unmarshal(mutated, &request); err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	params.CreateClusterRequestV2 = &request
+	return c.CreateClusterV2(ctx, params)
+}
+
+// toCloneClusterParams copies the values of the source cluster into the params to create the new cluster,
+// the overrides in request are applied on the copied values
+func (c *controller) toCloneClusterParams(ctx context.Context, clusterID uint,
+	r *CloneClusterRequest) (*CreateClusterParamsV2, *appmodels.Application, error) {
+	if r.Environment == "" || r.Region == "" {
+		return nil, nil, perror.Wrap(herrors.ErrParamInvalid, "environment and region are required")
+	}
+
+	// 1. get the source cluster and the application of the new cluster
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, nil, err
+	}
+	sourceApplication, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	application := sourceApplication
+	if r.ApplicationID != 0 && r.ApplicationID != sourceApplication.ID {
+		application, err = c.applicationMgr.GetByID(ctx, r.ApplicationID)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if _, err := c.envRegionMgr.GetByEnvironmentAndRegion(ctx, r.Environment, r.Region); err != nil {
+		return nil, nil, err
+	}
+
+	// 2. the source cluster is authorized by the middleware,
+	// while the new cluster can be in another application and environment
+	if err := c.authorize(ctx, "create", common.ResourceApplication, common.ResourceCluster, application.ID,
+		fmt.Sprintf("%s/%s", r.Environment, r.Region)); err != nil {
+		return nil, nil, err
+	}
+
+	// 3. copy the values of the source cluster
+	files, err := c.clusterGitRepo.GetCluster(ctx, sourceApplication.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		return nil, nil, err
+	}
+	tags, err := c.tagMgr.ListByResourceTypeID(ctx, common.ResourceCluster, cluster.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	params := &CreateClusterParamsV2{
+		CreateClusterRequestV2: &CreateClusterRequestV2{
+			Name:        r.Name,
+			Description: cluster.Description,
+			ExpireTime:  r.ExpireTime,
+			Git:         r.Git,
+			Image:       r.Image,
+			Tags:        tagmodels.Tags(tags).IntoTagsBasic(),
+			TemplateInfo: &codemodels.TemplateInfo{
+				Name:    cluster.Template,
+				Release: cluster.TemplateRelease,
+			},
+		},
+		ApplicationID: application.ID,
+		Environment:   r.Environment,
+		Region:        r.Region,
+	}
+	if r.Description != "" {
+		params.Description = r.Description
+	}
+	if r.Tags != nil {
+		params.Tags = r.Tags
+	}
+	if r.TemplateInfo != nil {
+		params.TemplateInfo = r.TemplateInfo
+	}
+	if params.Git == nil && params.Image == nil {
+		if cluster.GitURL != "" {
+			params.Git = codemodels.NewGit(cluster.GitURL, cluster.GitSubfolder,
+				cluster.GitRefType, cluster.GitRef)
+		} else if cluster.Image != "" {
+			image := cluster.Image
+			params.Image = &image
+		}
+	}
+
+	// 4. merge the configs, from the environment template, the source cluster to the overrides
+	buildConfigs := []map[string]interface{}{files.PipelineJSONBlob, r.BuildConfig}
+	templateConfigs := []map[string]interface{}{files.ApplicationJSONBlob, r.TemplateConfig}
+	if r.WithEnvTemplate && params.TemplateInfo.Name == application.Template {
+		appFiles, err := c.applicationGitRepo.GetApplication(ctx, application.Name, r.Environment)
+		if err != nil {
+			return nil, nil, err
+		}
+		buildConfigs = append([]map[string]interface{}{appFiles.BuildConf}, buildConfigs...)
+		templateConfigs = append([]map[string]interface{}{appFiles.TemplateConf}, templateConfigs...)
+	}
+	buildConfig, err := mergeConfigs(buildConfigs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	templateConfig, err := mergeConfigs(templateConfigs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(buildConfig) > 0 {
+		params.BuildConfig = buildConfig
+	}
+	params.TemplateConfig = templateConfig
+	return params, application, nil
+}
+
+func (c *controller) PromoteConfig(ctx context.Context, applicationID uint,
+	r *PromoteConfigRequest, preview bool) (*PromoteConfigResponse, error) {
+	const op = "cluster controller: promote config"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if len(r.Items) == 0 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "no clusters to promote")
+	}
+	application, err := c.applicationMgr.GetByID(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. diff the configs, all of the clusters are checked before any of them is updated
+	resp := &PromoteConfigResponse{Items: make([]*PromoteConfigResult, 0, len(r.Items))}
+	requests := make([]*UpdateClusterRequestV2, 0, len(r.Items))
+	targets := make([]*models.Cluster, 0, len(r.Items))
+	for _, item := range r.Items {
+		if item.SourceClusterID == item.TargetClusterID {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"cannot promote the config of cluster %d to itself", item.SourceClusterID)
+		}
+		source, err := c.getClusterToPromote(ctx, application, item.SourceClusterID, "get")
+		if err != nil {
+			return nil, err
+		}
+		target, err := c.getClusterToPromote(ctx, application, item.TargetClusterID, "update")
+		if err != nil {
+			return nil, err
+		}
+		sourceFiles, err := c.clusterGitRepo.GetCluster(ctx, application.Name, source.Name, source.Template)
+		if err != nil {
+			return nil, err
+		}
+		targetFiles, err := c.clusterGitRepo.GetCluster(ctx, application.Name, target.Name, target.Template)
+		if err != nil {
+			return nil, err
+		}
+
+		result := &PromoteConfigResult{
+			Source: newPromotedCluster(source),
+			Target: newPromotedCluster(target),
+		}
+		request := &UpdateClusterRequestV2{
+			Description:    target.Description,
+			TemplateConfig: sourceFiles.ApplicationJSONBlob,
+		}
+		if source.Template != target.Template || source.TemplateRelease != target.TemplateRelease {
+			result.TemplateInfo = &codemodels.TemplateInfo{
+				Name:    source.Template,
+				Release: source.TemplateRelease,
+			}
+			request.TemplateInfo = result.TemplateInfo
+		}
+		// the build config of target is kept if the source cluster has none
+		if len(sourceFiles.PipelineJSONBlob) > 0 {
+			result.BuildConfig, err = diffConfig(targetFiles.PipelineJSONBlob, sourceFiles.PipelineJSONBlob)
+			if err != nil {
+				return nil, err
+			}
+			request.BuildConfig = sourceFiles.PipelineJSONBlob
+		}
+		result.TemplateConfig, err = diffConfig(targetFiles.ApplicationJSONBlob, sourceFiles.ApplicationJSONBlob)
+		if err != nil {
+			return nil, err
+		}
+		resp.Items = append(resp.Items, result)
+		requests = append(requests, request)
+		targets = append(targets, target)
+	}
+	if preview {
+		return resp, nil
+	}
+
+	// 2. promote the configs, the failure of one cluster does not stop the others
+	for i, result := range resp.Items {
+		if !result.changed() {
+			continue
+		}
+		request, err := c.admitPromotion(ctx, application, targets[i], requests[i])
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		if err := c.UpdateClusterV2(ctx, result.Target.ID, request, false); err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		result.Promoted = true
+	}
+	return resp, nil
+}
+
+// admitPromotion admits the update of target cluster as if it's updated by the api of updating clusters
+func (c *controller) admitPromotion(ctx context.Context, application *appmodels.Application,
+	target *models.Cluster, r *UpdateClusterRequestV2) (*UpdateClusterRequestV2, error) {
+	mutated, err := c.admitObject(ctx, &admission.Request{
+		Operation: admissionmodels.OperationUpdate,
+		Resource:  common.ResourceCluster,
+		Name:      strconv.FormatUint(uint64(target.ID), 10),
+		Version:   "v2",
+		OldObject: admission.NewClusterObject(application, target),
+	}, r)
+	if err != nil {
+		return nil, err
+	}
+	var request UpdateClusterRequestV2
+	if err := json.Unmarshal(mutated, &request); err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	return &request, nil
+}
+
+// admitObject mutates and validates the object like the admission middleware does with request bodies,
+// and returns the mutated object in json
+func (c *controller) admitObject(ctx context.Context, request *admission.Request,
+	object interface{}) ([]byte, error) {
+	bts, err := json.Marshal(object)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	var body interface{}
+	if err := json.Unmarshal(bts, &body); err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	request.Object = body
+	request, err = admission.Mutating(ctx, request)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "admission mutating failed: %v", err)
+	}
+	if err := admission.Validating(ctx, request); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "admission validating failed: %v", err)
+	}
+	bts, err = json.Marshal(request.Object)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	return bts, nil
+}
+
+func (c *controller) getClusterToPromote(ctx context.Context, application *appmodels.Application,
+	clusterID uint, verb string) (*models.Cluster, error) {
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if cluster.ApplicationID != application.ID {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"cluster %s does not belong to application %s", cluster.Name, application.Name)
+	}
+	if err := c.authorize(ctx, verb, common.ResourceCluster, "", cluster.ID,
+		fmt.Sprintf("%s/%s", cluster.EnvironmentName, cluster.RegionName)); err != nil {
+		return nil, err
+	}
+	return cluster, nil
+}
+
+// authorize checks if the current user is permitted to access the resource by rbac
+func (c *controller) authorize(ctx context.Context, verb, resource, subResource string,
+	resourceID uint, scope string) error {
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	name := strconv.FormatUint(uint64(resourceID), 10)
+	path := fmt.Sprintf("/apis/%s/v2/%s/%s", common.GroupCore, resource, name)
+	if subResource != "" {
+		path = fmt.Sprintf("%s/%s", path, subResource)
+	}
+	decision, reason, err := c.authorizer.Authorize(ctx, hauth.AttributesRecord{
+		User:            user,
+		Verb:            verb,
+		APIGroup:        common.GroupCore,
+		APIVersion:      "v2",
+		Resource:        resource,
+		SubResource:     subResource,
+		Name:            name,
+		Scope:           scope,
+		ResourceRequest: true,
+		Path:            path,
+	})
+	if err != nil {
+		return err
+	}
+	if decision != hauth.DecisionAllow {
+		return perror.Wrapf(herrors.ErrForbidden, "%s %s is not permitted: %s", verb, path, reason)
+	}
+
+	// the scope of the access token only gets checked for the request path by the middleware
+	token, ok := common.AccessTokenFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	permitted, reason, err := c.oauthChecker.CheckScopePermission(ctx, token, hauth.RequestInfo{
+		IsResourceRequest: true,
+		Path:              path,
+		Verb:              verb,
+		APIPrefix:         "apis",
+		APIGroup:          common.GroupCore,
+		APIVersion:        "v2",
+		Resource:          resource,
+		Subresource:       subResource,
+		Name:              name,
+		Scope:             scope,
+	})
+	if err != nil {
+		return err
+	}
+	if !permitted {
+		return perror.Wrapf(herrors.ErrForbidden, "%s %s is not permitted by the token: %s", verb, path, reason)
+	}
+	return nil
+}
+
+// mergeConfigs merges the configs into a new one, the latter takes precedence over the former
+func mergeConfigs(configs ...map[string]interface{}) (map[string]interface{}, error) {
+	merged := make(map[string]interface{})
+	for _, config := range configs {
+		if config == nil {
+			continue
+		}
+		// deep copy the config, so that merging does not change the original ones
+		bts, err := json.Marshal(config)
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		copied := make(map[string]interface{})
+		if err := json.Unmarshal(bts, &copied); err != nil {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		if merged, err = mergemap.Merge(merged, copied); err != nil {
+			return nil, err
+		}
+	}
+	return merged, nil
+}
+
+// diffConfig returns the json patch to transform the config from one to the other
+func diffConfig(from, to map[string]interface{}) ([]jsonpatch.JsonPatchOperation, error) {
+	if from == nil {
+		from = map[string]interface{}{}
+	}
+	if to == nil {
+		to = map[string]interface{}{}
+	}
+	fromBts, err := json.Marshal(from)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	toBts, err := json.Marshal(to)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	patch, err := jsonpatch.CreatePatch(fromBts, toBts)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	return patch, nil
+}
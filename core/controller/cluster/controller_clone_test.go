@@ -0,0 +1,339 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattbaird/jsonpatch"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/oauthcheck"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	applicationgitrepomock "github.com/horizoncd/horizon/mock/pkg/application/gitrepo"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	rbacmock "github.com/horizoncd/horizon/mock/pkg/rbac"
+	"github.com/horizoncd/horizon/pkg/admission"
+	admissionmodels "github.com/horizoncd/horizon/pkg/admission/models"
+	appgitrepo "github.com/horizoncd/horizon/pkg/application/gitrepo"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	hauth "github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	clustergitrepo "github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/oauth"
+	envregionmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/oauth/scope"
+	hparam "github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	rbactypes "github.com/horizoncd/horizon/pkg/rbac/types"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+// deniedWebhook denies the requests and records them while it's enabled
+type deniedWebhook struct {
+	enabled  bool
+	requests []*admission.Request
+}
+
+func (w *deniedWebhook) Handle(_ context.Context, req *admission.Request) (*admission.Response, error) {
+	w.requests = append(w.requests, req)
+	allowed := false
+	return &admission.Response{Allowed: &allowed, Result: "denied"}, nil
+}
+
+func (w *deniedWebhook) IgnoreError() bool {
+	return false
+}
+
+func (w *deniedWebhook) Interest(*admission.Request) bool {
+	return w.enabled
+}
+
+func TestCloneAndPromoteConfig(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&appmodels.Application{}, &models.Cluster{}, &membermodels.Member{},
+		&envregionmodels.EnvironmentRegion{}, &tagmodels.Tag{}, &usermodels.User{}, &tokenmodels.Token{}); err != nil {
+		panic(err)
+	}
+	param := managerparam.InitManager(db)
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   uint(1),
+	})
+
+	app, err := param.ApplicationMgr.Create(ctx, &appmodels.Application{
+		Name:            "app",
+		Template:        "javaapp",
+		TemplateRelease: "v1.0.0",
+	}, nil)
+	assert.Nil(t, err)
+	otherApp, err := param.ApplicationMgr.Create(ctx, &appmodels.Application{
+		Name:            "other-app",
+		Template:        "javaapp",
+		TemplateRelease: "v1.0.0",
+	}, nil)
+	assert.Nil(t, err)
+	for _, env := range []string{"test", "online"} {
+		_, err = param.EnvRegionMgr.CreateEnvironmentRegion(ctx, &envregionmodels.EnvironmentRegion{
+			EnvironmentName: env,
+			RegionName:      "hz",
+		})
+		assert.Nil(t, err)
+	}
+	testCluster, err := param.ClusterMgr.Create(ctx, &models.Cluster{
+		ApplicationID:   app.ID,
+		Name:            "app-test",
+		Description:     "test cluster",
+		EnvironmentName: "test",
+		RegionName:      "hz",
+		GitURL:          "ssh://git@github.com/horizoncd/horizon.git",
+		GitRef:          "main",
+		GitRefType:      codemodels.GitRefTypeBranch,
+		Template:        "javaapp",
+		TemplateRelease: "v1.0.1",
+	}, []*tagmodels.Tag{{Key: "k", Value: "v"}}, nil)
+	assert.Nil(t, err)
+	onlineCluster, err := param.ClusterMgr.Create(ctx, &models.Cluster{
+		ApplicationID:   app.ID,
+		Name:            "app-online",
+		EnvironmentName: "online",
+		RegionName:      "hz",
+		Template:        "javaapp",
+		TemplateRelease: "v1.0.0",
+	}, nil, nil)
+	assert.Nil(t, err)
+	otherCluster, err := param.ClusterMgr.Create(ctx, &models.Cluster{
+		ApplicationID:   otherApp.ID,
+		Name:            "other-app-online",
+		EnvironmentName: "online",
+		RegionName:      "hz",
+		Template:        "javaapp",
+		TemplateRelease: "v1.0.0",
+	}, nil, nil)
+	assert.Nil(t, err)
+
+	mockCtl := gomock.NewController(t)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), app.Name, testCluster.Name, gomock.Any()).
+		Return(&clustergitrepo.ClusterFiles{
+			PipelineJSONBlob: map[string]interface{}{"buildxml": "xml"},
+			ApplicationJSONBlob: map[string]interface{}{
+				"app": map[string]interface{}{"replicas": float64(1), "port": float64(8080)},
+			},
+		}, nil).AnyTimes()
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), app.Name, onlineCluster.Name, gomock.Any()).
+		Return(&clustergitrepo.ClusterFiles{
+			ApplicationJSONBlob: map[string]interface{}{
+				"app": map[string]interface{}{"replicas": float64(4)},
+			},
+		}, nil).AnyTimes()
+	applicationGitRepo := applicationgitrepomock.NewMockApplicationGitRepo2(mockCtl)
+	applicationGitRepo.EXPECT().GetApplication(gomock.Any(), otherApp.Name, "online").
+		Return(&appgitrepo.GetResponse{
+			TemplateConf: map[string]interface{}{
+				"app": map[string]interface{}{"replicas": float64(2), "cpu": float64(2)},
+			},
+		}, nil).AnyTimes()
+
+	// the requests of the paths in denied are denied
+	denied := map[string]bool{}
+	authorizer := rbacmock.NewMockAuthorizer(mockCtl)
+	authorizer.EXPECT().Authorize(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, attr hauth.Attributes) (hauth.Decision, string, error) {
+			if denied[attr.GetPath()] {
+				return hauth.DecisionDeny, "denied", nil
+			}
+			return hauth.DecisionAllow, "allowed", nil
+		}).AnyTimes()
+	c := &controller{
+		clusterMgr:         param.ClusterMgr,
+		applicationMgr:     param.ApplicationMgr,
+		envRegionMgr:       param.EnvRegionMgr,
+		tagMgr:             param.TagMgr,
+		clusterGitRepo:     clusterGitRepo,
+		applicationGitRepo: applicationGitRepo,
+		authorizer:         authorizer,
+	}
+
+	// clone to another application with the env template and overrides
+	preview, err := c.PreviewCloneCluster(ctx, testCluster.ID, &CloneClusterRequest{
+		ApplicationID:   otherApp.ID,
+		Name:            "other-app-clone",
+		Environment:     "online",
+		Region:          "hz",
+		WithEnvTemplate: true,
+		TemplateInfo:    &codemodels.TemplateInfo{Name: "javaapp", Release: "v1.0.0"},
+		TemplateConfig: map[string]interface{}{
+			"app": map[string]interface{}{"replicas": float64(3)},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "other-app-clone", preview.Name)
+	assert.Equal(t, "test cluster", preview.Description)
+	assert.Equal(t, otherApp.ID, preview.Application.ID)
+	assert.Equal(t, &Scope{Environment: "online", Region: "hz"}, preview.Scope)
+	assert.Equal(t, testCluster.GitURL, preview.Git.URL)
+	assert.Equal(t, "main", preview.Git.Branch)
+	assert.Equal(t, tagmodels.TagsBasic{{Key: "k", Value: "v"}}, preview.Tags)
+	assert.Equal(t, "v1.0.0", preview.TemplateInfo.Release)
+	assert.Equal(t, map[string]interface{}{"buildxml": "xml"}, preview.BuildConfig)
+	assert.Equal(t, map[string]interface{}{
+		"app": map[string]interface{}{"replicas": float64(3), "port": float64(8080), "cpu": float64(2)},
+	}, preview.TemplateConfig)
+
+	// the configs of the source cluster are not changed by merging
+	files, err := clusterGitRepo.GetCluster(ctx, app.Name, testCluster.Name, "")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"replicas": float64(1), "port": float64(8080)},
+		files.ApplicationJSONBlob["app"])
+
+	_, err = c.PreviewCloneCluster(ctx, testCluster.ID, &CloneClusterRequest{Environment: "online"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// the new cluster is admitted before it's created
+	webhook := &deniedWebhook{}
+	admission.Register(admissionmodels.KindValidating, webhook)
+	webhook.enabled = true
+	_, err = c.CloneCluster(ctx, testCluster.ID, &CloneClusterRequest{
+		ApplicationID: otherApp.ID,
+		Name:          "other-app-clone",
+		Environment:   "online",
+		Region:        "hz",
+	})
+	webhook.enabled = false
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	assert.Equal(t, 1, len(webhook.requests))
+	assert.Equal(t, admissionmodels.OperationCreate, webhook.requests[0].Operation)
+	assert.Equal(t, common.ResourceApplication, webhook.requests[0].Resource)
+	assert.Equal(t, fmt.Sprintf("%d", otherApp.ID), webhook.requests[0].Name)
+	assert.Equal(t, common.ResourceCluster, webhook.requests[0].SubResource)
+	assert.Equal(t, "other-app-clone", webhook.requests[0].Object.(map[string]interface{})["name"])
+
+	denied[fmt.Sprintf("/apis/core/v2/applications/%d/clusters", otherApp.ID)] = true
+	_, err = c.PreviewCloneCluster(ctx, testCluster.ID, &CloneClusterRequest{
+		ApplicationID: otherApp.ID,
+		Environment:   "online",
+		Region:        "hz",
+	})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	// promote the config from test to online
+	resp, err := c.PromoteConfig(ctx, app.ID, &PromoteConfigRequest{
+		Items: []*PromoteConfigItem{{SourceClusterID: testCluster.ID, TargetClusterID: onlineCluster.ID}},
+	}, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(resp.Items))
+	result := resp.Items[0]
+	assert.Equal(t, onlineCluster.ID, result.Target.ID)
+	assert.Equal(t, &codemodels.TemplateInfo{Name: "javaapp", Release: "v1.0.1"}, result.TemplateInfo)
+	assert.Equal(t, []jsonpatch.JsonPatchOperation{
+		{Operation: "add", Path: "/buildxml", Value: "xml"},
+	}, result.BuildConfig)
+	assert.ElementsMatch(t, []jsonpatch.JsonPatchOperation{
+		{Operation: "replace", Path: "/app/replicas", Value: float64(1)},
+		{Operation: "add", Path: "/app/port", Value: float64(8080)},
+	}, result.TemplateConfig)
+	assert.False(t, result.Promoted)
+
+	// the update of the target cluster is admitted before it's promoted
+	webhook.enabled = true
+	resp, err = c.PromoteConfig(ctx, app.ID, &PromoteConfigRequest{
+		Items: []*PromoteConfigItem{{SourceClusterID: testCluster.ID, TargetClusterID: onlineCluster.ID}},
+	}, false)
+	webhook.enabled = false
+	assert.Nil(t, err)
+	assert.False(t, resp.Items[0].Promoted)
+	assert.Contains(t, resp.Items[0].Error, "admission validating failed")
+	assert.Equal(t, 2, len(webhook.requests))
+	assert.Equal(t, admissionmodels.OperationUpdate, webhook.requests[1].Operation)
+	assert.Equal(t, common.ResourceCluster, webhook.requests[1].Resource)
+	assert.Equal(t, fmt.Sprintf("%d", onlineCluster.ID), webhook.requests[1].Name)
+	assert.Equal(t, admission.NewClusterObject(app, onlineCluster), webhook.requests[1].OldObject)
+
+	for _, item := range []*PromoteConfigItem{
+		{SourceClusterID: testCluster.ID, TargetClusterID: testCluster.ID},
+		{SourceClusterID: testCluster.ID, TargetClusterID: otherCluster.ID},
+	} {
+		_, err = c.PromoteConfig(ctx, app.ID, &PromoteConfigRequest{Items: []*PromoteConfigItem{item}}, true)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	}
+
+	denied[fmt.Sprintf("/apis/core/v2/clusters/%d", onlineCluster.ID)] = true
+	_, err = c.PromoteConfig(ctx, app.ID, &PromoteConfigRequest{
+		Items: []*PromoteConfigItem{{SourceClusterID: testCluster.ID, TargetClusterID: onlineCluster.ID}},
+	}, true)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	delete(denied, fmt.Sprintf("/apis/core/v2/clusters/%d", onlineCluster.ID))
+	delete(denied, fmt.Sprintf("/apis/core/v2/applications/%d/clusters", otherApp.ID))
+
+	// the token constrained to test can't clone or promote into online
+	scopeSvc, err := scope.NewFileScopeService(oauth.Scopes{
+		Roles: []rbactypes.Role{{
+			Name: "clusters:read-write",
+			PolicyRules: []rbactypes.PolicyRule{{
+				Verbs:     []string{"*"},
+				APIGroups: []string{common.GroupCore},
+				Resources: []string{"applications/clusters", "clusters"},
+				Scopes:    []string{"*"},
+			}},
+		}},
+	})
+	assert.Nil(t, err)
+	c.oauthChecker = oauthcheck.NewOauthChecker(&hparam.Param{Manager: param, ScopeService: scopeSvc})
+	user, err := param.UserMgr.Create(ctx, &usermodels.User{Name: "Tony"})
+	assert.Nil(t, err)
+	_, err = param.TokenMgr.CreateToken(ctx, &tokenmodels.Token{
+		Code:   "test-token",
+		Scope:  "clusters:read-write@test",
+		UserID: user.ID,
+	})
+	assert.Nil(t, err)
+	tokenCtx := common.WithContextAccessToken(ctx, "test-token")
+
+	_, err = c.PreviewCloneCluster(tokenCtx, testCluster.ID, &CloneClusterRequest{
+		ApplicationID: otherApp.ID,
+		Environment:   "test",
+		Region:        "hz",
+	})
+	assert.Nil(t, err)
+	_, err = c.PreviewCloneCluster(tokenCtx, testCluster.ID, &CloneClusterRequest{
+		ApplicationID: otherApp.ID,
+		Environment:   "online",
+		Region:        "hz",
+	})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	_, err = c.CloneCluster(tokenCtx, testCluster.ID, &CloneClusterRequest{
+		ApplicationID: otherApp.ID,
+		Name:          "other-app-clone",
+		Environment:   "online",
+		Region:        "hz",
+	})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	_, err = c.PromoteConfig(tokenCtx, app.ID, &PromoteConfigRequest{
+		Items: []*PromoteConfigItem{{SourceClusterID: testCluster.ID, TargetClusterID: onlineCluster.ID}},
+	}, false)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+}
@@ -177,10 +177,14 @@ func (c *controller) InternalDeployV2(ctx context.Context, clusterID uint,
 
 	// 8. deploy cluster in cd system
 	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
-		Environment: cluster.EnvironmentName,
-		Cluster:     cluster.Name,
-		Revision:    masterRevision,
-		Region:      cluster.RegionName,
+		Environment:     cluster.EnvironmentName,
+		Cluster:         cluster.Name,
+		Revision:        masterRevision,
+		Region:          cluster.RegionName,
+		Application:     application.Name,
+		Template:        cluster.Template,
+		TemplateRelease: cluster.TemplateRelease,
+		PipelinerunID:   pr.ID,
 	}); err != nil {
 		return nil, err
 	}
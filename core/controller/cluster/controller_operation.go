@@ -25,6 +25,8 @@ import (
 
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/admission"
+	admissionmodels "github.com/horizoncd/horizon/pkg/admission/models"
 	amodels "github.com/horizoncd/horizon/pkg/application/models"
 	"github.com/horizoncd/horizon/pkg/cd"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
@@ -41,7 +43,8 @@ import (
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 )
 
-func (c *controller) Restart(ctx context.Context, clusterID uint) (_ *PipelinerunIDResponse, err error) {
+func (c *controller) Restart(ctx context.Context, clusterID uint,
+	r *RestartRequest) (_ *PipelinerunIDResponse, err error) {
 	const op = "cluster controller: restart "
 	defer wlog.Start(ctx, op).StopPrint()
 
@@ -60,6 +63,16 @@ func (c *controller) Restart(ctx context.Context, clusterID uint) (_ *Pipelineru
 		return nil, herrors.ErrFreedClusterNotSupportedRestart
 	}
 
+	if err := c.admit(ctx, admissionmodels.OperationAction, prmodels.ActionRestart,
+		application, cluster, r, nil); err != nil {
+		return nil, err
+	}
+
+	if err := c.checkDeployWindow(ctx, application, cluster, prmodels.ActionRestart,
+		r.DeployWindowOverride); err != nil {
+		return nil, err
+	}
+
 	// 1. get config commit now
 	lastConfigCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, application.Name, cluster.Name)
 	if err != nil {
@@ -92,10 +105,14 @@ func (c *controller) Restart(ctx context.Context, clusterID uint) (_ *Pipelineru
 
 	// 3. deploy cluster in cd system
 	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
-		Environment: cluster.EnvironmentName,
-		Cluster:     cluster.Name,
-		Revision:    commit,
-		Region:      cluster.RegionName,
+		Environment:     cluster.EnvironmentName,
+		Cluster:         cluster.Name,
+		Revision:        commit,
+		Region:          cluster.RegionName,
+		Application:     application.Name,
+		Template:        cluster.Template,
+		TemplateRelease: cluster.TemplateRelease,
+		PipelinerunID:   prCreated.ID,
 	}); err != nil {
 		return nil, err
 	}
@@ -133,6 +150,14 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkDeployWindow(ctx, application, cluster, prmodels.ActionDeploy,
+		r.DeployWindowOverride); err != nil {
+		return nil, err
+	}
+	if err := c.admit(ctx, admissionmodels.OperationAction, prmodels.ActionDeploy,
+		application, cluster, r, nil); err != nil {
+		return nil, err
+	}
 	clusterFiles, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
 	if err != nil {
 		return nil, err
@@ -242,6 +267,32 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 	}, nil
 }
 
+// checkDeployWindow checks the deploy windows of cluster before creating pipelinerun.
+// Rollbacks are not checked, so that clusters can always be recovered.
+func (c *controller) checkDeployWindow(ctx context.Context, application *amodels.Application,
+	cluster *cmodels.Cluster, action string, override *DeployWindowOverride) error {
+	var reason string
+	if override != nil {
+		reason = override.Reason
+	}
+	return c.deployWindowSvc.Check(ctx, application, cluster, action, reason)
+}
+
+// admit admits the operation on the cluster which is not admitted by the admission middleware
+func (c *controller) admit(ctx context.Context, operation admissionmodels.Operation, subResource string,
+	application *amodels.Application, cluster *cmodels.Cluster, object interface{},
+	options map[string]interface{}) error {
+	return admission.Admit(ctx, &admission.Request{
+		Operation:   operation,
+		Resource:    common.ResourceCluster,
+		Name:        strconv.FormatUint(uint64(cluster.ID), 10),
+		SubResource: subResource,
+		Object:      object,
+		OldObject:   admission.NewClusterObject(application, cluster),
+		Options:     options,
+	})
+}
+
 func (c *controller) checkAllowDeploy(ctx context.Context,
 	application *amodels.Application, cluster *cmodels.Cluster,
 	clusterFiles *gitrepo.ClusterFiles, configCommit *gitrepo.ClusterCommit) error {
@@ -314,6 +365,11 @@ func (c *controller) Rollback(ctx context.Context,
 		return nil, err
 	}
 
+	if err := c.admit(ctx, admissionmodels.OperationAction, prmodels.ActionRollback,
+		application, cluster, r, nil); err != nil {
+		return nil, err
+	}
+
 	// 2. get config commit now
 	lastConfigCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, application.Name, cluster.Name)
 	if err != nil {
@@ -409,12 +465,25 @@ func (c *controller) Rollback(ctx context.Context,
 	}
 
 	// 9. deploy cluster in cd and update status
-	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
-		Environment: cluster.EnvironmentName,
-		Cluster:     cluster.Name,
-		Revision:    masterRevision,
-		Region:      cluster.RegionName,
-	}); err != nil {
+	deployParams := &cd.DeployClusterParams{
+		Environment:     cluster.EnvironmentName,
+		Cluster:         cluster.Name,
+		Revision:        masterRevision,
+		Region:          cluster.RegionName,
+		Application:     application.Name,
+		Template:        cluster.Template,
+		TemplateRelease: cluster.TemplateRelease,
+		PipelinerunID:   prCreated.ID,
+	}
+	if rollbackCD, ok := c.cd.(cd.RollbackCD); ok {
+		err = rollbackCD.RollbackCluster(ctx, &cd.RollbackClusterParams{
+			DeployClusterParams: *deployParams,
+			TargetPipelinerunID: r.PipelinerunID,
+		})
+	} else {
+		err = c.cd.DeployCluster(ctx, deployParams)
+	}
+	if err != nil {
 		return nil, err
 	}
 	if err := c.updatePipelineRunStatus(ctx,
@@ -466,11 +535,21 @@ func (c *controller) retrieveClusterCtx(ctx context.Context, clusterID uint) (*c
 
 func (c *controller) ExecuteAction(ctx context.Context, clusterID uint,
 	action string, gvr schema.GroupVersionResource) error {
-	cluster, _, _, regionEntity, envValue, err := c.retrieveClusterCtx(ctx, clusterID)
+	cluster, application, _, regionEntity, envValue, err := c.retrieveClusterCtx(ctx, clusterID)
 	if err != nil {
 		return err
 	}
 
+	if err := c.admit(ctx, admissionmodels.OperationAction, "action", application, cluster, nil,
+		map[string]interface{}{
+			"action":   action,
+			"group":    gvr.Group,
+			"version":  gvr.Version,
+			"resource": gvr.Resource,
+		}); err != nil {
+		return err
+	}
+
 	return c.k8sutil.ExecuteAction(ctx, &cd.ExecuteActionParams{
 		RegionEntity: regionEntity,
 		Namespace:    envValue.Namespace,
@@ -533,6 +612,10 @@ func (c *controller) Exec(ctx context.Context, clusterID uint, r *ExecRequest) (
 		return nil, err
 	}
 
+	if err := c.admit(ctx, admissionmodels.OperationAction, "exec", application, cluster, r, nil); err != nil {
+		return nil, err
+	}
+
 	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
 	if err != nil {
 		return nil, err
@@ -534,6 +534,7 @@ func (c *controller) GetStep(ctx context.Context, clusterID uint) (resp *GetStep
 			Replicas:     steps.Replicas,
 			ManualPaused: steps.ManualPaused,
 			AutoPromote:  steps.AutoPromote,
+			Aborted:      steps.Aborted,
 			Extra:        steps.Extra,
 		}
 	} else {
@@ -548,6 +549,22 @@ func (c *controller) GetStep(ctx context.Context, clusterID uint) (resp *GetStep
 	return
 }
 
+func (c *controller) ListAnalysisRuns(ctx context.Context, clusterID uint) ([]cd.AnalysisRun, error) {
+	const op = "cluster controller: list analysis runs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, _, _, regionEntity, envValue, err := c.retrieveClusterCtx(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.k8sutil.ListAnalysisRuns(ctx, &cd.ListAnalysisRunsParams{
+		RegionEntity: regionEntity,
+		Namespace:    envValue.Namespace,
+		Cluster:      cluster.Name,
+	})
+}
+
 func willExpireIn(ttl uint, tms ...time.Time) *uint {
 	var (
 		latestTime time.Time
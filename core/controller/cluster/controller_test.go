@@ -28,6 +28,8 @@ import (
 	appservice "github.com/horizoncd/horizon/pkg/application/service"
 	badgemodels "github.com/horizoncd/horizon/pkg/badge/models"
 	clustercd "github.com/horizoncd/horizon/pkg/cd"
+	deploywindowmodels "github.com/horizoncd/horizon/pkg/deploywindow/models"
+	deploywindowservice "github.com/horizoncd/horizon/pkg/deploywindow/service"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	templatemodels "github.com/horizoncd/horizon/pkg/template/models"
 
@@ -58,6 +60,7 @@ import (
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	"github.com/horizoncd/horizon/pkg/cluster/models"
+	clusterregistry "github.com/horizoncd/horizon/pkg/cluster/registry"
 	cluterservice "github.com/horizoncd/horizon/pkg/cluster/service"
 	gitconfig "github.com/horizoncd/horizon/pkg/config/git"
 	templateconfig "github.com/horizoncd/horizon/pkg/config/template"
@@ -460,7 +463,7 @@ func TestMain(m *testing.M) {
 		&registrymodels.Registry{}, eventmodels.Event{}, &templatemodels.Template{},
 		&regionmodels.Region{}, &envregionmodels.EnvironmentRegion{}, &eventmodels.Event{},
 		&prmodels.Pipelinerun{}, &schematagmodel.ClusterTemplateSchemaTag{}, &tmodel.Tag{},
-		&envmodels.Environment{}, &tokenmodels.Token{}, &badgemodels.Badge{}); err != nil {
+		&envmodels.Environment{}, &tokenmodels.Token{}, &badgemodels.Badge{}, &deploywindowmodels.DeployWindow{}); err != nil {
 		panic(err)
 	}
 	ctx = context.TODO()
@@ -662,6 +665,7 @@ func test(t *testing.T) {
 		tagMgr:               tagManager,
 		applicationGitRepo:   applicationGitRepo,
 		eventSvc:             eventservice.New(manager),
+		deployWindowSvc:      deploywindowservice.NewService(manager, nil, eventservice.New(manager)),
 		memberManager:        manager.MemberMgr,
 		tokenSvc: tokenservice.NewService(manager, tokenconfig.Config{
 			JwtSigningKey:         "horizon",
@@ -981,7 +985,7 @@ func test(t *testing.T) {
 	clusterGitRepo.EXPECT().UpdateRestartTime(ctx, gomock.Any(), gomock.Any(),
 		gomock.Any()).Return("update-image-commit", nil)
 
-	restartResp, err := c.Restart(ctx, resp.ID)
+	restartResp, err := c.Restart(ctx, resp.ID, &RestartRequest{})
 	assert.Nil(t, err)
 	assert.NotNil(t, resp)
 	b, _ = json.Marshal(restartResp)
@@ -1431,6 +1435,7 @@ func testV2(t *testing.T) {
 		registryFty:          registryFty,
 		cd:                   mockCd,
 		eventSvc:             eventservice.New(manager),
+		deployWindowSvc:      deploywindowservice.NewService(manager, nil, eventservice.New(manager)),
 		memberManager:        manager.MemberMgr,
 	}
 	applicationGitRepo.EXPECT().GetApplication(gomock.Any(), applicationName, gomock.Any()).
@@ -1553,6 +1558,14 @@ func testV2(t *testing.T) {
 	assert.Nil(t, err)
 
 	registry := registrymock.NewMockRegistry(mockCtl)
+	registryFty.EXPECT().GetRegistryByConfig(gomock.Any(), gomock.Any()).Return(registry, nil).Times(1)
+	registry.EXPECT().ListImages(gomock.Any(), applicationName, createClusterName).Return(
+		[]*clusterregistry.Image{{Repository: "harbor.com/library/app/cluster", Tags: []string{"v1"}}}, nil).Times(1)
+	images, err := c.ListImages(ctx, getClusterResp.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(images))
+	assert.Equal(t, []string{"v1"}, images[0].Tags)
+
 	registryFty.EXPECT().GetRegistryByConfig(gomock.Any(), gomock.Any()).Return(registry, nil).Times(1)
 	registry.EXPECT().DeleteImage(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
 	clusterGitRepo.EXPECT().DeleteCluster(gomock.Any(), applicationName,
@@ -1656,6 +1669,7 @@ func testUpgrade(t *testing.T) {
 		registryFty:           registryFty,
 		cd:                    mockCd,
 		eventSvc:              eventservice.New(manager),
+		deployWindowSvc:       deploywindowservice.NewService(manager, nil, eventservice.New(manager)),
 		templateUpgradeMapper: templateUpgradeMapper,
 		memberManager:         manager.MemberMgr,
 	}
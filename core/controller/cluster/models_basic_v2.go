@@ -247,4 +247,6 @@ type CreatePipelineRunRequest struct {
 	ImageTag string                 `json:"imageTag,omitempty"`
 	// for rollback
 	PipelinerunID uint `json:"pipelinerunID,omitempty"`
+
+	DeployWindowOverride *DeployWindowOverride `json:"deployWindowOverride,omitempty"`
 }
@@ -14,10 +14,18 @@
 
 package cluster
 
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
+)
+
 type BuildDeployRequest struct {
 	Title       string                 `json:"title"`
 	Description string                 `json:"description"`
 	Git         *BuildDeployRequestGit `json:"git"`
+
+	DeployWindowOverride *DeployWindowOverride `json:"deployWindowOverride,omitempty"`
 }
 
 type BuildDeployRequestGit struct {
@@ -47,3 +55,27 @@ type CodeInfo struct {
 	// code history link
 	Link string `json:"link"`
 }
+
+type Image struct {
+	// image repository without tag, such as harbor.example.com/library/app/cluster
+	Repository string `json:"repository"`
+	// tags pointing to this image, one of them can be used as imageTag to deploy
+	Tags      []string  `json:"tags"`
+	Digest    string    `json:"digest"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func ofImages(images []*registry.Image) []*Image {
+	resp := make([]*Image, 0, len(images))
+	for _, image := range images {
+		resp = append(resp, &Image{
+			Repository: image.Repository,
+			Tags:       image.Tags,
+			Digest:     image.Digest,
+			Size:       image.Size,
+			CreatedAt:  image.CreatedAt,
+		})
+	}
+	return resp
+}
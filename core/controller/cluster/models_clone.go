@@ -0,0 +1,104 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"github.com/mattbaird/jsonpatch"
+
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	"github.com/horizoncd/horizon/pkg/cluster/models"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+)
+
+type CloneClusterRequest struct {
+	// ApplicationID is the application of the new cluster, defaults to the application of the source cluster
+	ApplicationID uint   `json:"applicationID"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	Environment   string `json:"environment"`
+	Region        string `json:"region"`
+	ExpireTime    string `json:"expireTime"`
+	// WithEnvTemplate fills the values absent in the source cluster from the environment template of the application
+	WithEnvTemplate bool `json:"withEnvTemplate"`
+
+	// overrides of the values copied from the source cluster, the configs are merged into the copied ones
+	Git            *codemodels.Git          `json:"git"`
+	Image          *string                  `json:"image"`
+	Tags           tagmodels.TagsBasic      `json:"tags"`
+	BuildConfig    map[string]interface{}   `json:"buildConfig"`
+	TemplateInfo   *codemodels.TemplateInfo `json:"templateInfo"`
+	TemplateConfig map[string]interface{}   `json:"templateConfig"`
+}
+
+type CloneClusterPreviewResponse struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Application *Application        `json:"application"`
+	Scope       *Scope              `json:"scope"`
+	Git         *codemodels.Git     `json:"git"`
+	Image       *string             `json:"image"`
+	Tags        tagmodels.TagsBasic `json:"tags"`
+
+	BuildConfig    map[string]interface{}   `json:"buildConfig"`
+	TemplateInfo   *codemodels.TemplateInfo `json:"templateInfo"`
+	TemplateConfig map[string]interface{}   `json:"templateConfig"`
+}
+
+type PromoteConfigRequest struct {
+	Items []*PromoteConfigItem `json:"items"`
+}
+
+type PromoteConfigItem struct {
+	SourceClusterID uint `json:"sourceClusterID"`
+	TargetClusterID uint `json:"targetClusterID"`
+}
+
+type PromoteConfigResponse struct {
+	Items []*PromoteConfigResult `json:"items"`
+}
+
+type PromoteConfigResult struct {
+	Source *PromotedCluster `json:"source"`
+	Target *PromotedCluster `json:"target"`
+	// TemplateInfo is the template of the target cluster after promotion, it's nil if unchanged
+	TemplateInfo *codemodels.TemplateInfo `json:"templateInfo,omitempty"`
+	// BuildConfig and TemplateConfig are the json patches from the configs of the target cluster to the source's
+	BuildConfig    []jsonpatch.JsonPatchOperation `json:"buildConfig"`
+	TemplateConfig []jsonpatch.JsonPatchOperation `json:"templateConfig"`
+	// Promoted indicates whether the config is promoted to the target cluster
+	Promoted bool   `json:"promoted"`
+	Error    string `json:"error,omitempty"`
+}
+
+func (r *PromoteConfigResult) changed() bool {
+	return r.TemplateInfo != nil || len(r.BuildConfig) > 0 || len(r.TemplateConfig) > 0
+}
+
+type PromotedCluster struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Scope *Scope `json:"scope"`
+}
+
+func newPromotedCluster(cluster *models.Cluster) *PromotedCluster {
+	return &PromotedCluster{
+		ID:   cluster.ID,
+		Name: cluster.Name,
+		Scope: &Scope{
+			Environment: cluster.EnvironmentName,
+			Region:      cluster.RegionName,
+		},
+	}
+}
@@ -28,6 +28,18 @@ type DeployRequest struct {
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	ImageTag    string `json:"imageTag"`
+
+	DeployWindowOverride *DeployWindowOverride `json:"deployWindowOverride,omitempty"`
+}
+
+type RestartRequest struct {
+	DeployWindowOverride *DeployWindowOverride `json:"deployWindowOverride,omitempty"`
+}
+
+// DeployWindowOverride overrides the deploy windows forbidding the action,
+// only owners of the cluster can override them
+type DeployWindowOverride struct {
+	Reason string `json:"reason"`
 }
 
 type ExecuteActionRequest struct {
@@ -84,5 +84,6 @@ type GetStepResponse struct {
 	Replicas     []int   `json:"replicas"`
 	ManualPaused bool    `json:"manualPaused"`
 	AutoPromote  bool    `json:"autoPromote"`
+	Aborted      bool    `json:"aborted"`
 	Extra        *string `json:"extra"`
 }
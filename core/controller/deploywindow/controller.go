@@ -0,0 +1,232 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploywindow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	deploywindowmanager "github.com/horizoncd/horizon/pkg/deploywindow/manager"
+	"github.com/horizoncd/horizon/pkg/deploywindow/models"
+	deploywindowservice "github.com/horizoncd/horizon/pkg/deploywindow/service"
+	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	Create(ctx context.Context, resourceType string, resourceID uint,
+		request *CreateOrUpdateRequest) (*DeployWindow, error)
+	List(ctx context.Context, resourceType string, resourceID uint) ([]*DeployWindow, error)
+	Get(ctx context.Context, resourceType string, resourceID uint, id uint) (*DeployWindow, error)
+	Update(ctx context.Context, resourceType string, resourceID uint, id uint,
+		request *CreateOrUpdateRequest) (*DeployWindow, error)
+	Delete(ctx context.Context, resourceType string, resourceID uint, id uint) error
+}
+
+type controller struct {
+	deployWindowMgr deploywindowmanager.Manager
+	envMgr          envmanager.Manager
+	groupMgr        groupmanager.Manager
+	applicationMgr  appmanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		deployWindowMgr: param.DeployWindowMgr,
+		envMgr:          param.EnvMgr,
+		groupMgr:        param.GroupMgr,
+		applicationMgr:  param.ApplicationMgr,
+	}
+}
+
+// checkResource checks the resource exists. Environments are not authorized by members,
+// so only admins are allowed to change the deploy windows of environments.
+func (c *controller) checkResource(ctx context.Context, resourceType string, resourceID uint, write bool) error {
+	switch resourceType {
+	case common.ResourceEnvironment:
+		if write {
+			currentUser, err := common.UserFromContext(ctx)
+			if err != nil {
+				return err
+			}
+			if !currentUser.IsAdmin() {
+				return perror.Wrap(herrors.ErrForbidden, "only admins can change deploy windows of environments")
+			}
+		}
+		_, err := c.envMgr.GetByID(ctx, resourceID)
+		return err
+	case common.ResourceGroup:
+		_, err := c.groupMgr.GetByID(ctx, resourceID)
+		return err
+	case common.ResourceApplication:
+		_, err := c.applicationMgr.GetByID(ctx, resourceID)
+		return err
+	default:
+		return perror.Wrapf(herrors.ErrParamInvalid, "unsupported resource type: %s", resourceType)
+	}
+}
+
+// get gets the window and checks it's attached to the resource
+func (c *controller) get(ctx context.Context, resourceType string,
+	resourceID uint, id uint) (*models.DeployWindow, error) {
+	window, err := c.deployWindowMgr.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if window.ResourceType != resourceType || window.ResourceID != resourceID {
+		return nil, herrors.NewErrNotFound(herrors.DeployWindowInDB,
+			fmt.Sprintf("deploy window %d not found in %s %d", id, resourceType, resourceID))
+	}
+	return window, nil
+}
+
+func (c *controller) validate(ctx context.Context, window *models.DeployWindow) error {
+	if window.Timezone == "" {
+		window.Timezone = time.UTC.String()
+	}
+	if err := deploywindowservice.Validate(window); err != nil {
+		return err
+	}
+	if window.Environment != "" {
+		if _, err := c.envMgr.GetByName(ctx, window.Environment); err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				return perror.Wrapf(herrors.ErrParamInvalid, "environment %s not found", window.Environment)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *controller) Create(ctx context.Context, resourceType string, resourceID uint,
+	request *CreateOrUpdateRequest) (*DeployWindow, error) {
+	const op = "deploy window controller: create"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := c.checkResource(ctx, resourceType, resourceID, true); err != nil {
+		return nil, err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	window := &models.DeployWindow{
+		Name:         request.Name,
+		Description:  request.Description,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Environment:  request.Environment,
+		Kind:         request.Kind,
+		Schedule:     request.Schedule,
+		Duration:     request.Duration,
+		Timezone:     request.Timezone,
+		CreatedBy:    currentUser.GetID(),
+		UpdatedBy:    currentUser.GetID(),
+	}
+	if err := c.validate(ctx, window); err != nil {
+		return nil, err
+	}
+	window, err = c.deployWindowMgr.Create(ctx, window)
+	if err != nil {
+		return nil, err
+	}
+	return ofDeployWindow(window), nil
+}
+
+func (c *controller) List(ctx context.Context, resourceType string, resourceID uint) ([]*DeployWindow, error) {
+	const op = "deploy window controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := c.checkResource(ctx, resourceType, resourceID, false); err != nil {
+		return nil, err
+	}
+	windows, err := c.deployWindowMgr.List(ctx, resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*DeployWindow, 0, len(windows))
+	for _, window := range windows {
+		result = append(result, ofDeployWindow(window))
+	}
+	return result, nil
+}
+
+func (c *controller) Get(ctx context.Context, resourceType string,
+	resourceID uint, id uint) (*DeployWindow, error) {
+	const op = "deploy window controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	window, err := c.get(ctx, resourceType, resourceID, id)
+	if err != nil {
+		return nil, err
+	}
+	return ofDeployWindow(window), nil
+}
+
+func (c *controller) Update(ctx context.Context, resourceType string, resourceID uint, id uint,
+	request *CreateOrUpdateRequest) (*DeployWindow, error) {
+	const op = "deploy window controller: update"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := c.checkResource(ctx, resourceType, resourceID, true); err != nil {
+		return nil, err
+	}
+	window, err := c.get(ctx, resourceType, resourceID, id)
+	if err != nil {
+		return nil, err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	window.Name = request.Name
+	window.Description = request.Description
+	window.Environment = request.Environment
+	window.Kind = request.Kind
+	window.Schedule = request.Schedule
+	window.Duration = request.Duration
+	window.Timezone = request.Timezone
+	window.UpdatedBy = currentUser.GetID()
+	if err := c.validate(ctx, window); err != nil {
+		return nil, err
+	}
+	window, err = c.deployWindowMgr.Update(ctx, window)
+	if err != nil {
+		return nil, err
+	}
+	return ofDeployWindow(window), nil
+}
+
+func (c *controller) Delete(ctx context.Context, resourceType string, resourceID uint, id uint) error {
+	const op = "deploy window controller: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := c.checkResource(ctx, resourceType, resourceID, true); err != nil {
+		return err
+	}
+	if _, err := c.get(ctx, resourceType, resourceID, id); err != nil {
+		return err
+	}
+	return c.deployWindowMgr.Delete(ctx, id)
+}
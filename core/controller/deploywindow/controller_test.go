@@ -0,0 +1,102 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploywindow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/deploywindow/models"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+)
+
+func TestController(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&models.DeployWindow{}, &envmodels.Environment{},
+		&groupmodels.Group{}, &appmodels.Application{}))
+	manager := managerparam.InitManager(db)
+	c := NewController(&param.Param{Manager: manager})
+
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "Tony", ID: 1})
+	adminCtx := common.WithContext(context.Background(),
+		&userauth.DefaultInfo{Name: "admin", ID: 2, Admin: true})
+
+	env, err := manager.EnvMgr.CreateEnvironment(ctx, &envmodels.Environment{Name: "online"})
+	assert.Nil(t, err)
+	group := &groupmodels.Group{Name: "group", Path: "group"}
+	assert.Nil(t, db.Create(group).Error)
+
+	request := &CreateOrUpdateRequest{
+		Name:        "weekend",
+		Description: "no deploys on weekends",
+		Environment: env.Name,
+		Kind:        models.KindFreeze,
+		Schedule:    "0 18 * * 5",
+		Duration:    2*24*60 + 6*60,
+	}
+	window, err := c.Create(ctx, common.ResourceGroup, group.ID, request)
+	assert.Nil(t, err)
+	assert.Equal(t, "UTC", window.Timezone)
+	assert.Equal(t, uint(1), window.CreatedBy)
+
+	_, err = c.Create(ctx, common.ResourceEnvironment, env.ID, request)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	_, err = c.Create(adminCtx, common.ResourceEnvironment, env.ID, request)
+	assert.Nil(t, err)
+
+	_, err = c.Create(ctx, common.ResourceCluster, 1, request)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.Create(ctx, common.ResourceGroup, group.ID, &CreateOrUpdateRequest{
+		Name:        "weekend",
+		Environment: "nonexistent",
+		Kind:        models.KindFreeze,
+		Schedule:    "0 18 * * 5",
+		Duration:    60,
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	windows, err := c.List(ctx, common.ResourceGroup, group.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(windows))
+	assert.Equal(t, window.ID, windows[0].ID)
+
+	request.Kind = models.KindAllow
+	request.Timezone = "Asia/Shanghai"
+	window, err = c.Update(ctx, common.ResourceGroup, group.ID, window.ID, request)
+	assert.Nil(t, err)
+	assert.Equal(t, models.KindAllow, window.Kind)
+	assert.Equal(t, "Asia/Shanghai", window.Timezone)
+
+	// the window is not attached to the environment
+	_, err = c.Get(ctx, common.ResourceEnvironment, env.ID, window.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	assert.Nil(t, c.Delete(ctx, common.ResourceGroup, group.ID, window.ID))
+	_, err = c.Get(ctx, common.ResourceGroup, group.ID, window.ID)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
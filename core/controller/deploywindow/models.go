@@ -0,0 +1,77 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploywindow
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/deploywindow/models"
+	deploywindowservice "github.com/horizoncd/horizon/pkg/deploywindow/service"
+)
+
+type CreateOrUpdateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Environment limits the window to clusters of the environment, empty means all the environments
+	Environment string `json:"environment"`
+	// Kind is freeze or allow
+	Kind string `json:"kind"`
+	// Schedule is a standard cron expression, such as "0 22 * * 5" which opens at 22:00 on every Friday
+	Schedule string `json:"schedule"`
+	// Duration is minutes for which the window stays open after each activation of schedule
+	Duration uint `json:"duration"`
+	// Timezone is an IANA time zone name such as Asia/Shanghai, default to UTC
+	Timezone string `json:"timezone"`
+}
+
+type DeployWindow struct {
+	ID           uint      `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	ResourceType string    `json:"resourceType"`
+	ResourceID   uint      `json:"resourceID"`
+	Environment  string    `json:"environment"`
+	Kind         string    `json:"kind"`
+	Schedule     string    `json:"schedule"`
+	Duration     uint      `json:"duration"`
+	Timezone     string    `json:"timezone"`
+	Open         bool      `json:"open"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+	CreatedBy    uint      `json:"createdBy"`
+	UpdatedBy    uint      `json:"updatedBy"`
+}
+
+func ofDeployWindow(window *models.DeployWindow) *DeployWindow {
+	// windows are validated when saved, so the error can be ignored
+	open, _ := deploywindowservice.IsOpen(window, time.Now())
+	return &DeployWindow{
+		ID:           window.ID,
+		Name:         window.Name,
+		Description:  window.Description,
+		ResourceType: window.ResourceType,
+		ResourceID:   window.ResourceID,
+		Environment:  window.Environment,
+		Kind:         window.Kind,
+		Schedule:     window.Schedule,
+		Duration:     window.Duration,
+		Timezone:     window.Timezone,
+		Open:         open,
+		CreatedAt:    window.CreatedAt,
+		UpdatedAt:    window.UpdatedAt,
+		CreatedBy:    window.CreatedBy,
+		UpdatedBy:    window.UpdatedBy,
+	}
+}
@@ -16,23 +16,51 @@ package event
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"time"
 
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	hauth "github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
+	"github.com/horizoncd/horizon/pkg/event/models"
+	membermanager "github.com/horizoncd/horizon/pkg/member/manager"
 	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/rbac"
+	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 )
 
+const (
+	_streamPollInterval = time.Second
+	_streamBatchSize    = 100
+)
+
 type Controller interface {
 	ListSupportEvents(ctx context.Context) map[string]string
+	// StreamEvents streams the events matching the filter as they are written,
+	// only the events on resources which the current user can get are sent.
+	// The channel is closed when ctx is done.
+	StreamEvents(ctx context.Context, filter *StreamFilter) (<-chan *Event, error)
 }
 
 type controller struct {
-	eventMgr eventmanager.Manager
+	eventMgr     eventmanager.Manager
+	memberMgr    membermanager.Manager
+	authorizer   rbac.Authorizer
+	pollInterval time.Duration
 }
 
-func NewController(param *param.Param) Controller {
+func NewController(param *param.Param, authorizer rbac.Authorizer) Controller {
 	return &controller{
-		eventMgr: param.EventMgr,
+		eventMgr:     param.EventMgr,
+		memberMgr:    param.MemberMgr,
+		authorizer:   authorizer,
+		pollInterval: _streamPollInterval,
 	}
 }
 
@@ -42,3 +70,146 @@ func (c *controller) ListSupportEvents(ctx context.Context) map[string]string {
 
 	return c.eventMgr.ListSupportEvents()
 }
+
+func (c *controller) StreamEvents(ctx context.Context, filter *StreamFilter) (<-chan *Event, error) {
+	const op = "event controller: stream events"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keywords, err := c.validateStreamFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// reject the stream directly if the user can not get the resource to watch
+	authorized := map[string]bool{}
+	if filter.ResourceType != "" && filter.ResourceID != 0 {
+		if !c.authorize(ctx, currentUser, authorized, filter.ResourceType, filter.ResourceID) {
+			return nil, perror.Wrapf(herrors.ErrForbidden,
+				"no privilege to get %s %d", filter.ResourceType, filter.ResourceID)
+		}
+	}
+
+	var cursor uint
+	if filter.Cursor != nil {
+		cursor = *filter.Cursor
+	} else {
+		cursor, err = c.eventMgr.GetLatestEventID(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ch := make(chan *Event)
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(c.pollInterval)
+		defer ticker.Stop()
+		for {
+			keywords[common.StartID] = cursor
+			events, err := c.eventMgr.ListEvents(ctx, q.New(keywords))
+			if err != nil {
+				log.Errorf(ctx, "failed to list events after %d, err: %s", cursor, err.Error())
+			}
+			for _, event := range events {
+				cursor = event.ID
+				if !c.authorizeEvent(ctx, currentUser, authorized, event) {
+					continue
+				}
+				select {
+				case ch <- ofEvent(event):
+				case <-ctx.Done():
+					return
+				}
+			}
+			// list the remaining events without waiting
+			if len(events) == _streamBatchSize {
+				continue
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// validateStreamFilter validates the filter and converts it to keywords to list events
+func (c *controller) validateStreamFilter(filter *StreamFilter) (q.KeyWords, error) {
+	keywords := q.KeyWords{
+		common.Limit: _streamBatchSize,
+	}
+	if filter.ResourceID != 0 && filter.ResourceType == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "resourceType is required when resourceID is set")
+	}
+	if filter.ResourceType != "" {
+		keywords[common.ParamResourceType] = filter.ResourceType
+	}
+	if filter.ResourceID != 0 {
+		keywords[common.ParamResourceID] = filter.ResourceID
+	}
+	if len(filter.EventTypes) > 0 {
+		supportedEvents := c.eventMgr.ListSupportEvents()
+		for _, eventType := range filter.EventTypes {
+			if _, ok := supportedEvents[eventType]; !ok {
+				return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported event type: %s", eventType)
+			}
+		}
+		keywords[common.EventType] = filter.EventTypes
+	}
+	return keywords, nil
+}
+
+// authorizeEvent checks if the user can get the resource of event
+func (c *controller) authorizeEvent(ctx context.Context, user userauth.User,
+	authorized map[string]bool, event *models.Event) bool {
+	switch event.ResourceType {
+	case common.ResourceRole:
+		// roles are visible to everyone
+		return true
+	case common.ResourceMember:
+		// members are authorized by the resource which they belong to
+		member, err := c.memberMgr.GetByIDIncludeSoftDelete(ctx, event.ResourceID)
+		if err != nil {
+			log.Warningf(ctx, "failed to get member %d of event %d, err: %s",
+				event.ResourceID, event.ID, err.Error())
+			return false
+		}
+		return c.authorize(ctx, user, authorized, string(member.ResourceType), member.ResourceID)
+	default:
+		return c.authorize(ctx, user, authorized, event.ResourceType, event.ResourceID)
+	}
+}
+
+// authorize checks if the user can get the resource by rbac, the decisions are cached in authorized
+func (c *controller) authorize(ctx context.Context, user userauth.User,
+	authorized map[string]bool, resourceType string, resourceID uint) bool {
+	key := fmt.Sprintf("%s/%d", resourceType, resourceID)
+	if allowed, ok := authorized[key]; ok {
+		return allowed
+	}
+
+	name := strconv.FormatUint(uint64(resourceID), 10)
+	decision, reason, err := c.authorizer.Authorize(ctx, hauth.AttributesRecord{
+		User:            user,
+		Verb:            "get",
+		APIGroup:        common.GroupCore,
+		APIVersion:      "v2",
+		Resource:        resourceType,
+		Name:            name,
+		ResourceRequest: true,
+		Path:            fmt.Sprintf("/apis/%s/v2/%s/%s", common.GroupCore, resourceType, name),
+	})
+	if err != nil {
+		log.Warningf(ctx, "failed to authorize %s for user %s, err: %s", key, user.String(), err.Error())
+		return false
+	}
+	log.Debugf(ctx, "authorize %s for user %s: %s", key, user.String(), reason)
+	authorized[key] = decision == hauth.DecisionAllow
+	return authorized[key]
+}
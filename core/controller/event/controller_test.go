@@ -0,0 +1,126 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	rbacmock "github.com/horizoncd/horizon/mock/pkg/rbac"
+	hauth "github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/event/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+)
+
+func TestStreamEvents(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&models.Event{}, &membermodels.Member{}))
+	mgr := managerparam.InitManager(db)
+	// nolint
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+
+	// only getting application 1 is allowed
+	mockCtl := gomock.NewController(t)
+	authorizer := rbacmock.NewMockAuthorizer(mockCtl)
+	authorizer.EXPECT().Authorize(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, attr hauth.Attributes) (hauth.Decision, string, error) {
+			if attr.GetVerb() == "get" && attr.GetResource() == common.ResourceApplication && attr.GetName() == "1" {
+				return hauth.DecisionAllow, "allowed", nil
+			}
+			return hauth.DecisionDeny, "denied", nil
+		}).AnyTimes()
+
+	ctl := NewController(&param.Param{Manager: mgr}, authorizer).(*controller)
+	ctl.pollInterval = 10 * time.Millisecond
+
+	createEvent := func(resourceType string, resourceID uint, eventType string) *models.Event {
+		events, err := mgr.EventMgr.CreateEvent(ctx, &models.Event{
+			EventSummary: models.EventSummary{
+				ResourceType: resourceType,
+				ResourceID:   resourceID,
+				EventType:    eventType,
+			},
+		})
+		assert.Nil(t, err)
+		return events[0]
+	}
+	receive := func(ch <-chan *Event) *Event {
+		select {
+		case e := <-ch:
+			return e
+		case <-time.After(time.Second):
+			return nil
+		}
+	}
+
+	old := createEvent(common.ResourceApplication, 1, models.ApplicationCreated)
+
+	// invalid filters
+	_, err := ctl.StreamEvents(ctx, &StreamFilter{ResourceID: 1})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = ctl.StreamEvents(ctx, &StreamFilter{EventTypes: []string{"unknown"}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	// watching a resource not authorized
+	_, err = ctl.StreamEvents(ctx, &StreamFilter{ResourceType: common.ResourceApplication, ResourceID: 2})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	// stream starts from new events, events of unauthorized resources are skipped
+	streamCtx, cancel := context.WithCancel(ctx)
+	ch, err := ctl.StreamEvents(streamCtx, &StreamFilter{})
+	assert.Nil(t, err)
+	createEvent(common.ResourceApplication, 2, models.ApplicationCreated)
+	updated := createEvent(common.ResourceApplication, 1, models.ApplicationUpdated)
+	role := createEvent(common.ResourceRole, 1, models.RoleCreated)
+	e := receive(ch)
+	assert.NotNil(t, e)
+	assert.Equal(t, updated.ID, e.ID)
+	assert.Equal(t, models.ApplicationUpdated, e.EventType)
+	e = receive(ch)
+	assert.NotNil(t, e)
+	assert.Equal(t, role.ID, e.ID)
+	cancel()
+	_, ok := <-ch
+	assert.False(t, ok)
+
+	// resume from the cursor with filters
+	cursor := old.ID
+	streamCtx, cancel = context.WithCancel(ctx)
+	defer cancel()
+	ch, err = ctl.StreamEvents(streamCtx, &StreamFilter{
+		ResourceType: common.ResourceApplication,
+		ResourceID:   1,
+		EventTypes:   []string{models.ApplicationUpdated},
+		Cursor:       &cursor,
+	})
+	assert.Nil(t, err)
+	e = receive(ch)
+	assert.NotNil(t, e)
+	assert.Equal(t, updated.ID, e.ID)
+	assert.Nil(t, receive(ch))
+}
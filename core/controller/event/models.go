@@ -0,0 +1,59 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/event/models"
+)
+
+// StreamFilter filters the events to stream
+type StreamFilter struct {
+	ResourceType string
+	ResourceID   uint
+	EventTypes   []string
+	// Cursor is the id of the last event received by the client, the events after it are streamed.
+	// Streaming starts from new events if Cursor is nil.
+	Cursor *uint
+}
+
+// Event is the event sent to the stream
+type Event struct {
+	ID           uint      `json:"id"`
+	ResourceType string    `json:"resourceType"`
+	ResourceID   uint      `json:"resourceID"`
+	EventType    string    `json:"eventType"`
+	Extra        string    `json:"extra,omitempty"`
+	ReqID        string    `json:"reqID,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	CreatedBy    uint      `json:"createdBy"`
+}
+
+func ofEvent(event *models.Event) *Event {
+	e := &Event{
+		ID:           event.ID,
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		EventType:    event.EventType,
+		ReqID:        event.ReqID,
+		CreatedAt:    event.CreatedAt,
+		CreatedBy:    event.CreatedBy,
+	}
+	if event.Extra != nil {
+		e.Extra = *event.Extra
+	}
+	return e
+}
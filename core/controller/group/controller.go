@@ -30,6 +30,8 @@ import (
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	"github.com/horizoncd/horizon/pkg/group/models"
 	"github.com/horizoncd/horizon/pkg/group/service"
@@ -39,6 +41,7 @@ import (
 	tmanager "github.com/horizoncd/horizon/pkg/template/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	"github.com/horizoncd/horizon/pkg/util/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 )
 
@@ -83,6 +86,7 @@ type controller struct {
 	memberSvc          memberservice.Service
 	templateMgr        tmanager.Manager
 	templateReleaseMgr trmanager.Manager
+	eventSvc           eventservice.Service
 }
 
 // NewController initializes a new group controller
@@ -94,6 +98,7 @@ func NewController(param *param.Param) Controller {
 		memberSvc:          param.MemberService,
 		templateMgr:        param.TemplateMgr,
 		templateReleaseMgr: param.TemplateReleaseMgr,
+		eventSvc:           param.EventSvc,
 	}
 }
 
@@ -317,6 +322,7 @@ func (c *controller) UpdateBasic(ctx context.Context, id uint, updateGroup *Upda
 		return err
 	}
 
+	c.recordEventByID(ctx, id, eventmodels.GroupUpdated)
 	return nil
 }
 
@@ -327,6 +333,7 @@ func (c *controller) Transfer(ctx context.Context, id, newParentID uint) error {
 		return err
 	}
 
+	c.recordEventByID(ctx, id, eventmodels.GroupTransferred)
 	return nil
 }
 
@@ -339,6 +346,7 @@ func (c *controller) CreateGroup(ctx context.Context, newGroup *NewGroup) (uint,
 		return 0, err
 	}
 
+	c.recordEvent(ctx, group, eventmodels.GroupCreated)
 	return group.ID, err
 }
 
@@ -571,6 +579,15 @@ func (c *controller) GetByID(ctx context.Context, id uint) (*StructuredGroup, er
 func (c *controller) Delete(ctx context.Context, id uint) error {
 	const op = "group *controller: delete group by id"
 
+	// get the group before deleting, so that the event can be associated with its ancestors
+	group, err := c.groupManager.GetByID(ctx, id)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return errors.E(op, http.StatusNotFound, ErrCodeNotFound, fmt.Sprintf("no group matching the id: %d", id))
+		}
+		return err
+	}
+
 	rowsAffected, err := c.groupManager.Delete(ctx, id)
 	if err != nil {
 		if err == herrors.ErrGroupHasChildren {
@@ -582,6 +599,7 @@ func (c *controller) Delete(ctx context.Context, id uint) error {
 		return errors.E(op, http.StatusNotFound, ErrCodeNotFound, fmt.Sprintf("no group matching the id: %d", id))
 	}
 
+	c.recordEvent(ctx, group, eventmodels.GroupDeleted)
 	return nil
 }
 
@@ -695,5 +713,32 @@ func (c *controller) UpdateRegionSelector(ctx context.Context, id uint, regionSe
 		return herrors.NewErrUpdateFailed(herrors.GroupInDB, err.Error())
 	}
 
-	return c.groupManager.UpdateRegionSelector(ctx, id, string(regionSelectorBytes))
+	if err := c.groupManager.UpdateRegionSelector(ctx, id, string(regionSelectorBytes)); err != nil {
+		return err
+	}
+
+	c.recordEventByID(ctx, id, eventmodels.GroupUpdated)
+	return nil
+}
+
+// recordEventByID records an event of the group after it has been changed
+func (c *controller) recordEventByID(ctx context.Context, id uint, eventType string) {
+	group, err := c.groupManager.GetByID(ctx, id)
+	if err != nil {
+		log.Warningf(ctx, "failed to get group %d for event %s, err: %s", id, eventType, err.Error())
+		return
+	}
+	c.recordEvent(ctx, group, eventType)
+}
+
+// recordEvent records the name, path and traversalIDs of group into the event,
+// so that the group can be identified even if it has been deleted
+func (c *controller) recordEvent(ctx context.Context, group *models.Group, eventType string) {
+	c.eventSvc.CreateResourceEventIgnoreError(ctx, common.ResourceGroup, group.ID, eventType,
+		&eventmodels.ResourceExtra{
+			Name:         group.Name,
+			Path:         group.Path,
+			GroupID:      group.ParentID,
+			TraversalIDs: group.TraversalIDs,
+		})
 }
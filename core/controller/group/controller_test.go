@@ -16,6 +16,7 @@ package group
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"reflect"
@@ -28,6 +29,7 @@ import (
 
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
 	groupmanagermock "github.com/horizoncd/horizon/mock/pkg/group/manager"
 	membermock "github.com/horizoncd/horizon/mock/pkg/member/service"
 	templatemock "github.com/horizoncd/horizon/mock/pkg/template/manager"
@@ -35,6 +37,8 @@ import (
 	applicationdao "github.com/horizoncd/horizon/pkg/application/dao"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	"github.com/horizoncd/horizon/pkg/group/models"
 	"github.com/horizoncd/horizon/pkg/group/service"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
@@ -52,7 +56,7 @@ var (
 	db, _    = orm.NewSqliteDB("")
 	ctx      = context.TODO()
 	manager  = managerparam.InitManager(db)
-	groupCtl = NewController(&param.Param{Manager: manager})
+	groupCtl = NewController(&param.Param{Manager: manager, EventSvc: eventservice.New(manager)})
 )
 
 func GroupValueEqual(g1, g2 *models.Group) bool {
@@ -92,6 +96,11 @@ func init() {
 		fmt.Printf("%+v", err)
 		os.Exit(1)
 	}
+	err = db.AutoMigrate(&eventmodels.Event{})
+	if err != nil {
+		fmt.Printf("%+v", err)
+		os.Exit(1)
+	}
 
 	callbacks.RegisterCustomCallbacks(db)
 }
@@ -103,6 +112,7 @@ func TestGetAuthedGroups(t *testing.T) {
 	myGroupCtl := NewController(&param.Param{
 		Manager:       manager,
 		MemberService: memberMock,
+		EventSvc:      eventservice.New(manager),
 	})
 
 	type args struct {
@@ -527,6 +537,23 @@ func TestControllerDelete(t *testing.T) {
 		})
 	}
 
+	events, err := manager.EventMgr.ListEvents(ctx, &q.Query{
+		Keywords: q.KeyWords{common.StartID: 0, common.Limit: 100},
+	})
+	assert.Nil(t, err)
+	eventTypes := make(map[string]string)
+	for _, event := range events {
+		if event.ResourceType == common.ResourceGroup && event.ResourceID == id {
+			eventTypes[event.EventType] = *event.Extra
+		}
+	}
+	assert.Contains(t, eventTypes, eventmodels.GroupCreated)
+	assert.Contains(t, eventTypes, eventmodels.GroupDeleted)
+	extra := eventmodels.ResourceExtra{}
+	assert.Nil(t, json.Unmarshal([]byte(eventTypes[eventmodels.GroupDeleted]), &extra))
+	assert.Equal(t, newRootGroup.Name, extra.Name)
+	assert.Equal(t, fmt.Sprintf("%d", id), extra.TraversalIDs)
+
 	db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Group{})
 }
 
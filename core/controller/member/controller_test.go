@@ -91,12 +91,12 @@ func createContext(t *testing.T) {
 		&tmodels.Template{}, &trmodels.TemplateRelease{}, &eventmodels.Event{})
 	assert.Nil(t, err)
 
-	groupCtl = group.NewController(&param.Param{Manager: manager})
+	eventSvc = eventservice.New(manager)
+	groupCtl = group.NewController(&param.Param{Manager: manager, EventSvc: eventSvc})
 	groupSvc = groupservice.NewService(manager)
 
 	applicationSvc = applicationservice.NewService(groupSvc, manager)
 	clusterSvc = clusterservice.NewService(applicationSvc, nil, manager)
-	eventSvc = eventservice.New(manager)
 }
 
 func MemberSame(m1, m2 Member) bool {
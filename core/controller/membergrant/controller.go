@@ -0,0 +1,389 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package membergrant
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	membermanager "github.com/horizoncd/horizon/pkg/member/manager"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	grantmanager "github.com/horizoncd/horizon/pkg/membergrant/manager"
+	"github.com/horizoncd/horizon/pkg/membergrant/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const (
+	_minDuration = time.Minute
+	_maxDuration = 30 * 24 * time.Hour
+)
+
+type Controller interface {
+	// CreateMemberGrant requests a temporary role on the resource for current user
+	CreateMemberGrant(ctx context.Context, resourceType string, resourceID uint,
+		request *CreateMemberGrantRequest) (*MemberGrant, error)
+	// GetMemberGrant gets a member grant by id, grants are only visible to the requester and owners of the resource
+	GetMemberGrant(ctx context.Context, id uint) (*MemberGrant, error)
+	// ListMemberGrants lists member grants filtered by resource, user and status,
+	// only the grants requested by current user and the grants on resources owned by current user are listed
+	ListMemberGrants(ctx context.Context, query *q.Query) ([]*MemberGrant, int64, error)
+	// ApproveMemberGrant approves a pending grant and creates a member which expires after the duration,
+	// only the owners of the resource can approve it
+	ApproveMemberGrant(ctx context.Context, id uint, request *ReviewMemberGrantRequest) (*MemberGrant, error)
+	// RejectMemberGrant rejects a pending grant, only the owners of the resource can reject it
+	RejectMemberGrant(ctx context.Context, id uint, request *ReviewMemberGrantRequest) (*MemberGrant, error)
+	// ListExpiredMemberGrants lists the approved grants which have expired
+	ListExpiredMemberGrants(ctx context.Context, query *q.Query) ([]*MemberGrant, error)
+	// ExpireMemberGrant removes the member created by the grant and marks the grant expired
+	ExpireMemberGrant(ctx context.Context, id uint) error
+}
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		grantMgr:       param.MemberGrantMgr,
+		memberMgr:      param.MemberMgr,
+		groupMgr:       param.GroupMgr,
+		applicationMgr: param.ApplicationMgr,
+		clusterMgr:     param.ClusterMgr,
+		memberService:  param.MemberService,
+		roleService:    param.RoleService,
+		eventSvc:       param.EventSvc,
+	}
+}
+
+type controller struct {
+	grantMgr       grantmanager.Manager
+	memberMgr      membermanager.Manager
+	groupMgr       groupmanager.Manager
+	applicationMgr applicationmanager.Manager
+	clusterMgr     clustermanager.Manager
+	memberService  memberservice.Service
+	roleService    role.Service
+	eventSvc       eventservice.Service
+}
+
+func (c *controller) CreateMemberGrant(ctx context.Context, resourceType string, resourceID uint,
+	request *CreateMemberGrantRequest) (*MemberGrant, error) {
+	const op = "member grant controller: create member grant"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := time.ParseDuration(request.Duration)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid duration %s: %v", request.Duration, err)
+	}
+	if duration < _minDuration || duration > _maxDuration {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"duration should be between %v and %v", _minDuration, _maxDuration)
+	}
+	if _, err := c.roleService.GetRole(ctx, request.Role); err != nil {
+		if perror.Cause(err) == role.ErrorRoleNotFound {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "role %s does not exist", request.Role)
+		}
+		return nil, err
+	}
+	if err := c.checkResource(ctx, resourceType, resourceID); err != nil {
+		return nil, err
+	}
+
+	grant, err := c.grantMgr.Create(ctx, &models.MemberGrant{
+		ResourceType:    resourceType,
+		ResourceID:      resourceID,
+		Role:            request.Role,
+		UserID:          currentUser.GetID(),
+		DurationSeconds: uint(duration.Seconds()),
+		Reason:          request.Reason,
+		Status:          models.StatusPending,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ofMemberGrantModel(grant), nil
+}
+
+func (c *controller) GetMemberGrant(ctx context.Context, id uint) (*MemberGrant, error) {
+	grant, err := c.grantMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if currentUser.IsAdmin() || grant.UserID == currentUser.GetID() {
+		return ofMemberGrantModel(grant), nil
+	}
+	if err := c.memberService.RequirePermissionEqualOrHigher(ctx, role.Owner,
+		grant.ResourceType, grant.ResourceID); err != nil {
+		return nil, perror.Wrapf(herrors.ErrForbidden,
+			"member grant %d is only visible to the requester and owners of %s/%d: %v",
+			grant.ID, grant.ResourceType, grant.ResourceID, err)
+	}
+	return ofMemberGrantModel(grant), nil
+}
+
+func (c *controller) ListMemberGrants(ctx context.Context, query *q.Query) ([]*MemberGrant, int64, error) {
+	visibility, err := c.visibility(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	if query == nil {
+		query = &q.Query{}
+	}
+	if visibility != nil {
+		if query.Keywords == nil {
+			query.Keywords = q.KeyWords{}
+		}
+		query.Keywords[common.MemberGrantQueryVisibleTo] = visibility
+	}
+	grants, total, err := c.grantMgr.List(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	return ofMemberGrantModels(grants), total, nil
+}
+
+func (c *controller) ApproveMemberGrant(ctx context.Context, id uint,
+	request *ReviewMemberGrantRequest) (*MemberGrant, error) {
+	const op = "member grant controller: approve member grant"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	grant, err := c.getPendingGrantToReview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. check the user is not a member yet, the expired member not cleaned yet is replaced when creating
+	memberInDB, err := c.memberMgr.Get(ctx, membermodels.ResourceType(grant.ResourceType), grant.ResourceID,
+		membermodels.MemberUser, grant.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if memberInDB != nil {
+		return nil, perror.Wrapf(herrors.ErrMemberAlreadyExists,
+			"user %d is already a member of %s/%d", grant.UserID, grant.ResourceType, grant.ResourceID)
+	}
+
+	// 2. create the member expiring after the duration
+	now := time.Now()
+	expiredAt := now.Add(time.Duration(grant.DurationSeconds) * time.Second)
+	member, err := c.memberMgr.Create(ctx, &membermodels.Member{
+		ResourceType: membermodels.ResourceType(grant.ResourceType),
+		ResourceID:   grant.ResourceID,
+		Role:         grant.Role,
+		MemberType:   membermodels.MemberUser,
+		MemberNameID: grant.UserID,
+		GrantedBy:    currentUser.GetID(),
+		ExpiredAt:    &expiredAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. mark the grant approved, roll back the member if the grant has been reviewed by others
+	grant.Status = models.StatusApproved
+	grant.ReviewedBy = currentUser.GetID()
+	grant.ReviewedAt = &now
+	grant.ReviewComment = request.Comment
+	grant.MemberID = member.ID
+	grant.ExpiredAt = &expiredAt
+	if err := c.grantMgr.UpdateStatusByID(ctx, grant.ID, models.StatusPending, grant); err != nil {
+		if err := c.memberMgr.DeleteMember(ctx, member.ID); err != nil {
+			log.Errorf(ctx, "failed to roll back member %d, err: %v", member.ID, err)
+		}
+		return nil, err
+	}
+
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceMember, member.ID,
+		eventmodels.MemberCreated, nil)
+	return ofMemberGrantModel(grant), nil
+}
+
+func (c *controller) RejectMemberGrant(ctx context.Context, id uint,
+	request *ReviewMemberGrantRequest) (*MemberGrant, error) {
+	const op = "member grant controller: reject member grant"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	grant, err := c.getPendingGrantToReview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	grant.Status = models.StatusRejected
+	grant.ReviewedBy = currentUser.GetID()
+	grant.ReviewedAt = &now
+	grant.ReviewComment = request.Comment
+	if err := c.grantMgr.UpdateStatusByID(ctx, grant.ID, models.StatusPending, grant); err != nil {
+		return nil, err
+	}
+	return ofMemberGrantModel(grant), nil
+}
+
+func (c *controller) ListExpiredMemberGrants(ctx context.Context, query *q.Query) ([]*MemberGrant, error) {
+	grants, err := c.grantMgr.ListExpired(ctx, time.Now(), query)
+	if err != nil {
+		return nil, err
+	}
+	return ofMemberGrantModels(grants), nil
+}
+
+func (c *controller) ExpireMemberGrant(ctx context.Context, id uint) error {
+	const op = "member grant controller: expire member grant"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	grant, err := c.grantMgr.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if grant.Status != models.StatusApproved || grant.ExpiredAt == nil || grant.ExpiredAt.After(now) {
+		return perror.Wrapf(herrors.ErrParamInvalid, "member grant %d has not expired", id)
+	}
+
+	// the member may have been removed, or been made permanent by owners
+	member, err := c.memberMgr.GetByID(ctx, grant.MemberID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return err
+		}
+	}
+	if member != nil && member.Expired(now) {
+		if err := c.deleteMember(ctx, member); err != nil {
+			return err
+		}
+	}
+
+	grant.Status = models.StatusExpired
+	return c.grantMgr.UpdateStatusByID(ctx, grant.ID, models.StatusApproved, grant)
+}
+
+// getPendingGrantToReview gets the grant and checks if current user can review it,
+// the reviewer should be an owner of the resource and can't be the requester
+func (c *controller) getPendingGrantToReview(ctx context.Context, id uint) (*models.MemberGrant, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	grant, err := c.grantMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if grant.Status != models.StatusPending {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"member grant %d has been %s", grant.ID, grant.Status)
+	}
+	if grant.UserID == currentUser.GetID() {
+		return nil, perror.Wrap(herrors.ErrForbidden, "cannot review the member grant requested by yourself")
+	}
+	if err := c.memberService.RequirePermissionEqualOrHigher(ctx, role.Owner,
+		grant.ResourceType, grant.ResourceID); err != nil {
+		return nil, perror.Wrapf(herrors.ErrForbidden,
+			"only owners of %s/%d can review the member grant: %v", grant.ResourceType, grant.ResourceID, err)
+	}
+	if err := c.memberService.RequirePermissionEqualOrHigher(ctx, grant.Role,
+		grant.ResourceType, grant.ResourceID); err != nil {
+		return nil, perror.Wrapf(herrors.ErrForbidden,
+			"cannot grant role %s higher than yours: %v", grant.Role, err)
+	}
+	return grant, nil
+}
+
+// visibility returns the grants visible to current user, or nil if all grants are visible.
+// Owners of the resource filtered by can see all grants on it, otherwise only the grants requested by
+// current user and the grants on resources which current user is a direct owner of are visible.
+func (c *controller) visibility(ctx context.Context, query *q.Query) (*models.Visibility, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if currentUser.IsAdmin() {
+		return nil, nil
+	}
+	if query != nil {
+		resourceType, _ := query.Keywords[common.MemberGrantQueryByResourceType].(string)
+		resourceID, _ := query.Keywords[common.MemberGrantQueryByResourceID].(uint)
+		if resourceType != "" && resourceID != 0 && c.memberService.RequirePermissionEqualOrHigher(ctx,
+			role.Owner, resourceType, resourceID) == nil {
+			return nil, nil
+		}
+	}
+
+	members, err := c.memberMgr.ListMembersByUserID(ctx, currentUser.GetID())
+	if err != nil {
+		return nil, err
+	}
+	visibility := &models.Visibility{UserID: currentUser.GetID()}
+	now := time.Now()
+	for _, member := range members {
+		if member.MemberType == membermodels.MemberUser && member.Role == role.Owner && !member.Expired(now) {
+			visibility.Resources = append(visibility.Resources, models.Resource{
+				Type: string(member.ResourceType),
+				ID:   member.ResourceID,
+			})
+		}
+	}
+	return visibility, nil
+}
+
+func (c *controller) deleteMember(ctx context.Context, member *membermodels.Member) error {
+	if err := c.memberMgr.DeleteMember(ctx, member.ID); err != nil {
+		return err
+	}
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceMember, member.ID,
+		eventmodels.MemberDeleted, nil)
+	return nil
+}
+
+func (c *controller) checkResource(ctx context.Context, resourceType string, resourceID uint) error {
+	var err error
+	switch resourceType {
+	case common.ResourceGroup:
+		_, err = c.groupMgr.GetByID(ctx, resourceID)
+	case common.ResourceApplication:
+		_, err = c.applicationMgr.GetByID(ctx, resourceID)
+	case common.ResourceCluster:
+		_, err = c.clusterMgr.GetByID(ctx, resourceID)
+	default:
+		err = perror.Wrap(herrors.ErrParamInvalid, fmt.Sprintf("unsupported resource type %s", resourceType))
+	}
+	return err
+}
@@ -0,0 +1,211 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package membergrant
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	"github.com/horizoncd/horizon/pkg/membergrant/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+func TestController(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&usermodels.User{}, &groupmodels.Group{}, &membermodels.Member{},
+		&models.MemberGrant{}, &eventmodels.Event{}))
+	manager := managerparam.InitManager(db)
+
+	roleService, err := role.NewFileRoleFrom2(context.TODO(), roleconfig.Config{
+		RolePriorityRankDesc: []string{role.Owner, "maintainer", "guest"},
+		DefaultRole:          "guest",
+		Roles:                []types.Role{{Name: role.Owner}, {Name: "maintainer"}, {Name: "guest"}},
+	})
+	assert.Nil(t, err)
+
+	owner := &usermodels.User{Name: "owner", Email: "owner@horizon.com"}
+	requester := &usermodels.User{Name: "requester", Email: "requester@horizon.com"}
+	assert.Nil(t, db.Create(owner).Error)
+	assert.Nil(t, db.Create(requester).Error)
+	stranger := &usermodels.User{Name: "stranger", Email: "stranger@horizon.com"}
+	assert.Nil(t, db.Create(stranger).Error)
+	group := &groupmodels.Group{Name: "group", Path: "group"}
+	assert.Nil(t, db.Create(group).Error)
+	group.TraversalIDs = "1"
+	assert.Nil(t, db.Save(group).Error)
+	_, err = manager.MemberMgr.Create(context.TODO(), &membermodels.Member{
+		ResourceType: membermodels.TypeGroup,
+		ResourceID:   group.ID,
+		Role:         role.Owner,
+		MemberNameID: owner.ID,
+	})
+	assert.Nil(t, err)
+
+	ownerCtx := common.WithContext(context.TODO(), &userauth.DefaultInfo{Name: owner.Name, ID: owner.ID})
+	requesterCtx := common.WithContext(context.TODO(),
+		&userauth.DefaultInfo{Name: requester.Name, ID: requester.ID})
+	strangerCtx := common.WithContext(context.TODO(),
+		&userauth.DefaultInfo{Name: stranger.Name, ID: stranger.ID})
+
+	c := NewController(&param.Param{
+		Manager:       manager,
+		MemberService: memberservice.NewService(roleService, nil, manager),
+		RoleService:   roleService,
+		EventSvc:      eventservice.New(manager),
+	})
+
+	// invalid requests
+	_, err = c.CreateMemberGrant(requesterCtx, common.ResourceGroup, group.ID,
+		&CreateMemberGrantRequest{Role: "maintainer", Duration: "1s"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.CreateMemberGrant(requesterCtx, common.ResourceGroup, group.ID,
+		&CreateMemberGrantRequest{Role: "not-exist", Duration: "1h"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// reject
+	grant, err := c.CreateMemberGrant(requesterCtx, common.ResourceGroup, group.ID,
+		&CreateMemberGrantRequest{Role: "maintainer", Duration: "1h", Reason: "fix online issue"})
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusPending, grant.Status)
+	assert.Equal(t, "1h0m0s", grant.Duration)
+	grant, err = c.RejectMemberGrant(ownerCtx, grant.ID, &ReviewMemberGrantRequest{Comment: "no"})
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusRejected, grant.Status)
+	_, err = c.ApproveMemberGrant(ownerCtx, grant.ID, &ReviewMemberGrantRequest{})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// approve
+	grant, err = c.CreateMemberGrant(requesterCtx, common.ResourceGroup, group.ID,
+		&CreateMemberGrantRequest{Role: "maintainer", Duration: "1h"})
+	assert.Nil(t, err)
+	_, err = c.ApproveMemberGrant(requesterCtx, grant.ID, &ReviewMemberGrantRequest{})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	grant, err = c.ApproveMemberGrant(ownerCtx, grant.ID, &ReviewMemberGrantRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusApproved, grant.Status)
+	assert.Equal(t, owner.ID, grant.ReviewedBy)
+	assert.NotNil(t, grant.ExpiredAt)
+
+	members, err := manager.MemberMgr.ListDirectMember(context.TODO(), membermodels.TypeGroup, group.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(members))
+
+	grants, total, err := c.ListMemberGrants(ownerCtx, q.New(q.KeyWords{
+		common.MemberGrantQueryByResourceType: common.ResourceGroup,
+		common.MemberGrantQueryByResourceID:   group.ID,
+		common.MemberGrantQueryByStatus:       models.StatusApproved,
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, grant.ID, grants[0].ID)
+
+	// grants are only visible to the requester and owners of the resource
+	for _, ctx := range []context.Context{ownerCtx, requesterCtx} {
+		_, err = c.GetMemberGrant(ctx, grant.ID)
+		assert.Nil(t, err)
+		_, total, err = c.ListMemberGrants(ctx, q.New(q.KeyWords{}))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), total)
+	}
+	_, err = c.GetMemberGrant(strangerCtx, grant.ID)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	_, total, err = c.ListMemberGrants(strangerCtx, q.New(q.KeyWords{
+		common.MemberGrantQueryByResourceType: common.ResourceGroup,
+		common.MemberGrantQueryByResourceID:   group.ID,
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+
+	// a pending grant can't be approved while the user is still a member
+	another, err := c.CreateMemberGrant(requesterCtx, common.ResourceGroup, group.ID,
+		&CreateMemberGrantRequest{Role: role.Owner, Duration: "1h"})
+	assert.Nil(t, err)
+	_, err = c.ApproveMemberGrant(ownerCtx, another.ID, &ReviewMemberGrantRequest{})
+	assert.Equal(t, herrors.ErrMemberAlreadyExists, perror.Cause(err))
+
+	// expire
+	expired, err := c.ListExpiredMemberGrants(ownerCtx, &q.Query{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(expired))
+
+	past := time.Now().Add(-time.Minute)
+	assert.Nil(t, db.Model(&membermodels.Member{}).Where("id = ?", grant.MemberID).
+		Update("expired_at", past).Error)
+	assert.Nil(t, db.Model(&models.MemberGrant{}).Where("id = ?", grant.ID).
+		Update("expired_at", past).Error)
+
+	members, err = manager.MemberMgr.ListDirectMember(context.TODO(), membermodels.TypeGroup, group.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(members))
+
+	expired, err = c.ListExpiredMemberGrants(ownerCtx, &q.Query{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(expired))
+	assert.Nil(t, c.ExpireMemberGrant(ownerCtx, grant.ID))
+
+	member, err := manager.MemberMgr.GetByID(context.TODO(), grant.MemberID)
+	assert.Nil(t, err)
+	assert.Nil(t, member)
+	grant, err = c.GetMemberGrant(ownerCtx, grant.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusExpired, grant.Status)
+
+	events, err := manager.EventMgr.ListEventsByRange(context.TODO(), 0, 100)
+	assert.Nil(t, err)
+	eventTypes := make([]string, 0, len(events))
+	for _, event := range events {
+		eventTypes = append(eventTypes, event.EventType)
+	}
+	assert.Equal(t, []string{eventmodels.MemberCreated, eventmodels.MemberDeleted}, eventTypes)
+
+	// the expired member which has not been removed yet is replaced by the new one
+	grant, err = c.CreateMemberGrant(requesterCtx, common.ResourceGroup, group.ID,
+		&CreateMemberGrantRequest{Role: "maintainer", Duration: "1h"})
+	assert.Nil(t, err)
+	grant, err = c.ApproveMemberGrant(ownerCtx, grant.ID, &ReviewMemberGrantRequest{})
+	assert.Nil(t, err)
+	assert.Nil(t, db.Model(&membermodels.Member{}).Where("id = ?", grant.MemberID).
+		Update("expired_at", past).Error)
+	member, err = manager.MemberMgr.Get(context.TODO(), membermodels.TypeGroup, group.ID,
+		membermodels.MemberUser, requester.ID)
+	assert.Nil(t, err)
+	assert.Nil(t, member)
+	grant, err = c.ApproveMemberGrant(ownerCtx, another.ID, &ReviewMemberGrantRequest{})
+	assert.Nil(t, err)
+	member, err = manager.MemberMgr.Get(context.TODO(), membermodels.TypeGroup, group.ID,
+		membermodels.MemberUser, requester.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, grant.MemberID, member.ID)
+	assert.Equal(t, role.Owner, member.Role)
+}
@@ -0,0 +1,78 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package membergrant
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/membergrant/models"
+)
+
+type CreateMemberGrantRequest struct {
+	Role string `json:"role"`
+	// Duration how long the member lasts after approved, such as 2h, 30m
+	Duration string `json:"duration"`
+	Reason   string `json:"reason"`
+}
+
+type ReviewMemberGrantRequest struct {
+	Comment string `json:"comment"`
+}
+
+type MemberGrant struct {
+	ID            uint       `json:"id"`
+	ResourceType  string     `json:"resourceType"`
+	ResourceID    uint       `json:"resourceID"`
+	Role          string     `json:"role"`
+	UserID        uint       `json:"userID"`
+	Duration      string     `json:"duration"`
+	Reason        string     `json:"reason"`
+	Status        string     `json:"status"`
+	ReviewedBy    uint       `json:"reviewedBy,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewedAt,omitempty"`
+	ReviewComment string     `json:"reviewComment,omitempty"`
+	MemberID      uint       `json:"memberID,omitempty"`
+	ExpiredAt     *time.Time `json:"expiredAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+func ofMemberGrantModel(grant *models.MemberGrant) *MemberGrant {
+	return &MemberGrant{
+		ID:            grant.ID,
+		ResourceType:  grant.ResourceType,
+		ResourceID:    grant.ResourceID,
+		Role:          grant.Role,
+		UserID:        grant.UserID,
+		Duration:      (time.Duration(grant.DurationSeconds) * time.Second).String(),
+		Reason:        grant.Reason,
+		Status:        grant.Status,
+		ReviewedBy:    grant.ReviewedBy,
+		ReviewedAt:    grant.ReviewedAt,
+		ReviewComment: grant.ReviewComment,
+		MemberID:      grant.MemberID,
+		ExpiredAt:     grant.ExpiredAt,
+		CreatedAt:     grant.CreatedAt,
+		UpdatedAt:     grant.UpdatedAt,
+	}
+}
+
+func ofMemberGrantModels(grants []*models.MemberGrant) []*MemberGrant {
+	ret := make([]*MemberGrant, 0, len(grants))
+	for _, grant := range grants {
+		ret = append(ret, ofMemberGrantModel(grant))
+	}
+	return ret
+}
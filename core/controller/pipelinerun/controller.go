@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/config"
@@ -43,6 +44,7 @@ import (
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	membermanager "github.com/horizoncd/horizon/pkg/member/manager"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
@@ -83,6 +85,17 @@ type Controller interface {
 		request *CreateOrUpdateCheckRunRequest) (*prmodels.CheckRun, error)
 	ListPRMessages(ctx context.Context, pipelineRunID uint, q *q.Query) (int, []*PRMessage, error)
 	CreatePRMessage(ctx context.Context, pipelineRunID uint, request *CreatePRMessageRequest) (*PRMessage, error)
+
+	// Approve approves the pending approval check runs of a pipelineRun which the current user can approve.
+	Approve(ctx context.Context, pipelinerunID uint, request *ApprovalRequest) error
+	// Reject rejects the pending approval check runs of a pipelineRun which the current user can approve,
+	// and the pipelineRun fails.
+	Reject(ctx context.Context, pipelinerunID uint, request *ApprovalRequest) error
+	ListApprovals(ctx context.Context, pipelinerunID uint) ([]*Approval, error)
+	// ListStaleApprovalCheckRuns lists pending approval check runs created before the time.
+	ListStaleApprovalCheckRuns(ctx context.Context, before time.Time, query *q.Query) ([]*prmodels.CheckRun, error)
+	// ExpireApprovalCheckRun cancels a pending approval check run, and the pending pipelineRun is cancelled.
+	ExpireApprovalCheckRun(ctx context.Context, checkRunID uint) error
 }
 
 const _userTypeBot = "bot"
@@ -98,6 +111,7 @@ type controller struct {
 	tokenSvc           tokensvc.Service
 	tokenConfig        token.Config
 	memberMgr          membermanager.Manager
+	memberSvc          memberservice.Service
 	templateReleaseMgr trmanager.Manager
 	commitGetter       code.GitGetter
 	clusterGitRepo     gitrepo.ClusterGitRepo
@@ -121,6 +135,7 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		commitGetter:       param.GitGetter,
 		appMgr:             param.ApplicationMgr,
 		memberMgr:          param.MemberMgr,
+		memberSvc:          param.MemberService,
 		regionMgr:          param.RegionMgr,
 		clusterGitRepo:     param.ClusterGitRepo,
 		userMgr:            param.UserMgr,
@@ -367,7 +382,20 @@ func (c *controller) UpdateCheckRunByID(ctx context.Context, checkRunID uint,
 	const op = "pipelinerun controller: update check run"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	err := c.prMgr.Check.UpdateByID(ctx, checkRunID, &prmodels.CheckRun{
+	checkRun, err := c.prMgr.Check.GetCheckRunByID(ctx, checkRunID)
+	if err != nil {
+		return err
+	}
+	check, err := c.prMgr.Check.Get(ctx, checkRun.CheckID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return err
+		}
+	} else if check.Kind == prmodels.CheckKindApproval {
+		return perror.Wrap(herrors.ErrParamInvalid, "check runs of approval checks can only be updated by approvers")
+	}
+
+	err = c.prMgr.Check.UpdateByID(ctx, checkRunID, &prmodels.CheckRun{
 		Name:      request.Name,
 		Status:    prmodels.String2CheckRunStatus(request.Status),
 		Message:   request.Message,
@@ -555,10 +583,14 @@ func (c *controller) executeRestart(ctx context.Context, application *appmodels.
 	}
 	// 3. deploy cluster in cd system
 	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
-		Environment: cluster.EnvironmentName,
-		Cluster:     cluster.Name,
-		Revision:    commit,
-		Region:      cluster.RegionName,
+		Environment:     cluster.EnvironmentName,
+		Cluster:         cluster.Name,
+		Revision:        commit,
+		Region:          cluster.RegionName,
+		Application:     application.Name,
+		Template:        cluster.Template,
+		TemplateRelease: cluster.TemplateRelease,
+		PipelinerunID:   pr.ID,
 	}); err != nil {
 		return perror.Wrapf(err, "failed to deploy cluster in CD, cluster = %s, revision = %s",
 			cluster.Name, commit)
@@ -665,12 +697,25 @@ func (c *controller) executeRollback(ctx context.Context, application *appmodels
 	}
 
 	// 8. deploy cluster in cd and update status
-	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
-		Environment: cluster.EnvironmentName,
-		Cluster:     cluster.Name,
-		Revision:    masterRevision,
-		Region:      cluster.RegionName,
-	}); err != nil {
+	deployParams := &cd.DeployClusterParams{
+		Environment:     cluster.EnvironmentName,
+		Cluster:         cluster.Name,
+		Revision:        masterRevision,
+		Region:          cluster.RegionName,
+		Application:     application.Name,
+		Template:        cluster.Template,
+		TemplateRelease: cluster.TemplateRelease,
+		PipelinerunID:   pr.ID,
+	}
+	if rollbackCD, ok := c.cd.(cd.RollbackCD); ok {
+		err = rollbackCD.RollbackCluster(ctx, &cd.RollbackClusterParams{
+			DeployClusterParams: *deployParams,
+			TargetPipelinerunID: *pr.RollbackFrom,
+		})
+	} else {
+		err = c.cd.DeployCluster(ctx, deployParams)
+	}
+	if err != nil {
 		return perror.Wrapf(err, "failed to deploy cluster in CD, cluster = %s, revision = %s",
 			cluster.Name, masterRevision)
 	}
@@ -0,0 +1,265 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	prservice "github.com/horizoncd/horizon/pkg/pr/service"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) Approve(ctx context.Context, pipelinerunID uint, request *ApprovalRequest) error {
+	const op = "pipelinerun controller: approve pipelinerun"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.decide(ctx, pipelinerunID, prmodels.ApprovalDecisionApproved, request.Comment)
+}
+
+func (c *controller) Reject(ctx context.Context, pipelinerunID uint, request *ApprovalRequest) error {
+	const op = "pipelinerun controller: reject pipelinerun"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.decide(ctx, pipelinerunID, prmodels.ApprovalDecisionRejected, request.Comment)
+}
+
+// decide records the decision of the current user on each pending approval check run the user can approve,
+// a check run succeeds once it gets the required approvals, and fails once it's rejected
+func (c *controller) decide(ctx context.Context, pipelinerunID uint, decision, comment string) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	pr, err := c.prMgr.PipelineRun.GetByID(ctx, pipelinerunID)
+	if err != nil {
+		return err
+	}
+	if pr.Status != string(prmodels.StatusPending) {
+		return perror.Wrapf(herrors.ErrParamInvalid, "pipelinerun is not pending")
+	}
+	if decision == prmodels.ApprovalDecisionApproved && pr.CreatedBy == currentUser.GetID() {
+		return perror.Wrap(herrors.ErrForbidden, "creator of the pipelinerun cannot approve it")
+	}
+
+	checkRuns, err := c.prMgr.Check.ListCheckRuns(ctx, pr.ID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		approver bool
+		decided  int
+	)
+	for _, run := range checkRuns {
+		if run.Status != prmodels.CheckStatusPending {
+			continue
+		}
+		check, err := c.prMgr.Check.Get(ctx, run.CheckID)
+		if err != nil {
+			return err
+		}
+		if check.Kind != prmodels.CheckKindApproval {
+			continue
+		}
+		policy, err := prservice.ParseApprovalPolicy(check)
+		if err != nil {
+			return err
+		}
+		ok, err := c.canApprove(ctx, policy, currentUser, pr.ClusterID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		approver = true
+
+		// the approvals are counted with the check run locked, so that concurrent decisions are not lost
+		decidedRun, err := c.prMgr.Approval.Decide(ctx, &prmodels.Approval{
+			CheckRunID:    run.ID,
+			PipelineRunID: pr.ID,
+			Decision:      decision,
+			Comment:       comment,
+			CreatedBy:     currentUser.GetID(),
+		}, func(run *prmodels.CheckRun, approvals []*prmodels.Approval) {
+			if decision == prmodels.ApprovalDecisionRejected {
+				run.Status = prmodels.CheckStatusFailure
+				run.Message = fmt.Sprintf("rejected by %s", currentUser.GetFullName())
+				return
+			}
+			approved := 0
+			for _, approval := range approvals {
+				if approval.Decision == prmodels.ApprovalDecisionApproved {
+					approved++
+				}
+			}
+			run.Message = prservice.ApprovalMessage(approved, policy)
+			if approved >= int(policy.Required) {
+				run.Status = prmodels.CheckStatusSuccess
+			}
+		})
+		if err != nil {
+			return err
+		}
+		if decidedRun == nil {
+			continue
+		}
+		if err := c.updatePrStatus(ctx, decidedRun); err != nil {
+			return err
+		}
+		decided++
+	}
+	if !approver {
+		return perror.Wrap(herrors.ErrForbidden, "current user is not an approver of the pipelinerun")
+	}
+	if decided == 0 {
+		return perror.Wrap(herrors.ErrParamInvalid, "current user has already made a decision on the pipelinerun")
+	}
+
+	message := common.MessagePipelinerunApproved
+	if decision == prmodels.ApprovalDecisionRejected {
+		message = common.MessagePipelinerunRejected
+	}
+	if comment != "" {
+		message = fmt.Sprintf("%s: %s", message, comment)
+	}
+	c.prSvc.CreateSystemMessageAsync(ctx, pr.ID, message)
+	return nil
+}
+
+// canApprove checks if current user is one of the users of policy,
+// or has a role of cluster equal or higher than one of the roles of policy
+func (c *controller) canApprove(ctx context.Context, policy *prmodels.ApprovalPolicy,
+	currentUser user.User, clusterID uint) (bool, error) {
+	for _, id := range policy.Users {
+		if id == currentUser.GetID() {
+			return true, nil
+		}
+	}
+	for _, role := range policy.Roles {
+		err := c.memberSvc.RequirePermissionEqualOrHigher(ctx, role, common.ResourceCluster, clusterID)
+		if err == nil {
+			return true, nil
+		}
+		if perror.Cause(err) != herrors.ErrNoPrivilege {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+func (c *controller) ListApprovals(ctx context.Context, pipelinerunID uint) ([]*Approval, error) {
+	const op = "pipelinerun controller: list approvals"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	checkRuns, err := c.prMgr.Check.ListCheckRuns(ctx, pipelinerunID)
+	if err != nil {
+		return nil, err
+	}
+	runIDs := make([]uint, 0, len(checkRuns))
+	for _, run := range checkRuns {
+		runIDs = append(runIDs, run.ID)
+	}
+	approvals, err := c.prMgr.Approval.ListByCheckRunIDs(ctx, runIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uint, 0, len(approvals))
+	for _, approval := range approvals {
+		userIDs = append(userIDs, approval.CreatedBy)
+	}
+	userMap := make(map[uint]*usermodels.User)
+	if len(userIDs) > 0 {
+		_, users, err := c.userMgr.List(ctx, &q.Query{
+			WithoutPagination: true,
+			Keywords:          map[string]interface{}{common.UserQueryID: userIDs},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			userMap[u.ID] = u
+		}
+	}
+
+	result := make([]*Approval, 0, len(approvals))
+	for _, approval := range approvals {
+		item := &Approval{
+			ID:         approval.ID,
+			CheckRunID: approval.CheckRunID,
+			Decision:   approval.Decision,
+			Comment:    approval.Comment,
+			CreatedBy:  User{ID: approval.CreatedBy},
+			CreatedAt:  approval.CreatedAt,
+		}
+		if u, ok := userMap[approval.CreatedBy]; ok {
+			item.CreatedBy.Name = u.FullName
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+func (c *controller) ListStaleApprovalCheckRuns(ctx context.Context, before time.Time,
+	query *q.Query) ([]*prmodels.CheckRun, error) {
+	const op = "pipelinerun controller: list stale approval check runs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.prMgr.Check.ListPendingApprovalCheckRuns(ctx, before, query)
+}
+
+func (c *controller) ExpireApprovalCheckRun(ctx context.Context, checkRunID uint) error {
+	const op = "pipelinerun controller: expire approval check run"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	run, err := c.prMgr.Check.GetCheckRunByID(ctx, checkRunID)
+	if err != nil {
+		return err
+	}
+	if run.Status != prmodels.CheckStatusPending {
+		return perror.Wrapf(herrors.ErrParamInvalid, "check run %d is not pending", checkRunID)
+	}
+	run.Status = prmodels.CheckStatusCancelled
+	run.Message = common.MessageApprovalExpired
+	if err := c.prMgr.Check.UpdateByID(ctx, run.ID, &prmodels.CheckRun{
+		Status:  run.Status,
+		Message: run.Message,
+	}); err != nil {
+		return err
+	}
+
+	pr, err := c.prMgr.PipelineRun.GetByID(ctx, run.PipelineRunID)
+	if err != nil {
+		return err
+	}
+	if pr.Status != string(prmodels.StatusPending) {
+		return nil
+	}
+	if err := c.updatePrStatus(ctx, run); err != nil {
+		return err
+	}
+	c.prSvc.CreateSystemMessageAsync(ctx, pr.ID, common.MessageApprovalExpired)
+	return nil
+}
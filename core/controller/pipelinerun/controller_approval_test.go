@@ -0,0 +1,220 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinerun
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	memberservicemock "github.com/horizoncd/horizon/mock/pkg/member/service"
+	applicationmodel "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodel "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	prservice "github.com/horizoncd/horizon/pkg/pr/service"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	usermodel "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+func TestApproval(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&groupmodels.Group{}, &applicationmodel.Application{}, &clustermodel.Cluster{},
+		&prmodels.Check{}, &prmodels.CheckRun{}, &prmodels.Pipelinerun{}, &prmodels.Approval{},
+		&prmodels.PRMessage{}, &usermodel.User{}, &membermodels.Member{}); err != nil {
+		panic(err)
+	}
+	param := managerparam.InitManager(db)
+
+	userCtx := func(id uint, name string) context.Context {
+		return common.WithContext(context.Background(), &userauth.DefaultInfo{
+			Name:     name,
+			FullName: name,
+			ID:       id,
+		})
+	}
+	creatorCtx, aliceCtx, bobCtx, carolCtx := userCtx(1, "creator"), userCtx(2, "alice"),
+		userCtx(3, "bob"), userCtx(4, "carol")
+	for _, name := range []string{"creator", "alice", "bob", "carol"} {
+		_, err := param.UserMgr.Create(creatorCtx, &usermodel.User{Name: name, FullName: name})
+		assert.NoError(t, err)
+	}
+
+	// alice is an owner of the cluster, which is higher than maintainer
+	priorities := map[string]int{role.Guest: 0, role.Maintainer: 1, role.Owner: 2}
+	mockCtl := gomock.NewController(t)
+	memberSvc := memberservicemock.NewMockService(mockCtl)
+	memberSvc.EXPECT().RequirePermissionEqualOrHigher(gomock.Any(), gomock.Any(), common.ResourceCluster,
+		gomock.Any()).DoAndReturn(func(ctx context.Context, required, _ string, _ uint) error {
+		currentUser, err := common.UserFromContext(ctx)
+		if err != nil {
+			return err
+		}
+		if currentUser.GetID() == 2 && priorities[role.Owner] >= priorities[required] {
+			return nil
+		}
+		return herrors.ErrNoPrivilege
+	}).AnyTimes()
+
+	ctrl := controller{
+		clusterMgr: param.ClusterMgr,
+		prMgr:      param.PRMgr,
+		prSvc:      prservice.NewService(param),
+		memberSvc:  memberSvc,
+		userMgr:    param.UserMgr,
+	}
+
+	group, err := param.GroupMgr.Create(creatorCtx, &groupmodels.Group{Name: "test"})
+	assert.NoError(t, err)
+	app, err := param.ApplicationMgr.Create(creatorCtx, &applicationmodel.Application{
+		Name:    "test",
+		GroupID: group.ID,
+	}, nil)
+	assert.NoError(t, err)
+	cluster, err := param.ClusterMgr.Create(creatorCtx, &clustermodel.Cluster{
+		Name:            "cluster",
+		ApplicationID:   app.ID,
+		EnvironmentName: "online",
+	}, nil, nil)
+	assert.NoError(t, err)
+
+	// two approvals from the maintainers of the cluster and bob are required in online
+	policy, _ := json.Marshal(&prmodels.ApprovalPolicy{
+		Required: 2,
+		Roles:    []string{role.Maintainer},
+		Users:    []uint{3},
+	})
+	_, err = param.PRMgr.Check.Create(creatorCtx, &prmodels.Check{
+		Resource:    common.Resource{ResourceID: app.ID, Type: common.ResourceApplication},
+		Kind:        prmodels.CheckKindApproval,
+		Environment: "online",
+		Approval:    string(policy),
+	})
+	assert.NoError(t, err)
+	// the check of test does not apply to the cluster
+	_, err = param.PRMgr.Check.Create(creatorCtx, &prmodels.Check{
+		Resource:    common.Resource{ResourceID: app.ID, Type: common.ResourceApplication},
+		Kind:        prmodels.CheckKindApproval,
+		Environment: "test",
+		Approval:    string(policy),
+	})
+	assert.NoError(t, err)
+
+	createPR := func() *prmodels.Pipelinerun {
+		pr, err := param.PRMgr.PipelineRun.Create(creatorCtx, &prmodels.Pipelinerun{
+			Status:    string(prmodels.StatusPending),
+			ClusterID: cluster.ID,
+			CreatedBy: 1,
+		})
+		assert.NoError(t, err)
+		checks, err := ctrl.prSvc.GetCheckByResource(creatorCtx, cluster.ID, common.ResourceCluster)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(checks))
+		assert.NoError(t, ctrl.prSvc.CreateApprovalCheckRuns(creatorCtx, pr.ID, checks))
+		return pr
+	}
+
+	// approved by alice and bob
+	pr := createPR()
+	checkRuns, err := ctrl.ListCheckRuns(creatorCtx, pr.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(checkRuns))
+	assert.Equal(t, prmodels.CheckStatusPending, checkRuns[0].Status)
+	assert.Equal(t, "0/2 approvals", checkRuns[0].Message)
+
+	err = ctrl.UpdateCheckRunByID(creatorCtx, checkRuns[0].ID, &CreateOrUpdateCheckRunRequest{
+		Status: string(prmodels.CheckStatusSuccess),
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	err = ctrl.Approve(creatorCtx, pr.ID, &ApprovalRequest{})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	err = ctrl.Approve(carolCtx, pr.ID, &ApprovalRequest{})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	assert.NoError(t, ctrl.Approve(aliceCtx, pr.ID, &ApprovalRequest{Comment: "lgtm"}))
+	err = ctrl.Approve(aliceCtx, pr.ID, &ApprovalRequest{})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	checkRun, err := ctrl.GetCheckRunByID(creatorCtx, checkRuns[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, prmodels.CheckStatusPending, checkRun.Status)
+	assert.Equal(t, "1/2 approvals", checkRun.Message)
+
+	assert.NoError(t, ctrl.Approve(bobCtx, pr.ID, &ApprovalRequest{}))
+	checkRun, err = ctrl.GetCheckRunByID(creatorCtx, checkRuns[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, prmodels.CheckStatusSuccess, checkRun.Status)
+	prInDB, err := param.PRMgr.PipelineRun.GetByID(creatorCtx, pr.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, string(prmodels.StatusReady), prInDB.Status)
+
+	approvals, err := ctrl.ListApprovals(creatorCtx, pr.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(approvals))
+	assert.Equal(t, "alice", approvals[0].CreatedBy.Name)
+	assert.Equal(t, "lgtm", approvals[0].Comment)
+	assert.Equal(t, prmodels.ApprovalDecisionApproved, approvals[1].Decision)
+
+	err = ctrl.Reject(bobCtx, pr.ID, &ApprovalRequest{})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// rejected by bob
+	pr = createPR()
+	assert.NoError(t, ctrl.Approve(aliceCtx, pr.ID, &ApprovalRequest{}))
+	assert.NoError(t, ctrl.Reject(bobCtx, pr.ID, &ApprovalRequest{Comment: "not now"}))
+	prInDB, err = param.PRMgr.PipelineRun.GetByID(creatorCtx, pr.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, string(prmodels.StatusFailed), prInDB.Status)
+	assert.Eventually(t, func() bool {
+		_, messages, err := param.PRMgr.Message.List(creatorCtx, pr.ID, &q.Query{})
+		if err != nil {
+			return false
+		}
+		for _, message := range messages {
+			if message.Content == "rejected pipelinerun: not now" {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	// expired
+	pr = createPR()
+	staleRuns, err := ctrl.ListStaleApprovalCheckRuns(creatorCtx, time.Now().Add(-time.Hour), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(staleRuns))
+	staleRuns, err = ctrl.ListStaleApprovalCheckRuns(creatorCtx, time.Now().Add(time.Hour), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(staleRuns))
+	assert.Equal(t, pr.ID, staleRuns[0].PipelineRunID)
+	assert.NoError(t, ctrl.ExpireApprovalCheckRun(creatorCtx, staleRuns[0].ID))
+	prInDB, err = param.PRMgr.PipelineRun.GetByID(creatorCtx, pr.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, string(prmodels.StatusCancelled), prInDB.Status)
+	err = ctrl.ExpireApprovalCheckRun(creatorCtx, staleRuns[0].ID)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
@@ -72,3 +72,16 @@ type CreateOrUpdateCheckRunRequest struct {
 	ExternalID string `json:"externalId"`
 	DetailURL  string `json:"detailUrl"`
 }
+
+type ApprovalRequest struct {
+	Comment string `json:"comment"`
+}
+
+type Approval struct {
+	ID         uint      `json:"id"`
+	CheckRunID uint      `json:"checkRunId"`
+	Decision   string    `json:"decision"`
+	Comment    string    `json:"comment"`
+	CreatedBy  User      `json:"createdBy"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
@@ -16,24 +16,50 @@ package role
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"regexp"
 
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
+	rolemanager "github.com/horizoncd/horizon/pkg/rbac/role/manager"
+	"github.com/horizoncd/horizon/pkg/rbac/role/models"
 	"github.com/horizoncd/horizon/pkg/rbac/types"
 	"github.com/horizoncd/horizon/pkg/util/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
 )
 
+var _roleNameRegex = regexp.MustCompile(`^[a-z][a-z0-9-]{0,62}$`)
+
 type Controller interface {
 	ListRole(ctx context.Context) ([]types.Role, error)
+	// CreateRole creates a custom role
+	CreateRole(ctx context.Context, request *CreateRoleRequest) (*Role, error)
+	// GetRole gets a custom role by id
+	GetRole(ctx context.Context, id uint) (*Role, error)
+	// UpdateRole updates description, priority and rules of a custom role
+	UpdateRole(ctx context.Context, id uint, request *UpdateRoleRequest) (*Role, error)
+	// DeleteRole deletes a custom role which is not bound to any member
+	DeleteRole(ctx context.Context, id uint) error
 }
 
 func NewController(param *param.Param) Controller {
-	return &controller{roleService: param.RoleService}
+	return &controller{
+		roleService: param.RoleService,
+		roleMgr:     param.RoleMgr,
+		eventSvc:    param.EventSvc,
+	}
 }
 
 type controller struct {
 	roleService role.Service
+	roleMgr     rolemanager.Manager
+	eventSvc    eventservice.Service
 }
 
 func (c controller) ListRole(ctx context.Context) ([]types.Role, error) {
@@ -44,3 +70,109 @@ func (c controller) ListRole(ctx context.Context) ([]types.Role, error) {
 	}
 	return roles, nil
 }
+
+func (c controller) CreateRole(ctx context.Context, request *CreateRoleRequest) (*Role, error) {
+	if !_roleNameRegex.MatchString(request.Name) {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"invalid role name %s, should match %s", request.Name, _roleNameRegex.String())
+	}
+	_, err := c.roleService.GetRole(ctx, request.Name)
+	if err == nil {
+		return nil, perror.Wrapf(herrors.ErrNameConflict, "role %s already exists", request.Name)
+	}
+	if perror.Cause(err) != role.ErrorRoleNotFound {
+		return nil, err
+	}
+
+	rules, err := c.marshalRules(request.Priority, request.PolicyRules)
+	if err != nil {
+		return nil, err
+	}
+	entity, err := c.roleMgr.Create(ctx, &models.Role{
+		Name:        request.Name,
+		Description: request.Desc,
+		Priority:    request.Priority,
+		Rules:       rules,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.recordEvent(ctx, entity, eventmodels.RoleCreated)
+	return ofRoleModel(entity)
+}
+
+func (c controller) GetRole(ctx context.Context, id uint) (*Role, error) {
+	entity, err := c.roleMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ofRoleModel(entity)
+}
+
+func (c controller) UpdateRole(ctx context.Context, id uint, request *UpdateRoleRequest) (*Role, error) {
+	if _, err := c.roleMgr.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	rules, err := c.marshalRules(request.Priority, request.PolicyRules)
+	if err != nil {
+		return nil, err
+	}
+	err = c.roleMgr.UpdateByID(ctx, id, &models.Role{
+		Description: request.Desc,
+		Priority:    request.Priority,
+		Rules:       rules,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entity, err := c.roleMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.recordEvent(ctx, entity, eventmodels.RoleUpdated)
+	return ofRoleModel(entity)
+}
+
+func (c controller) DeleteRole(ctx context.Context, id uint) error {
+	entity, err := c.roleMgr.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := c.roleMgr.DeleteByID(ctx, id); err != nil {
+		return err
+	}
+
+	c.recordEvent(ctx, entity, eventmodels.RoleDeleted)
+	return nil
+}
+
+func (c controller) marshalRules(priority int, rules []types.PolicyRule) (string, error) {
+	if priority <= 0 {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "priority should be positive, got %d", priority)
+	}
+	if err := role.ValidateRules(rules); err != nil {
+		return "", err
+	}
+	content, err := json.Marshal(rules)
+	if err != nil {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "failed to marshal rules: %v", err)
+	}
+	return string(content), nil
+}
+
+// recordEvent records the name and priority of the role,
+// so that the role can be identified even if it has been deleted
+func (c controller) recordEvent(ctx context.Context, entity *models.Role, eventType string) {
+	bts, err := json.Marshal(map[string]interface{}{
+		"name":     entity.Name,
+		"priority": entity.Priority,
+	})
+	if err != nil {
+		log.Warningf(ctx, "failed to marshal event extra, err: %s", err.Error())
+	}
+	extra := string(bts)
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceRole, entity.ID, eventType, &extra)
+}
@@ -0,0 +1,63 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/rbac/role/models"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+)
+
+// Role is a custom role stored in db
+type Role struct {
+	ID          uint               `json:"id"`
+	Name        string             `json:"name"`
+	Desc        string             `json:"desc"`
+	Priority    int                `json:"priority"`
+	PolicyRules []types.PolicyRule `json:"rules"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt"`
+}
+
+type CreateRoleRequest struct {
+	Name        string             `json:"name"`
+	Desc        string             `json:"desc"`
+	Priority    int                `json:"priority"`
+	PolicyRules []types.PolicyRule `json:"rules"`
+}
+
+type UpdateRoleRequest struct {
+	Desc        string             `json:"desc"`
+	Priority    int                `json:"priority"`
+	PolicyRules []types.PolicyRule `json:"rules"`
+}
+
+func ofRoleModel(entity *models.Role) (*Role, error) {
+	r, err := role.OfModel(entity)
+	if err != nil {
+		return nil, err
+	}
+	return &Role{
+		ID:          entity.ID,
+		Name:        entity.Name,
+		Desc:        entity.Description,
+		Priority:    entity.Priority,
+		PolicyRules: r.PolicyRules,
+		CreatedAt:   entity.CreatedAt,
+		UpdatedAt:   entity.UpdatedAt,
+	}, nil
+}
@@ -32,6 +32,8 @@ import (
 	"github.com/horizoncd/horizon/lib/q"
 	hctx "github.com/horizoncd/horizon/pkg/context"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	"github.com/horizoncd/horizon/pkg/git"
 	gmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	groupModels "github.com/horizoncd/horizon/pkg/group/models"
@@ -46,6 +48,7 @@ import (
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	"github.com/horizoncd/horizon/pkg/templaterelease/schema"
 	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/permission"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 )
@@ -93,6 +96,7 @@ type controller struct {
 	memberMgr            membermanager.Manager
 	memberSvc            memberservice.Service
 	templateSchemaGetter schema.Getter
+	eventSvc             eventservice.Service
 }
 
 var _ Controller = (*controller)(nil)
@@ -108,6 +112,7 @@ func NewController(param *param.Param, repo templaterepo.TemplateRepo) Controlle
 		memberMgr:            param.MemberMgr,
 		memberSvc:            param.MemberService,
 		groupMgr:             param.GroupMgr,
+		eventSvc:             param.EventSvc,
 	}
 }
 
@@ -594,6 +599,7 @@ func (c *controller) CreateTemplate(ctx context.Context,
 		return nil, err
 	}
 
+	c.recordTemplateEvent(ctx, template, eventmodels.TemplateCreated)
 	return toTemplate(template), nil
 }
 
@@ -636,6 +642,7 @@ func (c *controller) CreateRelease(ctx context.Context,
 	if newRelease, err = c.templateReleaseMgr.Create(ctx, release); err != nil {
 		return nil, err
 	}
+	c.recordReleaseEvent(ctx, template, newRelease, eventmodels.TemplateReleaseCreated)
 	return toRelease(newRelease), nil
 }
 
@@ -703,6 +710,11 @@ func (c *controller) DeleteTemplate(ctx context.Context, templateID uint) error
 	const op = "template controller: deleteTemplate"
 	defer wlog.Start(ctx, op).StopPrint()
 
+	template, err := c.templateMgr.GetByID(ctx, templateID)
+	if err != nil {
+		return err
+	}
+
 	releases, err := c.templateReleaseMgr.ListByTemplateID(ctx, templateID)
 	if err != nil {
 		return err
@@ -730,13 +742,22 @@ func (c *controller) DeleteTemplate(ctx context.Context, templateID uint) error
 		return perror.Wrap(herrors.ErrSubResourceExist, "this template cannot be deleted because it was used by clusters.")
 	}
 
-	return c.templateMgr.DeleteByID(ctx, templateID)
+	if err := c.templateMgr.DeleteByID(ctx, templateID); err != nil {
+		return err
+	}
+	c.recordTemplateEvent(ctx, template, eventmodels.TemplateDeleted)
+	return nil
 }
 
 func (c *controller) DeleteRelease(ctx context.Context, releaseID uint) error {
 	const op = "template controller: deleteRelease"
 	defer wlog.Start(ctx, op).StopPrint()
 
+	release, err := c.templateReleaseMgr.GetByID(ctx, releaseID)
+	if err != nil {
+		return err
+	}
+
 	ctx = context.WithValue(ctx, hctx.TemplateOnlyRefCount, true)
 	_, count, err := c.templateReleaseMgr.GetRefOfApplication(ctx, releaseID)
 	if err != nil {
@@ -754,7 +775,11 @@ func (c *controller) DeleteRelease(ctx context.Context, releaseID uint) error {
 		return perror.Wrap(herrors.ErrSubResourceExist, "this release cannot be deleted because it was used by clusters.")
 	}
 
-	return c.templateReleaseMgr.DeleteByID(ctx, releaseID)
+	if err := c.templateReleaseMgr.DeleteByID(ctx, releaseID); err != nil {
+		return err
+	}
+	c.recordReleaseEvent(ctx, nil, release, eventmodels.TemplateReleaseDeleted)
+	return nil
 }
 
 // UpdateTemplate deletes a template by ID
@@ -784,7 +809,11 @@ func (c *controller) UpdateTemplate(ctx context.Context, templateID uint, reques
 		return err
 	}
 
-	return c.templateMgr.UpdateByID(ctx, templateID, tplUpdate)
+	if err := c.templateMgr.UpdateByID(ctx, templateID, tplUpdate); err != nil {
+		return err
+	}
+	c.recordTemplateEvent(ctx, template, eventmodels.TemplateUpdated)
+	return nil
 }
 
 // UpdateRelease deletes a template release by ID
@@ -797,7 +826,16 @@ func (c *controller) UpdateRelease(ctx context.Context, releaseID uint, request
 		return err
 	}
 
-	return c.templateReleaseMgr.UpdateByID(ctx, releaseID, trUpdate)
+	if err := c.templateReleaseMgr.UpdateByID(ctx, releaseID, trUpdate); err != nil {
+		return err
+	}
+	release, err := c.templateReleaseMgr.GetByID(ctx, releaseID)
+	if err != nil {
+		log.Warningf(ctx, "failed to get release %d for event, err: %s", releaseID, err.Error())
+		return nil
+	}
+	c.recordReleaseEvent(ctx, nil, release, eventmodels.TemplateReleaseUpdated)
+	return nil
 }
 
 func (c *controller) SyncReleaseToRepo(ctx context.Context, releaseID uint) error {
@@ -953,3 +991,35 @@ func (c *controller) checkHasOnlyOwnerPermissionForRelease(ctx context.Context,
 	}
 	return false
 }
+
+// recordTemplateEvent records the name and group of template into the event,
+// so that the template can be identified even if it has been deleted
+func (c *controller) recordTemplateEvent(ctx context.Context, template *models.Template, eventType string) {
+	c.eventSvc.CreateResourceEventIgnoreError(ctx, common.ResourceTemplate, template.ID, eventType,
+		&eventmodels.ResourceExtra{
+			Name:    template.Name,
+			GroupID: template.GroupID,
+		})
+}
+
+// recordReleaseEvent records the name, template and group of release into the event.
+// template is queried by the release if it is not provided.
+func (c *controller) recordReleaseEvent(ctx context.Context, template *models.Template,
+	release *trmodels.TemplateRelease, eventType string) {
+	if template == nil {
+		var err error
+		template, err = c.templateMgr.GetByID(ctx, release.Template)
+		if err != nil {
+			log.Warningf(ctx, "failed to get template %d for event %s, err: %s",
+				release.Template, eventType, err.Error())
+			return
+		}
+	}
+	c.eventSvc.CreateResourceEventIgnoreError(ctx, common.ResourceTemplateRelease, release.ID, eventType,
+		&eventmodels.ResourceExtra{
+			Name:         release.Name,
+			GroupID:      template.GroupID,
+			TemplateID:   template.ID,
+			TemplateName: template.Name,
+		})
+}
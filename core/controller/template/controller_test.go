@@ -17,6 +17,7 @@ package template
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
@@ -32,6 +33,7 @@ import (
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
 	gitmock "github.com/horizoncd/horizon/mock/pkg/git"
 	groupmanagermock "github.com/horizoncd/horizon/mock/pkg/group/manager"
 	membermock "github.com/horizoncd/horizon/mock/pkg/member/manager"
@@ -46,6 +48,8 @@ import (
 	gitconfig "github.com/horizoncd/horizon/pkg/config/git"
 	hctx "github.com/horizoncd/horizon/pkg/context"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	"github.com/horizoncd/horizon/pkg/git"
 	"github.com/horizoncd/horizon/pkg/git/github"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
@@ -463,6 +467,22 @@ func TestDeleteTemplate(t *testing.T) {
 	template, err := mgr.TemplateMgr.GetByID(ctx, 1)
 	assert.NotNil(t, err)
 	assert.Nil(t, template)
+
+	events, err := mgr.EventMgr.ListEvents(ctx, &q.Query{
+		Keywords: q.KeyWords{common.StartID: 0, common.Limit: 100},
+	})
+	assert.Nil(t, err)
+	eventTypes := make(map[string]string)
+	for _, event := range events {
+		eventTypes[event.EventType] = *event.Extra
+	}
+	assert.Contains(t, eventTypes, eventmodels.TemplateCreated)
+	assert.Contains(t, eventTypes, eventmodels.TemplateReleaseCreated)
+	assert.Contains(t, eventTypes, eventmodels.TemplateDeleted)
+	extra := eventmodels.ResourceExtra{}
+	assert.Nil(t, json.Unmarshal([]byte(eventTypes[eventmodels.TemplateReleaseDeleted]), &extra))
+	assert.Equal(t, templateTag, extra.Name)
+	assert.Equal(t, templateName, extra.TemplateName)
 }
 
 func TestGetTemplate(t *testing.T) {
@@ -592,7 +612,8 @@ func createContext() {
 	db, _ = orm.NewSqliteDB("")
 	if err := db.AutoMigrate(&trmodels.TemplateRelease{},
 		&amodels.Application{}, &cmodels.Cluster{}, &membermodels.Member{},
-		&tmodels.Template{}, &membermodels.Member{}, &groupmodels.Group{}, &usermodels.User{}); err != nil {
+		&tmodels.Template{}, &membermodels.Member{}, &groupmodels.Group{}, &usermodels.User{},
+		&eventmodels.Event{}); err != nil {
 		panic(err)
 	}
 	mgr = managerparam.InitManager(db)
@@ -634,6 +655,7 @@ func createController(t *testing.T) (Controller, *mock_repo.MockTemplateRepo) {
 		templateReleaseMgr:   mgr.TemplateReleaseMgr,
 		memberMgr:            mgr.MemberMgr,
 		templateSchemaGetter: getter,
+		eventSvc:             eventservice.New(mgr),
 	}
 	return ctl, repo
 }
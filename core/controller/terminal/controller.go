@@ -25,7 +25,10 @@ import (
 
 	"k8s.io/client-go/tools/remotecommand"
 
+	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/admission"
+	admissionmodels "github.com/horizoncd/horizon/pkg/admission/models"
 	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	"github.com/horizoncd/horizon/pkg/cluster/kubeclient"
@@ -76,10 +79,38 @@ func NewController(param *param.Param) Controller {
 	}
 }
 
+// admit admits connecting to the container of the cluster
+func (c *controller) admit(ctx context.Context, clusterID uint, subResource, podName, containerName string) error {
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return err
+	}
+	return admission.Admit(ctx, &admission.Request{
+		Operation:   admissionmodels.OperationConnect,
+		Resource:    common.ResourceCluster,
+		Name:        strconv.FormatUint(uint64(clusterID), 10),
+		SubResource: subResource,
+		OldObject:   admission.NewClusterObject(application, cluster),
+		Options: map[string]interface{}{
+			"podName":       podName,
+			"containerName": containerName,
+		},
+	})
+}
+
 func (c *controller) GetTerminalID(ctx context.Context, clusterID uint, podName,
 	containerName string) (*SessionIDResp, error) {
 	const op = "terminal: get terminal id"
 	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := c.admit(ctx, clusterID, "terminal", podName, containerName); err != nil {
+		return nil, err
+	}
+
 	sessionID := &SessionIDResp{}
 	randomID, err := genRandomID()
 	if err != nil {
@@ -155,6 +186,10 @@ func (c *controller) CreateShell(ctx context.Context, clusterID uint, podName,
 	const op = "terminal controller: create shell"
 	defer wlog.Start(ctx, op).StopPrint()
 
+	if err := c.admit(ctx, clusterID, "shell", podName, containerName); err != nil {
+		return "", nil, err
+	}
+
 	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
 	if err != nil {
 		return "", nil, err
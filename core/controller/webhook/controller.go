@@ -16,12 +16,16 @@ package webhook
 
 import (
 	"context"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
 	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
+	"github.com/horizoncd/horizon/pkg/eventhandler/wlgenerator"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	"github.com/horizoncd/horizon/pkg/param"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
@@ -43,6 +47,10 @@ type Controller interface {
 	ListWebhookLogs(ctx context.Context, wID uint, query *q.Query) ([]*LogSummary, int64, error)
 	GetWebhookLog(ctx context.Context, id uint) (*Log, error)
 	ResendWebhook(ctx context.Context, id uint) (*models.WebhookLog, error)
+	RedeliverWebhookLogs(ctx context.Context, wID uint,
+		r *RedeliverWebhookLogsRequest) (*RedeliverWebhookLogsResponse, error)
+	PreviewWebhookPayload(ctx context.Context, id uint,
+		r *PreviewWebhookPayloadRequest) (*PreviewWebhookPayloadResponse, error)
 }
 
 type controller struct {
@@ -52,6 +60,7 @@ type controller struct {
 	groupMgr       groupmanager.Manager
 	applicationMgr applicationmanager.Manager
 	clusterMgr     clustermanager.Manager
+	logGenerator   *wlgenerator.WebhookLogGenerator
 }
 
 func NewController(param *param.Param) Controller {
@@ -62,6 +71,7 @@ func NewController(param *param.Param) Controller {
 		clusterMgr:     param.ClusterMgr,
 		applicationMgr: param.ApplicationMgr,
 		groupMgr:       param.GroupMgr,
+		logGenerator:   wlgenerator.NewWebhookLogGenerator(param.Manager),
 	}
 }
 
@@ -117,6 +127,12 @@ func (c *controller) UpdateWebhook(ctx context.Context, id uint,
 		return nil, err
 	}
 	wm = w.toModel(wm)
+	if err := validatePayload(wm.PayloadFormat, wm.PayloadTemplate); err != nil {
+		return nil, err
+	}
+	if err := validateSecrets(wm.Secret, wm.SecondarySecret); err != nil {
+		return nil, err
+	}
 
 	// 3. update webhook
 	wm, err = c.webhookMgr.UpdateWebhook(ctx, id, wm)
@@ -253,3 +269,64 @@ func (c *controller) ResendWebhook(ctx context.Context, id uint) (*models.Webhoo
 
 	return c.webhookMgr.ResendWebhook(ctx, id)
 }
+
+func (c *controller) RedeliverWebhookLogs(ctx context.Context, wID uint,
+	r *RedeliverWebhookLogsRequest) (*RedeliverWebhookLogsResponse, error) {
+	const op = "wehook controller: redeliver logs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// 1. validate request
+	if r.Since.IsZero() {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "since should not be empty")
+	}
+	if r.Since.After(time.Now()) {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "since should not be in the future")
+	}
+
+	// 2. make sure the webhook exists
+	if _, err := c.webhookMgr.GetWebhook(ctx, wID); err != nil {
+		return nil, err
+	}
+
+	// 3. reset failed and dead-lettered logs to waiting
+	count, err := c.webhookMgr.RedeliverWebhookLogs(ctx, wID, r.Since)
+	if err != nil {
+		return nil, err
+	}
+	return &RedeliverWebhookLogsResponse{Count: count}, nil
+}
+
+func (c *controller) PreviewWebhookPayload(ctx context.Context, id uint,
+	r *PreviewWebhookPayloadRequest) (*PreviewWebhookPayloadResponse, error) {
+	const op = "wehook controller: preview payload"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// 1. get webhook and apply the unsaved payload settings
+	wm, err := c.webhookMgr.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.PayloadFormat != nil {
+		wm.PayloadFormat = *r.PayloadFormat
+	}
+	if r.PayloadTemplate != nil {
+		wm.PayloadTemplate = *r.PayloadTemplate
+	}
+	if err := validatePayload(wm.PayloadFormat, wm.PayloadTemplate); err != nil {
+		return nil, err
+	}
+
+	// 2. render payload against the event
+	if r.EventID == 0 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "eventID should not be empty")
+	}
+	event, err := c.eventMgr.GetEvent(ctx, r.EventID)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.logGenerator.Preview(ctx, wm, event)
+	if err != nil {
+		return nil, err
+	}
+	return &PreviewWebhookPayloadResponse{RequestData: body}, nil
+}
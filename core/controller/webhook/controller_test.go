@@ -17,6 +17,7 @@ package webhook
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
@@ -96,6 +97,8 @@ func Test(t *testing.T) {
 	w, err := c.CreateWebhook(ctx, resourceType, resourceID, &createWebhookReq)
 	assert.Nil(t, err)
 	assert.Equal(t, createWebhookReq.URL, w.URL)
+	assert.Equal(t, uint(_defaultMaxAttempts), w.MaxAttempts)
+	assert.Equal(t, uint(_defaultRetryBackoffSeconds), w.RetryBackoffSeconds)
 
 	w, err = c.GetWebhook(ctx, w.ID)
 	assert.Nil(t, err)
@@ -107,6 +110,22 @@ func Test(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, *(uw.URL), w.URL)
 
+	invalidUw := updateWebhookReq
+	invalidUw.MaxAttempts = utilcommon.UintPtr(_maxAttemptsLimit + 1)
+	_, err = c.UpdateWebhook(ctx, w.ID, &invalidUw)
+	assert.NotNil(t, err)
+
+	invalidUw = updateWebhookReq
+	invalidUw.Secret = utilcommon.StringPtr("secret")
+	invalidUw.SecondarySecret = utilcommon.StringPtr("secret")
+	_, err = c.UpdateWebhook(ctx, w.ID, &invalidUw)
+	assert.NotNil(t, err)
+
+	uw.MaxAttempts = utilcommon.UintPtr(3)
+	w, err = c.UpdateWebhook(ctx, w.ID, &uw)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(3), w.MaxAttempts)
+
 	ws, _, err := c.ListWebhooks(ctx, common.ResourceCluster, resourceID, nil)
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(ws))
@@ -139,6 +158,9 @@ func Test(t *testing.T) {
 	_, _, err = c.ListWebhookLogs(ctx, w.ID, query)
 	assert.Nil(t, err)
 
+	testRedeliverWebhookLogs(t, w)
+	testPreviewWebhookPayload(t, w)
+
 	err = c.DeleteWebhook(ctx, w.ID)
 	assert.Nil(t, err)
 
@@ -148,21 +170,152 @@ func Test(t *testing.T) {
 		},
 	}
 
-	ok, err := CheckIfEventMatch(&webhookmodels.Webhook{
+	ok := (&webhookmodels.Webhook{
 		Triggers: eventmodels.Any,
-	}, &event)
-	assert.Nil(t, err)
+	}).MatchEventType(event.EventType)
 	assert.Equal(t, true, ok)
 
-	ok, err = CheckIfEventMatch(&webhookmodels.Webhook{
+	ok = (&webhookmodels.Webhook{
 		Triggers: eventmodels.ApplicationCreated,
-	}, &event)
-	assert.Nil(t, err)
+	}).MatchEventType(event.EventType)
 	assert.Equal(t, true, ok)
 
-	ok, err = CheckIfEventMatch(&webhookmodels.Webhook{
+	ok = (&webhookmodels.Webhook{
 		Triggers: eventmodels.ClusterBuildDeployed,
-	}, &event)
-	assert.Nil(t, err)
+	}).MatchEventType(event.EventType)
 	assert.Equal(t, false, ok)
 }
+
+func testRedeliverWebhookLogs(t *testing.T, w *Webhook) {
+	since := time.Now().Add(-time.Minute)
+	nextRetryAt := time.Now().Add(time.Minute)
+	statuses := []string{
+		webhookmodels.StatusDeadLetter,
+		webhookmodels.StatusFailed,
+		webhookmodels.StatusRetrying,
+		webhookmodels.StatusSuccess,
+	}
+	ids := make(map[string]uint)
+	for _, status := range statuses {
+		wl, err := c.webhookMgr.CreateWebhookLog(ctx, &webhookmodels.WebhookLog{
+			WebhookID:    w.ID,
+			URL:          w.URL,
+			Status:       status,
+			ErrorMessage: "unexpected response code: 503",
+			Attempts:     3,
+			NextRetryAt:  &nextRetryAt,
+		})
+		assert.Nil(t, err)
+		ids[status] = wl.ID
+	}
+
+	_, err := c.RedeliverWebhookLogs(ctx, w.ID, &RedeliverWebhookLogsRequest{})
+	assert.NotNil(t, err)
+	_, err = c.RedeliverWebhookLogs(ctx, w.ID, &RedeliverWebhookLogsRequest{
+		Since: time.Now().Add(time.Hour),
+	})
+	assert.NotNil(t, err)
+	_, err = c.RedeliverWebhookLogs(ctx, w.ID+100, &RedeliverWebhookLogsRequest{Since: since})
+	assert.NotNil(t, err)
+
+	resp, err := c.RedeliverWebhookLogs(ctx, w.ID, &RedeliverWebhookLogsRequest{Since: since})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), resp.Count)
+
+	for _, status := range []string{webhookmodels.StatusDeadLetter, webhookmodels.StatusFailed} {
+		wl, err := c.GetWebhookLog(ctx, ids[status])
+		assert.Nil(t, err)
+		assert.Equal(t, webhookmodels.StatusWaiting, wl.Status)
+		assert.Equal(t, uint(0), wl.Attempts)
+		assert.Nil(t, wl.NextRetryAt)
+		assert.Equal(t, "", wl.ErrorMessage)
+	}
+	wl, err := c.GetWebhookLog(ctx, ids[webhookmodels.StatusRetrying])
+	assert.Nil(t, err)
+	assert.Equal(t, webhookmodels.StatusRetrying, wl.Status)
+
+	wls, err := c.webhookMgr.ListWebhookLogsToSend(ctx, w.ID, time.Now())
+	assert.Nil(t, err)
+	for _, wl := range wls {
+		assert.NotEqual(t, ids[webhookmodels.StatusRetrying], wl.ID)
+	}
+	wls, err = c.webhookMgr.ListWebhookLogsToSend(ctx, w.ID, nextRetryAt.Add(time.Second))
+	assert.Nil(t, err)
+	found := false
+	for _, wl := range wls {
+		if wl.ID == ids[webhookmodels.StatusRetrying] {
+			found = true
+		}
+	}
+	assert.True(t, found)
+}
+
+func testPreviewWebhookPayload(t *testing.T, w *Webhook) {
+	app := &applicationmodels.Application{Name: "demo"}
+	assert.Nil(t, db.Create(app).Error)
+	cluster := &clustermodels.Cluster{
+		Name:            "demo-test",
+		ApplicationID:   app.ID,
+		EnvironmentName: "test",
+	}
+	assert.Nil(t, db.Create(cluster).Error)
+	otherCluster := &clustermodels.Cluster{Name: "demo-online", ApplicationID: app.ID}
+	assert.Nil(t, db.Create(otherCluster).Error)
+
+	// move the webhook onto the cluster
+	assert.Nil(t, db.Model(&webhookmodels.Webhook{ID: w.ID}).Update("resource_id", cluster.ID).Error)
+
+	events, err := c.eventMgr.CreateEvent(ctx, &eventmodels.Event{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourceCluster,
+			ResourceID:   cluster.ID,
+			EventType:    eventmodels.ClusterCreated,
+		},
+	}, &eventmodels.Event{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourceCluster,
+			ResourceID:   otherCluster.ID,
+			EventType:    eventmodels.ClusterCreated,
+		},
+	})
+	assert.Nil(t, err)
+
+	uw := UpdateWebhookRequest{
+		PayloadFormat: utilcommon.StringPtr(webhookmodels.PayloadFormatTemplate),
+	}
+	_, err = c.UpdateWebhook(ctx, w.ID, &uw)
+	assert.NotNil(t, err)
+
+	uw.PayloadFormat = utilcommon.StringPtr(webhookmodels.PayloadFormatSlack)
+	updated, err := c.UpdateWebhook(ctx, w.ID, &uw)
+	assert.Nil(t, err)
+	assert.Equal(t, webhookmodels.PayloadFormatSlack, updated.PayloadFormat)
+
+	resp, err := c.PreviewWebhookPayload(ctx, w.ID, &PreviewWebhookPayloadRequest{
+		EventID: events[0].ID,
+	})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"text":"[Horizon] clusters_created: clusters demo-test (test)"}`, resp.RequestData)
+
+	resp, err = c.PreviewWebhookPayload(ctx, w.ID, &PreviewWebhookPayloadRequest{
+		EventID:         events[0].ID,
+		PayloadFormat:   utilcommon.StringPtr(webhookmodels.PayloadFormatTemplate),
+		PayloadTemplate: utilcommon.StringPtr(`{"cluster":{{json .Cluster.Name}}}`),
+	})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"cluster":"demo-test"}`, resp.RequestData)
+
+	// the template fails when the field does not exist in the message
+	_, err = c.PreviewWebhookPayload(ctx, w.ID, &PreviewWebhookPayloadRequest{
+		EventID:         events[0].ID,
+		PayloadFormat:   utilcommon.StringPtr(webhookmodels.PayloadFormatTemplate),
+		PayloadTemplate: utilcommon.StringPtr(`{{.Pipelinerun.Title}}`),
+	})
+	assert.NotNil(t, err)
+
+	// events of other resources can not be previewed
+	_, err = c.PreviewWebhookPayload(ctx, w.ID, &PreviewWebhookPayloadRequest{
+		EventID: events[1].ID,
+	})
+	assert.NotNil(t, err)
+}
@@ -24,31 +24,47 @@ import (
 	herrors "github.com/horizoncd/horizon/core/errors"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/eventhandler/wlgenerator"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	commonvalidate "github.com/horizoncd/horizon/pkg/util/validate"
 	wmodels "github.com/horizoncd/horizon/pkg/webhook/models"
 )
 
 const (
-	_triggerSeparator = ","
+	_triggerSeparator = wmodels.TriggerSeparator
+
+	_defaultMaxAttempts         = 5
+	_defaultRetryBackoffSeconds = 10
+	_maxAttemptsLimit           = 20
+	_retryBackoffSecondsLimit   = 3600
 )
 
 type UpdateWebhookRequest struct {
-	Enabled          *bool    `json:"enabled"`
-	URL              *string  `json:"url"`
-	SSLVerifyEnabled *bool    `json:"sslVerifyEnabled"`
-	Description      *string  `json:"description"`
-	Secret           *string  `json:"secret"`
-	Triggers         []string `json:"triggers"`
+	Enabled             *bool    `json:"enabled"`
+	URL                 *string  `json:"url"`
+	SSLVerifyEnabled    *bool    `json:"sslVerifyEnabled"`
+	Description         *string  `json:"description"`
+	Secret              *string  `json:"secret"`
+	SecondarySecret     *string  `json:"secondarySecret"`
+	Triggers            []string `json:"triggers"`
+	MaxAttempts         *uint    `json:"maxAttempts"`
+	RetryBackoffSeconds *uint    `json:"retryBackoffSeconds"`
+	PayloadFormat       *string  `json:"payloadFormat"`
+	PayloadTemplate     *string  `json:"payloadTemplate"`
 }
 
 type CreateWebhookRequest struct {
-	Enabled          bool     `json:"enabled"`
-	URL              string   `json:"url"`
-	SSLVerifyEnabled bool     `json:"sslVerifyEnabled"`
-	Description      string   `json:"description"`
-	Secret           string   `json:"secret"`
-	Triggers         []string `json:"triggers"`
+	Enabled             bool     `json:"enabled"`
+	URL                 string   `json:"url"`
+	SSLVerifyEnabled    bool     `json:"sslVerifyEnabled"`
+	Description         string   `json:"description"`
+	Secret              string   `json:"secret"`
+	SecondarySecret     string   `json:"secondarySecret"`
+	Triggers            []string `json:"triggers"`
+	MaxAttempts         uint     `json:"maxAttempts"`
+	RetryBackoffSeconds uint     `json:"retryBackoffSeconds"`
+	PayloadFormat       string   `json:"payloadFormat"`
+	PayloadTemplate     string   `json:"payloadTemplate"`
 }
 
 type Webhook struct {
@@ -72,6 +88,8 @@ type LogSummary struct {
 	EventType    string                `json:"eventType"`
 	Extra        *string               `json:"extra"`
 	ErrorMessage string                `json:"errorMessage"`
+	Attempts     uint                  `json:"attempts"`
+	NextRetryAt  *time.Time            `json:"nextRetryAt,omitempty"`
 	CreatedAt    time.Time             `json:"createdAt"`
 	CreatedBy    *usermodels.UserBasic `json:"createdBy,omitempty"`
 	UpdatedAt    time.Time             `json:"updatedAt"`
@@ -86,6 +104,27 @@ type Log struct {
 	ResponseBody    string `json:"responseBody"`
 }
 
+type PreviewWebhookPayloadRequest struct {
+	// EventID is the past event to render the payload against
+	EventID uint `json:"eventID"`
+	// PayloadFormat and PayloadTemplate override the ones of webhook to preview unsaved changes
+	PayloadFormat   *string `json:"payloadFormat"`
+	PayloadTemplate *string `json:"payloadTemplate"`
+}
+
+type PreviewWebhookPayloadResponse struct {
+	RequestData string `json:"requestData"`
+}
+
+type RedeliverWebhookLogsRequest struct {
+	// Since is the creation time of the earliest log to redeliver
+	Since time.Time `json:"since"`
+}
+
+type RedeliverWebhookLogsResponse struct {
+	Count int64 `json:"count"`
+}
+
 func (w *UpdateWebhookRequest) toModel(wm *wmodels.Webhook) *wmodels.Webhook {
 	if w.Enabled != nil {
 		wm.Enabled = *w.Enabled
@@ -102,9 +141,24 @@ func (w *UpdateWebhookRequest) toModel(wm *wmodels.Webhook) *wmodels.Webhook {
 	if w.Secret != nil {
 		wm.Secret = *w.Secret
 	}
+	if w.SecondarySecret != nil {
+		wm.SecondarySecret = *w.SecondarySecret
+	}
 	if len(w.Triggers) > 0 {
 		wm.Triggers = JoinTriggers(w.Triggers)
 	}
+	if w.MaxAttempts != nil {
+		wm.MaxAttempts = *w.MaxAttempts
+	}
+	if w.RetryBackoffSeconds != nil {
+		wm.RetryBackoffSeconds = *w.RetryBackoffSeconds
+	}
+	if w.PayloadFormat != nil {
+		wm.PayloadFormat = wlgenerator.NormalizePayloadFormat(*w.PayloadFormat)
+	}
+	if w.PayloadTemplate != nil {
+		wm.PayloadTemplate = *w.PayloadTemplate
+	}
 	return wm
 }
 
@@ -114,6 +168,16 @@ func (c *controller) validateUpdateRequest(w *UpdateWebhookRequest) error {
 			return err
 		}
 	}
+	if w.MaxAttempts != nil {
+		if err := validateMaxAttempts(*w.MaxAttempts); err != nil {
+			return err
+		}
+	}
+	if w.RetryBackoffSeconds != nil {
+		if err := validateRetryBackoffSeconds(*w.RetryBackoffSeconds); err != nil {
+			return err
+		}
+	}
 	if len(w.Triggers) > 0 {
 		return c.validateEvents(w.Triggers)
 	}
@@ -123,14 +187,25 @@ func (c *controller) validateUpdateRequest(w *UpdateWebhookRequest) error {
 func (w *CreateWebhookRequest) toModel(ctx context.Context,
 	resourceType string, resourceID uint) (*wmodels.Webhook, error) {
 	wm := &wmodels.Webhook{
-		ResourceType:     resourceType,
-		ResourceID:       resourceID,
-		Enabled:          w.Enabled,
-		URL:              w.URL,
-		SSLVerifyEnabled: w.SSLVerifyEnabled,
-		Description:      w.Description,
-		Secret:           w.Secret,
-		Triggers:         JoinTriggers(w.Triggers),
+		ResourceType:        resourceType,
+		ResourceID:          resourceID,
+		Enabled:             w.Enabled,
+		URL:                 w.URL,
+		SSLVerifyEnabled:    w.SSLVerifyEnabled,
+		Description:         w.Description,
+		Secret:              w.Secret,
+		SecondarySecret:     w.SecondarySecret,
+		Triggers:            JoinTriggers(w.Triggers),
+		MaxAttempts:         w.MaxAttempts,
+		RetryBackoffSeconds: w.RetryBackoffSeconds,
+		PayloadFormat:       wlgenerator.NormalizePayloadFormat(w.PayloadFormat),
+		PayloadTemplate:     w.PayloadTemplate,
+	}
+	if wm.MaxAttempts == 0 {
+		wm.MaxAttempts = _defaultMaxAttempts
+	}
+	if wm.RetryBackoffSeconds == 0 {
+		wm.RetryBackoffSeconds = _defaultRetryBackoffSeconds
 	}
 	return wm, nil
 }
@@ -147,9 +222,60 @@ func (c *controller) validateCreateRequest(resourceType string, w *CreateWebhook
 		return perror.Wrapf(herrors.ErrParamInvalid, "sslVerifyEnabled is only valid for https")
 	}
 
+	if err := validatePayload(w.PayloadFormat, w.PayloadTemplate); err != nil {
+		return err
+	}
+	if err := validateSecrets(w.Secret, w.SecondarySecret); err != nil {
+		return err
+	}
+
+	// zero values mean the defaults
+	if w.MaxAttempts != 0 {
+		if err := validateMaxAttempts(w.MaxAttempts); err != nil {
+			return err
+		}
+	}
+	if w.RetryBackoffSeconds != 0 {
+		if err := validateRetryBackoffSeconds(w.RetryBackoffSeconds); err != nil {
+			return err
+		}
+	}
+
 	return c.validateEvents(w.Triggers)
 }
 
+func validatePayload(format, template string) error {
+	if err := wlgenerator.ValidatePayload(format, template); err != nil {
+		return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	return nil
+}
+
+// validateSecrets checks the secrets used to sign requests,
+// the secondary secret keeps requests verifiable by receivers which have not switched to the new secret
+func validateSecrets(secret, secondarySecret string) error {
+	if secondarySecret != "" && secondarySecret == secret {
+		return perror.Wrap(herrors.ErrParamInvalid, "secondarySecret should be different from secret")
+	}
+	return nil
+}
+
+func validateMaxAttempts(maxAttempts uint) error {
+	if maxAttempts < 1 || maxAttempts > _maxAttemptsLimit {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"maxAttempts should be between 1 and %d", _maxAttemptsLimit)
+	}
+	return nil
+}
+
+func validateRetryBackoffSeconds(backoff uint) error {
+	if backoff < 1 || backoff > _retryBackoffSecondsLimit {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"retryBackoffSeconds should be between 1 and %d", _retryBackoffSecondsLimit)
+	}
+	return nil
+}
+
 func (c *controller) validateResourceType(resource string) error {
 	switch resource {
 	case common.ResourceGroup, common.ResourceApplication, common.ResourceCluster:
@@ -185,25 +311,20 @@ func JoinTriggers(triggers []string) string {
 	return strings.Join(triggers, _triggerSeparator)
 }
 
-func CheckIfEventMatch(webhook *wmodels.Webhook, event *models.Event) (bool, error) {
-	triggers := ParseTriggerStr(webhook.Triggers)
-	for _, trigger := range triggers {
-		if (trigger == models.Any) || (trigger == event.EventType) {
-			return true, nil
-		}
-	}
-	return false, nil
-}
-
 func ofWebhookModel(wm *wmodels.Webhook) *Webhook {
 	w := &Webhook{
 		CreateWebhookRequest: CreateWebhookRequest{
-			Enabled:          wm.Enabled,
-			URL:              wm.URL,
-			SSLVerifyEnabled: wm.SSLVerifyEnabled,
-			Description:      wm.Description,
-			Secret:           wm.Secret,
-			Triggers:         ParseTriggerStr(wm.Triggers),
+			Enabled:             wm.Enabled,
+			URL:                 wm.URL,
+			SSLVerifyEnabled:    wm.SSLVerifyEnabled,
+			Description:         wm.Description,
+			Secret:              wm.Secret,
+			SecondarySecret:     wm.SecondarySecret,
+			Triggers:            ParseTriggerStr(wm.Triggers),
+			MaxAttempts:         wm.MaxAttempts,
+			RetryBackoffSeconds: wm.RetryBackoffSeconds,
+			PayloadFormat:       wlgenerator.NormalizePayloadFormat(wm.PayloadFormat),
+			PayloadTemplate:     wm.PayloadTemplate,
 		},
 		ID:        wm.ID,
 		CreatedAt: wm.CreatedAt,
@@ -225,6 +346,8 @@ func ofWebhookLogSummaryModel(wm *wmodels.WebhookLogWithEventInfo) *LogSummary {
 		EventType:    wm.EventType,
 		Status:       wm.Status,
 		ErrorMessage: wm.ErrorMessage,
+		Attempts:     wm.Attempts,
+		NextRetryAt:  wm.NextRetryAt,
 		CreatedAt:    wm.CreatedAt,
 		UpdatedAt:    wm.UpdatedAt,
 	}
@@ -240,6 +363,8 @@ func ofWebhookLogModel(wm *wmodels.WebhookLog) *Log {
 			URL:          wm.URL,
 			Status:       wm.Status,
 			ErrorMessage: wm.ErrorMessage,
+			Attempts:     wm.Attempts,
+			NextRetryAt:  wm.NextRetryAt,
 			CreatedAt:    wm.CreatedAt,
 			UpdatedAt:    wm.UpdatedAt,
 		},
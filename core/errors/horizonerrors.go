@@ -29,13 +29,21 @@ var (
 	GitlabClient              = sourceType{name: "GitlabClient"}
 	GitlabResource            = sourceType{name: "GitlabResource"}
 	GithubResource            = sourceType{name: "GithubResource"}
+	GiteaResource             = sourceType{name: "GiteaResource"}
+	BitbucketResource         = sourceType{name: "BitbucketResource"}
+	GitRepoResource           = sourceType{name: "GitRepoResource"}
 	ClusterInDB               = sourceType{name: "ClusterInDB"}
 	CollectionInDB            = sourceType{name: "CollectionInDB"}
 	ClusterStateInArgo        = sourceType{name: "ClusterStateInArgo"}
 	TagInDB                   = sourceType{name: "TagInDB"}
 	BadgeInDB                 = sourceType{name: "BadgeInDB"}
+	DeployWindowInDB          = sourceType{name: "DeployWindowInDB"}
+	AdmissionPolicyInDB       = sourceType{name: "AdmissionPolicyInDB"}
 	ApplicationInArgo         = sourceType{name: "ApplicationInArgo"}
 	ApplicationResourceInArgo = sourceType{name: "ApplicationResourceInArgo"}
+	HelmReleaseInFlux         = sourceType{name: "HelmReleaseInFlux"}
+	GitRepositoryInFlux       = sourceType{name: "GitRepositoryInFlux"}
+	ReleaseInHelm             = sourceType{name: "ReleaseInHelm"}
 	ApplicationInDB           = sourceType{name: "ApplicationInDB"}
 	ApplicationRegionInDB     = sourceType{name: "ApplicationRegionInDB"}
 	EnvironmentRegionInDB     = sourceType{name: "EnvironmentRegionInDB"}
@@ -44,6 +52,7 @@ var (
 	GroupInDB                 = sourceType{name: "GroupInDB"}
 	K8SClient                 = sourceType{name: "K8SClient"}
 	RegistryInDB              = sourceType{name: "RegistryInDB"}
+	RegistryImage             = sourceType{name: "RegistryImage"}
 	Pipelinerun               = sourceType{name: "Pipelinerun"}
 	PipelinerunInTekton       = sourceType{name: "PipelinerunInTekton"}
 	PipelinerunInDB           = sourceType{name: "PipelinerunInDB"}
@@ -75,6 +84,9 @@ var (
 	CheckInDB                 = sourceType{name: "CheckInDB"}
 	CheckRunInDB              = sourceType{name: "CheckRunInDB"}
 	PRMessageInDB             = sourceType{name: "PRMessageInDB"}
+	ApprovalInDB              = sourceType{name: "ApprovalInDB"}
+	RoleInDB                  = sourceType{name: "RoleInDB"}
+	MemberGrantInDB           = sourceType{name: "MemberGrantInDB"}
 
 	// S3
 	PipelinerunLog = sourceType{name: "PipelinerunLog"}
@@ -226,6 +238,7 @@ var (
 	ErrShouldBuildDeployFirst          = errors.New("clusters with build config should build and deploy first")
 	ErrBuildDeployNotSupported         = errors.New("builddeploy is not supported for this cluster")
 	ErrFreedClusterNotSupportedRestart = errors.New("freed cluster is not supported to restart")
+	ErrDeployWindowClosed              = errors.New("deploy is not allowed by deploy windows")
 
 	// pipelinerun
 
@@ -247,6 +260,8 @@ var (
 	ErrGitlabMRNotReady            = errors.New("gitlab mr is not ready and cannot be merged")
 	ErrGitlabResourceNotFound      = errors.New("gitlab resource not found")
 	ErrGitLabDefaultBranchNotMatch = errors.New("gitlab default branch do not match")
+	ErrGitRepoInternal             = errors.New("git repo internal")
+	ErrGitRepoMergeConflict        = errors.New("git repo branches diverged and cannot be merged")
 
 	// git
 	ErrBranchAndCommitEmpty      = errors.New("branch and commit cannot be empty at the same time")
@@ -286,6 +301,12 @@ var (
 	// ErrRegistryUsedByRegions used when deleting a registry that is still used by regions
 	ErrRegistryUsedByRegions = errors.New("cannot delete a registry when used by regions")
 
+	// ErrRoleUsedByMembers used when deleting a role that is still bound to members
+	ErrRoleUsedByMembers = errors.New("cannot delete a role when bound to members")
+
+	// ErrMemberAlreadyExists used when granting a temporary member to a user who is already a direct member
+	ErrMemberAlreadyExists = errors.New("member already exists")
+
 	// ErrRegionUsedByClusters used when deleting a region that is still used by clusters
 	ErrRegionUsedByClusters        = errors.New("cannot delete a region when used by clusters")
 	ErrPipelineOutPut              = errors.New("pipeline output is not valid")
@@ -252,8 +252,12 @@ func (a *API) validateScope(scopes []string) error {
 	for _, validScope := range validScopes {
 		scopeMap[validScope] = true
 	}
-	for _, scope := range scopes {
-		if _, ok := scopeMap[scope]; !ok {
+	for _, s := range scopes {
+		name, _, _, err := scopeservice.ParseScope(s)
+		if err != nil {
+			return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		if _, ok := scopeMap[name]; !ok {
 			return perror.Wrap(herrors.ErrParamInvalid,
 				fmt.Sprintf("invalid scope: %s", scopes))
 		}
@@ -210,6 +210,10 @@ func (a *API) Delete(c *gin.Context) {
 		}
 	}
 	if err := a.applicationCtl.DeleteApplication(c, uint(appID), hard); err != nil {
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 			if e.Source == herrors.GroupInDB || e.Source == herrors.ApplicationInDB {
 				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
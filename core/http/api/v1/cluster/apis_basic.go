@@ -335,6 +335,10 @@ func (a *API) Delete(c *gin.Context) {
 		}
 	}
 	if err := a.clusterCtl.DeleteCluster(c, uint(clusterID), hard); err != nil {
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
 			return
@@ -358,6 +362,10 @@ func (a *API) Free(c *gin.Context) {
 
 	err = a.clusterCtl.FreeCluster(c, uint(clusterID))
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
 			return
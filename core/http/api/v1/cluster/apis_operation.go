@@ -54,6 +54,14 @@ func (a *API) BuildDeploy(c *gin.Context) {
 
 	resp, err := a.clusterCtl.BuildDeploy(c, uint(clusterID), request)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrDeployWindowClosed {
+			response.AbortWithRPCError(c, rpcerror.DeployWindowClosedError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 			if e.Source == herrors.ClusterInDB {
 				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
@@ -215,8 +223,26 @@ func (a *API) Restart(c *gin.Context) {
 		return
 	}
 
-	resp, err := a.clusterCtl.Restart(c, uint(clusterID))
+	var request cluster.RestartRequest
+	// the request body is optional, it's only used to override deploy windows
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			response.AbortWithRequestError(c, common.InvalidRequestBody,
+				fmt.Sprintf("request body is invalid, err: %v", err))
+			return
+		}
+	}
+
+	resp, err := a.clusterCtl.Restart(c, uint(clusterID), &request)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrDeployWindowClosed {
+			response.AbortWithRPCError(c, rpcerror.DeployWindowClosedError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if perror.Cause(err) == herrors.ErrFreedClusterNotSupportedRestart {
 			response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
 			return
@@ -249,6 +275,14 @@ func (a *API) Deploy(c *gin.Context) {
 
 	resp, err := a.clusterCtl.Deploy(c, uint(clusterID), request)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrDeployWindowClosed {
+			response.AbortWithRPCError(c, rpcerror.DeployWindowClosedError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		switch e := perror.Cause(err).(type) {
 		case *herrors.HorizonErrNotFound:
 			if e.Source == herrors.ClusterInDB {
@@ -419,6 +453,10 @@ func (a *API) Exec(c *gin.Context) {
 
 	resp, err := a.clusterCtl.Exec(c, uint(clusterID), request)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
 			return
@@ -447,6 +485,10 @@ func (a *API) Rollback(c *gin.Context) {
 
 	resp, err := a.clusterCtl.Rollback(c, uint(clusterID), request)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
 			return
@@ -19,6 +19,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/horizoncd/horizon/core/controller/access"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/server/response"
 	"github.com/horizoncd/horizon/pkg/server/rpcerror"
 	"github.com/horizoncd/horizon/pkg/util/log"
@@ -58,3 +60,36 @@ func (a *API) AccessReview(c *gin.Context) {
 
 	response.SuccessWithData(c, reviewResp)
 }
+
+func (a *API) Explain(c *gin.Context) {
+	const op = "access: explain"
+
+	var request *access.ExplainRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.WithFiled(c, "op", op).Errorf(err.Error())
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("request body is invalid, err: %v", err)))
+		return
+	}
+
+	if request.URL == "" || request.Method == "" {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("url and method should not be empty"))
+		return
+	}
+
+	explanation, err := a.accessCtl.Explain(c, request)
+	if err != nil {
+		log.WithFiled(c, "op", op).Errorf(err.Error())
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+
+	response.SuccessWithData(c, explanation)
+}
@@ -30,6 +30,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodPost,
 			Pattern:     "/accessreview",
 			HandlerFunc: api.AccessReview,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     "/accessreview/explain",
+			HandlerFunc: api.Explain,
 		},
 	}
 
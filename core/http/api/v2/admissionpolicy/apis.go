@@ -0,0 +1,184 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admissionpolicy
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/controller/admissionpolicy"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/admission"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const _paramAdmissionPolicyID = "admissionPolicyID"
+
+type API struct {
+	admissionPolicyCtl admissionpolicy.Controller
+}
+
+func NewAPI(admissionPolicyCtl admissionpolicy.Controller) *API {
+	return &API{admissionPolicyCtl: admissionPolicyCtl}
+}
+
+func (a *API) Create(c *gin.Context) {
+	const op = "admission policy: create"
+	var request admissionpolicy.CreateOrUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
+			err.Error())))
+		return
+	}
+
+	policy, err := a.admissionPolicyCtl.Create(c, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, policy)
+}
+
+func (a *API) List(c *gin.Context) {
+	const op = "admission policy: list"
+	policies, err := a.admissionPolicyCtl.List(c)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, policies)
+}
+
+func (a *API) Get(c *gin.Context) {
+	const op = "admission policy: get"
+	id, ok := uintParam(c, _paramAdmissionPolicyID)
+	if !ok {
+		return
+	}
+
+	policy, err := a.admissionPolicyCtl.Get(c, id)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, policy)
+}
+
+func (a *API) Update(c *gin.Context) {
+	const op = "admission policy: update"
+	id, ok := uintParam(c, _paramAdmissionPolicyID)
+	if !ok {
+		return
+	}
+	var request admissionpolicy.CreateOrUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
+			err.Error())))
+		return
+	}
+
+	policy, err := a.admissionPolicyCtl.Update(c, id, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, policy)
+}
+
+func (a *API) Delete(c *gin.Context) {
+	const op = "admission policy: delete"
+	id, ok := uintParam(c, _paramAdmissionPolicyID)
+	if !ok {
+		return
+	}
+
+	if err := a.admissionPolicyCtl.Delete(c, id); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) Evaluate(c *gin.Context) {
+	const op = "admission policy: evaluate"
+	var request admissionpolicy.EvaluateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
+			err.Error())))
+		return
+	}
+
+	result, err := a.admissionPolicyCtl.Evaluate(c, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+func (a *API) EvaluateByID(c *gin.Context) {
+	const op = "admission policy: evaluate by id"
+	id, ok := uintParam(c, _paramAdmissionPolicyID)
+	if !ok {
+		return
+	}
+	var request admission.Request
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
+			err.Error())))
+		return
+	}
+
+	result, err := a.admissionPolicyCtl.EvaluateByID(c, id, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+func uintParam(c *gin.Context, param string) (uint, bool) {
+	idStr := c.Param(param)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid %s: %s, err: %s",
+			param, idStr, err.Error())))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch perror.Cause(err) {
+	case herrors.ErrParamInvalid:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+	case herrors.ErrNameConflict:
+		response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+	case herrors.ErrForbidden:
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+	default:
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+	}
+}
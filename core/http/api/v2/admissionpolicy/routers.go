@@ -0,0 +1,64 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admissionpolicy
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/pkg/server/route"
+)
+
+func (a *API) RegisterRoute(engine *gin.Engine) {
+	apiGroup := engine.Group("/apis/core/v2/admissionpolicies")
+	routes := route.Routes{
+		{
+			Method:      http.MethodPost,
+			HandlerFunc: a.Create,
+		},
+		{
+			Method:      http.MethodGet,
+			HandlerFunc: a.List,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     "/evaluate",
+			HandlerFunc: a.Evaluate,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/:%v", _paramAdmissionPolicyID),
+			HandlerFunc: a.Get,
+		},
+		{
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/:%v", _paramAdmissionPolicyID),
+			HandlerFunc: a.Update,
+		},
+		{
+			Method:      http.MethodDelete,
+			Pattern:     fmt.Sprintf("/:%v", _paramAdmissionPolicyID),
+			HandlerFunc: a.Delete,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/:%v/evaluate", _paramAdmissionPolicyID),
+			HandlerFunc: a.EvaluateByID,
+		},
+	}
+	route.RegisterRoutes(apiGroup, routes)
+}
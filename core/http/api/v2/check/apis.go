@@ -0,0 +1,173 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/check"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const _paramCheckID = "checkID"
+
+type API struct {
+	checkCtl check.Controller
+}
+
+func NewAPI(checkCtl check.Controller) *API {
+	return &API{checkCtl: checkCtl}
+}
+
+func (a *API) Create(c *gin.Context) {
+	const op = "check: create"
+	resourceType, resourceID, ok := resourceParams(c)
+	if !ok {
+		return
+	}
+	var request check.CreateOrUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
+			err.Error())))
+		return
+	}
+
+	result, err := a.checkCtl.Create(c, resourceType, resourceID, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+func (a *API) List(c *gin.Context) {
+	const op = "check: list"
+	resourceType, resourceID, ok := resourceParams(c)
+	if !ok {
+		return
+	}
+
+	checks, err := a.checkCtl.List(c, resourceType, resourceID)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, checks)
+}
+
+func (a *API) Get(c *gin.Context) {
+	const op = "check: get"
+	resourceType, resourceID, ok := resourceParams(c)
+	if !ok {
+		return
+	}
+	id, ok := uintParam(c, _paramCheckID)
+	if !ok {
+		return
+	}
+
+	result, err := a.checkCtl.Get(c, resourceType, resourceID, id)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+func (a *API) Update(c *gin.Context) {
+	const op = "check: update"
+	resourceType, resourceID, ok := resourceParams(c)
+	if !ok {
+		return
+	}
+	id, ok := uintParam(c, _paramCheckID)
+	if !ok {
+		return
+	}
+	var request check.CreateOrUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
+			err.Error())))
+		return
+	}
+
+	result, err := a.checkCtl.Update(c, resourceType, resourceID, id, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+func (a *API) Delete(c *gin.Context) {
+	const op = "check: delete"
+	resourceType, resourceID, ok := resourceParams(c)
+	if !ok {
+		return
+	}
+	id, ok := uintParam(c, _paramCheckID)
+	if !ok {
+		return
+	}
+
+	if err := a.checkCtl.Delete(c, resourceType, resourceID, id); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func resourceParams(c *gin.Context) (string, uint, bool) {
+	resourceID, ok := uintParam(c, common.ParamResourceID)
+	if !ok {
+		return "", 0, false
+	}
+	return c.Param(common.ParamResourceType), resourceID, true
+}
+
+func uintParam(c *gin.Context, param string) (uint, bool) {
+	idStr := c.Param(param)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid %s: %s, err: %s",
+			param, idStr, err.Error())))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch perror.Cause(err) {
+	case herrors.ErrParamInvalid:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+	case herrors.ErrForbidden:
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+	default:
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+	}
+}
@@ -351,6 +351,10 @@ func (a *API) Delete(c *gin.Context) {
 		}
 	}
 	if err := a.clusterCtl.DeleteCluster(c, uint(clusterID), hard); err != nil {
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
 			return
@@ -374,6 +378,10 @@ func (a *API) Free(c *gin.Context) {
 
 	err = a.clusterCtl.FreeCluster(c, uint(clusterID))
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
 			return
@@ -546,6 +554,14 @@ func (a *API) CreatePipelineRun(c *gin.Context) {
 	}
 	pipelineRun, err := a.clusterCtl.CreatePipelineRun(c, uint(clusterID), &req)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrDeployWindowClosed {
+			response.AbortWithRPCError(c, rpcerror.DeployWindowClosedError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
 			return
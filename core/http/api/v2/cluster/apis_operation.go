@@ -52,6 +52,14 @@ func (a *API) BuildDeploy(c *gin.Context) {
 
 	resp, err := a.clusterCtl.BuildDeploy(c, uint(clusterID), request)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrDeployWindowClosed {
+			response.AbortWithRPCError(c, rpcerror.DeployWindowClosedError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 			if e.Source == herrors.ClusterInDB {
 				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
@@ -112,6 +120,30 @@ func (a *API) GetDiff(c *gin.Context) {
 	response.SuccessWithData(c, resp)
 }
 
+func (a *API) ListImages(c *gin.Context) {
+	op := "cluster: list images"
+	clusterIDStr := c.Param(common.ParamClusterID)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+
+	resp, err := a.clusterCtl.ListImages(c, uint(clusterID))
+	if err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			if e.Source == herrors.ClusterInDB {
+				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+				return
+			}
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
 func (a *API) GetResourceTree(c *gin.Context) {
 	op := "cluster: get resource tree"
 	clusterIDStr := c.Param(common.ParamClusterID)
@@ -156,6 +188,28 @@ func (a *API) GetStep(c *gin.Context) {
 	response.SuccessWithData(c, resp)
 }
 
+func (a *API) ListAnalysisRuns(c *gin.Context) {
+	op := "cluster: list analysis runs"
+	clusterIDStr := c.Param(common.ParamClusterID)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+
+	resp, err := a.clusterCtl.ListAnalysisRuns(c, uint(clusterID))
+	if err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithError(c, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
 func (a *API) ClusterStatus(c *gin.Context) {
 	op := "cluster: cluster status"
 	clusterIDStr := c.Param(common.ParamClusterID)
@@ -333,8 +387,26 @@ func (a *API) Restart(c *gin.Context) {
 		return
 	}
 
-	resp, err := a.clusterCtl.Restart(c, uint(clusterID))
+	var request cluster.RestartRequest
+	// the request body is optional, it's only used to override deploy windows
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			response.AbortWithRequestError(c, common.InvalidRequestBody,
+				fmt.Sprintf("request body is invalid, err: %v", err))
+			return
+		}
+	}
+
+	resp, err := a.clusterCtl.Restart(c, uint(clusterID), &request)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrDeployWindowClosed {
+			response.AbortWithRPCError(c, rpcerror.DeployWindowClosedError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if perror.Cause(err) == herrors.ErrFreedClusterNotSupportedRestart {
 			response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
 			return
@@ -375,6 +447,10 @@ func (a *API) ExecuteAction(c *gin.Context) {
 	})
 	if err != nil {
 		err = perror.Wrap(err, "failed to promote cluster")
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
 			return
@@ -407,6 +483,14 @@ func (a *API) Deploy(c *gin.Context) {
 
 	resp, err := a.clusterCtl.Deploy(c, uint(clusterID), request)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrDeployWindowClosed {
+			response.AbortWithRPCError(c, rpcerror.DeployWindowClosedError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		switch e := perror.Cause(err).(type) {
 		case *herrors.HorizonErrNotFound:
 			if e.Source == herrors.ClusterInDB {
@@ -487,6 +571,10 @@ func (a *API) Exec(c *gin.Context) {
 
 	resp, err := a.clusterCtl.Exec(c, uint(clusterID), request)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
 			return
@@ -515,6 +603,10 @@ func (a *API) Rollback(c *gin.Context) {
 
 	resp, err := a.clusterCtl.Rollback(c, uint(clusterID), request)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
 			return
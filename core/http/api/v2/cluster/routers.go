@@ -35,6 +35,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/applications/:%v/clusters", common.ParamApplicationID),
 			HandlerFunc: api.ListByApplication,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/applications/:%v/promoteconfig", common.ParamApplicationID),
+			HandlerFunc: api.PromoteConfig,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     "/clusters",
@@ -59,10 +63,18 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/clusters/:%v/diffs", common.ParamClusterID),
 			HandlerFunc: api.GetDiff,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/images", common.ParamClusterID),
+			HandlerFunc: api.ListImages,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/clusters/:%v/step", common.ParamClusterID),
 			HandlerFunc: api.GetStep,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/analysisruns", common.ParamClusterID),
+			HandlerFunc: api.ListAnalysisRuns,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/clusters/:%v/resourcetree", common.ParamClusterID),
@@ -139,6 +151,10 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodPost,
 			Pattern:     fmt.Sprintf("/clusters/:%v/pipelineruns", common.ParamClusterID),
 			HandlerFunc: api.CreatePipelineRun,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/clone", common.ParamClusterID),
+			HandlerFunc: api.Clone,
 		},
 	}
 
@@ -15,12 +15,33 @@
 package event
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/controller/event"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	_cursorQuery       = "cursor"
+	_lastEventIDHeader = "Last-Event-ID"
+	// _heartbeatInterval keeps the stream alive through proxies while there is no event
+	_heartbeatInterval = 15 * time.Second
+	// _retryMilliseconds tells the client how long to wait before reconnecting
+	_retryMilliseconds = 3000
+)
+
 type API struct {
 	eventCtl event.Controller
 }
@@ -36,3 +57,100 @@ func NewAPI(controller event.Controller) *API {
 func (a *API) ListSupportEvents(c *gin.Context) {
 	response.SuccessWithData(c, a.eventCtl.ListSupportEvents(c))
 }
+
+// StreamEvents streams events as server-sent events, the id of each message is the event id.
+// Clients resume from the last received event by the Last-Event-ID header or the cursor query.
+func (a *API) StreamEvents(c *gin.Context) {
+	const op = "event: stream events"
+	filter := &event.StreamFilter{
+		ResourceType: c.Query(common.ParamResourceType),
+		EventTypes:   c.QueryArray(common.EventType),
+	}
+	if resourceIDStr := c.Query(common.ParamResourceID); resourceIDStr != "" {
+		resourceID, err := strconv.ParseUint(resourceIDStr, 10, 0)
+		if err != nil {
+			response.AbortWithRPCError(c, rpcerror.ParamError.
+				WithErrMsgf("invalid resource id: %s", resourceIDStr))
+			return
+		}
+		filter.ResourceID = uint(resourceID)
+	}
+	cursorStr := c.GetHeader(_lastEventIDHeader)
+	if cursorStr == "" {
+		cursorStr = c.Query(_cursorQuery)
+	}
+	if cursorStr != "" {
+		cursor, err := strconv.ParseUint(cursorStr, 10, 0)
+		if err != nil {
+			response.AbortWithRPCError(c, rpcerror.ParamError.
+				WithErrMsgf("invalid cursor: %s", cursorStr))
+			return
+		}
+		cursorUint := uint(cursor)
+		filter.Cursor = &cursorUint
+	}
+
+	// gin.Context is never done, so stop streaming when the client disconnects
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
+	go func() {
+		select {
+		case <-c.Request.Context().Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	events, err := a.eventCtl.StreamEvents(ctx, filter)
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	if _, err := fmt.Fprintf(c.Writer, "retry: %d\n\n", _retryMilliseconds); err != nil {
+		return
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(_heartbeatInterval)
+	defer heartbeat.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return false
+			}
+			if err := writeEvent(w, e); err != nil {
+				log.WithFiled(c, "op", op).Warningf("failed to write event %d: %s", e.ID, err.Error())
+				return false
+			}
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		}
+	})
+}
+
+// writeEvent writes the event in the format of server-sent events
+func writeEvent(w io.Writer, e *event.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.EventType, data)
+	return err
+}
@@ -31,6 +31,11 @@ func (a *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			HandlerFunc: a.ListSupportEvents,
 		},
+		{
+			Pattern:     "/events/stream",
+			Method:      http.MethodGet,
+			HandlerFunc: a.StreamEvents,
+		},
 	}
 
 	route.RegisterRoutes(coreAPI, coreRoutes)
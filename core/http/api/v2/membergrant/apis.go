@@ -0,0 +1,186 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package membergrant
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/membergrant"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+)
+
+type API struct {
+	grantCtrl membergrant.Controller
+}
+
+func NewAPI(controller membergrant.Controller) *API {
+	return &API{grantCtrl: controller}
+}
+
+func (a *API) CreateGroupMemberGrant(c *gin.Context) {
+	a.createMemberGrant(c, common.ResourceGroup, common.ParamGroupID)
+}
+
+func (a *API) CreateApplicationMemberGrant(c *gin.Context) {
+	a.createMemberGrant(c, common.ResourceApplication, common.ParamApplicationID)
+}
+
+func (a *API) CreateClusterMemberGrant(c *gin.Context) {
+	a.createMemberGrant(c, common.ResourceCluster, common.ParamClusterID)
+}
+
+func (a *API) createMemberGrant(c *gin.Context, resourceType, idParam string) {
+	resourceID, ok := uintParam(c, idParam)
+	if !ok {
+		return
+	}
+
+	var request *membergrant.CreateMemberGrantRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
+			err.Error())))
+		return
+	}
+
+	grant, err := a.grantCtrl.CreateMemberGrant(c, resourceType, resourceID, request)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response.SuccessWithData(c, grant)
+}
+
+func (a *API) ListMemberGrants(c *gin.Context) {
+	keywords := q.KeyWords{}
+	for _, key := range []string{common.MemberGrantQueryByResourceType, common.MemberGrantQueryByStatus} {
+		if v := c.Query(key); v != "" {
+			keywords[key] = v
+		}
+	}
+	for _, key := range []string{common.MemberGrantQueryByResourceID, common.MemberGrantQueryByUserID} {
+		if v := c.Query(key); v != "" {
+			id, err := strconv.ParseUint(v, 10, 0)
+			if err != nil {
+				response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf("invalid %s: %s", key, v))
+				return
+			}
+			keywords[key] = uint(id)
+		}
+	}
+
+	grants, total, err := a.grantCtrl.ListMemberGrants(c, q.New(keywords).WithPagination(c))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Items: grants,
+		Total: total,
+	})
+}
+
+func (a *API) GetMemberGrant(c *gin.Context) {
+	id, ok := uintParam(c, common.ParamMemberGrantID)
+	if !ok {
+		return
+	}
+
+	grant, err := a.grantCtrl.GetMemberGrant(c, id)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response.SuccessWithData(c, grant)
+}
+
+func (a *API) ApproveMemberGrant(c *gin.Context) {
+	id, request, ok := reviewRequest(c)
+	if !ok {
+		return
+	}
+
+	grant, err := a.grantCtrl.ApproveMemberGrant(c, id, request)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response.SuccessWithData(c, grant)
+}
+
+func (a *API) RejectMemberGrant(c *gin.Context) {
+	id, request, ok := reviewRequest(c)
+	if !ok {
+		return
+	}
+
+	grant, err := a.grantCtrl.RejectMemberGrant(c, id, request)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response.SuccessWithData(c, grant)
+}
+
+func reviewRequest(c *gin.Context) (uint, *membergrant.ReviewMemberGrantRequest, bool) {
+	id, ok := uintParam(c, common.ParamMemberGrantID)
+	if !ok {
+		return 0, nil, false
+	}
+
+	request := &membergrant.ReviewMemberGrantRequest{}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(request); err != nil {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
+				err.Error())))
+			return 0, nil, false
+		}
+	}
+	return id, request, true
+}
+
+func uintParam(c *gin.Context, param string) (uint, bool) {
+	idStr := c.Param(param)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid %s: %s, err: %s",
+			param, idStr, err.Error())))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func abortWithError(c *gin.Context, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch perror.Cause(err) {
+	case herrors.ErrParamInvalid:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+	case herrors.ErrForbidden:
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+	case herrors.ErrMemberAlreadyExists:
+		response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+	default:
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+	}
+}
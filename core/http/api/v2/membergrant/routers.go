@@ -0,0 +1,68 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package membergrant
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/pkg/server/route"
+)
+
+// RegisterRoute registers routes
+func (a *API) RegisterRoute(engine *gin.Engine) {
+	apiGroup := engine.Group("/apis/core/v2")
+
+	var routes = route.Routes{
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/groups/:%v/membergrants", common.ParamGroupID),
+			HandlerFunc: a.CreateGroupMemberGrant,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/applications/:%v/membergrants", common.ParamApplicationID),
+			HandlerFunc: a.CreateApplicationMemberGrant,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/membergrants", common.ParamClusterID),
+			HandlerFunc: a.CreateClusterMemberGrant,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     "/membergrants",
+			HandlerFunc: a.ListMemberGrants,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/membergrants/:%v", common.ParamMemberGrantID),
+			HandlerFunc: a.GetMemberGrant,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/membergrants/:%v/approve", common.ParamMemberGrantID),
+			HandlerFunc: a.ApproveMemberGrant,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/membergrants/:%v/reject", common.ParamMemberGrantID),
+			HandlerFunc: a.RejectMemberGrant,
+		},
+	}
+	route.RegisterRoutes(apiGroup, routes)
+}
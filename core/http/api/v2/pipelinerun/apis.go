@@ -15,6 +15,7 @@
 package pipelinerun
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 
@@ -28,6 +29,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/server/response"
 	"github.com/horizoncd/horizon/pkg/server/rpcerror"
 	"github.com/horizoncd/horizon/pkg/util/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
 
 	"github.com/gin-gonic/gin"
 )
@@ -328,6 +330,55 @@ func (a *API) ListPrMessages(c *gin.Context) {
 	})
 }
 
+func (a *API) Approve(c *gin.Context) {
+	a.decide(c, "pipelinerun: approve", a.prCtl.Approve)
+}
+
+func (a *API) Reject(c *gin.Context) {
+	a.decide(c, "pipelinerun: reject", a.prCtl.Reject)
+}
+
+func (a *API) decide(c *gin.Context, op string,
+	f func(ctx context.Context, pipelinerunID uint, request *prctl.ApprovalRequest) error) {
+	var req prctl.ApprovalRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+			return
+		}
+	}
+	a.withPipelinerunID(c, func(prID uint) {
+		if err := f(c, prID, &req); err != nil {
+			if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(e.Error()))
+				return
+			}
+			switch perror.Cause(err) {
+			case herrors.ErrParamInvalid:
+				response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			case herrors.ErrForbidden:
+				response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			default:
+				log.WithFiled(c, "op", op).Errorf("%+v", err)
+				response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+			}
+			return
+		}
+		response.Success(c)
+	})
+}
+
+func (a *API) ListApprovals(c *gin.Context) {
+	a.withPipelinerunID(c, func(prID uint) {
+		approvals, err := a.prCtl.ListApprovals(c, prID)
+		if err != nil {
+			response.AbortWithError(c, err)
+			return
+		}
+		response.SuccessWithData(c, approvals)
+	})
+}
+
 func (a *API) withPipelinerunID(c *gin.Context, f func(pipelineRunID uint)) {
 	idStr := c.Param(_pipelinerunIDParam)
 	id, err := strconv.ParseUint(idStr, 10, 0)
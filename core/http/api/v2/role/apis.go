@@ -15,9 +15,20 @@
 package role
 
 import (
+	"fmt"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	"github.com/horizoncd/horizon/core/controller/role"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+)
+
+const (
+	// param
+	_roleIDParam = "roleID"
 )
 
 type API struct {
@@ -36,3 +47,95 @@ func (a *API) ListRole(c *gin.Context) {
 	}
 	response.SuccessWithData(c, roles)
 }
+
+func (a *API) CreateRole(c *gin.Context) {
+	var request *role.CreateRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
+			err.Error())))
+		return
+	}
+
+	r, err := a.roleCtrl.CreateRole(c, request)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response.SuccessWithData(c, r)
+}
+
+func (a *API) GetRole(c *gin.Context) {
+	id, ok := roleID(c)
+	if !ok {
+		return
+	}
+
+	r, err := a.roleCtrl.GetRole(c, id)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response.SuccessWithData(c, r)
+}
+
+func (a *API) UpdateRole(c *gin.Context) {
+	id, ok := roleID(c)
+	if !ok {
+		return
+	}
+
+	var request *role.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
+			err.Error())))
+		return
+	}
+
+	r, err := a.roleCtrl.UpdateRole(c, id, request)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response.SuccessWithData(c, r)
+}
+
+func (a *API) DeleteRole(c *gin.Context) {
+	id, ok := roleID(c)
+	if !ok {
+		return
+	}
+
+	if err := a.roleCtrl.DeleteRole(c, id); err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+func roleID(c *gin.Context) (uint, bool) {
+	idStr := c.Param(_roleIDParam)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid id: %s, err: %s",
+			idStr, err.Error())))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func abortWithError(c *gin.Context, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch perror.Cause(err) {
+	case herrors.ErrParamInvalid:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+	case herrors.ErrNameConflict:
+		response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+	case herrors.ErrRoleUsedByMembers:
+		response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
+	default:
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+	}
+}
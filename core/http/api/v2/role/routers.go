@@ -15,6 +15,7 @@
 package role
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -30,6 +31,22 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			Pattern:     "/roles",
 			HandlerFunc: api.ListRole,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     "/roles",
+			HandlerFunc: api.CreateRole,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/roles/:%v", _roleIDParam),
+			HandlerFunc: api.GetRole,
+		}, {
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/roles/:%v", _roleIDParam),
+			HandlerFunc: api.UpdateRole,
+		}, {
+			Method:      http.MethodDelete,
+			Pattern:     fmt.Sprintf("/roles/:%v", _roleIDParam),
+			HandlerFunc: api.DeleteRole,
 		},
 	}
 	route.RegisterRoutes(apiGroup, routes)
@@ -63,6 +63,16 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     fmt.Sprintf("/webhooks/:%v/logs", _webhookIDParam),
 			HandlerFunc: api.ListWebhookLogs,
 		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/webhooks/:%v/redeliver", _webhookIDParam),
+			HandlerFunc: api.RedeliverWebhookLogs,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/webhooks/:%v/preview", _webhookIDParam),
+			HandlerFunc: api.PreviewWebhookPayload,
+		},
 		{
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/webhooklogs/:%v", _webhookLogIDParam),
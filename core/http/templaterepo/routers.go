@@ -0,0 +1,45 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templaterepo
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/horizoncd/horizon/pkg/server/route"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/templaterepo/filerepo"
+)
+
+// RegisterRoutes register routes to serve charts, only for template repos which store charts by horizon itself
+func RegisterRoutes(engine *gin.Engine, repo templaterepo.TemplateRepo) {
+	if cached, ok := repo.(*templaterepo.RepoWithCache); ok {
+		repo = cached.TemplateRepo
+	}
+	handler, ok := repo.(http.Handler)
+	if !ok {
+		return
+	}
+	api := engine.Group(filerepo.ServePath)
+
+	var routes = route.Routes{
+		{
+			Method:      http.MethodGet,
+			Pattern:     "/:file",
+			HandlerFunc: gin.WrapH(handler),
+		},
+	}
+	route.RegisterRoutes(api, routes)
+}
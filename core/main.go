@@ -20,18 +20,31 @@ import (
 	"github.com/horizoncd/horizon/core/cmd"
 
 	// for image registry
+	_ "github.com/horizoncd/horizon/pkg/cluster/registry/distribution"
 	_ "github.com/horizoncd/horizon/pkg/cluster/registry/harbor/v1"
 	_ "github.com/horizoncd/horizon/pkg/cluster/registry/harbor/v2"
 
 	_ "github.com/horizoncd/horizon/pkg/git"
+	_ "github.com/horizoncd/horizon/pkg/git/bitbucket"
+	_ "github.com/horizoncd/horizon/pkg/git/gitea"
 	_ "github.com/horizoncd/horizon/pkg/git/github"
 	_ "github.com/horizoncd/horizon/pkg/git/gitlab"
 
+	// for event sink
+	_ "github.com/horizoncd/horizon/pkg/eventhandler/sink/httpsink"
+	_ "github.com/horizoncd/horizon/pkg/eventhandler/sink/kafkarestsink"
+	_ "github.com/horizoncd/horizon/pkg/eventhandler/sink/natssink"
+
 	// for template repo
 	_ "github.com/horizoncd/horizon/pkg/templaterepo/chartmuseumbase"
+	_ "github.com/horizoncd/horizon/pkg/templaterepo/filerepo"
+	_ "github.com/horizoncd/horizon/pkg/templaterepo/oci"
 
 	// for k8s workload
+	_ "github.com/horizoncd/horizon/pkg/workload/cronjob"
+	_ "github.com/horizoncd/horizon/pkg/workload/daemonset"
 	_ "github.com/horizoncd/horizon/pkg/workload/deployment"
+	_ "github.com/horizoncd/horizon/pkg/workload/job"
 	_ "github.com/horizoncd/horizon/pkg/workload/kservice"
 	_ "github.com/horizoncd/horizon/pkg/workload/pod"
 	_ "github.com/horizoncd/horizon/pkg/workload/rollout"
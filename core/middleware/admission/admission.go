@@ -35,6 +35,13 @@ func Middleware(skippers ...middleware.Skipper) gin.HandlerFunc {
 			c.Next()
 			return
 		}
+		// deletion of applications and clusters is admitted by the controllers,
+		// which are able to provide the object being deleted as oldObject
+		if attr.GetVerb() == string(admissionmodels.OperationDelete) && attr.GetSubResource() == "" &&
+			(attr.GetResource() == common.ResourceApplication || attr.GetResource() == common.ResourceCluster) {
+			c.Next()
+			return
+		}
 		var object interface{}
 		// read request body and avoid side-effects on c.Request.Body
 		bodyBytes, err := ioutil.ReadAll(c.Request.Body)
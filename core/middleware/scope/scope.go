@@ -107,26 +107,24 @@ func Middleware(param *param.Param, applicationRegionCtl applicationregion.Contr
 			response.AbortWithRequestError(c, common.RequestInfoError, err.Error())
 			return
 		}
-		// for request to access cluster and its sub resources, set scope param
+		// for request to access cluster and its sub resources, set scope param.
+		// the scope is always resolved from the cluster rather than trusting the one in query,
+		// since rules and oauth scopes can be constrained by the environment and region
 		if requestInfo.APIGroup == common.GroupCore && (requestInfo.Resource == common.ResourceCluster ||
 			requestInfo.Resource == common.ResourcePipelinerun ||
 			requestInfo.Resource == common.ResourceCheckrun) && requestInfo.Name != "" {
-			query := c.Request.URL.Query()
-			if query.Get(hctx.ParamScope) == "" {
-				scope, err := getScope(c, mgr, requestInfo.Resource, requestInfo.Name)
-				if err != nil {
-					if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
-						response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
-						return
-					}
-					log.Errorf(c, "failed to get scope: %v", err.Error())
-					c.Next()
+			scope, err := getScope(c, mgr, requestInfo.Resource, requestInfo.Name)
+			if err != nil {
+				if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+					response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
 					return
 				}
-				hctx.SetScope(c, scope)
-				log.Debugf(c, "success to set scope to param: %s", scope)
-				c.Next()
+				log.Errorf(c, "failed to get scope: %v", err.Error())
+				response.AbortWithInternalError(c, fmt.Sprintf("failed to get scope: %v", err.Error()))
+				return
 			}
+			hctx.SetScope(c, scope)
+			log.Debugf(c, "success to set scope to param: %s", scope)
 		}
 		c.Next()
 	}, skippers...)
@@ -73,6 +73,8 @@ func MiddleWare(oauthCtl oauthcheck.Controller, skipMatchers ...middleware.Skipp
 			return
 		}
 		common.SetUser(c, user)
+		// the controllers check the scope of token for the resources not in the request path
+		common.SetAccessToken(c, token)
 
 		requestInfo, err := RequestInfoFty.NewRequestInfo(c, c.Request)
 		if err != nil {
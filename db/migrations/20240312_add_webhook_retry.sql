@@ -0,0 +1,23 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+ALTER TABLE `tb_webhook`
+    ADD COLUMN `max_attempts` int(10) unsigned NOT NULL DEFAULT '1' COMMENT 'max number of attempts to send a log, 1 means no retry' AFTER `triggers`,
+    ADD COLUMN `retry_backoff_seconds` int(10) unsigned NOT NULL DEFAULT '10' COMMENT 'base delay of the exponential backoff between attempts' AFTER `max_attempts`;
+
+ALTER TABLE `tb_webhook_log`
+    ADD COLUMN `attempts` int(10) unsigned NOT NULL DEFAULT '0' COMMENT 'number of attempts already made' AFTER `error_message`,
+    ADD COLUMN `next_retry_at` datetime DEFAULT NULL COMMENT 'time of the next attempt when retrying' AFTER `attempts`,
+    ADD KEY `idx_webhook_id_created_at` (`webhook_id`, `created_at`);
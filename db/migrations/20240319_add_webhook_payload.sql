@@ -0,0 +1,18 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+ALTER TABLE `tb_webhook`
+    ADD COLUMN `payload_format` varchar(64) NOT NULL DEFAULT 'default' COMMENT 'default/template/slack/feishu/dingtalk/wecom' AFTER `retry_backoff_seconds`,
+    ADD COLUMN `payload_template` text NOT NULL COMMENT 'go template to render the payload' AFTER `payload_format`;
@@ -0,0 +1,37 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- deploy window table
+CREATE TABLE IF NOT EXISTS `tb_deploy_window`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `name`          varchar(64)         NOT NULL DEFAULT '' COMMENT 'name of the window',
+    `description`   varchar(256)        NOT NULL DEFAULT '' COMMENT 'description of the window',
+    `resource_type` varchar(64)         NOT NULL COMMENT 'environments, groups or applications',
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the resource',
+    `environment`   varchar(128)        NOT NULL DEFAULT '' COMMENT 'environment the window applies to, empty means all',
+    `kind`          varchar(16)         NOT NULL COMMENT 'freeze or allow',
+    `schedule`      varchar(128)        NOT NULL COMMENT 'cron expression of the window openings',
+    `duration`      int(11) unsigned    NOT NULL DEFAULT 0 COMMENT 'minutes the window stays open',
+    `timezone`      varchar(64)         NOT NULL DEFAULT 'UTC' COMMENT 'timezone of the schedule',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`    bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    KEY `idx_resource` (`resource_type`, `resource_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,40 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- approval checks
+ALTER TABLE `tb_check`
+    ADD COLUMN `kind`        varchar(32)   NOT NULL DEFAULT '' COMMENT 'empty for external checks, or approval' AFTER `resource_id`,
+    ADD COLUMN `environment` varchar(128)  NOT NULL DEFAULT '' COMMENT 'environment the check applies to, empty means all' AFTER `kind`,
+    ADD COLUMN `approval`    varchar(2048) NOT NULL DEFAULT '' COMMENT 'approval policy in json' AFTER `environment`,
+    DROP INDEX `uk_resource_deleted`,
+    ADD UNIQUE KEY `uk_resource_kind_environment_deleted` (`resource_type`, `resource_id`, `kind`, `environment`, `deleted_ts`);
+
+-- approval table
+CREATE TABLE IF NOT EXISTS `tb_pr_approval`
+(
+    `id`              bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `checkrun_id`     bigint(20) unsigned NOT NULL COMMENT 'check run id',
+    `pipeline_run_id` bigint(20) unsigned NOT NULL COMMENT 'pipeline run id',
+    `decision`        varchar(32)         NOT NULL COMMENT 'approved or rejected',
+    `comment`         varchar(1024)       NOT NULL DEFAULT '' COMMENT 'comment of the approver',
+    `created_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`      bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'approver',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_checkrun_created_by_deleted` (`checkrun_id`, `created_by`, `deleted_ts`),
+    KEY `idx_pipeline_run_id` (`pipeline_run_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
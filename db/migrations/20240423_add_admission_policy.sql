@@ -0,0 +1,37 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- admission policy table
+CREATE TABLE IF NOT EXISTS `tb_admission_policy`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `name`           varchar(64)         NOT NULL COMMENT 'name of the policy',
+    `description`    varchar(256)        NOT NULL DEFAULT '' COMMENT 'description of the policy',
+    `kind`           varchar(16)         NOT NULL COMMENT 'validating or mutating',
+    `language`       varchar(16)         NOT NULL DEFAULT 'cel' COMMENT 'language of the expression',
+    `rules`          varchar(2048)       NOT NULL COMMENT 'json of the rules the policy applies to',
+    `expression`     text                NOT NULL COMMENT 'expression evaluated against admission requests',
+    `message`        varchar(512)        NOT NULL DEFAULT '' COMMENT 'message when the request is denied',
+    `failure_policy` varchar(16)         NOT NULL DEFAULT 'fail' COMMENT 'ignore or fail',
+    `dry_run`        tinyint(1)          NOT NULL DEFAULT 0 COMMENT 'evaluate and log only',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`     bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`     bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`     bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_name_deleted_ts` (`name`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -10,9 +10,11 @@ require (
 	github.com/aws/aws-sdk-go v1.38.49
 	github.com/coreos/go-oidc/v3 v3.2.0
 	github.com/gin-gonic/gin v1.7.7
+	github.com/go-git/go-git/v5 v5.4.2
 	github.com/go-redis/redis/v8 v8.3.3
 	github.com/golang-jwt/jwt/v4 v4.4.3
 	github.com/golang/mock v1.6.0
+	github.com/google/cel-go v0.6.0
 	github.com/google/go-containerregistry v0.1.3
 	github.com/google/go-github/v41 v41.0.0
 	github.com/google/uuid v1.2.0
@@ -23,6 +25,7 @@ require (
 	github.com/johannesboyne/gofakes3 v0.0.0-20210819161434-5c8dfcfe5310
 	github.com/mattbaird/jsonpatch v0.0.0-20230413205102-771768614e91
 	github.com/mozillazg/go-pinyin v0.18.0
+	github.com/nats-io/nats.go v1.11.0
 	github.com/pkg/errors v0.9.1
 	github.com/prometheus/client_golang v1.11.0
 	github.com/rbcervilla/redisstore/v8 v8.1.0
@@ -37,6 +40,7 @@ require (
 	github.com/xanzy/go-gitlab v0.50.4
 	golang.org/x/net v0.0.0-20220107192237-5cfca573fb4d
 	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d
+	google.golang.org/protobuf v1.26.0-rc.1
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
 	gopkg.in/evanphx/json-patch.v5 v5.9.0
 	gopkg.in/igm/sockjs-go.v3 v3.0.1
@@ -47,7 +51,7 @@ require (
 	gorm.io/gorm v1.21.15
 	gorm.io/plugin/prometheus v0.0.0-20210820101226-2a49866f83ee
 	gorm.io/plugin/soft_delete v1.0.3
-	helm.sh/helm/v3 v3.1.1
+	helm.sh/helm/v3 v3.5.4
 	k8s.io/api v0.23.5
 	k8s.io/apimachinery v0.23.5
 	k8s.io/cli-runtime v0.23.5
@@ -61,6 +65,8 @@ require (
 )
 
 replace (
+	github.com/docker/distribution => github.com/docker/distribution v0.0.0-20191216044856-a8371794149d
+	github.com/docker/docker => github.com/docker/docker v17.12.0-ce-rc1.0.20200916142827-bd33bbf0497b+incompatible
 	k8s.io/api => k8s.io/api v0.20.10
 	k8s.io/apiextensions-apiserver => k8s.io/apiextensions-apiserver v0.20.10
 	k8s.io/apimachinery => k8s.io/apimachinery v0.20.10
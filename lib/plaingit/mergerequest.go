@@ -0,0 +1,203 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plaingit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/xanzy/go-gitlab"
+)
+
+// _mergeRequestRefPrefix is the prefix of references which store merge requests.
+// Each merge request is stored as a commit with an empty tree, whose message is the merge request in json,
+// so that merge requests are kept along with the repository and pushed to remote repositories.
+// refs/merge-requests is not used since it's reserved by gitlab.
+const _mergeRequestRefPrefix = "refs/horizon/merge-requests/"
+
+func mergeRequestRefName(iid int) plumbing.ReferenceName {
+	return plumbing.ReferenceName(fmt.Sprintf("%s%d", _mergeRequestRefPrefix, iid))
+}
+
+// mergeRequests returns all merge requests of the repository, ordered by IID
+func (r *repository) mergeRequests() ([]*gitlab.MergeRequest, error) {
+	iter, err := r.References()
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	defer iter.Close()
+	hashes := make([]plumbing.Hash, 0)
+	_ = iter.ForEach(func(ref *plumbing.Reference) error {
+		if strings.HasPrefix(ref.Name().String(), _mergeRequestRefPrefix) {
+			hashes = append(hashes, ref.Hash())
+		}
+		return nil
+	})
+
+	mrs := make([]*gitlab.MergeRequest, 0, len(hashes))
+	for _, hash := range hashes {
+		c, err := r.commitObject(hash)
+		if err != nil {
+			return nil, err
+		}
+		mr := &gitlab.MergeRequest{}
+		if err := json.Unmarshal([]byte(c.Message), mr); err != nil {
+			return nil, perror.Wrapf(herrors.ErrGitRepoInternal,
+				"failed to unmarshal merge request of %s: %v", r.path, err)
+		}
+		mrs = append(mrs, mr)
+	}
+	sort.Slice(mrs, func(i, j int) bool {
+		return mrs[i].IID < mrs[j].IID
+	})
+	return mrs, nil
+}
+
+// mergeRequest returns the merge request with the given IID
+func (r *repository) mergeRequest(iid int) (*gitlab.MergeRequest, error) {
+	ref, err := r.Reference(mergeRequestRefName(iid), true)
+	if err != nil {
+		if err == plumbing.ErrReferenceNotFound {
+			return nil, herrors.NewErrNotFound(herrors.GitRepoResource,
+				fmt.Sprintf("merge request !%d not found in %s", iid, r.path))
+		}
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	c, err := r.commitObject(ref.Hash())
+	if err != nil {
+		return nil, err
+	}
+	mr := &gitlab.MergeRequest{}
+	if err := json.Unmarshal([]byte(c.Message), mr); err != nil {
+		return nil, perror.Wrapf(herrors.ErrGitRepoInternal,
+			"failed to unmarshal merge request !%d of %s: %v", iid, r.path, err)
+	}
+	return mr, nil
+}
+
+// nextMergeRequestIID returns the IID for a new merge request
+func (r *repository) nextMergeRequestIID() (int, error) {
+	iter, err := r.References()
+	if err != nil {
+		return 0, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	defer iter.Close()
+	last := 0
+	_ = iter.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().String()
+		if !strings.HasPrefix(name, _mergeRequestRefPrefix) {
+			return nil
+		}
+		if iid, err := strconv.Atoi(strings.TrimPrefix(name, _mergeRequestRefPrefix)); err == nil && iid > last {
+			last = iid
+		}
+		return nil
+	})
+	return last + 1, nil
+}
+
+// saveMergeRequest stores the merge request and points its reference to it
+func (r *repository) saveMergeRequest(mr *gitlab.MergeRequest) error {
+	content, err := json.Marshal(mr)
+	if err != nil {
+		return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	treeHash, err := r.storeTree(map[string][]byte{}, "")
+	if err != nil {
+		return err
+	}
+	c, err := r.storeCommit(treeHash, string(content))
+	if err != nil {
+		return err
+	}
+	name := mergeRequestRefName(mr.IID)
+	if err := r.Storer.SetReference(plumbing.NewHashReference(name, c.Hash)); err != nil {
+		return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	r.changed[name] = struct{}{}
+	return nil
+}
+
+// merge merges files of the source commit into the target commit with a three-way merge,
+// a file is merged only if it is changed by one side since the merge base, or changed by both sides identically.
+// It returns ErrGitRepoMergeConflict if a file is changed by both sides differently.
+func (r *repository) merge(source, target *object.Commit) (map[string][]byte, error) {
+	bases, err := source.MergeBase(target)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	var base *object.Commit
+	if len(bases) > 0 {
+		base = bases[0]
+	}
+	baseFiles, err := r.files(base)
+	if err != nil {
+		return nil, err
+	}
+	sourceFiles, err := r.files(source)
+	if err != nil {
+		return nil, err
+	}
+	targetFiles, err := r.files(target)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := map[string]struct{}{}
+	for _, files := range []map[string][]byte{baseFiles, sourceFiles, targetFiles} {
+		for name := range files {
+			paths[name] = struct{}{}
+		}
+	}
+	merged := map[string][]byte{}
+	conflicts := make([]string, 0)
+	for name := range paths {
+		baseContent, inBase := baseFiles[name]
+		sourceContent, inSource := sourceFiles[name]
+		targetContent, inTarget := targetFiles[name]
+		sameAs := func(content1 []byte, in1 bool, content2 []byte, in2 bool) bool {
+			return in1 == in2 && bytes.Equal(content1, content2)
+		}
+		switch {
+		case sameAs(sourceContent, inSource, targetContent, inTarget),
+			sameAs(sourceContent, inSource, baseContent, inBase):
+			// not changed by the source, or changed by both sides identically
+			if inTarget {
+				merged[name] = targetContent
+			}
+		case sameAs(targetContent, inTarget, baseContent, inBase):
+			// only changed by the source
+			if inSource {
+				merged[name] = sourceContent
+			}
+		default:
+			conflicts = append(conflicts, name)
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, perror.Wrapf(herrors.ErrGitRepoMergeConflict,
+			"conflicts in %s", strings.Join(conflicts, ", "))
+	}
+	return merged, nil
+}
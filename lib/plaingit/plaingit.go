@@ -0,0 +1,1065 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plaingit implements gitlab.Interface on top of plain git repositories with go-git,
+// so that the gitops repos of horizon can be stored in any git server or in local bare repositories.
+//
+// Projects are mapped to repositories named "<root>/<group full path>/<project path>.git".
+// For local roots, groups are directories and projects are created as bare repositories.
+// For remote roots, groups are virtual and projects are created by pushing to them,
+// so the git server should support push-to-create.
+// Merge requests are stored as references of the repository, and accepted by three-way merges of files.
+// IDs of groups and projects are hashes of their full paths.
+package plaingit
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	_readmeFile    = "README.md"
+	_authUsername  = "horizon"
+	_mergedState   = "merged"
+	_closedState   = "closed"
+	_transferSpecs = "+refs/*:refs/*"
+)
+
+var _ gitlablib.Interface = (*helper)(nil)
+
+type helper struct {
+	// url is the root url of repositories, it can be a local directory or a remote base url
+	url           string
+	remote        bool
+	auth          transport.AuthMethod
+	defaultBranch string
+
+	// lock guards the repositories, writing operations are serialized
+	lock sync.RWMutex
+	// ids caches the full paths of groups and projects by their IDs
+	ids sync.Map
+}
+
+// New an instance of plain git, the url can be a local directory, a file:// url
+// or the base url of a git server such as https://gitea.example.com.
+func New(token, url, defaultBranch string) (gitlablib.Interface, error) {
+	h := &helper{
+		defaultBranch: defaultBranch,
+	}
+	if h.defaultBranch == "" {
+		h.defaultBranch = "master"
+	}
+	switch {
+	case strings.HasPrefix(url, "http://"), strings.HasPrefix(url, "https://"),
+		strings.HasPrefix(url, "ssh://"), strings.HasPrefix(url, "git@"):
+		h.remote = true
+		h.url = strings.TrimSuffix(url, "/")
+		if token != "" {
+			h.auth = &githttp.BasicAuth{Username: _authUsername, Password: token}
+		}
+	default:
+		root, err := filepath.Abs(strings.TrimPrefix(url, "file://"))
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		if err := os.MkdirAll(root, 0755); err != nil {
+			return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+		h.url = root
+	}
+	return h, nil
+}
+
+func (h *helper) GetGroup(ctx context.Context, gid interface{}) (_ *gitlab.Group, err error) {
+	const op = "plain git: get group"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	fullPath, err := h.fullPath(gid)
+	if err != nil {
+		return nil, err
+	}
+	if !h.remote {
+		info, err := os.Stat(h.localPath(fullPath))
+		if err != nil || !info.IsDir() || strings.HasSuffix(fullPath, ".git") {
+			return nil, herrors.NewErrNotFound(herrors.GitRepoResource,
+				fmt.Sprintf("group %s not found", fullPath))
+		}
+	}
+	return h.toGroup(fullPath), nil
+}
+
+func (h *helper) ListGroupProjects(ctx context.Context, gid interface{},
+	page, perPage int) (_ []*gitlab.Project, err error) {
+	const op = "plain git: list group projects"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if page < 1 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "page cannot be less 1")
+	}
+	if perPage < 1 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "perPage cannot be less 1")
+	}
+	if h.remote {
+		return nil, perror.Wrap(herrors.ErrNotSupport, "cannot list projects of remote git repositories")
+	}
+	group, err := h.GetGroup(ctx, gid)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ioutil.ReadDir(h.localPath(group.FullPath))
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	names := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasSuffix(entry.Name(), ".git") {
+			names = append(names, strings.TrimSuffix(entry.Name(), ".git"))
+		}
+	}
+
+	projects := make([]*gitlab.Project, 0)
+	for _, name := range paginate(names, page, perPage) {
+		projects = append(projects, h.toProject(path.Join(group.FullPath, name), h.defaultBranch))
+	}
+	return projects, nil
+}
+
+func (h *helper) CreateGroup(ctx context.Context, name, groupPath string,
+	parentID *int, visibility string) (_ *gitlab.Group, err error) {
+	const op = "plain git: create group"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	fullPath := groupPath
+	if parentID != nil {
+		parentPath, err := h.fullPath(*parentID)
+		if err != nil {
+			return nil, err
+		}
+		fullPath = path.Join(parentPath, groupPath)
+	}
+	if !h.remote {
+		if err := os.MkdirAll(h.localPath(fullPath), 0755); err != nil {
+			return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+	}
+	group := h.toGroup(fullPath)
+	group.Name = name
+	return group, nil
+}
+
+func (h *helper) DeleteGroup(ctx context.Context, gid interface{}) (err error) {
+	const op = "plain git: delete group"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	fullPath, err := h.fullPath(gid)
+	if err != nil {
+		return err
+	}
+	// groups of remote repositories are virtual, there is nothing to delete
+	if h.remote {
+		return nil
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if err := os.RemoveAll(h.localPath(fullPath)); err != nil {
+		return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return nil
+}
+
+func (h *helper) GetProject(ctx context.Context, pid interface{}) (_ *gitlab.Project, err error) {
+	const op = "plain git: get project"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	fullPath, err := h.fullPath(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	r, err := h.open(ctx, fullPath)
+	if err != nil {
+		return nil, err
+	}
+	defaultBranch := h.defaultBranch
+	if !h.remote {
+		if head, err := r.Storer.Reference(plumbing.HEAD); err == nil && head.Type() == plumbing.SymbolicReference {
+			defaultBranch = head.Target().Short()
+		}
+	}
+	return h.toProject(fullPath, defaultBranch), nil
+}
+
+func (h *helper) CreateProject(ctx context.Context, name string,
+	groupID int, visibility string) (_ *gitlab.Project, err error) {
+	const op = "plain git: create project"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	groupPath, err := h.fullPath(groupID)
+	if err != nil {
+		return nil, err
+	}
+	fullPath := path.Join(groupPath, name)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if _, err := h.open(ctx, fullPath); err == nil {
+		return nil, perror.Wrapf(herrors.ErrNameConflict, "project %s has already been taken", fullPath)
+	} else if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+		return nil, err
+	}
+
+	r, err := h.init(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	// initialize with readme, just like gitlab does
+	commit, err := r.commitFiles(map[string][]byte{
+		_readmeFile: []byte(fmt.Sprintf("# %s\n", name)),
+	}, "Initial commit")
+	if err != nil {
+		return nil, err
+	}
+	if err := r.setBranch(h.defaultBranch, commit.Hash); err != nil {
+		return nil, err
+	}
+	if err := r.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD,
+		plumbing.NewBranchReferenceName(h.defaultBranch))); err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	if err := h.save(ctx, r); err != nil {
+		return nil, err
+	}
+	return h.toProject(fullPath, h.defaultBranch), nil
+}
+
+func (h *helper) DeleteProject(ctx context.Context, pid interface{}) (err error) {
+	const op = "plain git: delete project"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	fullPath, err := h.fullPath(pid)
+	if err != nil {
+		return err
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if !h.remote {
+		if _, err := h.open(ctx, fullPath); err != nil {
+			return err
+		}
+		if err := os.RemoveAll(h.localPath(fullPath) + ".git"); err != nil {
+			return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+		return nil
+	}
+
+	// a remote repository cannot be removed by git, so remove all of its references instead,
+	// then it will be regarded as not found
+	r, err := h.open(ctx, fullPath)
+	if err != nil {
+		return err
+	}
+	if err := r.removeAllReferences(); err != nil {
+		return err
+	}
+	return h.save(ctx, r)
+}
+
+func (h *helper) GetCommit(ctx context.Context, pid interface{}, commit string) (_ *gitlab.Commit, err error) {
+	const op = "plain git: get commit"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	r, err := h.openForRead(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	defer h.lock.RUnlock()
+
+	c, err := r.resolve(commit)
+	if err != nil {
+		return nil, err
+	}
+	return toGitlabCommit(c), nil
+}
+
+func (h *helper) GetBranch(ctx context.Context, pid interface{}, branch string) (_ *gitlab.Branch, err error) {
+	const op = "plain git: get branch"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	r, err := h.openForRead(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	defer h.lock.RUnlock()
+
+	c, err := r.branchCommit(branch)
+	if err != nil {
+		return nil, err
+	}
+	return &gitlab.Branch{
+		Name:    branch,
+		Commit:  toGitlabCommit(c),
+		Default: branch == h.defaultBranch,
+	}, nil
+}
+
+func (h *helper) GetTag(ctx context.Context, pid interface{}, tag string) (_ *gitlab.Tag, err error) {
+	const op = "plain git: get tag"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	r, err := h.openForRead(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	defer h.lock.RUnlock()
+
+	return r.tag(tag)
+}
+
+func (h *helper) CreateBranch(ctx context.Context, pid interface{},
+	branch, fromRef string) (_ *gitlab.Branch, err error) {
+	const op = "plain git: create branch"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	r, err := h.openForWrite(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	defer h.lock.Unlock()
+
+	if _, err := r.branchCommit(branch); err == nil {
+		return nil, perror.Wrapf(herrors.ErrNameConflict, "branch %s already exists", branch)
+	}
+	c, err := r.resolve(fromRef)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.setBranch(branch, c.Hash); err != nil {
+		return nil, err
+	}
+	if err := h.save(ctx, r); err != nil {
+		return nil, err
+	}
+	return &gitlab.Branch{
+		Name:   branch,
+		Commit: toGitlabCommit(c),
+	}, nil
+}
+
+func (h *helper) DeleteBranch(ctx context.Context, pid interface{}, branch string) (err error) {
+	const op = "plain git: delete branch"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	r, err := h.openForWrite(ctx, pid)
+	if err != nil {
+		return err
+	}
+	defer h.lock.Unlock()
+
+	if _, err := r.branchCommit(branch); err != nil {
+		return err
+	}
+	if err := r.removeReference(plumbing.NewBranchReferenceName(branch)); err != nil {
+		return err
+	}
+	return h.save(ctx, r)
+}
+
+func (h *helper) ListBranch(ctx context.Context, pid interface{},
+	listBranchOptions *gitlab.ListBranchesOptions) (_ []*gitlab.Branch, err error) {
+	const op = "plain git: list branch"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	r, err := h.openForRead(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	defer h.lock.RUnlock()
+
+	search, page, perPage := "", 0, 0
+	if listBranchOptions != nil {
+		page, perPage = listBranchOptions.Page, listBranchOptions.PerPage
+		if listBranchOptions.Search != nil {
+			search = *listBranchOptions.Search
+		}
+	}
+	names, err := r.referenceNames(func(name plumbing.ReferenceName) bool { return name.IsBranch() }, search)
+	if err != nil {
+		return nil, err
+	}
+	branches := make([]*gitlab.Branch, 0)
+	for _, name := range paginate(names, page, perPage) {
+		c, err := r.branchCommit(name)
+		if err != nil {
+			return nil, err
+		}
+		branches = append(branches, &gitlab.Branch{
+			Name:    name,
+			Commit:  toGitlabCommit(c),
+			Default: name == h.defaultBranch,
+		})
+	}
+	return branches, nil
+}
+
+func (h *helper) ListTag(ctx context.Context, pid interface{},
+	listTagOptions *gitlab.ListTagsOptions) (_ []*gitlab.Tag, err error) {
+	const op = "plain git: list tag"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	r, err := h.openForRead(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	defer h.lock.RUnlock()
+
+	search, page, perPage := "", 0, 0
+	if listTagOptions != nil {
+		page, perPage = listTagOptions.Page, listTagOptions.PerPage
+		if listTagOptions.Search != nil {
+			search = *listTagOptions.Search
+		}
+	}
+	names, err := r.referenceNames(func(name plumbing.ReferenceName) bool { return name.IsTag() }, search)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]*gitlab.Tag, 0)
+	for _, name := range paginate(names, page, perPage) {
+		tag, err := r.tag(name)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func (h *helper) CreateMR(ctx context.Context, pid interface{},
+	source, target, title string) (_ *gitlab.MergeRequest, err error) {
+	const op = "plain git: create mr"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	r, err := h.openForWrite(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	defer h.lock.Unlock()
+
+	for _, branch := range []string{source, target} {
+		if _, err := r.branchCommit(branch); err != nil {
+			return nil, err
+		}
+	}
+	iid, err := r.nextMergeRequestIID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	mr := &gitlab.MergeRequest{
+		ID:              iid,
+		IID:             iid,
+		ProjectID:       h.id(r.path),
+		SourceProjectID: h.id(r.path),
+		TargetProjectID: h.id(r.path),
+		SourceBranch:    source,
+		TargetBranch:    target,
+		Title:           title,
+		State:           common.GitopsMergeRequestStateOpen,
+		CreatedAt:       &now,
+	}
+	if err := r.saveMergeRequest(mr); err != nil {
+		return nil, err
+	}
+	if err := h.save(ctx, r); err != nil {
+		return nil, err
+	}
+	return mr, nil
+}
+
+func (h *helper) ListMRs(ctx context.Context, pid interface{},
+	source, target, state string) (_ []*gitlab.MergeRequest, err error) {
+	const op = "plain git: list mrs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	r, err := h.openForRead(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	defer h.lock.RUnlock()
+
+	all, err := r.mergeRequests()
+	if err != nil {
+		return nil, err
+	}
+	mrs := make([]*gitlab.MergeRequest, 0)
+	for _, mr := range all {
+		if (source == "" || mr.SourceBranch == source) &&
+			(target == "" || mr.TargetBranch == target) &&
+			(state == "" || state == "all" || mr.State == state) {
+			mrs = append(mrs, mr)
+		}
+	}
+	return mrs, nil
+}
+
+func (h *helper) CloseMR(ctx context.Context, pid interface{}, mrID int) (mr *gitlab.MergeRequest, err error) {
+	const op = "plain git: close mr"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	r, err := h.openForWrite(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	defer h.lock.Unlock()
+
+	mr, err = r.mergeRequest(mrID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	mr.State = _closedState
+	mr.ClosedAt = &now
+	if err := r.saveMergeRequest(mr); err != nil {
+		return nil, err
+	}
+	if err := h.save(ctx, r); err != nil {
+		return nil, err
+	}
+	return mr, nil
+}
+
+func (h *helper) AcceptMR(ctx context.Context, pid interface{}, mrID int,
+	mergeCommitMsg *string, shouldRemoveSourceBranch *bool) (_ *gitlab.MergeRequest, err error) {
+	const op = "plain git: accept mr"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	r, err := h.openForWrite(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	defer h.lock.Unlock()
+
+	mr, err := r.mergeRequest(mrID)
+	if err != nil {
+		return nil, err
+	}
+	if mr.State != common.GitopsMergeRequestStateOpen {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "merge request !%d is %s", mrID, mr.State)
+	}
+	source, err := r.branchCommit(mr.SourceBranch)
+	if err != nil {
+		return nil, err
+	}
+	target, err := r.branchCommit(mr.TargetBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	mergeCommit := target
+	sourceMerged, err := source.IsAncestor(target)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	if source.Hash != target.Hash && !sourceMerged {
+		files, err := r.merge(source, target)
+		if err != nil {
+			return nil, perror.WithMessagef(err, "cannot merge %s into %s", mr.SourceBranch, mr.TargetBranch)
+		}
+		message := fmt.Sprintf("Merge branch '%s' into '%s'\n\n%s", mr.SourceBranch, mr.TargetBranch, mr.Title)
+		if mergeCommitMsg != nil {
+			message = *mergeCommitMsg
+		}
+		// the merge commit has parents of the target and the source, so that the source branch
+		// can keep going on and be merged again with the merge commit as the merge base
+		mergeCommit, err = r.commitFiles(files, message, target.Hash, source.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.setBranch(mr.TargetBranch, mergeCommit.Hash); err != nil {
+			return nil, err
+		}
+	}
+	if shouldRemoveSourceBranch != nil && *shouldRemoveSourceBranch {
+		if err := r.removeReference(plumbing.NewBranchReferenceName(mr.SourceBranch)); err != nil {
+			return nil, err
+		}
+	}
+	now := time.Now()
+	mr.State = _mergedState
+	mr.MergedAt = &now
+	mr.SHA = source.Hash.String()
+	mr.MergeCommitSHA = mergeCommit.Hash.String()
+	if err := r.saveMergeRequest(mr); err != nil {
+		return nil, err
+	}
+	if err := h.save(ctx, r); err != nil {
+		return nil, err
+	}
+	return mr, nil
+}
+
+func (h *helper) WriteFiles(ctx context.Context, pid interface{}, branch, commitMsg string,
+	startBranch *string, actions []gitlablib.CommitAction) (_ *gitlab.Commit, err error) {
+	const op = "plain git: write files"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	r, err := h.openForWrite(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	defer h.lock.Unlock()
+
+	parent, err := r.branchCommit(branch)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok || startBranch == nil {
+			return nil, err
+		}
+		if parent, err = r.branchCommit(*startBranch); err != nil {
+			return nil, err
+		}
+	}
+	files, err := r.files(parent)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyActions(files, actions); err != nil {
+		return nil, err
+	}
+	commit, err := r.commitFiles(files, commitMsg, parent.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.setBranch(branch, commit.Hash); err != nil {
+		return nil, err
+	}
+	if err := h.save(ctx, r); err != nil {
+		return nil, err
+	}
+	return toGitlabCommit(commit), nil
+}
+
+func (h *helper) GetFile(ctx context.Context, pid interface{}, ref, filepath string) (_ []byte, err error) {
+	const op = "plain git: get file"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	r, err := h.openForRead(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	defer h.lock.RUnlock()
+
+	c, err := r.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	file, err := c.File(strings.TrimPrefix(filepath, "/"))
+	if err != nil {
+		if err == object.ErrFileNotFound {
+			return nil, herrors.NewErrNotFound(herrors.GitRepoResource,
+				fmt.Sprintf("file %s not found in %s at %s", filepath, r.path, ref))
+		}
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return []byte(content), nil
+}
+
+func (h *helper) TransferProject(ctx context.Context, pid interface{}, gid interface{}) (err error) {
+	const op = "plain git: transfer project"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	fullPath, err := h.fullPath(pid)
+	if err != nil {
+		return err
+	}
+	groupPath, err := h.fullPath(gid)
+	if err != nil {
+		return err
+	}
+	return h.move(ctx, fullPath, path.Join(groupPath, path.Base(fullPath)))
+}
+
+func (h *helper) EditNameAndPathForProject(ctx context.Context, pid interface{}, newName, newPath *string) (err error) {
+	const op = "plain git: edit name and path for project"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	fullPath, err := h.fullPath(pid)
+	if err != nil {
+		return err
+	}
+	// the name of a plain git repository is its path
+	name := newPath
+	if name == nil {
+		name = newName
+	}
+	if name == nil || *name == path.Base(fullPath) {
+		return nil
+	}
+	return h.move(ctx, fullPath, path.Join(path.Dir(fullPath), *name))
+}
+
+func (h *helper) Compare(ctx context.Context, pid interface{}, from, to string,
+	straight *bool) (_ *gitlab.Compare, err error) {
+	const op = "plain git: compare branchs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	r, err := h.openForRead(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	defer h.lock.RUnlock()
+
+	fromCommit, err := r.resolve(from)
+	if err != nil {
+		return nil, err
+	}
+	toCommit, err := r.resolve(to)
+	if err != nil {
+		return nil, err
+	}
+
+	// compare from the merge base by default, just like gitlab does
+	base := fromCommit
+	if straight == nil || !*straight {
+		bases, err := fromCommit.MergeBase(toCommit)
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+		if len(bases) > 0 {
+			base = bases[0]
+		}
+	}
+
+	diffs, err := diff(base, toCommit)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := r.commitsBetween(base, toCommit)
+	if err != nil {
+		return nil, err
+	}
+	return &gitlab.Compare{
+		Commit:         toGitlabCommit(toCommit),
+		Commits:        commits,
+		Diffs:          diffs,
+		CompareSameRef: fromCommit.Hash == toCommit.Hash,
+	}, nil
+}
+
+func (h *helper) GetRepositoryArchive(ctx context.Context, pid interface{}, sha string) ([]byte, error) {
+	const op = "plain git: get repository archive"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	r, err := h.openForRead(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	defer h.lock.RUnlock()
+
+	c, err := r.resolve(sha)
+	if err != nil {
+		return nil, err
+	}
+	files, err := r.files(c)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	prefix := fmt.Sprintf("%s-%s", path.Base(r.path), c.Hash.String())
+	for _, name := range names {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    path.Join(prefix, name),
+			Mode:    0644,
+			Size:    int64(len(files[name])),
+			ModTime: c.Committer.When,
+		}); err != nil {
+			return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	if err := gw.Close(); err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return buf.Bytes(), nil
+}
+
+// GetHTTPURL implements Interface
+func (h *helper) GetHTTPURL(ctx context.Context) string {
+	return h.url
+}
+
+func (h *helper) GetCreatedGroup(ctx context.Context, parentID int,
+	parentFullPath string, name string, visibility string) (*gitlab.Group, error) {
+	var group *gitlab.Group
+	group, err := h.GetGroup(ctx, fmt.Sprintf("%v/%v", parentFullPath, name))
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return nil, err
+		}
+		return h.CreateGroup(ctx, name, name, &parentID, visibility)
+	}
+
+	return group, nil
+}
+
+// openForRead opens the project for reading, the read lock is held if no error returned
+func (h *helper) openForRead(ctx context.Context, pid interface{}) (*repository, error) {
+	fullPath, err := h.fullPath(pid)
+	if err != nil {
+		return nil, err
+	}
+	h.lock.RLock()
+	r, err := h.open(ctx, fullPath)
+	if err != nil {
+		h.lock.RUnlock()
+		return nil, err
+	}
+	return r, nil
+}
+
+// openForWrite opens the project for writing, the write lock is held if no error returned
+func (h *helper) openForWrite(ctx context.Context, pid interface{}) (*repository, error) {
+	fullPath, err := h.fullPath(pid)
+	if err != nil {
+		return nil, err
+	}
+	h.lock.Lock()
+	r, err := h.open(ctx, fullPath)
+	if err != nil {
+		h.lock.Unlock()
+		return nil, err
+	}
+	return r, nil
+}
+
+// move moves a project to another path, merge requests are moved along with the references
+func (h *helper) move(ctx context.Context, fullPath, newFullPath string) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if _, err := h.open(ctx, newFullPath); err == nil {
+		return perror.Wrapf(herrors.ErrNameConflict, "project %s has already been taken", newFullPath)
+	}
+	r, err := h.open(ctx, fullPath)
+	if err != nil {
+		return err
+	}
+	if !h.remote {
+		newLocalPath := h.localPath(newFullPath) + ".git"
+		if err := os.MkdirAll(filepath.Dir(newLocalPath), 0755); err != nil {
+			return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+		if err := os.Rename(h.localPath(fullPath)+".git", newLocalPath); err != nil {
+			return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+	} else {
+		const target = "target"
+		if _, err := r.CreateRemote(&config.RemoteConfig{
+			Name: target,
+			URLs: []string{h.repoURL(newFullPath)},
+		}); err != nil {
+			return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+		if err := r.PushContext(ctx, &git.PushOptions{
+			RemoteName: target,
+			RefSpecs:   []config.RefSpec{_transferSpecs},
+			Auth:       h.auth,
+		}); err != nil && err != git.NoErrAlreadyUpToDate {
+			return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+		if err := r.removeAllReferences(); err != nil {
+			return err
+		}
+		if err := h.save(ctx, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fullPath returns the full path of a group or project, the id can be its ID or full path
+func (h *helper) fullPath(id interface{}) (string, error) {
+	switch v := id.(type) {
+	case int:
+		if fullPath, ok := h.ids.Load(v); ok {
+			return fullPath.(string), nil
+		}
+		// the cache is empty after restarting, derive the IDs from local directories again
+		if err := h.loadIDs(); err != nil {
+			return "", err
+		}
+		if fullPath, ok := h.ids.Load(v); ok {
+			return fullPath.(string), nil
+		}
+		return "", herrors.NewErrNotFound(herrors.GitRepoResource, fmt.Sprintf("id %d not found", v))
+	case string:
+		fullPath := strings.Trim(v, "/")
+		if fullPath == "" || strings.Contains(fullPath, "..") {
+			return "", perror.Wrapf(herrors.ErrParamInvalid, "path %s is invalid", v)
+		}
+		return fullPath, nil
+	default:
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "id %v is invalid", id)
+	}
+}
+
+// id generates a stable ID for the full path, and remembers it to look up the full path by ID later
+func (h *helper) id(fullPath string) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(fullPath))
+	id := int(hash.Sum32() & 0x7fffffff)
+	h.ids.Store(id, fullPath)
+	return id
+}
+
+// loadIDs walks the local directories and caches the IDs of all groups and projects.
+// Groups of remote repositories are virtual, so their IDs are only known after returned by this helper.
+func (h *helper) loadIDs() error {
+	if h.remote {
+		return nil
+	}
+	err := filepath.Walk(h.url, func(localPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() || localPath == h.url {
+			return nil
+		}
+		rel, err := filepath.Rel(h.url, localPath)
+		if err != nil {
+			return err
+		}
+		fullPath := filepath.ToSlash(rel)
+		if strings.HasSuffix(fullPath, ".git") {
+			h.id(strings.TrimSuffix(fullPath, ".git"))
+			return filepath.SkipDir
+		}
+		h.id(fullPath)
+		return nil
+	})
+	if err != nil {
+		return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return nil
+}
+
+func (h *helper) localPath(fullPath string) string {
+	return filepath.Join(h.url, filepath.FromSlash(fullPath))
+}
+
+func (h *helper) repoURL(fullPath string) string {
+	if !h.remote {
+		return h.localPath(fullPath) + ".git"
+	}
+	return fmt.Sprintf("%s/%s.git", h.url, fullPath)
+}
+
+func (h *helper) toGroup(fullPath string) *gitlab.Group {
+	group := &gitlab.Group{
+		ID:       h.id(fullPath),
+		Name:     path.Base(fullPath),
+		Path:     path.Base(fullPath),
+		FullName: fullPath,
+		FullPath: fullPath,
+		WebURL:   fmt.Sprintf("%s/%s", h.url, fullPath),
+	}
+	if parent := path.Dir(fullPath); parent != "." {
+		group.ParentID = h.id(parent)
+	}
+	return group
+}
+
+func (h *helper) toProject(fullPath, defaultBranch string) *gitlab.Project {
+	namespace := path.Dir(fullPath)
+	return &gitlab.Project{
+		ID:                h.id(fullPath),
+		Name:              path.Base(fullPath),
+		NameWithNamespace: fullPath,
+		Path:              path.Base(fullPath),
+		PathWithNamespace: fullPath,
+		DefaultBranch:     defaultBranch,
+		HTTPURLToRepo:     h.repoURL(fullPath),
+		WebURL:            fmt.Sprintf("%s/%s", h.url, fullPath),
+		Namespace: &gitlab.ProjectNamespace{
+			ID:       h.id(namespace),
+			Name:     path.Base(namespace),
+			Path:     path.Base(namespace),
+			Kind:     "group",
+			FullPath: namespace,
+		},
+	}
+}
+
+func paginate(names []string, page, perPage int) []string {
+	if perPage <= 0 {
+		return names
+	}
+	if page < 1 {
+		page = 1
+	}
+	start := (page - 1) * perPage
+	if start >= len(names) {
+		return []string{}
+	}
+	end := start + perPage
+	if end > len(names) {
+		end = len(names)
+	}
+	return names[start:end]
+}
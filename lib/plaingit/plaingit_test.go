@@ -0,0 +1,292 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plaingit
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	defaultBranch = "master"
+	gitopsBranch  = "gitops"
+)
+
+func isNotFound(err error) bool {
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	return ok
+}
+
+func TestGroupAndProject(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plaingit")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	ctx := context.Background()
+	g, err := New("", dir, defaultBranch)
+	assert.Nil(t, err)
+
+	_, err = g.GetGroup(ctx, "horizon")
+	assert.True(t, isNotFound(err))
+
+	root, err := g.CreateGroup(ctx, "horizon", "horizon", nil, "private")
+	assert.Nil(t, err)
+	group, err := g.GetCreatedGroup(ctx, root.ID, root.FullPath, "clusters", "private")
+	assert.Nil(t, err)
+	assert.Equal(t, "horizon/clusters", group.FullPath)
+	assert.Equal(t, root.ID, group.ParentID)
+
+	project, err := g.CreateProject(ctx, "demo", group.ID, "private")
+	assert.Nil(t, err)
+	assert.Equal(t, "horizon/clusters/demo", project.PathWithNamespace)
+	_, err = g.CreateProject(ctx, "demo", group.ID, "private")
+	assert.NotNil(t, err)
+
+	project, err = g.GetProject(ctx, "horizon/clusters/demo")
+	assert.Nil(t, err)
+	assert.Equal(t, defaultBranch, project.DefaultBranch)
+
+	projects, err := g.ListGroupProjects(ctx, group.ID, 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(projects))
+
+	recycling, err := g.CreateGroup(ctx, "recycling", "recycling", &root.ID, "private")
+	assert.Nil(t, err)
+	assert.Nil(t, g.TransferProject(ctx, project.ID, recycling.FullPath))
+	_, err = g.GetProject(ctx, "horizon/clusters/demo")
+	assert.True(t, isNotFound(err))
+
+	newPath := "demo-recycled"
+	assert.Nil(t, g.EditNameAndPathForProject(ctx, "horizon/recycling/demo", &newPath, &newPath))
+	project, err = g.GetProject(ctx, "horizon/recycling/demo-recycled")
+	assert.Nil(t, err)
+
+	assert.Nil(t, g.DeleteProject(ctx, project.ID))
+	_, err = g.GetProject(ctx, project.ID)
+	assert.True(t, isNotFound(err))
+
+	assert.Nil(t, g.DeleteGroup(ctx, root.ID))
+	_, err = g.GetGroup(ctx, "horizon")
+	assert.True(t, isNotFound(err))
+}
+
+func TestFilesAndMergeRequests(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plaingit")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	ctx := context.Background()
+	g, err := New("", "file://"+dir, defaultBranch)
+	assert.Nil(t, err)
+	group, err := g.CreateGroup(ctx, "app", "app", nil, "private")
+	assert.Nil(t, err)
+	project, err := g.CreateProject(ctx, "cluster", group.ID, "private")
+	assert.Nil(t, err)
+	pid := project.PathWithNamespace
+
+	_, err = g.CreateBranch(ctx, pid, gitopsBranch, defaultBranch)
+	assert.Nil(t, err)
+	first, err := g.WriteFiles(ctx, pid, gitopsBranch, "create files", nil, []gitlablib.CommitAction{
+		{Action: gitlablib.FileCreate, FilePath: "application.yaml", Content: "replicas: 1\n"},
+		{Action: gitlablib.FileCreate, FilePath: "chart/Chart.yaml", Content: "name: demo\n"},
+	})
+	assert.Nil(t, err)
+
+	// gitlab rejects creating an existing file and updating a missing file
+	_, err = g.WriteFiles(ctx, pid, gitopsBranch, "create again", nil, []gitlablib.CommitAction{
+		{Action: gitlablib.FileCreate, FilePath: "application.yaml", Content: "replicas: 2\n"},
+	})
+	assert.NotNil(t, err)
+	_, err = g.WriteFiles(ctx, pid, gitopsBranch, "update missing", nil, []gitlablib.CommitAction{
+		{Action: gitlablib.FileUpdate, FilePath: "missing.yaml", Content: "a: b\n"},
+	})
+	assert.NotNil(t, err)
+
+	content, err := g.GetFile(ctx, pid, gitopsBranch, "chart/Chart.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, "name: demo\n", string(content))
+	_, err = g.GetFile(ctx, pid, defaultBranch, "application.yaml")
+	assert.True(t, isNotFound(err))
+
+	compare, err := g.Compare(ctx, pid, defaultBranch, gitopsBranch, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(compare.Diffs))
+	assert.Equal(t, 1, len(compare.Commits))
+	for _, diff := range compare.Diffs {
+		assert.True(t, diff.NewFile)
+	}
+
+	mr, err := g.CreateMR(ctx, pid, gitopsBranch, defaultBranch, "deploy")
+	assert.Nil(t, err)
+	mrs, err := g.ListMRs(ctx, pid, gitopsBranch, defaultBranch, common.GitopsMergeRequestStateOpen)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(mrs))
+	assert.Equal(t, mr.IID, mrs[0].IID)
+	assert.Equal(t, "deploy", mrs[0].Title)
+	removeSourceBranch := false
+	mr, err = g.AcceptMR(ctx, pid, mr.IID, nil, &removeSourceBranch)
+	assert.Nil(t, err)
+	master, err := g.GetBranch(ctx, pid, defaultBranch)
+	assert.Nil(t, err)
+	assert.Equal(t, mr.MergeCommitSHA, master.Commit.ID)
+	assert.Equal(t, 2, len(master.Commit.ParentIDs))
+	compare, err = g.Compare(ctx, pid, defaultBranch, gitopsBranch, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(compare.Diffs))
+
+	// update and then compare straightly with the first commit, like rollback does
+	second, err := g.WriteFiles(ctx, pid, gitopsBranch, "update replicas", nil, []gitlablib.CommitAction{
+		{Action: gitlablib.FileUpdate, FilePath: "application.yaml", Content: "replicas: 2\n"},
+		{Action: gitlablib.FileDelete, FilePath: "chart/Chart.yaml"},
+	})
+	assert.Nil(t, err)
+	straight := true
+	compare, err = g.Compare(ctx, pid, second.ID, first.ShortID, &straight)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(compare.Diffs))
+	for _, diff := range compare.Diffs {
+		switch diff.NewPath {
+		case "application.yaml":
+			assert.False(t, diff.NewFile || diff.DeletedFile || diff.RenamedFile)
+			assert.Contains(t, diff.Diff, "+replicas: 1")
+		case "chart/Chart.yaml":
+			assert.True(t, diff.NewFile)
+		default:
+			t.Fatalf("unexpected diff: %v", diff)
+		}
+	}
+
+	// diverged branches are merged if they change different files
+	_, err = g.WriteFiles(ctx, pid, defaultBranch, "diverge", nil, []gitlablib.CommitAction{
+		{Action: gitlablib.FileCreate, FilePath: "other.yaml", Content: "a: b\n"},
+	})
+	assert.Nil(t, err)
+	mr, err = g.CreateMR(ctx, pid, gitopsBranch, defaultBranch, "deploy")
+	assert.Nil(t, err)
+	_, err = g.AcceptMR(ctx, pid, mr.IID, nil, &removeSourceBranch)
+	assert.Nil(t, err)
+	for file, expected := range map[string]string{"application.yaml": "replicas: 2\n", "other.yaml": "a: b\n"} {
+		content, err = g.GetFile(ctx, pid, defaultBranch, file)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, string(content))
+	}
+	_, err = g.GetFile(ctx, pid, defaultBranch, "chart/Chart.yaml")
+	assert.True(t, isNotFound(err))
+
+	// diverged branches cannot be merged if they change the same file differently
+	for branch, replicas := range map[string]string{defaultBranch: "3", gitopsBranch: "4"} {
+		_, err = g.WriteFiles(ctx, pid, branch, "conflict", nil, []gitlablib.CommitAction{
+			{Action: gitlablib.FileUpdate, FilePath: "application.yaml", Content: "replicas: " + replicas + "\n"},
+		})
+		assert.Nil(t, err)
+	}
+	mr, err = g.CreateMR(ctx, pid, gitopsBranch, defaultBranch, "deploy")
+	assert.Nil(t, err)
+	_, err = g.AcceptMR(ctx, pid, mr.IID, nil, &removeSourceBranch)
+	assert.Equal(t, herrors.ErrGitRepoMergeConflict, perror.Cause(err))
+	mr, err = g.CloseMR(ctx, pid, mr.IID)
+	assert.Nil(t, err)
+	assert.Equal(t, "closed", mr.State)
+
+	branches, err := g.ListBranch(ctx, pid, &gitlab.ListBranchesOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(branches))
+	assert.Nil(t, g.DeleteBranch(ctx, pid, gitopsBranch))
+	_, err = g.GetBranch(ctx, pid, gitopsBranch)
+	assert.True(t, isNotFound(err))
+
+	archive, err := g.GetRepositoryArchive(ctx, pid, defaultBranch)
+	assert.Nil(t, err)
+	gr, err := gzip.NewReader(bytes.NewReader(archive))
+	assert.Nil(t, err)
+	tr := tar.NewReader(gr)
+	names := make([]string, 0)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		names = append(names, header.Name)
+	}
+	assert.Equal(t, 3, len(names))
+}
+
+func TestConsecutiveDeploys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plaingit")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	ctx := context.Background()
+	g, err := New("", dir, defaultBranch)
+	assert.Nil(t, err)
+	group, err := g.CreateGroup(ctx, "app", "app", nil, "private")
+	assert.Nil(t, err)
+	project, err := g.CreateProject(ctx, "cluster", group.ID, "private")
+	assert.Nil(t, err)
+	_, err = g.CreateBranch(ctx, project.ID, gitopsBranch, defaultBranch)
+	assert.Nil(t, err)
+
+	// write to gitops and merge it into master, just like every deploy does
+	removeSourceBranch := false
+	for i, action := range []gitlablib.FileAction{gitlablib.FileCreate, gitlablib.FileUpdate, gitlablib.FileUpdate} {
+		content := fmt.Sprintf("replicas: %d\n", i+1)
+		_, err = g.WriteFiles(ctx, project.ID, gitopsBranch, "update", nil, []gitlablib.CommitAction{
+			{Action: action, FilePath: "application.yaml", Content: content},
+		})
+		assert.Nil(t, err)
+		mr, err := g.CreateMR(ctx, project.ID, gitopsBranch, defaultBranch, "deploy")
+		assert.Nil(t, err)
+		assert.Equal(t, i+1, mr.IID)
+		mr, err = g.AcceptMR(ctx, project.ID, mr.IID, nil, &removeSourceBranch)
+		assert.Nil(t, err)
+		assert.Equal(t, _mergedState, mr.State)
+		master, err := g.GetFile(ctx, project.ID, defaultBranch, "application.yaml")
+		assert.Nil(t, err)
+		assert.Equal(t, content, string(master))
+	}
+
+	// merge requests and IDs are kept after restarting
+	g, err = New("", dir, defaultBranch)
+	assert.Nil(t, err)
+	project, err = g.GetProject(ctx, project.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "app/cluster", project.PathWithNamespace)
+	_, err = g.GetGroup(ctx, group.ID)
+	assert.Nil(t, err)
+	mrs, err := g.ListMRs(ctx, project.ID, gitopsBranch, defaultBranch, _mergedState)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(mrs))
+	mr, err := g.CreateMR(ctx, project.ID, gitopsBranch, defaultBranch, "deploy")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, mr.IID)
+	mrs, err = g.ListMRs(ctx, project.ID, gitopsBranch, defaultBranch, common.GitopsMergeRequestStateOpen)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(mrs))
+}
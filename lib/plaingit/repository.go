@@ -0,0 +1,558 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plaingit
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	_remoteName = "origin"
+	_authorName = "horizon"
+	_authorMail = "horizon@horizoncd.github.io"
+)
+
+// repository is a git repository opened for a single operation.
+// For local bare repositories, references are updated in place;
+// for remote repositories, the changed references are pushed by save.
+type repository struct {
+	*git.Repository
+	path    string
+	changed map[plumbing.ReferenceName]struct{}
+}
+
+// open opens the repository with the given full path,
+// returns a HorizonErrNotFound error if the repository does not exist or is empty.
+func (h *helper) open(ctx context.Context, fullPath string) (*repository, error) {
+	if !h.remote {
+		r, err := git.PlainOpen(h.localPath(fullPath) + ".git")
+		if err != nil {
+			if err == git.ErrRepositoryNotExists {
+				return nil, herrors.NewErrNotFound(herrors.GitRepoResource,
+					fmt.Sprintf("project %s not found", fullPath))
+			}
+			return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+		return &repository{Repository: r, path: fullPath, changed: map[plumbing.ReferenceName]struct{}{}}, nil
+	}
+
+	r, err := h.init(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	err = r.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: _remoteName,
+		RefSpecs: []config.RefSpec{
+			"+refs/heads/*:refs/heads/*",
+			"+refs/tags/*:refs/tags/*",
+			config.RefSpec(fmt.Sprintf("+%s*:%s*", _mergeRequestRefPrefix, _mergeRequestRefPrefix)),
+		},
+		Auth: h.auth,
+		Tags: git.NoTags,
+	})
+	switch err {
+	case nil, git.NoErrAlreadyUpToDate:
+		return r, nil
+	case transport.ErrRepositoryNotFound, transport.ErrEmptyRemoteRepository:
+		return nil, herrors.NewErrNotFound(herrors.GitRepoResource,
+			fmt.Sprintf("project %s not found: %v", fullPath, err))
+	default:
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+}
+
+// init creates an empty repository whose changes will be saved to the project with the given full path.
+func (h *helper) init(fullPath string) (*repository, error) {
+	if !h.remote {
+		r, err := git.PlainInit(h.localPath(fullPath)+".git", true)
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+		return &repository{Repository: r, path: fullPath, changed: map[plumbing.ReferenceName]struct{}{}}, nil
+	}
+
+	r, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	if _, err := r.CreateRemote(&config.RemoteConfig{
+		Name: _remoteName,
+		URLs: []string{h.repoURL(fullPath)},
+	}); err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return &repository{Repository: r, path: fullPath, changed: map[plumbing.ReferenceName]struct{}{}}, nil
+}
+
+// save pushes the changed references to the remote, it's a no-op for local repositories.
+func (h *helper) save(ctx context.Context, r *repository) error {
+	if !h.remote || len(r.changed) == 0 {
+		return nil
+	}
+	refSpecs := make([]config.RefSpec, 0, len(r.changed))
+	for name := range r.changed {
+		if _, err := r.Reference(name, false); err == plumbing.ErrReferenceNotFound {
+			refSpecs = append(refSpecs, config.RefSpec(fmt.Sprintf(":%s", name)))
+		} else {
+			refSpecs = append(refSpecs, config.RefSpec(fmt.Sprintf("+%s:%s", name, name)))
+		}
+	}
+	err := r.PushContext(ctx, &git.PushOptions{
+		RemoteName: _remoteName,
+		RefSpecs:   refSpecs,
+		Auth:       h.auth,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	r.changed = map[plumbing.ReferenceName]struct{}{}
+	return nil
+}
+
+func (r *repository) setBranch(branch string, hash plumbing.Hash) error {
+	name := plumbing.NewBranchReferenceName(branch)
+	if err := r.Storer.SetReference(plumbing.NewHashReference(name, hash)); err != nil {
+		return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	r.changed[name] = struct{}{}
+	return nil
+}
+
+func (r *repository) removeReference(name plumbing.ReferenceName) error {
+	if err := r.Storer.RemoveReference(name); err != nil {
+		return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	r.changed[name] = struct{}{}
+	return nil
+}
+
+// branchCommit returns the head commit of the branch
+func (r *repository) branchCommit(branch string) (*object.Commit, error) {
+	ref, err := r.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		if err == plumbing.ErrReferenceNotFound {
+			return nil, herrors.NewErrNotFound(herrors.GitRepoResource,
+				fmt.Sprintf("branch %s not found in %s", branch, r.path))
+		}
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return r.commitObject(ref.Hash())
+}
+
+// resolve returns the commit of a branch, tag or commit ID
+func (r *repository) resolve(ref string) (*object.Commit, error) {
+	for _, name := range []plumbing.ReferenceName{
+		plumbing.NewBranchReferenceName(ref),
+		plumbing.NewTagReferenceName(ref),
+	} {
+		if reference, err := r.Reference(name, true); err == nil {
+			return r.commitObject(reference.Hash())
+		}
+	}
+	if plumbing.IsHash(ref) {
+		return r.commitObject(plumbing.NewHash(ref))
+	}
+	// short commit ID
+	iter, err := r.CommitObjects()
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	defer iter.Close()
+	var found *object.Commit
+	_ = iter.ForEach(func(c *object.Commit) error {
+		if ref != "" && strings.HasPrefix(c.Hash.String(), ref) {
+			found = c
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if found == nil {
+		return nil, herrors.NewErrNotFound(herrors.GitRepoResource,
+			fmt.Sprintf("ref %s not found in %s", ref, r.path))
+	}
+	return found, nil
+}
+
+func (r *repository) commitObject(hash plumbing.Hash) (*object.Commit, error) {
+	c, err := r.CommitObject(hash)
+	if err != nil {
+		if err == plumbing.ErrObjectNotFound {
+			return nil, herrors.NewErrNotFound(herrors.GitRepoResource,
+				fmt.Sprintf("commit %s not found in %s", hash, r.path))
+		}
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return c, nil
+}
+
+// files returns all files of the commit, keyed by file path
+func (r *repository) files(c *object.Commit) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	if c == nil {
+		return files, nil
+	}
+	iter, err := c.Files()
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	defer iter.Close()
+	err = iter.ForEach(func(f *object.File) error {
+		reader, err := f.Reader()
+		if err != nil {
+			return err
+		}
+		defer func() { _ = reader.Close() }()
+		content, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		files[f.Name] = content
+		return nil
+	})
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return files, nil
+}
+
+// applyActions applies the gitlab commit actions to files, it fails like gitlab does
+// when creating an existing file or updating a missing file
+func applyActions(files map[string][]byte, actions []gitlablib.CommitAction) error {
+	for _, action := range actions {
+		filePath := strings.TrimPrefix(action.FilePath, "/")
+		_, exists := files[filePath]
+		switch action.Action {
+		case gitlablib.FileCreate:
+			if exists {
+				return perror.Wrapf(herrors.ErrParamInvalid, "a file with path %s already exists", filePath)
+			}
+			files[filePath] = []byte(action.Content)
+		case gitlablib.FileUpdate:
+			if !exists {
+				return perror.Wrapf(herrors.ErrParamInvalid, "a file with path %s doesn't exist", filePath)
+			}
+			files[filePath] = []byte(action.Content)
+		case gitlablib.FileDelete:
+			if !exists {
+				return perror.Wrapf(herrors.ErrParamInvalid, "a file with path %s doesn't exist", filePath)
+			}
+			delete(files, filePath)
+		case gitlablib.FileMove:
+			previousPath := strings.TrimPrefix(action.PreviousPath, "/")
+			content, ok := files[previousPath]
+			if !ok {
+				return perror.Wrapf(herrors.ErrParamInvalid, "a file with path %s doesn't exist", previousPath)
+			}
+			delete(files, previousPath)
+			if action.Content != "" {
+				content = []byte(action.Content)
+			}
+			files[filePath] = content
+		default:
+			return perror.Wrapf(herrors.ErrParamInvalid, "file action %s is not supported", action.Action)
+		}
+	}
+	return nil
+}
+
+// commitFiles stores files as a new commit with the given parents, and returns the new commit
+func (r *repository) commitFiles(files map[string][]byte, message string,
+	parents ...plumbing.Hash) (*object.Commit, error) {
+	treeHash, err := r.storeTree(files, "")
+	if err != nil {
+		return nil, err
+	}
+	return r.storeCommit(treeHash, message, parents...)
+}
+
+func (r *repository) storeCommit(treeHash plumbing.Hash, message string,
+	parents ...plumbing.Hash) (*object.Commit, error) {
+	signature := object.Signature{Name: _authorName, Email: _authorMail, When: time.Now()}
+	commit := &object.Commit{
+		Author:       signature,
+		Committer:    signature,
+		Message:      message,
+		TreeHash:     treeHash,
+		ParentHashes: parents,
+	}
+	obj := r.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	hash, err := r.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return r.commitObject(hash)
+}
+
+// storeTree stores files under dir as tree objects recursively, and returns the hash of the tree for dir
+func (r *repository) storeTree(files map[string][]byte, dir string) (plumbing.Hash, error) {
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+	subDirs := map[string]struct{}{}
+	tree := &object.Tree{}
+	for name, content := range files {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(name, prefix)
+		if i := strings.Index(rest, "/"); i >= 0 {
+			subDirs[rest[:i]] = struct{}{}
+			continue
+		}
+		obj := r.Storer.NewEncodedObject()
+		obj.SetType(plumbing.BlobObject)
+		w, err := obj.Writer()
+		if err != nil {
+			return plumbing.ZeroHash, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+		if _, err := w.Write(content); err != nil {
+			return plumbing.ZeroHash, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+		if err := w.Close(); err != nil {
+			return plumbing.ZeroHash, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+		hash, err := r.Storer.SetEncodedObject(obj)
+		if err != nil {
+			return plumbing.ZeroHash, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: rest, Mode: filemode.Regular, Hash: hash})
+	}
+	for subDir := range subDirs {
+		hash, err := r.storeTree(files, path.Join(dir, subDir))
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: subDir, Mode: filemode.Dir, Hash: hash})
+	}
+	// git sorts tree entries by name, with directories compared as if they had a trailing slash
+	sortKey := func(e object.TreeEntry) string {
+		if e.Mode == filemode.Dir {
+			return e.Name + "/"
+		}
+		return e.Name
+	}
+	sort.Slice(tree.Entries, func(i, j int) bool {
+		return sortKey(tree.Entries[i]) < sortKey(tree.Entries[j])
+	})
+
+	obj := r.Storer.NewEncodedObject()
+	if err := tree.Encode(obj); err != nil {
+		return plumbing.ZeroHash, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	hash, err := r.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return hash, nil
+}
+
+// diff compares two commits and converts the changes to gitlab diffs
+func diff(from, to *object.Commit) ([]*gitlab.Diff, error) {
+	fromTree, err := from.Tree()
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	toTree, err := to.Tree()
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	changes, err := object.DiffTreeWithOptions(context.Background(), fromTree, toTree,
+		object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+
+	diffs := make([]*gitlab.Diff, 0, len(changes))
+	for _, change := range changes {
+		patch, err := change.Patch()
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+		d := &gitlab.Diff{
+			OldPath:     change.From.Name,
+			NewPath:     change.To.Name,
+			NewFile:     change.From.Name == "",
+			DeletedFile: change.To.Name == "",
+			Diff:        hunks(patch.String()),
+		}
+		if d.NewFile {
+			d.OldPath = d.NewPath
+		} else {
+			d.AMode = change.From.TreeEntry.Mode.String()
+		}
+		if d.DeletedFile {
+			d.NewPath = d.OldPath
+		} else {
+			d.BMode = change.To.TreeEntry.Mode.String()
+		}
+		d.RenamedFile = !d.NewFile && !d.DeletedFile && change.From.Name != change.To.Name
+		diffs = append(diffs, d)
+	}
+	return diffs, nil
+}
+
+// hunks strips the headers of a unified diff, just like the diff field returned by gitlab
+func hunks(patch string) string {
+	if i := strings.Index(patch, "\n@@"); i >= 0 {
+		return patch[i+1:]
+	}
+	return ""
+}
+
+func toGitlabCommit(c *object.Commit) *gitlab.Commit {
+	authoredDate := c.Author.When
+	committedDate := c.Committer.When
+	parentIDs := make([]string, 0, len(c.ParentHashes))
+	for _, parent := range c.ParentHashes {
+		parentIDs = append(parentIDs, parent.String())
+	}
+	id := c.Hash.String()
+	return &gitlab.Commit{
+		ID:             id,
+		ShortID:        id[:8],
+		Title:          strings.SplitN(strings.TrimSpace(c.Message), "\n", 2)[0],
+		AuthorName:     c.Author.Name,
+		AuthorEmail:    c.Author.Email,
+		AuthoredDate:   &authoredDate,
+		CommitterName:  c.Committer.Name,
+		CommitterEmail: c.Committer.Email,
+		CommittedDate:  &committedDate,
+		CreatedAt:      &committedDate,
+		Message:        c.Message,
+		ParentIDs:      parentIDs,
+	}
+}
+
+// removeAllReferences removes all branches, tags and merge requests of the repository
+func (r *repository) removeAllReferences() error {
+	iter, err := r.References()
+	if err != nil {
+		return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	defer iter.Close()
+	refs := make([]plumbing.ReferenceName, 0)
+	_ = iter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Name().IsBranch() || ref.Name().IsTag() ||
+			strings.HasPrefix(ref.Name().String(), _mergeRequestRefPrefix) {
+			refs = append(refs, ref.Name())
+		}
+		return nil
+	})
+	for _, name := range refs {
+		if err := r.removeReference(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// referenceNames returns the sorted short names of references which match the filter and contain search
+func (r *repository) referenceNames(filter func(plumbing.ReferenceName) bool, search string) ([]string, error) {
+	iter, err := r.References()
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	defer iter.Close()
+	names := make([]string, 0)
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		if filter(ref.Name()) && strings.Contains(ref.Name().Short(), search) {
+			names = append(names, ref.Name().Short())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (r *repository) tag(name string) (*gitlab.Tag, error) {
+	ref, err := r.Reference(plumbing.NewTagReferenceName(name), true)
+	if err != nil {
+		if err == plumbing.ErrReferenceNotFound {
+			return nil, herrors.NewErrNotFound(herrors.GitRepoResource,
+				fmt.Sprintf("tag %s not found in %s", name, r.path))
+		}
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	tag := &gitlab.Tag{Name: name}
+	hash := ref.Hash()
+	// annotated tag
+	if tagObject, err := r.TagObject(hash); err == nil {
+		tag.Message = tagObject.Message
+		hash = tagObject.Target
+	}
+	c, err := r.commitObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	tag.Commit = toGitlabCommit(c)
+	return tag, nil
+}
+
+// commitsBetween returns commits reachable from to but not from base, the oldest first
+func (r *repository) commitsBetween(base, to *object.Commit) ([]*gitlab.Commit, error) {
+	commits := make([]*gitlab.Commit, 0)
+	if base.Hash == to.Hash {
+		return commits, nil
+	}
+	iter, err := r.Log(&git.LogOptions{From: to.Hash})
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	defer iter.Close()
+	err = iter.ForEach(func(c *object.Commit) error {
+		if c.Hash == base.Hash {
+			return storer.ErrStop
+		}
+		if reachable, err := c.IsAncestor(base); err != nil {
+			return err
+		} else if !reachable {
+			commits = append(commits, toGitlabCommit(c))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
+	return commits, nil
+}
@@ -124,3 +124,18 @@ func (mr *MockK8sUtilMockRecorder) GetPodContainers(ctx, params interface{}) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPodContainers", reflect.TypeOf((*MockK8sUtil)(nil).GetPodContainers), ctx, params)
 }
+
+// ListAnalysisRuns mocks base method.
+func (m *MockK8sUtil) ListAnalysisRuns(ctx context.Context, params *cd.ListAnalysisRunsParams) ([]cd.AnalysisRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAnalysisRuns", ctx, params)
+	ret0, _ := ret[0].([]cd.AnalysisRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAnalysisRuns indicates an expected call of ListAnalysisRuns.
+func (mr *MockK8sUtilMockRecorder) ListAnalysisRuns(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAnalysisRuns", reflect.TypeOf((*MockK8sUtil)(nil).ListAnalysisRuns), ctx, params)
+}
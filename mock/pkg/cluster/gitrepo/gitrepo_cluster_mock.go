@@ -242,6 +242,21 @@ func (mr *MockClusterGitRepoMockRecorder) GetRestartTime(ctx, application, clust
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRestartTime", reflect.TypeOf((*MockClusterGitRepo)(nil).GetRestartTime), ctx, application, cluster, template)
 }
 
+// GetValueFiles mocks base method.
+func (m *MockClusterGitRepo) GetValueFiles(ctx context.Context, application, cluster, revision string) ([]gitrepo.ClusterValueFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetValueFiles", ctx, application, cluster, revision)
+	ret0, _ := ret[0].([]gitrepo.ClusterValueFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetValueFiles indicates an expected call of GetValueFiles.
+func (mr *MockClusterGitRepoMockRecorder) GetValueFiles(ctx, application, cluster, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValueFiles", reflect.TypeOf((*MockClusterGitRepo)(nil).GetValueFiles), ctx, application, cluster, revision)
+}
+
 // HardDeleteCluster mocks base method.
 func (m *MockClusterGitRepo) HardDeleteCluster(ctx context.Context, application, cluster string) error {
 	m.ctrl.T.Helper()
@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	registry "github.com/horizoncd/horizon/pkg/cluster/registry"
 )
 
 // MockRegistry is a mock of Registry interface.
@@ -47,3 +48,18 @@ func (mr *MockRegistryMockRecorder) DeleteImage(ctx, appName, clusterName interf
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteImage", reflect.TypeOf((*MockRegistry)(nil).DeleteImage), ctx, appName, clusterName)
 }
+
+// ListImages mocks base method.
+func (m *MockRegistry) ListImages(ctx context.Context, appName, clusterName string) ([]*registry.Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListImages", ctx, appName, clusterName)
+	ret0, _ := ret[0].([]*registry.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListImages indicates an expected call of ListImages.
+func (mr *MockRegistryMockRecorder) ListImages(ctx, appName, clusterName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListImages", reflect.TypeOf((*MockRegistry)(nil).ListImages), ctx, appName, clusterName)
+}
@@ -96,6 +96,21 @@ func (mr *MockServiceMockRecorder) ListMember(ctx, resourceType, resourceID inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMember", reflect.TypeOf((*MockService)(nil).ListMember), ctx, resourceType, resourceID)
 }
 
+// ListResourceChain mocks base method.
+func (m *MockService) ListResourceChain(ctx context.Context, resourceType, resourceID string) ([]service.Resource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListResourceChain", ctx, resourceType, resourceID)
+	ret0, _ := ret[0].([]service.Resource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListResourceChain indicates an expected call of ListResourceChain.
+func (mr *MockServiceMockRecorder) ListResourceChain(ctx, resourceType, resourceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListResourceChain", reflect.TypeOf((*MockService)(nil).ListResourceChain), ctx, resourceType, resourceID)
+}
+
 // RemoveMember mocks base method.
 func (m *MockService) RemoveMember(ctx context.Context, memberID uint) error {
 	m.ctrl.T.Helper()
@@ -7,9 +7,11 @@ package mock_manager
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	common "github.com/horizoncd/horizon/core/common"
+	q "github.com/horizoncd/horizon/lib/q"
 	models "github.com/horizoncd/horizon/pkg/pr/models"
 )
 
@@ -66,6 +68,35 @@ func (mr *MockCheckManagerMockRecorder) CreateCheckRun(ctx, checkRun interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCheckRun", reflect.TypeOf((*MockCheckManager)(nil).CreateCheckRun), ctx, checkRun)
 }
 
+// Delete mocks base method.
+func (m *MockCheckManager) Delete(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCheckManagerMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCheckManager)(nil).Delete), ctx, id)
+}
+
+// Get mocks base method.
+func (m *MockCheckManager) Get(ctx context.Context, id uint) (*models.Check, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*models.Check)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockCheckManagerMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCheckManager)(nil).Get), ctx, id)
+}
+
 // GetByResource mocks base method.
 func (m *MockCheckManager) GetByResource(ctx context.Context, resources ...common.Resource) ([]*models.Check, error) {
 	m.ctrl.T.Helper()
@@ -116,6 +147,36 @@ func (mr *MockCheckManagerMockRecorder) ListCheckRuns(ctx, pipelinerunID interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCheckRuns", reflect.TypeOf((*MockCheckManager)(nil).ListCheckRuns), ctx, pipelinerunID)
 }
 
+// ListPendingApprovalCheckRuns mocks base method.
+func (m *MockCheckManager) ListPendingApprovalCheckRuns(ctx context.Context, before time.Time, query *q.Query) ([]*models.CheckRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingApprovalCheckRuns", ctx, before, query)
+	ret0, _ := ret[0].([]*models.CheckRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingApprovalCheckRuns indicates an expected call of ListPendingApprovalCheckRuns.
+func (mr *MockCheckManagerMockRecorder) ListPendingApprovalCheckRuns(ctx, before, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingApprovalCheckRuns", reflect.TypeOf((*MockCheckManager)(nil).ListPendingApprovalCheckRuns), ctx, before, query)
+}
+
+// Update mocks base method.
+func (m *MockCheckManager) Update(ctx context.Context, check *models.Check) (*models.Check, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, check)
+	ret0, _ := ret[0].(*models.Check)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockCheckManagerMockRecorder) Update(ctx, check interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCheckManager)(nil).Update), ctx, check)
+}
+
 // UpdateByID mocks base method.
 func (m *MockCheckManager) UpdateByID(ctx context.Context, checkRunID uint, newCheckRun *models.CheckRun) error {
 	m.ctrl.T.Helper()
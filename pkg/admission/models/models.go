@@ -26,6 +26,11 @@ const (
 
 	OperationCreate Operation = "create"
 	OperationUpdate Operation = "update"
+	OperationDelete Operation = "delete"
+	// OperationAction is the operation of actions on resources, such as restarting clusters
+	OperationAction Operation = "action"
+	// OperationConnect is the operation of connecting to resources, such as terminals of clusters
+	OperationConnect Operation = "connect"
 
 	PatchTypeJSONPatch = "JSONPatch"
 )
@@ -0,0 +1,52 @@
+package admission
+
+import (
+	amodels "github.com/horizoncd/horizon/pkg/application/models"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+)
+
+// ApplicationObject is the application sent to webhooks as the old object of the operations on applications
+type ApplicationObject struct {
+	ID              uint   `json:"id"`
+	Name            string `json:"name"`
+	GroupID         uint   `json:"groupID"`
+	Priority        string `json:"priority"`
+	Template        string `json:"template"`
+	TemplateRelease string `json:"templateRelease"`
+}
+
+func NewApplicationObject(application *amodels.Application) *ApplicationObject {
+	return &ApplicationObject{
+		ID:              application.ID,
+		Name:            application.Name,
+		GroupID:         application.GroupID,
+		Priority:        string(application.Priority),
+		Template:        application.Template,
+		TemplateRelease: application.TemplateRelease,
+	}
+}
+
+// ClusterObject is the cluster sent to webhooks as the old object of the operations on clusters
+type ClusterObject struct {
+	ID              uint   `json:"id"`
+	Name            string `json:"name"`
+	Application     string `json:"application"`
+	Environment     string `json:"environment"`
+	Region          string `json:"region"`
+	Template        string `json:"template"`
+	TemplateRelease string `json:"templateRelease"`
+	Status          string `json:"status"`
+}
+
+func NewClusterObject(application *amodels.Application, cluster *cmodels.Cluster) *ClusterObject {
+	return &ClusterObject{
+		ID:              cluster.ID,
+		Name:            cluster.Name,
+		Application:     application.Name,
+		Environment:     cluster.EnvironmentName,
+		Region:          cluster.RegionName,
+		Template:        cluster.Template,
+		TemplateRelease: cluster.TemplateRelease,
+		Status:          cluster.Status,
+	}
+}
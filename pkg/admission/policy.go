@@ -0,0 +1,356 @@
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"google.golang.org/protobuf/encoding/protojson"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	jsonpatch "gopkg.in/evanphx/json-patch.v5"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/admission/models"
+	policymanager "github.com/horizoncd/horizon/pkg/admissionpolicy/manager"
+	policymodels "github.com/horizoncd/horizon/pkg/admissionpolicy/models"
+	config "github.com/horizoncd/horizon/pkg/config/admission"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// variables which can be used in the expressions of admission policies
+const (
+	policyVarObject    = "object"
+	policyVarOldObject = "oldObject"
+	policyVarRequest   = "request"
+	policyVarUser      = "user"
+)
+
+// CompiledPolicy is an admission policy ready to evaluate
+type CompiledPolicy struct {
+	policy   *policymodels.AdmissionPolicy
+	matchers ResourceMatchers
+	program  cel.Program
+}
+
+// CompilePolicy checks the policy and compiles its expression
+func CompilePolicy(policy *policymodels.AdmissionPolicy) (*CompiledPolicy, error) {
+	kind := models.Kind(policy.Kind)
+	if !kind.Eq(models.KindValidating) && !kind.Eq(models.KindMutating) {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid kind of admission policy: %s", policy.Kind)
+	}
+	if policy.Language != policymodels.LanguageCEL {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"unsupported language of admission policy: %s", policy.Language)
+	}
+	failurePolicy := config.FailurePolicy(policy.FailurePolicy)
+	if !failurePolicy.Eq(config.FailurePolicyIgnore) && !failurePolicy.Eq(config.FailurePolicyFail) {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid failure policy: %s", policy.FailurePolicy)
+	}
+
+	var rules []config.Rule
+	if err := json.Unmarshal([]byte(policy.Rules), &rules); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid rules of admission policy: %v", err)
+	}
+	if len(rules) == 0 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "rules of admission policy are empty")
+	}
+
+	env, err := cel.NewEnv(cel.Declarations(
+		decls.NewVar(policyVarObject, decls.Dyn),
+		decls.NewVar(policyVarOldObject, decls.Dyn),
+		decls.NewVar(policyVarRequest, decls.NewMapType(decls.String, decls.Dyn)),
+		decls.NewVar(policyVarUser, decls.NewMapType(decls.String, decls.Dyn)),
+	))
+	if err != nil {
+		return nil, perror.Wrap(err, "failed to create cel environment")
+	}
+	ast, issues := env.Compile(policy.Expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid expression: %v", issues.Err())
+	}
+	resultType := ast.ResultType()
+	if resultType.GetDyn() == nil {
+		if kind.Eq(models.KindValidating) && !reflect.DeepEqual(resultType, decls.Bool) {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, "expression of validating policy must return a bool")
+		}
+		if kind.Eq(models.KindMutating) && resultType.GetListType() == nil {
+			return nil, perror.Wrap(herrors.ErrParamInvalid,
+				"expression of mutating policy must return a list of json patch operations")
+		}
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid expression: %v", err)
+	}
+	return &CompiledPolicy{
+		policy:   policy,
+		matchers: NewResourceMatchers(rules),
+		program:  program,
+	}, nil
+}
+
+// Match returns true if the request matches the rules of the policy
+func (p *CompiledPolicy) Match(req *Request) bool {
+	return p.matchers.Match(req)
+}
+
+// Evaluate evaluates the policy against the request.
+// A validating policy responses whether the request is allowed,
+// and a mutating policy responses the json patch of the object.
+func (p *CompiledPolicy) Evaluate(ctx context.Context, req *Request) (*Response, error) {
+	activation, err := policyActivation(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	val, _, err := p.program.Eval(activation)
+	if err != nil {
+		return nil, perror.Errorf("failed to evaluate admission policy %s: %v", p.policy.Name, err)
+	}
+
+	allowed := true
+	if models.Kind(p.policy.Kind).Eq(models.KindValidating) {
+		result, ok := val.(types.Bool)
+		if !ok {
+			return nil, perror.Errorf("admission policy %s returns %v instead of a bool", p.policy.Name, val.Type())
+		}
+		if result {
+			return &Response{Allowed: &allowed}, nil
+		}
+		allowed = false
+		message := fmt.Sprintf("denied by admission policy %s", p.policy.Name)
+		if p.policy.Message != "" {
+			message = fmt.Sprintf("%s: %s", message, p.policy.Message)
+		}
+		return &Response{Allowed: &allowed, Result: message}, nil
+	}
+
+	patch, err := policyPatch(val)
+	if err != nil {
+		return nil, perror.Errorf("admission policy %s returns an invalid patch: %v", p.policy.Name, err)
+	}
+	response := &Response{Allowed: &allowed}
+	if patch != nil {
+		response.Patch = patch
+		response.PatchType = models.PatchTypeJSONPatch
+	}
+	return response, nil
+}
+
+// IgnoreError returns true if the request is admitted when the policy fails to evaluate
+func (p *CompiledPolicy) IgnoreError() bool {
+	return config.FailurePolicy(p.policy.FailurePolicy).Eq(config.FailurePolicyIgnore)
+}
+
+// policyActivation converts the request to the variables of expressions,
+// objects are converted to the values decoded from json, so numbers are always doubles
+func policyActivation(ctx context.Context, req *Request) (map[string]interface{}, error) {
+	var object, oldObject interface{}
+	if err := jsonRoundTrip(req.Object, &object); err != nil {
+		return nil, err
+	}
+	if err := jsonRoundTrip(req.OldObject, &oldObject); err != nil {
+		return nil, err
+	}
+	options := map[string]interface{}{}
+	if err := jsonRoundTrip(req.Options, &options); err != nil {
+		return nil, err
+	}
+	user := map[string]interface{}{}
+	if currentUser, err := common.UserFromContext(ctx); err == nil {
+		user = map[string]interface{}{
+			"id":    float64(currentUser.GetID()),
+			"name":  currentUser.GetName(),
+			"email": currentUser.GetEmail(),
+			"admin": currentUser.IsAdmin(),
+		}
+	}
+	return map[string]interface{}{
+		policyVarObject:    object,
+		policyVarOldObject: oldObject,
+		policyVarRequest: map[string]interface{}{
+			"operation":   string(req.Operation),
+			"resource":    req.Resource,
+			"subResource": req.SubResource,
+			"name":        req.Name,
+			"version":     req.Version,
+			"options":     options,
+		},
+		policyVarUser: user,
+	}, nil
+}
+
+func jsonRoundTrip(in interface{}, out interface{}) error {
+	if in == nil {
+		return nil
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// policyPatch converts the list returned by a mutating policy to a json patch,
+// an empty list means nothing to patch
+func policyPatch(val ref.Val) ([]byte, error) {
+	native, err := val.ConvertToNative(reflect.TypeOf(&structpb.Value{}))
+	if err != nil {
+		return nil, err
+	}
+	list := native.(*structpb.Value).GetListValue()
+	if list == nil {
+		return nil, perror.Errorf("%v is not a list", val.Type())
+	}
+	if len(list.GetValues()) == 0 {
+		return nil, nil
+	}
+	patch, err := protojson.Marshal(list)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := jsonpatch.DecodePatch(patch); err != nil {
+		return nil, err
+	}
+	return patch, nil
+}
+
+type PolicyAdmissionWebhook struct {
+	kind          models.Kind
+	policyManager policymanager.Manager
+
+	lock     sync.Mutex
+	compiled map[uint]*compiledPolicyCache
+}
+
+type compiledPolicyCache struct {
+	updatedAt time.Time
+	policy    *CompiledPolicy
+	err       error
+}
+
+// NewPolicyWebhooks registers the webhooks evaluating the admission policies in the database
+func NewPolicyWebhooks(policyManager policymanager.Manager) {
+	Register(models.KindMutating, NewPolicyWebhook(models.KindMutating, policyManager))
+	Register(models.KindValidating, NewPolicyWebhook(models.KindValidating, policyManager))
+}
+
+func NewPolicyWebhook(kind models.Kind, policyManager policymanager.Manager) Webhook {
+	return &PolicyAdmissionWebhook{
+		kind:          kind,
+		policyManager: policyManager,
+		compiled:      make(map[uint]*compiledPolicyCache),
+	}
+}
+
+// compile compiles the policies, and reuses the programs of policies not updated
+func (w *PolicyAdmissionWebhook) compile(policies []*policymodels.AdmissionPolicy) []*compiledPolicyCache {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	compiled := make(map[uint]*compiledPolicyCache, len(policies))
+	caches := make([]*compiledPolicyCache, 0, len(policies))
+	for _, policy := range policies {
+		cache, ok := w.compiled[policy.ID]
+		if !ok || !cache.updatedAt.Equal(policy.UpdatedAt) {
+			compiledPolicy, err := CompilePolicy(policy)
+			cache = &compiledPolicyCache{
+				updatedAt: policy.UpdatedAt,
+				policy:    compiledPolicy,
+				err:       err,
+			}
+		}
+		compiled[policy.ID] = cache
+		caches = append(caches, cache)
+	}
+	w.compiled = compiled
+	return caches
+}
+
+// Handle evaluates the policies matching the request in the order of creation.
+// Mutating policies are evaluated against the object patched by the former policies,
+// and the patches are joined into one.
+func (w *PolicyAdmissionWebhook) Handle(ctx context.Context, req *Request) (*Response, error) {
+	policies, err := w.policyManager.ListByKind(ctx, w.kind.String())
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := true
+	var patches []json.RawMessage
+	request := *req
+	for i, cache := range w.compile(policies) {
+		policy := policies[i]
+		if cache.err != nil {
+			if config.FailurePolicy(policy.FailurePolicy).Eq(config.FailurePolicyIgnore) {
+				log.Warningf(ctx, "failed to compile admission policy %s: %v", policy.Name, cache.err)
+				continue
+			}
+			return nil, perror.Wrapf(cache.err, "failed to compile admission policy %s", policy.Name)
+		}
+		if !cache.policy.Match(&request) {
+			continue
+		}
+		response, err := cache.policy.Evaluate(ctx, &request)
+		if err != nil {
+			if cache.policy.IgnoreError() {
+				log.Warningf(ctx, "%v", err)
+				continue
+			}
+			return nil, err
+		}
+		if policy.DryRun {
+			log.Infof(ctx, "dry run of admission policy %s: allowed: %v, result: %s, patch: %s",
+				policy.Name, *response.Allowed, response.Result, string(response.Patch))
+			continue
+		}
+		if !*response.Allowed {
+			return response, nil
+		}
+		if response.Patch != nil {
+			var operations []json.RawMessage
+			if err := json.Unmarshal(response.Patch, &operations); err != nil {
+				return nil, err
+			}
+			// the object is kept for the following policies if the patch fails
+			patched, err := jsonPatch(request.Object, response.Patch)
+			if err != nil {
+				if cache.policy.IgnoreError() {
+					log.Warningf(ctx, "failed to apply patch of admission policy %s: %v", policy.Name, err)
+					continue
+				}
+				return nil, perror.Errorf("failed to apply patch of admission policy %s: %v", policy.Name, err)
+			}
+			request.Object = patched
+			patches = append(patches, operations...)
+		}
+	}
+
+	response := &Response{Allowed: &allowed}
+	if len(patches) > 0 {
+		patch, err := json.Marshal(patches)
+		if err != nil {
+			return nil, err
+		}
+		response.Patch = patch
+		response.PatchType = models.PatchTypeJSONPatch
+	}
+	return response, nil
+}
+
+// IgnoreError returns false, the failure policy of each policy is handled by Handle
+func (w *PolicyAdmissionWebhook) IgnoreError() bool {
+	return false
+}
+
+// Interest returns true, the rules of each policy are matched by Handle
+func (w *PolicyAdmissionWebhook) Interest(*Request) bool {
+	return true
+}
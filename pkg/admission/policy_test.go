@@ -0,0 +1,222 @@
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/admission/models"
+	policymanager "github.com/horizoncd/horizon/pkg/admissionpolicy/manager"
+	policymodels "github.com/horizoncd/horizon/pkg/admissionpolicy/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	admissionconfig "github.com/horizoncd/horizon/pkg/config/admission"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+func newPolicy(name string, kind models.Kind, expression string) *policymodels.AdmissionPolicy {
+	rules, _ := json.Marshal([]admissionconfig.Rule{
+		{
+			Resources:  []string{"applications/clusters", "clusters"},
+			Operations: []models.Operation{models.OperationCreate, models.OperationUpdate},
+			Versions:   []string{models.MatchAll},
+		},
+	})
+	return &policymodels.AdmissionPolicy{
+		Name:          name,
+		Kind:          kind.String(),
+		Language:      policymodels.LanguageCEL,
+		Rules:         string(rules),
+		Expression:    expression,
+		FailurePolicy: string(admissionconfig.FailurePolicyFail),
+	}
+}
+
+func clusterRequest(object map[string]interface{}) *Request {
+	return &Request{
+		Operation:   models.OperationCreate,
+		Resource:    "applications",
+		Name:        "app",
+		SubResource: "clusters",
+		Version:     "v2",
+		Object:      object,
+	}
+}
+
+func TestCompilePolicy(t *testing.T) {
+	_, err := CompilePolicy(newPolicy("ok", models.KindValidating, `object.replicas >= 2.0`))
+	assert.Nil(t, err)
+
+	for _, policy := range []*policymodels.AdmissionPolicy{
+		newPolicy("kind", "unknown", `true`),
+		newPolicy("syntax", models.KindValidating, `object.replicas >=`),
+		newPolicy("not bool", models.KindValidating, `"true"`),
+		newPolicy("not list", models.KindMutating, `true`),
+		func() *policymodels.AdmissionPolicy {
+			policy := newPolicy("language", models.KindValidating, `true`)
+			policy.Language = "rego"
+			return policy
+		}(),
+		func() *policymodels.AdmissionPolicy {
+			policy := newPolicy("rules", models.KindValidating, `true`)
+			policy.Rules = "[]"
+			return policy
+		}(),
+		func() *policymodels.AdmissionPolicy {
+			policy := newPolicy("failure policy", models.KindValidating, `true`)
+			policy.FailurePolicy = "unknown"
+			return policy
+		}(),
+	} {
+		_, err := CompilePolicy(policy)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err), policy.Name)
+	}
+}
+
+func TestCompiledPolicyEvaluate(t *testing.T) {
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "Tony", ID: 1})
+
+	policy := newPolicy("online replicas", models.KindValidating,
+		`object.environment != "online" || object.replicas >= 2.0`)
+	policy.Message = "online clusters need at least 2 replicas"
+	compiled, err := CompilePolicy(policy)
+	assert.Nil(t, err)
+
+	req := clusterRequest(map[string]interface{}{"environment": "online", "replicas": 1})
+	assert.True(t, compiled.Match(req))
+	response, err := compiled.Evaluate(ctx, req)
+	assert.Nil(t, err)
+	assert.False(t, *response.Allowed)
+	assert.Equal(t, "denied by admission policy online replicas: online clusters need at least 2 replicas",
+		response.Result)
+
+	response, err = compiled.Evaluate(ctx, clusterRequest(map[string]interface{}{"environment": "test"}))
+	assert.Nil(t, err)
+	assert.True(t, *response.Allowed)
+
+	// the request of other resources is not matched
+	assert.False(t, compiled.Match(&Request{Operation: models.OperationCreate, Resource: "groups"}))
+
+	// fails to evaluate when the field does not exist
+	_, err = compiled.Evaluate(ctx, clusterRequest(map[string]interface{}{"environment": "online"}))
+	assert.NotNil(t, err)
+
+	// the request and the user can be used in expressions
+	compiled, err = CompilePolicy(newPolicy("user", models.KindValidating,
+		`request.subResource == "clusters" && user.name == "Tony" && !user.admin`))
+	assert.Nil(t, err)
+	response, err = compiled.Evaluate(ctx, clusterRequest(nil))
+	assert.Nil(t, err)
+	assert.True(t, *response.Allowed)
+
+	compiled, err = CompilePolicy(newPolicy("default replicas", models.KindMutating,
+		`has(object.replicas) ? [] : [{"op": "add", "path": "/replicas", "value": 2}]`))
+	assert.Nil(t, err)
+	response, err = compiled.Evaluate(ctx, clusterRequest(map[string]interface{}{}))
+	assert.Nil(t, err)
+	assert.True(t, *response.Allowed)
+	assert.Equal(t, models.PatchTypeJSONPatch, response.PatchType)
+	assert.JSONEq(t, `[{"op": "add", "path": "/replicas", "value": 2}]`, string(response.Patch))
+	response, err = compiled.Evaluate(ctx, clusterRequest(map[string]interface{}{"replicas": 1}))
+	assert.Nil(t, err)
+	assert.Nil(t, response.Patch)
+
+	compiled, err = CompilePolicy(newPolicy("invalid patch", models.KindMutating, `["replicas"]`))
+	assert.Nil(t, err)
+	_, err = compiled.Evaluate(ctx, clusterRequest(map[string]interface{}{}))
+	assert.NotNil(t, err)
+}
+
+func TestPolicyAdmissionWebhook(t *testing.T) {
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "Tony", ID: 1})
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&policymodels.AdmissionPolicy{}))
+	mgr := policymanager.New(db)
+
+	mutating := NewPolicyWebhook(models.KindMutating, mgr)
+	validating := NewPolicyWebhook(models.KindValidating, mgr)
+
+	// no policies
+	response, err := validating.Handle(ctx, clusterRequest(map[string]interface{}{}))
+	assert.Nil(t, err)
+	assert.True(t, *response.Allowed)
+
+	// the latter mutating policy sees the object patched by the former one
+	_, err = mgr.Create(ctx, newPolicy("default replicas", models.KindMutating,
+		`has(object.replicas) ? [] : [{"op": "add", "path": "/replicas", "value": 1}]`))
+	assert.Nil(t, err)
+	_, err = mgr.Create(ctx, newPolicy("online replicas", models.KindMutating,
+		`object.environment == "online" && object.replicas < 2.0 ? `+
+			`[{"op": "replace", "path": "/replicas", "value": 2}] : []`))
+	assert.Nil(t, err)
+	dryRun := newPolicy("dry run", models.KindMutating, `[{"op": "remove", "path": "/environment"}]`)
+	dryRun.DryRun = true
+	_, err = mgr.Create(ctx, dryRun)
+	assert.Nil(t, err)
+	ignored := newPolicy("ignored", models.KindMutating, `object.nonexistent`)
+	ignored.FailurePolicy = string(admissionconfig.FailurePolicyIgnore)
+	_, err = mgr.Create(ctx, ignored)
+	assert.Nil(t, err)
+
+	req := clusterRequest(map[string]interface{}{"environment": "online"})
+	response, err = mutating.Handle(ctx, req)
+	assert.Nil(t, err)
+	patched, err := jsonPatch(req.Object, response.Patch)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"environment": "online", "replicas": float64(2)}, patched)
+
+	// dry run policies never deny requests
+	deny := newPolicy("deny", models.KindValidating, `false`)
+	deny.DryRun = true
+	deny, err = mgr.Create(ctx, deny)
+	assert.Nil(t, err)
+	response, err = validating.Handle(ctx, req)
+	assert.Nil(t, err)
+	assert.True(t, *response.Allowed)
+
+	// the updated policy is compiled again
+	deny.DryRun = false
+	_, err = mgr.Update(ctx, deny)
+	assert.Nil(t, err)
+	response, err = validating.Handle(ctx, req)
+	assert.Nil(t, err)
+	assert.False(t, *response.Allowed)
+
+	// policies failed to evaluate deny requests by default
+	assert.Nil(t, mgr.Delete(ctx, deny.ID))
+	_, err = mgr.Create(ctx, newPolicy("failed", models.KindValidating, `object.nonexistent`))
+	assert.Nil(t, err)
+	_, err = validating.Handle(ctx, req)
+	assert.NotNil(t, err)
+}
+
+func TestPolicyAdmissionWebhookIgnoredPatchFailure(t *testing.T) {
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "Tony", ID: 1})
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&policymodels.AdmissionPolicy{}))
+	mgr := policymanager.New(db)
+	mutating := NewPolicyWebhook(models.KindMutating, mgr)
+
+	// the patch of the ignored policy fails to apply, the latter policy still sees the object
+	ignored := newPolicy("ignored", models.KindMutating,
+		`[{"op": "test", "path": "/environment", "value": "test"}]`)
+	ignored.FailurePolicy = string(admissionconfig.FailurePolicyIgnore)
+	_, err := mgr.Create(ctx, ignored)
+	assert.Nil(t, err)
+	_, err = mgr.Create(ctx, newPolicy("online replicas", models.KindMutating,
+		`object.environment == "online" ? [{"op": "add", "path": "/replicas", "value": 2}] : []`))
+	assert.Nil(t, err)
+
+	req := clusterRequest(map[string]interface{}{"environment": "online"})
+	response, err := mutating.Handle(ctx, req)
+	assert.Nil(t, err)
+	assert.True(t, *response.Allowed)
+	assert.JSONEq(t, `[{"op": "add", "path": "/replicas", "value": 2}]`, string(response.Patch))
+	patched, err := jsonPatch(req.Object, response.Patch)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"environment": "online", "replicas": float64(2)}, patched)
+}
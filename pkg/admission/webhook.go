@@ -6,8 +6,10 @@ import (
 
 	"k8s.io/apimachinery/pkg/util/runtime"
 
+	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/pkg/admission/models"
+	"github.com/horizoncd/horizon/pkg/auth"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/util/log"
 	jsonpatch "gopkg.in/evanphx/json-patch.v5"
@@ -140,6 +142,18 @@ func Validating(ctx context.Context, request *Request) error {
 	return nil
 }
 
+// Admit validates the request by the validating webhooks. It's called by controllers
+// for the operations not admitted by the middleware, such as deleting resources,
+// executing actions and connecting to terminals, in which the resource is the old object.
+func Admit(ctx context.Context, request *Request) error {
+	if request.Version == "" {
+		if record, ok := ctx.Value(common.ContextAuthRecord).(auth.AttributesRecord); ok {
+			request.Version = record.GetAPIVersion()
+		}
+	}
+	return Validating(ctx, request)
+}
+
 func loggingError(ctx context.Context, err error, webhook Webhook) error {
 	if err != nil {
 		if webhook.IgnoreError() {
@@ -1,23 +1,35 @@
-package admission
+package admission_test
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/horizoncd/horizon/core/common"
 	clusterctrl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/admission"
 	"github.com/horizoncd/horizon/pkg/admission/models"
+	policymanager "github.com/horizoncd/horizon/pkg/admissionpolicy/manager"
+	policymodels "github.com/horizoncd/horizon/pkg/admissionpolicy/models"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
 	admissionconfig "github.com/horizoncd/horizon/pkg/config/admission"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 )
 
 func TestWebhook(t *testing.T) {
 	ctx := context.Background()
 
-	server := NewDummyWebhookServer()
+	server := admission.NewDummyWebhookServer()
 	defer server.Stop()
 	mutatingURL := server.MutatingURL()
 	validatingURL := server.ValidatingURL()
@@ -83,7 +95,7 @@ func TestWebhook(t *testing.T) {
 			},
 		},
 	}
-	NewHTTPWebhooks(config)
+	admission.NewHTTPWebhooks(config)
 
 	createBody := clusterctrl.CreateClusterRequestV2{
 		Name:        "cluster-1",
@@ -99,7 +111,7 @@ func TestWebhook(t *testing.T) {
 		},
 	}
 
-	createRequest := &Request{
+	createRequest := &admission.Request{
 		Operation:   models.OperationCreate,
 		Resource:    "applications",
 		Name:        "1",
@@ -111,12 +123,12 @@ func TestWebhook(t *testing.T) {
 			"scope": []string{"online/hz1"},
 		},
 	}
-	err := Validating(ctx, createRequest)
+	err := admission.Validating(ctx, createRequest)
 	assert.NoError(t, err)
 
 	createBody.Name = "cluster-invalid"
 	createRequest.Object = createBody
-	err = Validating(ctx, createRequest)
+	err = admission.Validating(ctx, createRequest)
 	assert.Error(t, err)
 	t.Logf("error: %v", err)
 
@@ -129,7 +141,7 @@ func TestWebhook(t *testing.T) {
 			},
 		},
 	}
-	updateRequest := &Request{
+	updateRequest := &admission.Request{
 		Operation:   models.OperationUpdate,
 		Resource:    "clusters",
 		Name:        "1",
@@ -139,12 +151,70 @@ func TestWebhook(t *testing.T) {
 		OldObject:   nil,
 		Options:     nil,
 	}
-	err = Validating(ctx, updateRequest)
+	err = admission.Validating(ctx, updateRequest)
 	assert.Error(t, err)
 	t.Logf("error: %v", err.Error())
 
-	updateRequest, err = Mutating(ctx, updateRequest)
+	updateRequest, err = admission.Mutating(ctx, updateRequest)
 	assert.NoError(t, err)
-	err = Validating(ctx, updateRequest)
+	err = admission.Validating(ctx, updateRequest)
 	assert.NoError(t, err)
 }
+
+func TestAdmit(t *testing.T) {
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "Tony", ID: 1})
+	ctx = context.WithValue(ctx, common.ContextAuthRecord, auth.AttributesRecord{APIVersion: "v2"}) // nolint
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&policymodels.AdmissionPolicy{}))
+	mgr := policymanager.New(db)
+	admission.Register(models.KindValidating, admission.NewPolicyWebhook(models.KindValidating, mgr))
+
+	rules, _ := json.Marshal([]admissionconfig.Rule{
+		{
+			Resources:  []string{"clusters", "clusters/rollback", "clusters/terminal"},
+			Operations: []models.Operation{models.OperationDelete, models.OperationAction, models.OperationConnect},
+			Versions:   []string{"v2"},
+		},
+	})
+	_, err := mgr.Create(ctx, &policymodels.AdmissionPolicy{
+		Name:          "protect online",
+		Kind:          models.KindValidating.String(),
+		Language:      policymodels.LanguageCEL,
+		Rules:         string(rules),
+		Expression:    `oldObject.environment != "online" || request.operation == "connect"`,
+		Message:       "online clusters are protected",
+		FailurePolicy: string(admissionconfig.FailurePolicyFail),
+	})
+	assert.Nil(t, err)
+
+	application := &appmodels.Application{Name: "app"}
+	online := &clustermodels.Cluster{Name: "cluster-online", EnvironmentName: "online"}
+	test := &clustermodels.Cluster{Name: "cluster-test", EnvironmentName: "test"}
+
+	// the version is filled from the auth record in the context
+	for _, request := range []*admission.Request{
+		{Operation: models.OperationDelete, Resource: "clusters"},
+		{Operation: models.OperationAction, Resource: "clusters", SubResource: "rollback"},
+	} {
+		request.OldObject = admission.NewClusterObject(application, online)
+		err = admission.Admit(ctx, request)
+		assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+		request.OldObject = admission.NewClusterObject(application, test)
+		assert.Nil(t, admission.Admit(ctx, request))
+	}
+
+	assert.Nil(t, admission.Admit(ctx, &admission.Request{
+		Operation:   models.OperationConnect,
+		Resource:    "clusters",
+		SubResource: "terminal",
+		OldObject:   admission.NewClusterObject(application, online),
+	}))
+
+	// the operations not matched by rules are allowed
+	assert.Nil(t, admission.Admit(ctx, &admission.Request{
+		Operation: models.OperationDelete,
+		Resource:  "applications",
+		OldObject: admission.NewApplicationObject(application),
+	}))
+}
@@ -0,0 +1,111 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/admissionpolicy/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, policy *models.AdmissionPolicy) (*models.AdmissionPolicy, error)
+	Update(ctx context.Context, policy *models.AdmissionPolicy) (*models.AdmissionPolicy, error)
+	Get(ctx context.Context, id uint) (*models.AdmissionPolicy, error)
+	GetByName(ctx context.Context, name string) (*models.AdmissionPolicy, error)
+	List(ctx context.Context) ([]*models.AdmissionPolicy, error)
+	ListByKind(ctx context.Context, kind string) ([]*models.AdmissionPolicy, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, policy *models.AdmissionPolicy) (*models.AdmissionPolicy, error) {
+	if err := d.db.WithContext(ctx).Create(policy).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.AdmissionPolicyInDB, err.Error())
+	}
+	return policy, nil
+}
+
+func (d *dao) Update(ctx context.Context, policy *models.AdmissionPolicy) (*models.AdmissionPolicy, error) {
+	// select all the fields which can be updated, so that zero values are saved too
+	result := d.db.WithContext(ctx).Model(policy).
+		Select("name", "description", "kind", "language", "rules", "expression",
+			"message", "failure_policy", "dry_run", "updated_by").
+		Where("id = ?", policy.ID).Updates(policy)
+	if result.Error != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.AdmissionPolicyInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.AdmissionPolicyInDB, "admission policy not found")
+	}
+	return d.Get(ctx, policy.ID)
+}
+
+func (d *dao) Get(ctx context.Context, id uint) (*models.AdmissionPolicy, error) {
+	var policy models.AdmissionPolicy
+	if err := d.db.WithContext(ctx).First(&policy, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.AdmissionPolicyInDB, err.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.AdmissionPolicyInDB, err.Error())
+	}
+	return &policy, nil
+}
+
+func (d *dao) GetByName(ctx context.Context, name string) (*models.AdmissionPolicy, error) {
+	var policy models.AdmissionPolicy
+	if err := d.db.WithContext(ctx).Where("name = ?", name).First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.AdmissionPolicyInDB, err.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.AdmissionPolicyInDB, err.Error())
+	}
+	return &policy, nil
+}
+
+func (d *dao) List(ctx context.Context) ([]*models.AdmissionPolicy, error) {
+	var policies []*models.AdmissionPolicy
+	if err := d.db.WithContext(ctx).Order("id").Find(&policies).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.AdmissionPolicyInDB, err.Error())
+	}
+	return policies, nil
+}
+
+func (d *dao) ListByKind(ctx context.Context, kind string) ([]*models.AdmissionPolicy, error) {
+	var policies []*models.AdmissionPolicy
+	if err := d.db.WithContext(ctx).Where("kind = ?", kind).
+		Order("id").Find(&policies).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.AdmissionPolicyInDB, err.Error())
+	}
+	return policies, nil
+}
+
+func (d *dao) Delete(ctx context.Context, id uint) error {
+	if err := d.db.WithContext(ctx).Delete(&models.AdmissionPolicy{}, id).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.AdmissionPolicyInDB, err.Error())
+	}
+	return nil
+}
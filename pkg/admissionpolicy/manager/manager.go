@@ -0,0 +1,71 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/admissionpolicy/dao"
+	"github.com/horizoncd/horizon/pkg/admissionpolicy/models"
+)
+
+type Manager interface {
+	Create(ctx context.Context, policy *models.AdmissionPolicy) (*models.AdmissionPolicy, error)
+	Update(ctx context.Context, policy *models.AdmissionPolicy) (*models.AdmissionPolicy, error)
+	Get(ctx context.Context, id uint) (*models.AdmissionPolicy, error)
+	GetByName(ctx context.Context, name string) (*models.AdmissionPolicy, error)
+	List(ctx context.Context) ([]*models.AdmissionPolicy, error)
+	// ListByKind lists the validating or mutating policies in the order of creation
+	ListByKind(ctx context.Context, kind string) ([]*models.AdmissionPolicy, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, policy *models.AdmissionPolicy) (*models.AdmissionPolicy, error) {
+	return m.dao.Create(ctx, policy)
+}
+
+func (m *manager) Update(ctx context.Context, policy *models.AdmissionPolicy) (*models.AdmissionPolicy, error) {
+	return m.dao.Update(ctx, policy)
+}
+
+func (m *manager) Get(ctx context.Context, id uint) (*models.AdmissionPolicy, error) {
+	return m.dao.Get(ctx, id)
+}
+
+func (m *manager) GetByName(ctx context.Context, name string) (*models.AdmissionPolicy, error) {
+	return m.dao.GetByName(ctx, name)
+}
+
+func (m *manager) List(ctx context.Context) ([]*models.AdmissionPolicy, error) {
+	return m.dao.List(ctx)
+}
+
+func (m *manager) ListByKind(ctx context.Context, kind string) ([]*models.AdmissionPolicy, error) {
+	return m.dao.ListByKind(ctx, kind)
+}
+
+func (m *manager) Delete(ctx context.Context, id uint) error {
+	return m.dao.Delete(ctx, id)
+}
@@ -0,0 +1,47 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+const (
+	LanguageCEL = "cel"
+)
+
+// AdmissionPolicy is evaluated in process against the admission requests matching Rules.
+// The Expression of a validating policy returns whether the request is allowed,
+// and the Expression of a mutating policy returns a list of JSON patch operations applied to the object.
+type AdmissionPolicy struct {
+	global.Model
+
+	Name        string
+	Description string
+	// Kind is validating or mutating
+	Kind     string
+	Language string
+	// Rules is the json of the admission rules the policy applies to
+	Rules      string
+	Expression string
+	// Message is returned when the request is denied by a validating policy
+	Message string
+	// FailurePolicy decides whether the request is denied when the policy fails to evaluate
+	FailurePolicy string
+	// DryRun policies are evaluated and logged, but never deny or mutate requests
+	DryRun    bool
+	CreatedBy uint
+	UpdatedBy uint
+}
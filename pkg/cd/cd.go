@@ -33,8 +33,13 @@ import (
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	"github.com/horizoncd/horizon/pkg/cluster/kubeclient"
 	argocdconf "github.com/horizoncd/horizon/pkg/config/argocd"
+	fluxcdconf "github.com/horizoncd/horizon/pkg/config/fluxcd"
+	helmcdconf "github.com/horizoncd/horizon/pkg/config/helmcd"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	"github.com/horizoncd/horizon/pkg/regioninformers"
+	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
 	"github.com/horizoncd/horizon/pkg/util/kube"
 	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
@@ -96,6 +101,12 @@ type CD interface {
 	GetPodEvents(ctx context.Context, params *GetPodEventsParams) ([]Event, error)
 }
 
+// RollbackCD is implemented by cd which is able to roll back cluster to the deployment of a pipelinerun
+// by itself, otherwise cluster is rolled back by deploying the reverted revision of gitops repo.
+type RollbackCD interface {
+	RollbackCluster(ctx context.Context, params *RollbackClusterParams) error
+}
+
 type cd struct {
 	kubeClientFactory kubeclient.Factory
 	informerFactories *regioninformers.RegionInformers
@@ -104,15 +115,99 @@ type cd struct {
 	targetRevision    string
 }
 
+// NewCD returns a CD which deploys clusters by Flux CD for regions in regionFluxCDMapper,
+// by helm directly for regions in regionHelmCDMapper, and by Argo CD for the others.
 func NewCD(informerFactories *regioninformers.RegionInformers, clusterGitRepo gitrepo.ClusterGitRepo,
-	argoCDMapper argocdconf.Mapper, regionArgoCDMapper argocdconf.RegionMapper, targetRevision string) CD {
-	return &cd{
-		kubeClientFactory: kubeclient.Fty,
-		informerFactories: informerFactories,
-		factory:           argocd.NewFactory(argoCDMapper, regionArgoCDMapper),
-		clusterGitRepo:    clusterGitRepo,
-		targetRevision:    targetRevision,
+	templateRepo templaterepo.TemplateRepo, templateReleaseMgr trmanager.Manager,
+	regionMgr regionmanager.Manager, argoCDMapper argocdconf.Mapper, regionArgoCDMapper argocdconf.RegionMapper,
+	regionFluxCDMapper fluxcdconf.RegionMapper, regionHelmCDMapper helmcdconf.RegionMapper,
+	targetRevision string) CD {
+	return &regionCD{
+		argo: &cd{
+			kubeClientFactory: kubeclient.Fty,
+			informerFactories: informerFactories,
+			factory:           argocd.NewFactory(argoCDMapper, regionArgoCDMapper),
+			clusterGitRepo:    clusterGitRepo,
+			targetRevision:    targetRevision,
+		},
+		flux:               newFluxCD(informerFactories, clusterGitRepo, regionMgr, regionFluxCDMapper, targetRevision),
+		regionFluxCDMapper: regionFluxCDMapper,
+		helm: newHelmCD(informerFactories, clusterGitRepo, templateRepo, templateReleaseMgr,
+			regionMgr, regionHelmCDMapper),
+		regionHelmCDMapper: regionHelmCDMapper,
+	}
+}
+
+// regionCD chooses cd engine by region
+type regionCD struct {
+	argo               *cd
+	flux               *fluxCD
+	regionFluxCDMapper fluxcdconf.RegionMapper
+	helm               *helmCD
+	regionHelmCDMapper helmcdconf.RegionMapper
+}
+
+var (
+	_ LegacyCD   = (*regionCD)(nil)
+	_ RollbackCD = (*regionCD)(nil)
+)
+
+func (r *regionCD) engine(region string) CD {
+	if _, ok := r.regionFluxCDMapper[region]; ok {
+		return r.flux
+	}
+	if _, ok := r.regionHelmCDMapper[region]; ok {
+		return r.helm
+	}
+	return r.argo
+}
+
+func (r *regionCD) CreateCluster(ctx context.Context, params *CreateClusterParams) error {
+	return r.engine(params.RegionEntity.Name).CreateCluster(ctx, params)
+}
+
+func (r *regionCD) DeployCluster(ctx context.Context, params *DeployClusterParams) error {
+	return r.engine(params.Region).DeployCluster(ctx, params)
+}
+
+// RollbackCluster rolls back cluster by the engine if it supports, otherwise deploys the reverted revision
+func (r *regionCD) RollbackCluster(ctx context.Context, params *RollbackClusterParams) error {
+	engine := r.engine(params.Region)
+	if rollbackCD, ok := engine.(RollbackCD); ok {
+		return rollbackCD.RollbackCluster(ctx, params)
 	}
+	return engine.DeployCluster(ctx, &params.DeployClusterParams)
+}
+
+func (r *regionCD) DeleteCluster(ctx context.Context, params *DeleteClusterParams) error {
+	return r.engine(params.Region).DeleteCluster(ctx, params)
+}
+
+func (r *regionCD) GetClusterState(ctx context.Context, params *GetClusterStateV2Params) (*ClusterStateV2, error) {
+	return r.engine(params.RegionEntity.Name).GetClusterState(ctx, params)
+}
+
+func (r *regionCD) GetResourceTree(ctx context.Context, params *GetResourceTreeParams) ([]ResourceNode, error) {
+	return r.engine(params.RegionEntity.Name).GetResourceTree(ctx, params)
+}
+
+func (r *regionCD) GetStep(ctx context.Context, params *GetStepParams) (*Step, error) {
+	return r.engine(params.RegionEntity.Name).GetStep(ctx, params)
+}
+
+func (r *regionCD) GetPodEvents(ctx context.Context, params *GetPodEventsParams) ([]Event, error) {
+	return r.engine(params.RegionEntity.Name).GetPodEvents(ctx, params)
+}
+
+// Deprecated: using GetClusterState instead
+func (r *regionCD) GetClusterStateV1(ctx context.Context, params *GetClusterStateParams) (*ClusterState, error) {
+	legacyCD, ok := r.engine(params.RegionEntity.Name).(LegacyCD)
+	if !ok {
+		return nil, perror.Wrapf(herrors.ErrNotSupport,
+			"cd of region %s does not support legacy cluster state", params.RegionEntity.Name)
+	}
+	// nolint
+	return legacyCD.GetClusterStateV1(ctx, params)
 }
 
 func (c *cd) CreateCluster(ctx context.Context, params *CreateClusterParams) (err error) {
@@ -297,6 +392,7 @@ func (c *cd) GetStep(ctx context.Context, params *GetStepParams) (*Step, error)
 		Replicas:     step.Replicas,
 		ManualPaused: step.ManualPaused,
 		AutoPromote:  step.AutoPromote,
+		Aborted:      step.Aborted,
 		Extra:        step.Extra,
 	}, nil
 }
@@ -583,31 +679,36 @@ func (c *cd) GetPodEvents(ctx context.Context,
 	for i := range resourceTree {
 		pod := resourceTree[i].PodDetail
 		if pod != nil && pod.Metadata.Namespace == params.Namespace && pod.Metadata.Name == params.Pod {
-			k8sEvents, err := kube.GetPodEvents(ctx, kubeClient.Basic, params.Namespace, params.Pod)
-			if err != nil {
-				return nil, err
-			}
-
-			for _, event := range k8sEvents {
-				eventTimeStamp := metav1.Time{Time: event.EventTime.Time}
-				if eventTimeStamp.IsZero() {
-					eventTimeStamp = event.FirstTimestamp
-				}
-				events = append(events, Event{
-					Type:           event.Type,
-					Reason:         event.Reason,
-					Message:        event.Message,
-					Count:          event.Count,
-					EventTimestamp: eventTimeStamp,
-				})
-			}
-			return events, nil
+			return getPodEvents(ctx, kubeClient.Basic, params.Namespace, params.Pod)
 		}
 	}
 
 	return nil, herrors.NewErrNotFound(herrors.PodsInK8S, "pod does not exist")
 }
 
+func getPodEvents(ctx context.Context, kubeClient kubernetes.Interface,
+	namespace, pod string) (events []Event, err error) {
+	k8sEvents, err := kube.GetPodEvents(ctx, kubeClient, namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range k8sEvents {
+		eventTimeStamp := metav1.Time{Time: event.EventTime.Time}
+		if eventTimeStamp.IsZero() {
+			eventTimeStamp = event.FirstTimestamp
+		}
+		events = append(events, Event{
+			Type:           event.Type,
+			Reason:         event.Reason,
+			Message:        event.Message,
+			Count:          event.Count,
+			EventTimestamp: eventTimeStamp,
+		})
+	}
+	return events, nil
+}
+
 // Deprecated
 func (c *cd) paddingPodAndEventInfo(ctx context.Context, cluster, namespace string,
 	kubeClient kubernetes.Interface, clusterState *ClusterState) error {
@@ -0,0 +1,466 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/kubeclient"
+	fluxcdconf "github.com/horizoncd/horizon/pkg/config/fluxcd"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/regioninformers"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	_fluxDefaultNamespace = "flux-system"
+	_fluxDefaultInterval  = "1m"
+	_fluxRequestedAt      = "reconcile.fluxcd.io/requestedAt"
+	_fluxConditionReady   = "Ready"
+	_fluxWaitInterval     = time.Second
+	_fluxWaitTimeout      = 10 * time.Minute
+
+	_kindGitRepository = "GitRepository"
+	_kindHelmRelease   = "HelmRelease"
+)
+
+var (
+	GVRGitRepository = schema.GroupVersionResource{
+		Group:    "source.toolkit.fluxcd.io",
+		Version:  "v1beta2",
+		Resource: "gitrepositories",
+	}
+	GVRHelmRelease = schema.GroupVersionResource{
+		Group:    "helm.toolkit.fluxcd.io",
+		Version:  "v2beta1",
+		Resource: "helmreleases",
+	}
+)
+
+// fluxCD deploys clusters by Flux CD. Every cluster owns a GitRepository pointing to
+// its gitops repo and a HelmRelease rendering the chart in that repo.
+// Resource tree and state are computed from the region informers
+// since Flux CD does not track the resources it applied.
+type fluxCD struct {
+	*informerResources
+	clusterGitRepo gitrepo.ClusterGitRepo
+	regionMgr      regionmanager.Manager
+	mapper         fluxcdconf.RegionMapper
+	targetRevision string
+}
+
+func newFluxCD(informerFactories *regioninformers.RegionInformers, clusterGitRepo gitrepo.ClusterGitRepo,
+	regionMgr regionmanager.Manager, mapper fluxcdconf.RegionMapper, targetRevision string) *fluxCD {
+	return &fluxCD{
+		informerResources: &informerResources{
+			kubeClientFactory: kubeclient.Fty,
+			informerFactories: informerFactories,
+		},
+		clusterGitRepo: clusterGitRepo,
+		regionMgr:      regionMgr,
+		mapper:         mapper,
+		targetRevision: targetRevision,
+	}
+}
+
+func (f *fluxCD) getConfig(region string) (*fluxcdconf.FluxCD, error) {
+	config, ok := f.mapper[region]
+	if !ok || config == nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "flux cd is not configured for region %s", region)
+	}
+	namespace, interval := config.Namespace, config.Interval
+	if namespace == "" {
+		namespace = _fluxDefaultNamespace
+	}
+	if interval == "" {
+		interval = _fluxDefaultInterval
+	}
+	return &fluxcdconf.FluxCD{
+		Namespace: namespace,
+		Interval:  interval,
+		Timeout:   config.Timeout,
+		SecretRef: config.SecretRef,
+	}, nil
+}
+
+func (f *fluxCD) withClient(regionID uint, operation regioninformers.DynamicClientSetOperation) error {
+	return f.informerFactories.GetDynamicClientSet(regionID, operation)
+}
+
+func (f *fluxCD) assembleGitRepository(config *fluxcdconf.FluxCD,
+	params *CreateClusterParams) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"url":      params.GitRepoURL,
+		"interval": config.Interval,
+		"ref": map[string]interface{}{
+			"branch": f.targetRevision,
+		},
+	}
+	if config.SecretRef != "" {
+		spec["secretRef"] = map[string]interface{}{
+			"name": config.SecretRef,
+		}
+	}
+	return f.assemble(GVRGitRepository, _kindGitRepository, config.Namespace, params.Cluster, spec)
+}
+
+func (f *fluxCD) assembleHelmRelease(config *fluxcdconf.FluxCD,
+	params *CreateClusterParams) *unstructured.Unstructured {
+	valuesFiles := make([]interface{}, 0, len(params.ValueFiles))
+	for _, file := range params.ValueFiles {
+		valuesFiles = append(valuesFiles, file)
+	}
+	spec := map[string]interface{}{
+		"interval":         config.Interval,
+		"releaseName":      params.Cluster,
+		"targetNamespace":  params.Namespace,
+		"storageNamespace": params.Namespace,
+		"chart": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"chart": "./",
+				"sourceRef": map[string]interface{}{
+					"kind":      _kindGitRepository,
+					"name":      params.Cluster,
+					"namespace": config.Namespace,
+				},
+				"valuesFiles": valuesFiles,
+				// rebuild the chart for every commit of the gitops repo
+				"reconcileStrategy": "Revision",
+			},
+		},
+		"install": map[string]interface{}{
+			"createNamespace": true,
+		},
+	}
+	if config.Timeout != "" {
+		spec["timeout"] = config.Timeout
+	}
+	return f.assemble(GVRHelmRelease, _kindHelmRelease, config.Namespace, params.Cluster, spec)
+}
+
+func (f *fluxCD) assemble(gvr schema.GroupVersionResource, kind, namespace, name string,
+	spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": spec,
+	}}
+	obj.SetAPIVersion(gvr.GroupVersion().String())
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetLabels(map[string]string{
+		common.ClusterClusterLabelKey: name,
+	})
+	return obj
+}
+
+func (f *fluxCD) CreateCluster(ctx context.Context, params *CreateClusterParams) (err error) {
+	const op = "flux cd: create cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	config, err := f.getConfig(params.RegionEntity.Name)
+	if err != nil {
+		return err
+	}
+
+	objs := []struct {
+		gvr schema.GroupVersionResource
+		obj *unstructured.Unstructured
+	}{
+		{gvr: GVRGitRepository, obj: f.assembleGitRepository(config, params)},
+		{gvr: GVRHelmRelease, obj: f.assembleHelmRelease(config, params)},
+	}
+	return f.withClient(params.RegionEntity.ID, func(client dynamic.Interface) error {
+		for _, o := range objs {
+			_, err := client.Resource(o.gvr).Namespace(config.Namespace).
+				Create(ctx, o.obj, metav1.CreateOptions{})
+			if err != nil && !k8serrors.IsAlreadyExists(err) {
+				return perror.Wrapf(herrors.ErrHTTPRequestFailed,
+					"failed to create %s for cluster %s: %v", o.gvr.Resource, params.Cluster, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (f *fluxCD) DeployCluster(ctx context.Context, params *DeployClusterParams) (err error) {
+	const op = "flux cd: deploy cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	config, err := f.getConfig(params.Region)
+	if err != nil {
+		return err
+	}
+	regionEntity, err := f.regionMgr.GetRegionEntity(ctx, params.Region)
+	if err != nil {
+		return err
+	}
+
+	requestedAt := time.Now().Format(time.RFC3339Nano)
+	annotations := map[string]interface{}{
+		"annotations": map[string]interface{}{
+			_fluxRequestedAt: requestedAt,
+		},
+	}
+	// pin the source to the revision, so that exactly this commit is deployed
+	sourcePatch, err := json.Marshal(map[string]interface{}{
+		"metadata": annotations,
+		"spec": map[string]interface{}{
+			"ref": map[string]interface{}{
+				"commit": params.Revision,
+			},
+		},
+	})
+	if err != nil {
+		return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	releasePatch, err := json.Marshal(map[string]interface{}{
+		"metadata": annotations,
+	})
+	if err != nil {
+		return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+
+	return f.withClient(regionEntity.ID, func(client dynamic.Interface) error {
+		if _, err := client.Resource(GVRGitRepository).Namespace(config.Namespace).Patch(ctx,
+			params.Cluster, types.MergePatchType, sourcePatch, metav1.PatchOptions{}); err != nil {
+			return f.wrapError(err, _kindGitRepository, params.Cluster)
+		}
+		if _, err := client.Resource(GVRHelmRelease).Namespace(config.Namespace).Patch(ctx,
+			params.Cluster, types.MergePatchType, releasePatch, metav1.PatchOptions{}); err != nil {
+			return f.wrapError(err, _kindHelmRelease, params.Cluster)
+		}
+		return nil
+	})
+}
+
+func (f *fluxCD) DeleteCluster(ctx context.Context, params *DeleteClusterParams) (err error) {
+	const op = "flux cd: delete cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	config, err := f.getConfig(params.Region)
+	if err != nil {
+		return err
+	}
+	regionEntity, err := f.regionMgr.GetRegionEntity(ctx, params.Region)
+	if err != nil {
+		return err
+	}
+
+	return f.withClient(regionEntity.ID, func(client dynamic.Interface) error {
+		releases := client.Resource(GVRHelmRelease).Namespace(config.Namespace)
+		sources := client.Resource(GVRGitRepository).Namespace(config.Namespace)
+
+		// 1. delete helm release and wait for helm controller to uninstall it
+		release, err := releases.Get(ctx, params.Cluster, metav1.GetOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return f.wrapError(err, _kindHelmRelease, params.Cluster)
+		}
+		if err == nil {
+			if err := releases.Delete(ctx, params.Cluster, metav1.DeleteOptions{}); err != nil &&
+				!k8serrors.IsNotFound(err) {
+				return f.wrapError(err, _kindHelmRelease, params.Cluster)
+			}
+			if err := f.waitDeleted(ctx, releases, params.Cluster, release.GetUID()); err != nil {
+				return err
+			}
+		}
+
+		// 2. delete git repository
+		if err := sources.Delete(ctx, params.Cluster, metav1.DeleteOptions{}); err != nil &&
+			!k8serrors.IsNotFound(err) {
+			return f.wrapError(err, _kindGitRepository, params.Cluster)
+		}
+		return nil
+	})
+}
+
+func (f *fluxCD) waitDeleted(ctx context.Context, resource dynamic.ResourceInterface,
+	name string, uid types.UID) error {
+	err := wait.PollImmediate(_fluxWaitInterval, _fluxWaitTimeout, func() (bool, error) {
+		log.Infof(ctx, "wait for helm release %v to be deleted", name)
+		obj, err := resource.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, nil
+		}
+		if obj.GetUID() != uid {
+			return false, perror.Wrap(herrors.ErrNameConflict,
+				"the cluster has been recreated with the same name")
+		}
+		return false, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"timeout waiting for helm release %v to be deleted", name)
+	}
+	return err
+}
+
+func (f *fluxCD) wrapError(err error, kind, cluster string) error {
+	if k8serrors.IsNotFound(err) {
+		source := herrors.HelmReleaseInFlux
+		if kind == _kindGitRepository {
+			source = herrors.GitRepositoryInFlux
+		}
+		return herrors.NewErrNotFound(source, fmt.Sprintf("%s of cluster %s not found", kind, cluster))
+	}
+	return perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to operate %s of cluster %s: %v",
+		kind, cluster, err)
+}
+
+func (f *fluxCD) getObjects(ctx context.Context, regionEntity *regionmodels.RegionEntity,
+	cluster string) (release, source *unstructured.Unstructured, err error) {
+	config, err := f.getConfig(regionEntity.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = f.withClient(regionEntity.ID, func(client dynamic.Interface) error {
+		release, err = client.Resource(GVRHelmRelease).Namespace(config.Namespace).
+			Get(ctx, cluster, metav1.GetOptions{})
+		if err != nil {
+			return f.wrapError(err, _kindHelmRelease, cluster)
+		}
+		source, err = client.Resource(GVRGitRepository).Namespace(config.Namespace).
+			Get(ctx, cluster, metav1.GetOptions{})
+		if err != nil {
+			return f.wrapError(err, _kindGitRepository, cluster)
+		}
+		return nil
+	})
+	return release, source, err
+}
+
+// GetClusterState fetches status of cluster from the HelmRelease and the workloads in informers
+func (f *fluxCD) GetClusterState(ctx context.Context,
+	params *GetClusterStateV2Params) (*ClusterStateV2, error) {
+	const op = "flux cd: get cluster status"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	release, source, err := f.getObjects(ctx, params.RegionEntity, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &ClusterStateV2{
+		Status: string(helmReleaseHealth(release)),
+	}
+	if status.Status != string(health.HealthStatusHealthy) {
+		return status, nil
+	}
+
+	lastConfigCommit, err := f.clusterGitRepo.GetConfigCommit(ctx, params.Application, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	revision := sourceRevision(source)
+	if lastConfigCommit.Master != revision {
+		status.Status = string(health.HealthStatusProgressing)
+		log.Warningf(ctx,
+			"current revision(%s) is not consistent with gitops repo commit(%s)",
+			revision, lastConfigCommit.Master)
+		return status, nil
+	}
+
+	isHealthy, err := f.isHealthy(ctx, params.RegionEntity, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	if !isHealthy {
+		status.Status = string(health.HealthStatusProgressing)
+	}
+	return status, nil
+}
+
+// helmReleaseHealth maps the Ready condition of HelmRelease into health status
+func helmReleaseHealth(release *unstructured.Unstructured) health.HealthStatusCode {
+	if release.GetDeletionTimestamp() != nil {
+		return health.HealthStatusProgressing
+	}
+	observedGeneration, _, _ := unstructured.NestedInt64(release.Object, "status", "observedGeneration")
+	if observedGeneration != release.GetGeneration() {
+		return health.HealthStatusProgressing
+	}
+	suspend, _, _ := unstructured.NestedBool(release.Object, "spec", "suspend")
+	if suspend {
+		return health.HealthStatusSuspended
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(release.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != _fluxConditionReady {
+			continue
+		}
+		switch condition["status"] {
+		case string(metav1.ConditionTrue):
+			attempted, _, _ := unstructured.NestedString(release.Object, "status", "lastAttemptedRevision")
+			applied, _, _ := unstructured.NestedString(release.Object, "status", "lastAppliedRevision")
+			if attempted != applied {
+				return health.HealthStatusProgressing
+			}
+			return health.HealthStatusHealthy
+		case string(metav1.ConditionFalse):
+			reason, _ := condition["reason"].(string)
+			if strings.HasSuffix(reason, "Failed") {
+				return health.HealthStatusDegraded
+			}
+			return health.HealthStatusProgressing
+		default:
+			return health.HealthStatusProgressing
+		}
+	}
+	return health.HealthStatusProgressing
+}
+
+// sourceRevision returns commit of the artifact in GitRepository,
+// the revision looks like main/<sha> or main@sha1:<sha>
+func sourceRevision(source *unstructured.Unstructured) string {
+	revision, _, _ := unstructured.NestedString(source.Object, "status", "artifact", "revision")
+	if i := strings.LastIndexAny(revision, "/:"); i >= 0 {
+		return revision[i+1:]
+	}
+	return revision
+}
+
+func (f *fluxCD) GetResourceTree(ctx context.Context,
+	params *GetResourceTreeParams) ([]ResourceNode, error) {
+	const op = "flux cd: get resource tree"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, _, err := f.getObjects(ctx, params.RegionEntity, params.Cluster); err != nil {
+		return nil, err
+	}
+	return f.resourceTree(ctx, params.RegionEntity, params.Cluster)
+}
@@ -0,0 +1,76 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"testing"
+
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func helmRelease(generation, observedGeneration int64, status, reason,
+	attempted, applied string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"observedGeneration":    observedGeneration,
+			"lastAttemptedRevision": attempted,
+			"lastAppliedRevision":   applied,
+			"conditions": []interface{}{
+				map[string]interface{}{
+					"type":   "Ready",
+					"status": status,
+					"reason": reason,
+				},
+			},
+		},
+	}}
+	obj.SetGeneration(generation)
+	return obj
+}
+
+func TestHelmReleaseHealth(t *testing.T) {
+	cases := []struct {
+		release *unstructured.Unstructured
+		want    health.HealthStatusCode
+	}{
+		{helmRelease(2, 2, "True", "ReconciliationSucceeded", "0.1.0", "0.1.0"), health.HealthStatusHealthy},
+		{helmRelease(2, 1, "True", "ReconciliationSucceeded", "0.1.0", "0.1.0"), health.HealthStatusProgressing},
+		{helmRelease(2, 2, "True", "ReconciliationSucceeded", "0.1.1", "0.1.0"), health.HealthStatusProgressing},
+		{helmRelease(2, 2, "False", "UpgradeFailed", "0.1.1", "0.1.0"), health.HealthStatusDegraded},
+		{helmRelease(2, 2, "Unknown", "Progressing", "0.1.1", "0.1.0"), health.HealthStatusProgressing},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, helmReleaseHealth(c.release))
+	}
+}
+
+func TestSourceRevision(t *testing.T) {
+	for revision, want := range map[string]string{
+		"main/4f2a5c":      "4f2a5c",
+		"main@sha1:4f2a5c": "4f2a5c",
+		"4f2a5c":           "4f2a5c",
+	} {
+		source := &unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{
+				"artifact": map[string]interface{}{
+					"revision": revision,
+				},
+			},
+		}}
+		assert.Equal(t, want, sourceRevision(source))
+	}
+}
@@ -0,0 +1,477 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+
+	"github.com/argoproj/gitops-engine/pkg/health"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/kubeclient"
+	helmcdconf "github.com/horizoncd/horizon/pkg/config/helmcd"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/regioninformers"
+	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	_helmDriver = "secrets"
+	// annotations of the chart recording what is deployed by a release revision
+	_helmAnnotationRevision    = "horizon.io/revision"
+	_helmAnnotationPipelinerun = "horizon.io/pipelinerun"
+)
+
+// helmCD renders the chart of cluster and applies it by helm directly, without any gitops controller.
+// Release revisions of helm are the deploy history of cluster, so that cluster can be rolled back
+// to the revision deployed by a pipelinerun.
+type helmCD struct {
+	*informerResources
+	clusterGitRepo     gitrepo.ClusterGitRepo
+	templateRepo       templaterepo.TemplateRepo
+	templateReleaseMgr trmanager.Manager
+	regionMgr          regionmanager.Manager
+	mapper             helmcdconf.RegionMapper
+}
+
+var _ RollbackCD = (*helmCD)(nil)
+
+func newHelmCD(informerFactories *regioninformers.RegionInformers, clusterGitRepo gitrepo.ClusterGitRepo,
+	templateRepo templaterepo.TemplateRepo, templateReleaseMgr trmanager.Manager,
+	regionMgr regionmanager.Manager, mapper helmcdconf.RegionMapper) *helmCD {
+	return &helmCD{
+		informerResources: &informerResources{
+			kubeClientFactory: kubeclient.Fty,
+			informerFactories: informerFactories,
+		},
+		clusterGitRepo:     clusterGitRepo,
+		templateRepo:       templateRepo,
+		templateReleaseMgr: templateReleaseMgr,
+		regionMgr:          regionMgr,
+		mapper:             mapper,
+	}
+}
+
+func (h *helmCD) getConfig(region string) (*helmcdconf.HelmCD, error) {
+	config, ok := h.mapper[region]
+	if !ok {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "helm cd is not configured for region %s", region)
+	}
+	if config == nil {
+		config = &helmcdconf.HelmCD{}
+	}
+	return config, nil
+}
+
+// actionConfig returns helm configuration operating releases in namespace,
+// releases in all namespaces are visible if namespace is empty
+func (h *helmCD) actionConfig(ctx context.Context, regionEntity *regionmodels.RegionEntity,
+	namespace string) (*action.Configuration, error) {
+	restConfig, err := h.informerFactories.GetRestConfig(regionEntity.ID)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &action.Configuration{}
+	if err := cfg.Init(&restClientGetter{config: restConfig, namespace: namespace}, namespace, _helmDriver,
+		func(format string, v ...interface{}) {
+			log.Debugf(ctx, format, v...)
+		}); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to init helm: %v", err)
+	}
+	if kubeClient, ok := cfg.KubeClient.(*kube.Client); ok {
+		kubeClient.Namespace = namespace
+	}
+	return cfg, nil
+}
+
+// lastRelease returns the latest release of cluster in all namespaces
+func (h *helmCD) lastRelease(ctx context.Context, regionEntity *regionmodels.RegionEntity,
+	cluster string) (*release.Release, error) {
+	cfg, err := h.actionConfig(ctx, regionEntity, "")
+	if err != nil {
+		return nil, err
+	}
+	rel, err := cfg.Releases.Last(cluster)
+	if err != nil {
+		if stderrors.Is(err, driver.ErrReleaseNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.ReleaseInHelm,
+				fmt.Sprintf("release of cluster %s not found", cluster))
+		}
+		return nil, perror.Wrapf(herrors.ErrHTTPRequestFailed,
+			"failed to get release of cluster %s: %v", cluster, err)
+	}
+	return rel, nil
+}
+
+// CreateCluster does nothing, the release is installed when cluster is deployed at the first time
+func (h *helmCD) CreateCluster(ctx context.Context, params *CreateClusterParams) error {
+	_, err := h.getConfig(params.RegionEntity.Name)
+	return err
+}
+
+func (h *helmCD) DeployCluster(ctx context.Context, params *DeployClusterParams) (err error) {
+	const op = "helm cd: deploy cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	config, err := h.getConfig(params.Region)
+	if err != nil {
+		return err
+	}
+	regionEntity, err := h.regionMgr.GetRegionEntity(ctx, params.Region)
+	if err != nil {
+		return err
+	}
+
+	namespace, err := h.getNamespace(ctx, params)
+	if err != nil {
+		return err
+	}
+	chrt, values, err := h.renderChart(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := h.actionConfig(ctx, regionEntity, namespace)
+	if err != nil {
+		return err
+	}
+	_, err = cfg.Releases.Last(params.Cluster)
+	if err != nil && !stderrors.Is(err, driver.ErrReleaseNotFound) {
+		return perror.Wrapf(herrors.ErrHTTPRequestFailed,
+			"failed to get release of cluster %s: %v", params.Cluster, err)
+	}
+
+	if err != nil {
+		install := action.NewInstall(cfg)
+		install.ReleaseName = params.Cluster
+		install.Namespace = namespace
+		install.CreateNamespace = true
+		install.Timeout = config.Timeout
+		_, err = install.Run(chrt, values)
+	} else {
+		upgrade := action.NewUpgrade(cfg)
+		upgrade.Namespace = namespace
+		upgrade.MaxHistory = config.MaxHistory
+		upgrade.Timeout = config.Timeout
+		upgrade.Description = fmt.Sprintf("Deploy revision %s", params.Revision)
+		_, err = upgrade.Run(params.Cluster, chrt, values)
+	}
+	if err != nil {
+		return perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"failed to deploy release of cluster %s: %v", params.Cluster, err)
+	}
+	return nil
+}
+
+// RollbackCluster rolls back to the release revision deployed by the target pipelinerun,
+// and deploys the revision in params if no such release revision is kept.
+func (h *helmCD) RollbackCluster(ctx context.Context, params *RollbackClusterParams) (err error) {
+	const op = "helm cd: rollback cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	config, err := h.getConfig(params.Region)
+	if err != nil {
+		return err
+	}
+	regionEntity, err := h.regionMgr.GetRegionEntity(ctx, params.Region)
+	if err != nil {
+		return err
+	}
+	namespace, err := h.getNamespace(ctx, &params.DeployClusterParams)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := h.actionConfig(ctx, regionEntity, namespace)
+	if err != nil {
+		return err
+	}
+	history, err := cfg.Releases.History(params.Cluster)
+	if err != nil && !stderrors.Is(err, driver.ErrReleaseNotFound) {
+		return perror.Wrapf(herrors.ErrHTTPRequestFailed,
+			"failed to get release history of cluster %s: %v", params.Cluster, err)
+	}
+
+	target := findReleaseByPipelinerun(history, params.TargetPipelinerunID)
+	if target == nil {
+		log.Infof(ctx, "release deployed by pipelinerun %d not found, deploy revision %s instead",
+			params.TargetPipelinerunID, params.Revision)
+		return h.DeployCluster(ctx, &params.DeployClusterParams)
+	}
+
+	rollback := action.NewRollback(cfg)
+	rollback.Version = target.Version
+	rollback.MaxHistory = config.MaxHistory
+	rollback.Timeout = config.Timeout
+	if err := rollback.Run(params.Cluster); err != nil {
+		return perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"failed to rollback release of cluster %s to version %d: %v", params.Cluster, target.Version, err)
+	}
+	return nil
+}
+
+// findReleaseByPipelinerun finds the latest successful release revision deployed by the pipelinerun
+func findReleaseByPipelinerun(history []*release.Release, pipelinerunID uint) *release.Release {
+	var target *release.Release
+	for _, rel := range history {
+		if rel.Chart == nil || rel.Chart.Metadata == nil || rel.Info == nil {
+			continue
+		}
+		if rel.Info.Status != release.StatusDeployed && rel.Info.Status != release.StatusSuperseded {
+			continue
+		}
+		if rel.Chart.Metadata.Annotations[_helmAnnotationPipelinerun] != strconv.Itoa(int(pipelinerunID)) {
+			continue
+		}
+		if target == nil || rel.Version > target.Version {
+			target = rel
+		}
+	}
+	return target
+}
+
+func (h *helmCD) getNamespace(ctx context.Context, params *DeployClusterParams) (string, error) {
+	envValue, err := h.clusterGitRepo.GetEnvValue(ctx, params.Application, params.Cluster, params.Template)
+	if err != nil {
+		return "", err
+	}
+	if envValue == nil || envValue.Namespace == "" {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "namespace of cluster %s is empty", params.Cluster)
+	}
+	return envValue.Namespace, nil
+}
+
+// renderChart assembles the chart of cluster as the gitops repo does,
+// the template chart is a dependency of the cluster chart, and values are read from the value files.
+func (h *helmCD) renderChart(ctx context.Context,
+	params *DeployClusterParams) (*chart.Chart, map[string]interface{}, error) {
+	tr, err := h.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, params.Template, params.TemplateRelease)
+	if err != nil {
+		return nil, nil, err
+	}
+	templateChart, err := h.templateRepo.GetChart(tr.ChartName, tr.ChartVersion, tr.LastSyncAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	clusterTemplate, err := h.clusterGitRepo.GetClusterTemplate(ctx, params.Application, params.Cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	valueFiles, err := h.clusterGitRepo.GetValueFiles(ctx, params.Application, params.Cluster, params.Revision)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := map[string]interface{}{}
+	for _, file := range valueFiles {
+		content, err := yaml.Marshal(file.Content)
+		if err != nil {
+			return nil, nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"failed to marshal value file %s: %v", file.FileName, err)
+		}
+		fileValues, err := chartutil.ReadValues(content)
+		if err != nil {
+			return nil, nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"failed to read value file %s: %v", file.FileName, err)
+		}
+		values = mergeValues(values, fileValues)
+	}
+
+	// the template chart may be cached, so copy it before renaming
+	subChart := *templateChart
+	metadata := *templateChart.Metadata
+	metadata.Name = clusterTemplate.Name
+	subChart.Metadata = &metadata
+
+	clusterChart := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       params.Cluster,
+			Version:    templateChart.Metadata.Version,
+			AppVersion: params.Revision,
+			Annotations: map[string]string{
+				_helmAnnotationRevision:    params.Revision,
+				_helmAnnotationPipelinerun: strconv.Itoa(int(params.PipelinerunID)),
+			},
+		},
+	}
+	clusterChart.AddDependency(&subChart)
+	return clusterChart, values, nil
+}
+
+// mergeValues merges src into dst, values in src take precedence as helm does for multiple value files
+func mergeValues(dst, src map[string]interface{}) map[string]interface{} {
+	for k, v := range src {
+		if srcMap, ok := v.(map[string]interface{}); ok {
+			if dstMap, ok := dst[k].(map[string]interface{}); ok {
+				dst[k] = mergeValues(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+	return dst
+}
+
+func (h *helmCD) DeleteCluster(ctx context.Context, params *DeleteClusterParams) (err error) {
+	const op = "helm cd: delete cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	config, err := h.getConfig(params.Region)
+	if err != nil {
+		return err
+	}
+	regionEntity, err := h.regionMgr.GetRegionEntity(ctx, params.Region)
+	if err != nil {
+		return err
+	}
+
+	rel, err := h.lastRelease(ctx, regionEntity, params.Cluster)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil
+		}
+		return err
+	}
+
+	cfg, err := h.actionConfig(ctx, regionEntity, rel.Namespace)
+	if err != nil {
+		return err
+	}
+	uninstall := action.NewUninstall(cfg)
+	uninstall.Timeout = config.Timeout
+	if _, err := uninstall.Run(params.Cluster); err != nil {
+		if stderrors.Is(err, driver.ErrReleaseNotFound) {
+			return nil
+		}
+		return perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"failed to uninstall release of cluster %s: %v", params.Cluster, err)
+	}
+	return nil
+}
+
+// GetClusterState fetches status of cluster from the latest release and the workloads in informers
+func (h *helmCD) GetClusterState(ctx context.Context,
+	params *GetClusterStateV2Params) (*ClusterStateV2, error) {
+	const op = "helm cd: get cluster status"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	rel, err := h.lastRelease(ctx, params.RegionEntity, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &ClusterStateV2{
+		Status: string(releaseHealth(rel)),
+	}
+	if status.Status != string(health.HealthStatusHealthy) {
+		return status, nil
+	}
+
+	isHealthy, err := h.isHealthy(ctx, params.RegionEntity, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	if !isHealthy {
+		status.Status = string(health.HealthStatusProgressing)
+	}
+	return status, nil
+}
+
+// releaseHealth maps the status of release into health status
+func releaseHealth(rel *release.Release) health.HealthStatusCode {
+	if rel.Info == nil {
+		return health.HealthStatusUnknown
+	}
+	switch rel.Info.Status {
+	case release.StatusDeployed:
+		return health.HealthStatusHealthy
+	case release.StatusFailed:
+		return health.HealthStatusDegraded
+	case release.StatusUninstalled:
+		return health.HealthStatusMissing
+	case release.StatusPendingInstall, release.StatusPendingUpgrade,
+		release.StatusPendingRollback, release.StatusUninstalling:
+		return health.HealthStatusProgressing
+	default:
+		return health.HealthStatusUnknown
+	}
+}
+
+func (h *helmCD) GetResourceTree(ctx context.Context,
+	params *GetResourceTreeParams) ([]ResourceNode, error) {
+	const op = "helm cd: get resource tree"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := h.lastRelease(ctx, params.RegionEntity, params.Cluster); err != nil {
+		return nil, err
+	}
+	return h.resourceTree(ctx, params.RegionEntity, params.Cluster)
+}
+
+// restClientGetter adapts rest config of region to the getter required by helm
+type restClientGetter struct {
+	config    *rest.Config
+	namespace string
+}
+
+func (g *restClientGetter) ToRESTConfig() (*rest.Config, error) {
+	return rest.CopyConfig(g.config), nil
+}
+
+func (g *restClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(g.config)
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewMemCacheClient(discoveryClient), nil
+}
+
+func (g *restClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	discoveryClient, err := g.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient)
+	return restmapper.NewShortcutExpander(mapper, discoveryClient), nil
+}
+
+func (g *restClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	return clientcmd.NewDefaultClientConfig(*clientcmdapi.NewConfig(), &clientcmd.ConfigOverrides{
+		Context: clientcmdapi.Context{Namespace: g.namespace},
+	})
+}
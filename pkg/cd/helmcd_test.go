@@ -0,0 +1,74 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func releaseOf(version int, status release.Status, pipelinerun string) *release.Release {
+	return &release.Release{
+		Version: version,
+		Info:    &release.Info{Status: status},
+		Chart: &chart.Chart{Metadata: &chart.Metadata{
+			Annotations: map[string]string{_helmAnnotationPipelinerun: pipelinerun},
+		}},
+	}
+}
+
+func TestFindReleaseByPipelinerun(t *testing.T) {
+	history := []*release.Release{
+		releaseOf(1, release.StatusSuperseded, "1"),
+		releaseOf(2, release.StatusSuperseded, "2"),
+		releaseOf(3, release.StatusSuperseded, "1"),
+		releaseOf(4, release.StatusFailed, "1"),
+		releaseOf(5, release.StatusDeployed, "3"),
+	}
+	assert.Equal(t, 3, findReleaseByPipelinerun(history, 1).Version)
+	assert.Equal(t, 2, findReleaseByPipelinerun(history, 2).Version)
+	assert.Nil(t, findReleaseByPipelinerun(history, 4))
+}
+
+func TestMergeValues(t *testing.T) {
+	dst := map[string]interface{}{
+		"app": map[string]interface{}{
+			"image":    "nginx:1.0",
+			"replicas": 1,
+		},
+		"env": "test",
+	}
+	src := map[string]interface{}{
+		"app": map[string]interface{}{
+			"image": "nginx:1.1",
+		},
+		"horizon": map[string]interface{}{
+			"cluster": "cluster",
+		},
+	}
+	assert.Equal(t, map[string]interface{}{
+		"app": map[string]interface{}{
+			"image":    "nginx:1.1",
+			"replicas": 1,
+		},
+		"env": "test",
+		"horizon": map[string]interface{}{
+			"cluster": "cluster",
+		},
+	}, mergeValues(dst, src))
+}
@@ -0,0 +1,313 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	applicationV1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/kubeclient"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/regioninformers"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"github.com/horizoncd/horizon/pkg/workload"
+	"github.com/horizoncd/horizon/pkg/workload/getter"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+)
+
+// informerResources computes resource tree, step and health of cluster from the region informers.
+// It's shared by cd engines which don't track the resources they applied, such as flux and helm.
+type informerResources struct {
+	kubeClientFactory kubeclient.Factory
+	informerFactories *regioninformers.RegionInformers
+}
+
+func (r *informerResources) resourceTree(ctx context.Context, regionEntity *regionmodels.RegionEntity,
+	cluster string) ([]ResourceNode, error) {
+	nodes, err := r.listNodes(ctx, regionEntity, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := r.listPods(ctx, regionEntity, cluster)
+	if err != nil {
+		return nil, err
+	}
+	podMap := make(map[string]corev1.Pod, len(pods))
+	for _, pod := range pods {
+		podMap[string(pod.UID)] = pod
+	}
+
+	resourceTree := make([]ResourceNode, 0, len(nodes))
+	for _, node := range nodes {
+		n := ResourceNode{ResourceNode: node}
+		if pod, ok := podMap[node.UID]; ok {
+			t := Compact(pod)
+			n.PodDetail = &t
+		}
+		resourceTree = append(resourceTree, n)
+	}
+	return resourceTree, nil
+}
+
+// isHealthy checks health of workloads in the cluster
+func (r *informerResources) isHealthy(ctx context.Context, regionEntity *regionmodels.RegionEntity,
+	cluster string) (bool, error) {
+	_, kubeClient, err := r.kubeClientFactory.GetByK8SServer(regionEntity.Server, regionEntity.Certificate)
+	if err != nil {
+		return false, err
+	}
+
+	nodes, err := r.listNodes(ctx, regionEntity, cluster)
+	if err != nil {
+		return false, err
+	}
+
+	isHealthy := true
+	for i := range nodes {
+		node := &nodes[i]
+		workload.LoopAbilities(func(workload workload.Workload) bool {
+			if !workload.MatchGK(schema.GroupKind{Group: node.Group, Kind: node.Kind}) {
+				return true
+			}
+			gt := getter.New(workload)
+			nodeHealthy, err := gt.IsHealthy(node, kubeClient)
+			if err != nil {
+				return true
+			}
+			log.Debugf(ctx, "[cd get status from informers] node(%v) kind(%v) isHealthy(%v)",
+				node.Name, node.Kind, nodeHealthy)
+			isHealthy = isHealthy && nodeHealthy
+			return isHealthy
+		})
+		if !isHealthy {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// listNodes lists resources of cluster from the region informers,
+// the resources watched are registered by workloads
+func (r *informerResources) listNodes(ctx context.Context, regionEntity *regionmodels.RegionEntity,
+	cluster string) ([]applicationV1alpha1.ResourceNode, error) {
+	selector := labels.SelectorFromSet(labels.Set{common.ClusterClusterLabelKey: cluster})
+
+	objs := make([]*unstructured.Unstructured, 0, 8)
+	uids := make(map[types.UID]struct{})
+	visited := make(map[schema.GroupVersionResource]struct{})
+	for _, resource := range workload.Resources {
+		if _, ok := visited[resource.GVR]; ok {
+			continue
+		}
+		visited[resource.GVR] = struct{}{}
+
+		err := r.informerFactories.GetDynamicInformer(regionEntity.ID, resource.GVR,
+			func(informer informers.GenericInformer) error {
+				list, err := informer.Lister().List(selector)
+				if err != nil {
+					return err
+				}
+				for _, obj := range list {
+					un, ok := obj.(*unstructured.Unstructured)
+					if !ok {
+						continue
+					}
+					// resource may be served by multiple versions, such as cronjobs
+					if _, ok := uids[un.GetUID()]; ok {
+						continue
+					}
+					uids[un.GetUID()] = struct{}{}
+					objs = append(objs, un)
+				}
+				return nil
+			})
+		if err != nil {
+			// resource may not be installed in the region, such as rollouts
+			log.Debugf(ctx, "failed to list %v: %v", resource.GVR, err)
+			continue
+		}
+	}
+
+	nodes := make([]applicationV1alpha1.ResourceNode, 0, len(objs))
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		createdAt := obj.GetCreationTimestamp()
+		node := applicationV1alpha1.ResourceNode{
+			ResourceRef: applicationV1alpha1.ResourceRef{
+				Group:     gvk.Group,
+				Version:   gvk.Version,
+				Kind:      gvk.Kind,
+				Namespace: obj.GetNamespace(),
+				Name:      obj.GetName(),
+				UID:       string(obj.GetUID()),
+			},
+			ResourceVersion: obj.GetResourceVersion(),
+			CreatedAt:       &createdAt,
+		}
+		for _, owner := range obj.GetOwnerReferences() {
+			// only link to parents in the tree, so that the tree can be traversed
+			if _, ok := uids[owner.UID]; !ok {
+				continue
+			}
+			gv, _ := schema.ParseGroupVersion(owner.APIVersion)
+			node.ParentRefs = append(node.ParentRefs, applicationV1alpha1.ResourceRef{
+				Group:     gv.Group,
+				Version:   gv.Version,
+				Kind:      owner.Kind,
+				Namespace: obj.GetNamespace(),
+				Name:      owner.Name,
+				UID:       string(owner.UID),
+			})
+		}
+		if status, err := health.GetResourceHealth(obj, nil); err == nil && status != nil {
+			node.Health = &applicationV1alpha1.HealthStatus{
+				Status:  status.Status,
+				Message: status.Message,
+			}
+		}
+		nodes = append(nodes, node)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Kind != nodes[j].Kind {
+			return nodes[i].Kind < nodes[j].Kind
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+	return nodes, nil
+}
+
+func (r *informerResources) listPods(ctx context.Context, regionEntity *regionmodels.RegionEntity,
+	cluster string) ([]corev1.Pod, error) {
+	selector := labels.SelectorFromSet(labels.Set{common.ClusterClusterLabelKey: cluster})
+	pods := make([]corev1.Pod, 0)
+	err := r.informerFactories.GetDynamicInformer(regionEntity.ID, corev1.SchemeGroupVersion.WithResource("pods"),
+		func(informer informers.GenericInformer) error {
+			list, err := informer.Lister().List(selector)
+			if err != nil {
+				return err
+			}
+			for _, obj := range list {
+				un, ok := obj.(*unstructured.Unstructured)
+				if !ok {
+					continue
+				}
+				var pod corev1.Pod
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(un.Object, &pod); err != nil {
+					log.Warningf(ctx, "failed to convert pod %v: %v", un.GetName(), err)
+					continue
+				}
+				pods = append(pods, pod)
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ResourceInK8S,
+			fmt.Sprintf("failed to list pods of cluster %s: %v", cluster, err))
+	}
+	return pods, nil
+}
+
+func (r *informerResources) GetStep(ctx context.Context, params *GetStepParams) (*Step, error) {
+	const op = "cd: get step from informers"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	_, kubeClient, err := r.kubeClientFactory.GetByK8SServer(params.RegionEntity.Server,
+		params.RegionEntity.Certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := r.listNodes(ctx, params.RegionEntity, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	step := (*workload.Step)(nil)
+	for i := range nodes {
+		node := &nodes[i]
+		workload.LoopAbilities(func(workload workload.Workload) bool {
+			if !workload.MatchGK(schema.GroupKind{Group: node.Group, Kind: node.Kind}) {
+				return true
+			}
+			gt := getter.New(workload)
+			step, err = gt.GetSteps(node, kubeClient)
+			if err != nil {
+				return true
+			}
+			return false
+		})
+		if step != nil {
+			break
+		}
+	}
+
+	if step == nil {
+		return &Step{
+			Index:        0,
+			Total:        0,
+			Replicas:     []int{},
+			ManualPaused: false,
+			AutoPromote:  false,
+		}, nil
+	}
+
+	return &Step{
+		Index:        step.Index,
+		Total:        step.Total,
+		Replicas:     step.Replicas,
+		ManualPaused: step.ManualPaused,
+		AutoPromote:  step.AutoPromote,
+		Aborted:      step.Aborted,
+		Extra:        step.Extra,
+	}, nil
+}
+
+func (r *informerResources) GetPodEvents(ctx context.Context,
+	params *GetPodEventsParams) (events []Event, err error) {
+	const op = "cd: get cluster pod events from informers"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	_, kubeClient, err := r.kubeClientFactory.GetByK8SServer(params.RegionEntity.Server,
+		params.RegionEntity.Certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := r.listPods(ctx, params.RegionEntity, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods {
+		if pod.Namespace == params.Namespace && pod.Name == params.Pod {
+			return getPodEvents(ctx, kubeClient.Basic, params.Namespace, params.Pod)
+		}
+	}
+
+	return nil, herrors.NewErrNotFound(herrors.PodsInK8S, "pod does not exist")
+}
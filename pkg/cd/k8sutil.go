@@ -34,8 +34,10 @@ import (
 	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 	"github.com/horizoncd/horizon/pkg/workload"
+	"github.com/horizoncd/horizon/pkg/workload/getter"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
@@ -48,6 +50,7 @@ type K8sUtil interface {
 	Exec(ctx context.Context, params *ExecParams) (map[string]ExecResp, error)
 	DeletePods(ctx context.Context, params *DeletePodsParams) (map[string]OperationResult, error)
 	ExecuteAction(ctx context.Context, params *ExecuteActionParams) error
+	ListAnalysisRuns(ctx context.Context, params *ListAnalysisRunsParams) ([]AnalysisRun, error)
 	GetPodContainers(ctx context.Context, params *GetPodParams) ([]ContainerDetail, error)
 	GetPod(ctx context.Context, params *GetPodParams) (*corev1.Pod, error)
 	GetContainerLog(ctx context.Context, params *GetContainerLogParams) (<-chan string, error)
@@ -157,9 +160,21 @@ func (e *util) ExecuteAction(ctx context.Context,
 				fmt.Sprintf("failed to get %s(%s)", params.ResourceName, params.GVR.String()))
 		}
 
-		workload.LoopAbilities(func(workload workload.Workload) bool {
-			if workload.MatchGK(un.GroupVersionKind().GroupKind()) {
-				un, err = workload.Action(params.Action, un)
+		var (
+			creates    bool
+			createdGVR schema.GroupVersionResource
+			created    *unstructured.Unstructured
+		)
+		workload.LoopAbilities(func(w workload.Workload) bool {
+			if w.MatchGK(un.GroupVersionKind().GroupKind()) {
+				// some actions create new resources, such as triggering a cronjob
+				if creator, ok := w.(workload.ActionCreator); ok {
+					createdGVR, created, creates, err = creator.CreateByAction(params.Action, un)
+					if err != nil || creates {
+						return false
+					}
+				}
+				un, err = w.Action(params.Action, un)
 				return false
 			}
 			return true
@@ -169,28 +184,42 @@ func (e *util) ExecuteAction(ctx context.Context,
 				params.Action, params.ResourceName, params.GVR.String())
 		}
 
-		un, err = clientset.Resource(params.GVR).Namespace(params.Namespace).
-			Update(ctx, un, metav1.UpdateOptions{})
-		log.Debugf(ctx, "update %s(%s) with %s: %v", params.ResourceName,
-			params.GVR.String(), params.Action, un)
-		if err != nil {
-			return herrors.NewErrUpdateFailed(herrors.ResourceInK8S,
-				fmt.Sprintf("failed to update gvr(%s), ns(%s), name(%s)",
-					params.GVR.String(), params.Namespace, un.GetName()))
-		}
-		bts, err := json.Marshal(map[string]interface{}{
+		eventExtra := map[string]interface{}{
 			"action":       params.Action,
 			"gvr":          params.GVR.String(),
-			"resourceName": params.Namespace,
-		})
+			"resourceName": params.ResourceName,
+		}
+		if creates {
+			created, err = clientset.Resource(createdGVR).Namespace(params.Namespace).
+				Create(ctx, created, metav1.CreateOptions{})
+			if err != nil {
+				return herrors.NewErrCreateFailed(herrors.ResourceInK8S,
+					fmt.Sprintf("failed to create gvr(%s), ns(%s) for %s: %v",
+						createdGVR.String(), params.Namespace, params.Action, err))
+			}
+			log.Debugf(ctx, "create %s(%s) by %s of %s", created.GetName(),
+				createdGVR.String(), params.Action, params.ResourceName)
+			eventExtra["createdResourceName"] = created.GetName()
+		} else {
+			un, err = clientset.Resource(params.GVR).Namespace(params.Namespace).
+				Update(ctx, un, metav1.UpdateOptions{})
+			log.Debugf(ctx, "update %s(%s) with %s: %v", params.ResourceName,
+				params.GVR.String(), params.Action, un)
+			if err != nil {
+				return herrors.NewErrUpdateFailed(herrors.ResourceInK8S,
+					fmt.Sprintf("failed to update gvr(%s), ns(%s), name(%s)",
+						params.GVR.String(), params.Namespace, params.ResourceName))
+			}
+		}
+		bts, err := json.Marshal(eventExtra)
 		if err != nil {
 			log.Warningf(ctx, "failed to marshal event extra, err: %s", err.Error())
 		}
 		extra := string(bts)
 		if _, err = e.eventMgr.CreateEvent(ctx, &eventmodels.Event{
 			EventSummary: eventmodels.EventSummary{
-				ResourceType: common.ResourceApplication,
-				EventType:    eventmodels.ClusterRestarted,
+				ResourceType: common.ResourceCluster,
+				EventType:    eventmodels.ClusterAction,
 				ResourceID:   params.ClusterID,
 				Extra:        &extra,
 			},
@@ -203,6 +232,81 @@ func (e *util) ExecuteAction(ctx context.Context,
 	return err
 }
 
+// ListAnalysisRuns lists analysis runs of the current step of the rollout named after cluster
+func (e *util) ListAnalysisRuns(ctx context.Context,
+	params *ListAnalysisRunsParams) (runs []AnalysisRun, err error) {
+	const op = "cd: list analysis runs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	kubeClient := &kube.Client{}
+	if err := e.informerFactories.GetClientSet(params.RegionEntity.ID, func(clientset kubernetes.Interface) error {
+		kubeClient.Basic = clientset
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := e.informerFactories.GetDynamicClientSet(params.RegionEntity.ID,
+		func(clientset dynamic.Interface) error {
+			kubeClient.Dynamic = clientset
+			return nil
+		}); err != nil {
+		return nil, err
+	}
+
+	node := &applicationV1alpha1.ResourceNode{
+		ResourceRef: applicationV1alpha1.ResourceRef{
+			Group:     "argoproj.io",
+			Version:   "v1alpha1",
+			Kind:      "Rollout",
+			Namespace: params.Namespace,
+			Name:      params.Cluster,
+		},
+	}
+	var analysisRuns []workload.AnalysisRun
+	workload.LoopAbilities(func(w workload.Workload) bool {
+		if !w.MatchGK(schema.GroupKind{Group: node.Group, Kind: node.Kind}) {
+			return true
+		}
+		analysisRuns, err = getter.New(w).ListAnalysisRuns(node, kubeClient)
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	runs = make([]AnalysisRun, 0, len(analysisRuns))
+	for _, run := range analysisRuns {
+		metrics := make([]AnalysisMetric, 0, len(run.Metrics))
+		for _, metric := range run.Metrics {
+			measurements := make([]AnalysisMeasurement, 0, len(metric.Measurements))
+			for _, measurement := range metric.Measurements {
+				measurements = append(measurements, AnalysisMeasurement(measurement))
+			}
+			metrics = append(metrics, AnalysisMetric{
+				Name:         metric.Name,
+				Phase:        metric.Phase,
+				Message:      metric.Message,
+				Count:        metric.Count,
+				Successful:   metric.Successful,
+				Failed:       metric.Failed,
+				Inconclusive: metric.Inconclusive,
+				Error:        metric.Error,
+				Measurements: measurements,
+			})
+		}
+		runs = append(runs, AnalysisRun{
+			Name:      run.Name,
+			Type:      run.Type,
+			StepIndex: run.StepIndex,
+			Phase:     run.Phase,
+			Message:   run.Message,
+			StartedAt: run.StartedAt,
+			Metrics:   metrics,
+		})
+	}
+	return runs, nil
+}
+
 func (e *util) GetPodContainers(ctx context.Context,
 	params *GetPodParams) (containers []ContainerDetail, err error) {
 	pod, err := e.GetPod(ctx, params)
@@ -15,6 +15,8 @@
 package cd
 
 import (
+	"time"
+
 	applicationV1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
 	"github.com/argoproj/gitops-engine/pkg/health"
 	corev1 "k8s.io/api/core/v1"
@@ -63,6 +65,18 @@ type DeployClusterParams struct {
 	Cluster     string
 	Revision    string
 	Region      string
+
+	// fields below are required by cd which renders the chart by itself, such as helm
+	Application     string
+	Template        string
+	TemplateRelease string
+	PipelinerunID   uint
+}
+
+type RollbackClusterParams struct {
+	DeployClusterParams
+	// TargetPipelinerunID is the pipelinerun whose deployment the cluster is rolled back to
+	TargetPipelinerunID uint
 }
 
 type GetPodEventsParams struct {
@@ -101,6 +115,12 @@ type ExecuteActionParams struct {
 	ClusterID    uint
 }
 
+type ListAnalysisRunsParams struct {
+	RegionEntity *regionmodels.RegionEntity
+	Namespace    string
+	Cluster      string
+}
+
 type GetContainerLogParams struct {
 	RegionEntity *regionmodels.RegionEntity
 	Namespace    string
@@ -204,9 +224,40 @@ type Step struct {
 	Replicas     []int   `json:"replicas"`
 	ManualPaused bool    `json:"manualPaused"`
 	AutoPromote  bool    `json:"autoPromote"`
+	Aborted      bool    `json:"aborted"`
 	Extra        *string `json:"extra"`
 }
 
+type AnalysisRun struct {
+	Name      string           `json:"name"`
+	Type      string           `json:"type"`
+	StepIndex *int             `json:"stepIndex,omitempty"`
+	Phase     string           `json:"phase"`
+	Message   string           `json:"message,omitempty"`
+	StartedAt *time.Time       `json:"startedAt,omitempty"`
+	Metrics   []AnalysisMetric `json:"metrics"`
+}
+
+type AnalysisMetric struct {
+	Name         string                `json:"name"`
+	Phase        string                `json:"phase"`
+	Message      string                `json:"message,omitempty"`
+	Count        int                   `json:"count"`
+	Successful   int                   `json:"successful"`
+	Failed       int                   `json:"failed"`
+	Inconclusive int                   `json:"inconclusive"`
+	Error        int                   `json:"error"`
+	Measurements []AnalysisMeasurement `json:"measurements"`
+}
+
+type AnalysisMeasurement struct {
+	Phase      string     `json:"phase"`
+	Message    string     `json:"message,omitempty"`
+	Value      string     `json:"value,omitempty"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
 // ClusterVersion version information
 type ClusterVersion struct {
 	// Replicas the replicas of this revision
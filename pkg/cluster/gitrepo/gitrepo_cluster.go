@@ -121,6 +121,9 @@ type ClusterGitRepo interface {
 	GetCluster(ctx context.Context, application, cluster, templateName string) (*ClusterFiles, error)
 	GetClusterValueFiles(ctx context.Context,
 		application, cluster string) ([]ClusterValueFile, error)
+	// GetValueFiles returns value files used to render the chart at the revision in order,
+	// the default branch is used if revision is empty, and files not existed are skipped
+	GetValueFiles(ctx context.Context, application, cluster, revision string) ([]ClusterValueFile, error)
 	// GetClusterTemplate parses cluster's template name and release from GitopsFileChart
 	GetClusterTemplate(ctx context.Context, application, cluster string) (*ClusterTemplate, error)
 	CreateCluster(ctx context.Context, params *CreateClusterParams) error
@@ -397,6 +400,52 @@ func (g *clusterGitopsRepo) GetClusterValueFiles(ctx context.Context,
 	return clusterValueFiles, nil
 }
 
+func (g *clusterGitopsRepo) GetValueFiles(ctx context.Context,
+	application, cluster, revision string) (_ []ClusterValueFile, err error) {
+	const op = "cluster git repo: get value files"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if revision == "" {
+		revision = g.defaultBranch
+	}
+	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	fileNames := g.GetRepoInfo(ctx, application, cluster).ValueFiles
+	cases := make([]ReadFileParam, 0, len(fileNames))
+	for _, fileName := range fileNames {
+		cases = append(cases, ReadFileParam{FileName: fileName})
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(cases))
+	for i := 0; i < len(cases); i++ {
+		go func(index int) {
+			defer wg.Done()
+			cases[index].Bytes, cases[index].Err = g.gitlabLib.GetFile(ctx, pid,
+				revision, cases[index].FileName)
+		}(i)
+	}
+	wg.Wait()
+
+	valueFiles := make([]ClusterValueFile, 0, len(cases))
+	for _, oneCase := range cases {
+		if oneCase.Err != nil {
+			if _, ok := perror.Cause(oneCase.Err).(*herrors.HorizonErrNotFound); ok {
+				continue
+			}
+			return nil, oneCase.Err
+		}
+		var out map[interface{}]interface{}
+		if err := yaml.Unmarshal(oneCase.Bytes, &out); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "yaml Unmarshal err, file = %s", oneCase.FileName)
+		}
+		valueFiles = append(valueFiles, ClusterValueFile{
+			FileName: oneCase.FileName,
+			Content:  out,
+		})
+	}
+	return valueFiles, nil
+}
+
 func (g *clusterGitopsRepo) GetClusterTemplate(ctx context.Context, application,
 	cluster string) (*ClusterTemplate, error) {
 	const op = "cluster git repo: get cluster template"
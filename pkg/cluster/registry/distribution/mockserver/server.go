@@ -0,0 +1,257 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mockserver
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	bearerToken       = "mock-bearer-token"
+	manifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+)
+
+// DistributionServer is a fake of the registry:2 server
+type DistributionServer struct {
+	R *mux.Router
+	// TokenAuth requires clients to authenticate with a bearer token issued by /token
+	TokenAuth bool
+	// Repositories maps repository name to its tags, which map tag to manifest digest
+	Repositories map[string]map[string]string
+	// Blobs maps digest to the content of manifests and blobs
+	Blobs map[string][]byte
+
+	uploadID int
+	lock     sync.Mutex
+}
+
+func NewDistributionServer() *DistributionServer {
+	r := mux.NewRouter()
+	s := &DistributionServer{
+		R:            r,
+		Repositories: map[string]map[string]string{},
+		Blobs:        map[string][]byte{},
+	}
+	r.Path("/token").Methods(http.MethodGet).HandlerFunc(s.Token)
+	r.Path("/v2/{repository:.+}/tags/list").Methods(http.MethodGet).HandlerFunc(s.auth(s.ListTags))
+	r.Path("/v2/{repository:.+}/manifests/{reference}").
+		Methods(http.MethodHead, http.MethodGet).HandlerFunc(s.auth(s.GetManifest))
+	r.Path("/v2/{repository:.+}/manifests/{reference}").
+		Methods(http.MethodPut).HandlerFunc(s.auth(s.PutManifest))
+	r.Path("/v2/{repository:.+}/manifests/{reference}").
+		Methods(http.MethodDelete).HandlerFunc(s.auth(s.DeleteManifest))
+	r.Path("/v2/{repository:.+}/blobs/uploads/").Methods(http.MethodPost).HandlerFunc(s.auth(s.StartUpload))
+	r.Path("/v2/{repository:.+}/blobs/uploads/{uuid}").Methods(http.MethodPut).HandlerFunc(s.auth(s.FinishUpload))
+	r.Path("/v2/{repository:.+}/blobs/{digest}").
+		Methods(http.MethodHead, http.MethodGet).HandlerFunc(s.auth(s.GetBlob))
+	return s
+}
+
+// PushImage pushes an image with a single layer of content to the repository,
+// tags with the same content and created time share the same digest
+func (s *DistributionServer) PushImage(repository, tag, content string, created time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.Repositories[repository]; !ok {
+		s.Repositories[repository] = map[string]string{}
+	}
+	config, _ := json.Marshal(map[string]interface{}{"created": created})
+	configDigest := s.putBlob(config)
+	layerDigest := s.putBlob([]byte(content))
+	manifest, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     manifestMediaType,
+		"config":        map[string]interface{}{"digest": configDigest, "size": len(config)},
+		"layers":        []interface{}{map[string]interface{}{"digest": layerDigest, "size": len(content)}},
+	})
+	s.Repositories[repository][tag] = s.putBlob(manifest)
+}
+
+func (s *DistributionServer) putBlob(content []byte) string {
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+	s.Blobs[digest] = content
+	return digest
+}
+
+func (s *DistributionServer) Token(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("scope") == "" {
+		s.responseError(w, http.StatusBadRequest, fmt.Errorf("scope is required"))
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"token": bearerToken})
+}
+
+func (s *DistributionServer) ListTags(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	repository := mux.Vars(r)["repository"]
+	repo, ok := s.Repositories[repository]
+	if !ok {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("repository %s not found", repository))
+		return
+	}
+	tags := make([]string, 0, len(repo))
+	for tag := range repo {
+		if tag > r.URL.Query().Get("last") {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	if n, err := strconv.Atoi(r.URL.Query().Get("n")); err == nil && n < len(tags) {
+		tags = tags[:n]
+		w.Header().Set("Link", fmt.Sprintf(`</v2/%s/tags/list?n=%d&last=%s>; rel="next"`,
+			repository, n, tags[n-1]))
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"name": repository, "tags": tags})
+}
+
+func (s *DistributionServer) GetManifest(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	digest, ok := s.digest(mux.Vars(r)["repository"], mux.Vars(r)["reference"])
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", manifestMediaType)
+	w.Header().Set("Docker-Content-Digest", digest)
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(s.Blobs[digest])
+	}
+}
+
+func (s *DistributionServer) PutManifest(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	content, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		s.responseError(w, http.StatusBadRequest, err)
+		return
+	}
+	repository := mux.Vars(r)["repository"]
+	if _, ok := s.Repositories[repository]; !ok {
+		s.Repositories[repository] = map[string]string{}
+	}
+	digest := s.putBlob(content)
+	s.Repositories[repository][mux.Vars(r)["reference"]] = digest
+	w.Header().Set("Docker-Content-Digest", digest)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *DistributionServer) GetBlob(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	content, ok := s.Blobs[mux.Vars(r)["digest"]]
+	if !ok {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("blob %s not found", mux.Vars(r)["digest"]))
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(content)
+	}
+}
+
+func (s *DistributionServer) StartUpload(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.uploadID++
+	w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%d", mux.Vars(r)["repository"], s.uploadID))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// FinishUpload completes a monolithic upload
+func (s *DistributionServer) FinishUpload(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	content, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		s.responseError(w, http.StatusBadRequest, err)
+		return
+	}
+	if digest := s.putBlob(content); digest != r.URL.Query().Get("digest") {
+		delete(s.Blobs, digest)
+		s.responseError(w, http.StatusBadRequest, fmt.Errorf("digest mismatch"))
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *DistributionServer) DeleteManifest(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	repository, reference := mux.Vars(r)["repository"], mux.Vars(r)["reference"]
+	repo, ok := s.Repositories[repository]
+	if !ok {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("repository %s not found", repository))
+		return
+	}
+	deleted := false
+	for tag, digest := range repo {
+		if tag == reference {
+			// registry:2 does not allow deleting manifests by tag
+			s.responseError(w, http.StatusBadRequest, fmt.Errorf("reference must be a digest"))
+			return
+		}
+		if digest == reference {
+			delete(repo, tag)
+			deleted = true
+		}
+	}
+	if !deleted {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("manifest %s not found", reference))
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *DistributionServer) digest(repository, reference string) (string, bool) {
+	for tag, digest := range s.Repositories[repository] {
+		if tag == reference || digest == reference {
+			return digest, true
+		}
+	}
+	return "", false
+}
+
+func (s *DistributionServer) auth(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.TokenAuth && r.Header.Get("Authorization") != "Bearer "+bearerToken {
+			w.Header().Set("WWW-Authenticate",
+				fmt.Sprintf(`Bearer realm="http://%s/token",service="registry"`, r.Host))
+			s.responseError(w, http.StatusUnauthorized, fmt.Errorf("authentication required"))
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func (s *DistributionServer) responseError(w http.ResponseWriter, code int, err error) {
+	w.WriteHeader(code)
+	if err != nil {
+		_, _ = w.Write([]byte(err.Error()))
+	}
+}
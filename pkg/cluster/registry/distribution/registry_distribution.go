@@ -0,0 +1,416 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package distribution implements Registry with the OCI distribution spec (Docker Registry HTTP API v2),
+// which is supported by docker registry, zot, ECR and most other registries.
+package distribution
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const kind = "distribution"
+
+// default params
+const (
+	_backoffDuration = 1 * time.Second
+	_retry           = 3
+	_timeout         = 10 * time.Second
+)
+
+// manifest media types to accept, the digest of a manifest depends on the media type returned
+var _manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+var _challengeParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+func init() {
+	registry.Register(kind, NewDistributionRegistry)
+}
+
+// Registry implement Registry
+type Registry struct {
+	// registry server address
+	server string
+	// token for basic auth, which is base64(username:password)
+	token string
+	// path prefix
+	path string
+	// retryableClient retryable client
+	retryableClient *retryablehttp.Client
+
+	// bearerTokens caches bearer tokens issued by the token server, keyed by scope
+	bearerTokens sync.Map
+}
+
+func NewDistributionRegistry(config *registry.Config) (registry.Registry, error) {
+	transport := http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: config.InsecureSkipVerify,
+		},
+	}
+	return &Registry{
+		server: strings.TrimSuffix(config.Server, "/"),
+		token:  config.Token,
+		path:   strings.Trim(config.Path, "/"),
+		retryableClient: &retryablehttp.Client{
+			HTTPClient: &http.Client{
+				Transport: &transport,
+				Timeout:   _timeout,
+			},
+			RetryMax:   _retry,
+			CheckRetry: retryablehttp.DefaultRetryPolicy,
+			Backoff: func(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+				// wait for this duration if failed
+				return _backoffDuration
+			},
+		},
+	}, nil
+}
+
+// DeleteImage deletes all manifests of the repository, the distribution spec
+// has no api to delete a repository, so the repository itself is left to the garbage collection
+func (r *Registry) DeleteImage(ctx context.Context, appName string, clusterName string) (err error) {
+	const op = "registry: delete repository"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	repository := path.Join(r.path, appName, clusterName)
+	tags, err := r.listTags(ctx, repository)
+	if err != nil {
+		return err
+	}
+
+	// tags may share the same manifest, delete every digest only once
+	deleted := map[string]struct{}{}
+	for _, tag := range tags {
+		digest, err := r.getDigest(ctx, repository, tag)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				continue
+			}
+			return err
+		}
+		if _, ok := deleted[digest]; ok {
+			continue
+		}
+		if err := r.deleteManifest(ctx, repository, digest); err != nil {
+			return err
+		}
+		deleted[digest] = struct{}{}
+	}
+	return nil
+}
+
+// ListImages lists tags of the repository and groups them by manifest digest,
+// size and created time are read from the manifest and the image config
+func (r *Registry) ListImages(ctx context.Context, appName string, clusterName string) (_ []*registry.Image, err error) {
+	const op = "registry: list images"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	repository := path.Join(r.path, appName, clusterName)
+	tags, err := r.listTags(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]*registry.Image, 0)
+	imageByDigest := make(map[string]*registry.Image)
+	for _, tag := range tags {
+		image, err := r.getImage(ctx, repository, tag)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				continue
+			}
+			return nil, err
+		}
+		if existed, ok := imageByDigest[image.Digest]; ok {
+			existed.Tags = append(existed.Tags, tag)
+			continue
+		}
+		image.Repository = registry.ImageRepository(r.server, r.path, appName, clusterName)
+		image.Tags = []string{tag}
+		imageByDigest[image.Digest] = image
+		images = append(images, image)
+	}
+	registry.SortImages(images)
+	return images, nil
+}
+
+type descriptor struct {
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+}
+
+type manifest struct {
+	Config    *descriptor   `json:"config"`
+	Layers    []*descriptor `json:"layers"`
+	Manifests []*descriptor `json:"manifests"`
+}
+
+func (r *Registry) getImage(ctx context.Context, repository, reference string) (*registry.Image, error) {
+	link := fmt.Sprintf("/v2/%s/manifests/%s", repository, reference)
+	resp, err := r.sendHTTPRequest(ctx, http.MethodGet, link, repository, "pull", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, herrors.NewErrNotFound(herrors.RegistryImage,
+			fmt.Sprintf("manifest %s:%s not found", repository, reference))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+	}
+
+	var m manifest
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, err.Error())
+	}
+	image := &registry.Image{
+		Digest: resp.Header.Get("Docker-Content-Digest"),
+	}
+	// an image index has no layers, its size is the sum of manifests for all platforms
+	for _, d := range append(m.Layers, m.Manifests...) {
+		image.Size += d.Size
+	}
+	if m.Config != nil {
+		image.Size += m.Config.Size
+		image.CreatedAt, err = r.getCreatedTime(ctx, repository, m.Config.Digest)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return image, nil
+}
+
+func (r *Registry) getCreatedTime(ctx context.Context, repository, digest string) (time.Time, error) {
+	link := fmt.Sprintf("/v2/%s/blobs/%s", repository, digest)
+	resp, err := r.sendHTTPRequest(ctx, http.MethodGet, link, repository, "pull", nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+	}
+
+	var config struct {
+		Created time.Time `json:"created"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&config); err != nil {
+		return time.Time{}, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, err.Error())
+	}
+	return config.Created, nil
+}
+
+func (r *Registry) listTags(ctx context.Context, repository string) ([]string, error) {
+	type tagList struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+
+	tags := make([]string, 0)
+	link := fmt.Sprintf("/v2/%s/tags/list", repository)
+	for link != "" {
+		resp, err := r.sendHTTPRequest(ctx, http.MethodGet, link, repository, "pull", nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			_ = resp.Body.Close()
+			return tags, nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			defer func() { _ = resp.Body.Close() }()
+			return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+		}
+		var list tagList
+		err = json.NewDecoder(resp.Body).Decode(&list)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, err.Error())
+		}
+		tags = append(tags, list.Tags...)
+		link = nextLink(resp.Header.Get("Link"))
+	}
+	return tags, nil
+}
+
+func (r *Registry) getDigest(ctx context.Context, repository, reference string) (string, error) {
+	link := fmt.Sprintf("/v2/%s/manifests/%s", repository, reference)
+	resp, err := r.sendHTTPRequest(ctx, http.MethodHead, link, repository, "pull", nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		digest := resp.Header.Get("Docker-Content-Digest")
+		if digest == "" {
+			return "", perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+				"no digest returned for %s:%s", repository, reference)
+		}
+		return digest, nil
+	case http.StatusNotFound:
+		return "", herrors.NewErrNotFound(herrors.RegistryImage,
+			fmt.Sprintf("manifest %s:%s not found", repository, reference))
+	default:
+		return "", perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"failed to get digest of %s:%s, status code = %d", repository, reference, resp.StatusCode)
+	}
+}
+
+func (r *Registry) deleteManifest(ctx context.Context, repository, digest string) error {
+	link := fmt.Sprintf("/v2/%s/manifests/%s", repository, digest)
+	resp, err := r.sendHTTPRequest(ctx, http.MethodDelete, link, repository, "delete", nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusAccepted || resp.StatusCode == http.StatusOK ||
+		resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+}
+
+// sendHTTPRequest sends request to registry with basic auth,
+// and retries with a bearer token if the registry requires token authentication
+func (r *Registry) sendHTTPRequest(ctx context.Context, method, link, repository, action string,
+	body []byte) (*http.Response, error) {
+	scope := fmt.Sprintf("repository:%s:%s", repository, action)
+	if action == "delete" {
+		// some registries require pull and push permissions to delete
+		scope = fmt.Sprintf("repository:%s:pull,push,delete", repository)
+	}
+
+	token, _ := r.bearerTokens.Load(scope)
+	bearer, _ := token.(string)
+	resp, err := r.do(ctx, method, link, bearer, body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	_ = resp.Body.Close()
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"unauthorized to %s %s, challenge = %s", method, link, challenge)
+	}
+	bearer, err = r.fetchBearerToken(ctx, challenge, scope)
+	if err != nil {
+		return nil, err
+	}
+	r.bearerTokens.Store(scope, bearer)
+	return r.do(ctx, method, link, bearer, body)
+}
+
+func (r *Registry) do(ctx context.Context, method, link, bearer string, body []byte) (*http.Response, error) {
+	if !strings.HasPrefix(link, "http://") && !strings.HasPrefix(link, "https://") {
+		link = r.server + link
+	}
+	req, err := retryablehttp.NewRequest(method, link, body)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRequestFailed, err.Error())
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", strings.Join(_manifestMediaTypes, ", "))
+	if bearer != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", bearer))
+	} else if r.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Basic %s", r.token))
+	}
+	resp, err := r.retryableClient.Do(req)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRequestFailed, err.Error())
+	}
+	return resp, nil
+}
+
+// fetchBearerToken gets a token from the token server in the challenge,
+// see https://docs.docker.com/registry/spec/auth/token/ for more information
+func (r *Registry) fetchBearerToken(ctx context.Context, challenge, scope string) (string, error) {
+	params := map[string]string{}
+	for _, match := range _challengeParamRegex.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+	realm, ok := params["realm"]
+	if !ok {
+		return "", perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "no realm in challenge: %s", challenge)
+	}
+	query := url.Values{}
+	if service, ok := params["service"]; ok {
+		query.Set("service", service)
+	}
+	query.Set("scope", scope)
+
+	resp, err := r.do(ctx, http.MethodGet, fmt.Sprintf("%s?%s", realm, query.Encode()), "", nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", perror.Wrap(herrors.ErrHTTPRespNotAsExpected, err.Error())
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	log.Debugf(ctx, "fetched bearer token for scope %s", scope)
+	return token.Token, nil
+}
+
+// nextLink parses the next page from the Link header, such as
+// </v2/app/cluster/tags/list?n=100&last=v1>; rel="next"
+func nextLink(header string) string {
+	if header == "" || !strings.Contains(header, `rel="next"`) {
+		return ""
+	}
+	start, end := strings.Index(header, "<"), strings.Index(header, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return header[start+1 : end]
+}
@@ -0,0 +1,98 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distribution
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
+	"github.com/horizoncd/horizon/pkg/cluster/registry/distribution/mockserver"
+	"github.com/stretchr/testify/assert"
+)
+
+var config = &registry.Config{}
+var server = mockserver.NewDistributionServer()
+
+func TestMain(m *testing.M) {
+	s := httptest.NewServer(http.HandlerFunc(server.R.ServeHTTP))
+	config.Server = "http://" + s.Listener.Addr().String()
+	os.Exit(m.Run())
+}
+
+func TestByMock(t *testing.T) {
+	config.Path = "project1"
+	registry, _ := NewDistributionRegistry(config)
+	r := registry.(*Registry)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	repository := "project1/horizon-demo/horizon-demo-dev"
+	server.PushImage(repository, "v1", "v1", now.Add(-time.Hour))
+	server.PushImage(repository, "v2", "v2", now)
+	server.PushImage(repository, "latest", "v2", now)
+	server.PushImage("project1/horizon-demo/horizon-demo-online", "v1", "v1", now)
+
+	images, err := r.ListImages(ctx, "horizon-demo", "horizon-demo-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(images))
+	assert.Equal(t, []string{"latest", "v2"}, images[0].Tags)
+	assert.Equal(t, []string{"v1"}, images[1].Tags)
+	assert.True(t, now.Equal(images[0].CreatedAt))
+	assert.Equal(t, server.Repositories[repository]["v2"], images[0].Digest)
+	assert.Equal(t, config.Server[len("http://"):]+"/"+repository, images[0].Repository)
+	assert.True(t, images[0].Size > int64(len("v2")))
+
+	err = r.DeleteImage(ctx, "horizon-demo", "horizon-demo-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(server.Repositories[repository]))
+	assert.Equal(t, 1, len(server.Repositories["project1/horizon-demo/horizon-demo-online"]))
+	err = r.DeleteImage(ctx, "horizon-demo", "horizon-demo-dev")
+	assert.Nil(t, err)
+	err = r.DeleteImage(ctx, "horizon-demo", "not-exists")
+	assert.Nil(t, err)
+	images, err = r.ListImages(ctx, "horizon-demo", "horizon-demo-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(images))
+}
+
+func TestTokenAuth(t *testing.T) {
+	server.TokenAuth = true
+	defer func() { server.TokenAuth = false }()
+
+	config.Path = "project2"
+	registry, _ := NewDistributionRegistry(config)
+	r := registry.(*Registry)
+	ctx := context.Background()
+
+	repository := "project2/horizon-demo/horizon-demo-dev"
+	server.PushImage(repository, "v1", "v1", time.Now())
+	images, err := r.ListImages(ctx, "horizon-demo", "horizon-demo-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(images))
+	err = r.DeleteImage(ctx, "horizon-demo", "horizon-demo-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(server.Repositories[repository]))
+}
+
+func TestNextLink(t *testing.T) {
+	assert.Equal(t, "/v2/app/cluster/tags/list?n=100&last=v1",
+		nextLink(`</v2/app/cluster/tags/list?n=100&last=v1>; rel="next"`))
+	assert.Equal(t, "", nextLink(""))
+}
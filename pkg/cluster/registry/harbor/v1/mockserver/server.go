@@ -15,9 +15,12 @@
 package mockserver
 
 import (
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -56,6 +59,8 @@ func NewHarborServer() *HarborServer {
 
 	r.Path("/api/repositories/{project}/{repository:[0-9a-zA-Z/-]+}").
 		Methods(http.MethodDelete).HandlerFunc(s.DeleteRepository)
+	r.Path("/api/repositories/{project}/{repository:[0-9a-zA-Z/-]+}/tags").
+		Methods(http.MethodGet).HandlerFunc(s.ListTags)
 	return s
 }
 
@@ -153,6 +158,39 @@ func (s *HarborServer) DeleteRepository(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 }
 
+func (s *HarborServer) ListTags(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	repo := s.getRepository(vars["project"], vars["repository"])
+	if repo == nil {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("repository %s not found", vars["repository"]))
+		return
+	}
+	tags := make([]map[string]interface{}, 0, len(repo.Tags))
+	for i, tag := range repo.Tags {
+		tags = append(tags, map[string]interface{}{
+			"name":    tag,
+			"digest":  fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(tag))),
+			"size":    len(tag),
+			"created": time.Unix(int64(i), 0),
+		})
+	}
+	_ = json.NewEncoder(w).Encode(tags)
+}
+
+func (s *HarborServer) getRepository(project, repository string) *ProjectRepository {
+	for _, p := range s.Projects {
+		if p.Name != project {
+			continue
+		}
+		for _, repo := range p.Repositories {
+			if repo.Name == repository {
+				return repo
+			}
+		}
+	}
+	return nil
+}
+
 func (s *HarborServer) responseError(w http.ResponseWriter, code int, err error) {
 	w.WriteHeader(code)
 	if err != nil {
@@ -17,6 +17,7 @@ package v1
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -112,6 +113,60 @@ func (h *Registry) DeleteImage(ctx context.Context, appName string, clusterName
 	return perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
 }
 
+type tag struct {
+	Name    string    `json:"name"`
+	Digest  string    `json:"digest"`
+	Size    int64     `json:"size"`
+	Created time.Time `json:"created"`
+}
+
+func (h *Registry) ListImages(ctx context.Context, appName string, clusterName string) (_ []*registry.Image, err error) {
+	const op = "registry: list images"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	link := path.Join("/api/repositories", h.path, appName, clusterName, "tags")
+	link = fmt.Sprintf("%s%s", strings.TrimSuffix(h.server, "/"), link)
+
+	resp, err := h.sendHTTPRequest(ctx, http.MethodGet, link, nil, true, "listTags")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	images := make([]*registry.Image, 0)
+	if resp.StatusCode == http.StatusNotFound {
+		return images, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+	}
+	var tags []*tag
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, err.Error())
+	}
+
+	// harbor v1 lists tags, group tags with the same digest into one image
+	repository := registry.ImageRepository(h.server, h.path, appName, clusterName)
+	imageByDigest := make(map[string]*registry.Image)
+	for _, t := range tags {
+		if image, ok := imageByDigest[t.Digest]; ok {
+			image.Tags = append(image.Tags, t.Name)
+			continue
+		}
+		image := &registry.Image{
+			Repository: repository,
+			Tags:       []string{t.Name},
+			Digest:     t.Digest,
+			Size:       t.Size,
+			CreatedAt:  t.Created,
+		}
+		imageByDigest[t.Digest] = image
+		images = append(images, image)
+	}
+	registry.SortImages(images)
+	return images, nil
+}
+
 func (h *Registry) sendHTTPRequest(ctx context.Context, method string,
 	url string, body io.Reader, retry bool, operation string) (*http.Response, error) {
 	begin := time.Now()
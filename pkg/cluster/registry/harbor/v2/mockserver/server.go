@@ -15,9 +15,12 @@
 package mockserver
 
 import (
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -55,6 +58,8 @@ func NewHarborServer() *HarborServer {
 	}
 	r.Path("/api/v2.0/projects/{project}/repositories/{repository}").
 		Methods(http.MethodDelete).HandlerFunc(s.DeleteRepository)
+	r.Path("/api/v2.0/projects/{project}/repositories/{repository:.+}/artifacts").
+		Methods(http.MethodGet).HandlerFunc(s.ListArtifacts)
 	return s
 }
 
@@ -152,6 +157,39 @@ func (s *HarborServer) DeleteRepository(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 }
 
+func (s *HarborServer) ListArtifacts(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	repo := s.getRepository(vars["project"], vars["repository"])
+	if repo == nil {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("repository %s not found", vars["repository"]))
+		return
+	}
+	artifacts := make([]map[string]interface{}, 0, len(repo.Tags))
+	for i, tag := range repo.Tags {
+		artifacts = append(artifacts, map[string]interface{}{
+			"digest":    fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(tag))),
+			"size":      len(tag),
+			"push_time": time.Unix(int64(i), 0),
+			"tags":      []map[string]string{{"name": tag}},
+		})
+	}
+	_ = json.NewEncoder(w).Encode(artifacts)
+}
+
+func (s *HarborServer) getRepository(project, repository string) *ProjectRepository {
+	for _, p := range s.Projects {
+		if p.Name != project {
+			continue
+		}
+		for _, repo := range p.Repositories {
+			if repo.Name == repository {
+				return repo
+			}
+		}
+	}
+	return nil
+}
+
 func (s *HarborServer) responseError(w http.ResponseWriter, code int, err error) {
 	w.WriteHeader(code)
 	if err != nil {
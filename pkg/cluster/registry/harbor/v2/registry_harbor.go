@@ -17,6 +17,7 @@ package v2
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -37,6 +38,9 @@ import (
 
 const kind = "harbor"
 
+// _pageSize page size of listing artifacts
+const _pageSize = 100
+
 // default params
 const (
 	_backoffDuration = 1 * time.Second
@@ -114,6 +118,77 @@ func (h *Registry) DeleteImage(ctx context.Context, appName string, clusterName
 	return perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
 }
 
+type artifact struct {
+	Digest   string    `json:"digest"`
+	Size     int64     `json:"size"`
+	PushTime time.Time `json:"push_time"`
+	Tags     []struct {
+		Name string `json:"name"`
+	} `json:"tags"`
+}
+
+func (h *Registry) ListImages(ctx context.Context, appName string, clusterName string) (_ []*registry.Image, err error) {
+	const op = "registry: list images"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	link := path.Join("/api/v2.0/projects", h.path, "repositories",
+		url.PathEscape(path.Join(appName, clusterName)), "artifacts")
+	link = fmt.Sprintf("%s%s", strings.TrimSuffix(h.server, "/"), link)
+
+	repository := registry.ImageRepository(h.server, h.path, appName, clusterName)
+	images := make([]*registry.Image, 0)
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("with_tag", "true")
+		query.Set("page", strconv.Itoa(page))
+		query.Set("page_size", strconv.Itoa(_pageSize))
+
+		artifacts, err := h.listArtifacts(ctx, fmt.Sprintf("%s?%s", link, query.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range artifacts {
+			tags := make([]string, 0, len(a.Tags))
+			for _, tag := range a.Tags {
+				tags = append(tags, tag.Name)
+			}
+			images = append(images, &registry.Image{
+				Repository: repository,
+				Tags:       tags,
+				Digest:     a.Digest,
+				Size:       a.Size,
+				CreatedAt:  a.PushTime,
+			})
+		}
+		if len(artifacts) < _pageSize {
+			break
+		}
+	}
+	registry.SortImages(images)
+	return images, nil
+}
+
+func (h *Registry) listArtifacts(ctx context.Context, link string) ([]*artifact, error) {
+	resp, err := h.sendHTTPRequest(ctx, http.MethodGet, link, nil, true, "listArtifacts")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+	}
+
+	var artifacts []*artifact
+	if err := json.NewDecoder(resp.Body).Decode(&artifacts); err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, err.Error())
+	}
+	return artifacts, nil
+}
+
 func (h *Registry) sendHTTPRequest(ctx context.Context, method string,
 	url string, body io.Reader, retry bool, operation string) (*http.Response, error) {
 	begin := time.Now()
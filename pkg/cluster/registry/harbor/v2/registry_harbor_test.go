@@ -43,8 +43,22 @@ func TestByMock(t *testing.T) {
 
 	server.CreateProject("project1", nil)
 	server.PushImage("project1", "horizon-demo/horizon-demo-dev", "v1")
+	server.PushImage("project1", "horizon-demo/horizon-demo-dev", "v2")
 
-	err := h.DeleteImage(ctx, "horizon-demo", "horizon-demo-dev")
+	images, err := h.ListImages(ctx, "horizon-demo", "horizon-demo-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(images))
+	assert.Equal(t, []string{"v2"}, images[0].Tags)
+	assert.Equal(t, []string{"v1"}, images[1].Tags)
+	assert.Equal(t, int64(2), images[0].Size)
+	assert.Equal(t, config.Server[len("http://"):]+"/project1/horizon-demo/horizon-demo-dev",
+		images[0].Repository)
+
+	images, err = h.ListImages(ctx, "horizon-demo", "not-exists")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(images))
+
+	err = h.DeleteImage(ctx, "horizon-demo", "horizon-demo-dev")
 	assert.Nil(t, err)
 	err = h.DeleteImage(ctx, "horizon-demo", "horizon-demo-dev")
 	assert.Nil(t, err)
@@ -16,6 +16,10 @@ package registry
 
 import (
 	"context"
+	"path"
+	"sort"
+	"strings"
+	"time"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
 	perror "github.com/horizoncd/horizon/pkg/errors"
@@ -45,6 +49,19 @@ func Register(kind string, constructor Constructor) {
 type Registry interface {
 	// DeleteImage delete repository
 	DeleteImage(ctx context.Context, appName string, clusterName string) error
+	// ListImages list images in the repository of cluster, ordered by created time desc
+	ListImages(ctx context.Context, appName string, clusterName string) ([]*Image, error)
+}
+
+// Image is a manifest in the repository, tags pointing to the same manifest are grouped together
+type Image struct {
+	// Repository full name of the repository, such as harbor.example.com/library/app/cluster
+	Repository string
+	Tags       []string
+	Digest     string
+	// Size total size of the image in bytes
+	Size      int64
+	CreatedAt time.Time
 }
 
 type Config struct {
@@ -64,3 +81,18 @@ func NewRegistry(config *Config) (Registry, error) {
 	}
 	return nil, perror.Wrapf(herrors.ErrParamInvalid, "kind = %v is not implement", config.Kind)
 }
+
+// ImageRepository returns the repository where images of the cluster are pushed to,
+// which is in the same format as the image built by horizon
+func ImageRepository(server, pathPrefix, appName, clusterName string) string {
+	domain := strings.TrimPrefix(server, "http://")
+	domain = strings.TrimPrefix(domain, "https://")
+	return path.Join(strings.TrimSuffix(domain, "/"), pathPrefix, appName, clusterName)
+}
+
+// SortImages sorts images by created time desc
+func SortImages(images []*Image) {
+	sort.SliceStable(images, func(i, j int) bool {
+		return images[i].CreatedAt.After(images[j].CreatedAt)
+	})
+}
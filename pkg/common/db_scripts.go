@@ -90,6 +90,8 @@ const (
 	MemberHardDeleteByResourceTypeID = "delete from tb_member where resource_type = ?" +
 		" and resource_id = ?"
 	MemberHardDeleteByMemberNameID = "delete from tb_member where membername_id = ?"
+	MemberExpiredDelete            = "update tb_member set deleted_ts = ? where resource_type = ? and" +
+		" resource_id = ? and member_type = ? and membername_id = ? and deleted_ts = 0 and expired_at <= ?"
 	// todo: fix user_type to query condition
 	MemberSelectAll = "select m.* from tb_member m join tb_user u on m.membername_id = u.id" +
 		" where m.resource_type = ? and m.resource_id = ? and m.deleted_ts = 0"
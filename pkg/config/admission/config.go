@@ -25,9 +25,9 @@ type ClientConfig struct {
 }
 
 type Rule struct {
-	Resources  []string           `yaml:"resources"`
-	Operations []models.Operation `yaml:"operations"`
-	Versions   []string           `yaml:"versions"`
+	Resources  []string           `yaml:"resources" json:"resources"`
+	Operations []models.Operation `yaml:"operations" json:"operations"`
+	Versions   []string           `yaml:"versions" json:"versions"`
 }
 
 type Webhook struct {
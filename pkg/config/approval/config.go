@@ -0,0 +1,27 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import "time"
+
+type Config struct {
+	// AccountID the user who expires the stale approvals
+	AccountID uint `yaml:"accountID"`
+	// Expiry is the duration after which pending approval check runs are cancelled, 0 means never
+	Expiry        time.Duration `yaml:"expiry"`
+	JobInterval   time.Duration `yaml:"jobInterval"`
+	BatchInterval time.Duration `yaml:"batchInterval"`
+	BatchSize     int           `yaml:"batchSize"`
+}
@@ -21,4 +21,52 @@ type Config struct {
 	CursorSaveInterval uint `yaml:"cursorSaveInterval"`
 	// seconds to wait when there is no events
 	IdleWaitInterval uint `yaml:"idleWaitInterval"`
+	// sinks to publish events as CloudEvents
+	Sinks []SinkConfig `yaml:"sinks"`
+}
+
+// SinkConfig is the config of a sink which publishes events to a message bus
+type SinkConfig struct {
+	// name of sink, the position of events published is saved by name
+	Name string `yaml:"name"`
+	// kind of sink, one of http, kafka-rest and nats,
+	// there is no native kafka producer, so kafka-rest requires a Confluent REST proxy in front of kafka
+	Kind string `yaml:"kind"`
+	// source attribute of CloudEvents, default to horizon
+	Source string `yaml:"source"`
+	// event types to publish, all the events are published if empty
+	EventTypes []string `yaml:"eventTypes"`
+	// how many events to publish in one batch
+	BatchSize uint `yaml:"batchSize"`
+	// seconds to wait for the message bus to accept events
+	Timeout uint `yaml:"timeout"`
+
+	HTTP      *HTTPSinkConfig      `yaml:"http"`
+	KafkaREST *KafkaRESTSinkConfig `yaml:"kafkaRest"`
+	NATS      *NATSSinkConfig      `yaml:"nats"`
+}
+
+// HTTPSinkConfig posts events to an HTTP CloudEvents endpoint in structured mode
+type HTTPSinkConfig struct {
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+// KafkaRESTSinkConfig produces events to a kafka topic through the Confluent REST proxy (API v2),
+// horizon doesn't connect to kafka brokers directly, so the REST proxy must be deployed for the topic
+type KafkaRESTSinkConfig struct {
+	// url of Confluent REST proxy, such as http://kafka-rest:8082, rather than the address of brokers
+	RESTProxyURL string            `yaml:"restProxyURL"`
+	Topic        string            `yaml:"topic"`
+	Headers      map[string]string `yaml:"headers"`
+}
+
+// NATSSinkConfig publishes events to the subject {subject}.{eventType} of NATS JetStream,
+// a stream capturing the subjects {subject}.> is required to acknowledge the events
+type NATSSinkConfig struct {
+	URL      string `yaml:"url"`
+	Subject  string `yaml:"subject"`
+	Token    string `yaml:"token"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
 }
@@ -0,0 +1,30 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluxcd
+
+// RegionMapper represents a region-to-FluxCD Mapper configurations.
+// Regions listed here are deployed by Flux CD instead of Argo CD.
+type RegionMapper map[string]*FluxCD
+
+type FluxCD struct {
+	// Namespace is where GitRepository and HelmRelease objects are created
+	Namespace string `yaml:"namespace"`
+	// Interval is the reconcile interval of flux objects, such as 1m
+	Interval string `yaml:"interval"`
+	// Timeout is the timeout of helm operations, such as 5m
+	Timeout string `yaml:"timeout"`
+	// SecretRef is the name of the secret holding credentials of the gitops repo
+	SecretRef string `yaml:"secretRef"`
+}
@@ -14,8 +14,17 @@
 
 package gitlab
 
+const (
+	// GitopsRepoKindGitlab stores gitops repos in gitlab, it's the default kind
+	GitopsRepoKindGitlab = "gitlab"
+	// GitopsRepoKindGit stores gitops repos in plain git repositories,
+	// the url can be a local directory or the base url of a git server
+	GitopsRepoKindGit = "git"
+)
+
 // GitopsRepoConfig gitops repo config
 type GitopsRepoConfig struct {
+	Kind              string `yaml:"kind"`
 	URL               string `yaml:"url"`
 	Token             string `yaml:"token"`
 	RootGroupPath     string `yaml:"rootGroupPath"`
@@ -0,0 +1,28 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helmcd
+
+import "time"
+
+// RegionMapper represents a region-to-HelmCD Mapper configurations.
+// Regions listed here are deployed by applying charts with helm directly.
+type RegionMapper map[string]*HelmCD
+
+type HelmCD struct {
+	// MaxHistory limits the number of release revisions kept, 0 means no limit
+	MaxHistory int `yaml:"maxHistory"`
+	// Timeout is the timeout of helm operations
+	Timeout time.Duration `yaml:"timeout"`
+}
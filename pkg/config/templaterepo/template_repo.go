@@ -25,4 +25,23 @@ type Repo struct {
 	KeyFile  string `yaml:"keyFile"`
 	CAFile   string `yaml:"caFile"`
 	RepoName string `yaml:"repoName"`
+
+	// Path is the directory to store charts for local kind.
+	// The charts of local and s3 kinds are served by horizon,
+	// and Host should be the url of horizon accessible by argocd or flux
+	Path string `yaml:"path"`
+	// S3 is the bucket to store charts for s3 kind
+	S3 *S3 `yaml:"s3"`
+}
+
+type S3 struct {
+	AccessKey        string `yaml:"accessKey"`
+	SecretKey        string `yaml:"secretKey"`
+	Region           string `yaml:"region"`
+	Endpoint         string `yaml:"endpoint"`
+	Bucket           string `yaml:"bucket"`
+	Prefix           string `yaml:"prefix"`
+	DisableSSL       bool   `yaml:"disableSSL"`
+	SkipVerify       bool   `yaml:"skipVerify"`
+	S3ForcePathStyle bool   `yaml:"s3ForcePathStyle"`
 }
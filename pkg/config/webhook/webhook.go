@@ -23,4 +23,8 @@ type Config struct {
 	WorkerReconcileInterval uint `yaml:"workerReconcileInterval"`
 	// bytes limit to truncate for response body
 	ResponseBodyTruncateSize uint `yaml:"responseBodyTruncateSize"`
+	// stop sending the raw secret in the deprecated X-Horizon-Webhook-Secret header,
+	// it's sent by default for receivers which don't verify the signature headers yet,
+	// and the secret is never stored in webhook logs
+	DisableSecretHeader bool `yaml:"disableSecretHeader"`
 }
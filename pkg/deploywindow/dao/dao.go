@@ -0,0 +1,102 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/deploywindow/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, window *models.DeployWindow) (*models.DeployWindow, error)
+	Update(ctx context.Context, window *models.DeployWindow) (*models.DeployWindow, error)
+	Get(ctx context.Context, id uint) (*models.DeployWindow, error)
+	List(ctx context.Context, resourceType string, resourceIDs ...uint) ([]*models.DeployWindow, error)
+	Delete(ctx context.Context, id uint) error
+	DeleteByResource(ctx context.Context, resourceType string, resourceID uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, window *models.DeployWindow) (*models.DeployWindow, error) {
+	if err := d.db.WithContext(ctx).Create(window).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.DeployWindowInDB, err.Error())
+	}
+	return window, nil
+}
+
+func (d *dao) Update(ctx context.Context, window *models.DeployWindow) (*models.DeployWindow, error) {
+	// select all the fields which can be updated, so that zero values are saved too
+	result := d.db.WithContext(ctx).Model(window).
+		Select("name", "description", "environment", "kind", "schedule", "duration", "timezone", "updated_by").
+		Where("id = ?", window.ID).Updates(window)
+	if result.Error != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.DeployWindowInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.DeployWindowInDB, "deploy window not found")
+	}
+	return d.Get(ctx, window.ID)
+}
+
+func (d *dao) Get(ctx context.Context, id uint) (*models.DeployWindow, error) {
+	var window models.DeployWindow
+	if err := d.db.WithContext(ctx).First(&window, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.DeployWindowInDB, err.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.DeployWindowInDB, err.Error())
+	}
+	return &window, nil
+}
+
+func (d *dao) List(ctx context.Context, resourceType string,
+	resourceIDs ...uint) ([]*models.DeployWindow, error) {
+	var windows []*models.DeployWindow
+	if len(resourceIDs) == 0 {
+		return windows, nil
+	}
+	if err := d.db.WithContext(ctx).Where("resource_type = ? AND resource_id IN ?",
+		resourceType, resourceIDs).Order("id").Find(&windows).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.DeployWindowInDB, err.Error())
+	}
+	return windows, nil
+}
+
+func (d *dao) Delete(ctx context.Context, id uint) error {
+	if err := d.db.WithContext(ctx).Delete(&models.DeployWindow{}, id).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.DeployWindowInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) DeleteByResource(ctx context.Context, resourceType string, resourceID uint) error {
+	if err := d.db.WithContext(ctx).Where("resource_type = ? AND resource_id = ?",
+		resourceType, resourceID).Delete(&models.DeployWindow{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.DeployWindowInDB, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/deploywindow/dao"
+	"github.com/horizoncd/horizon/pkg/deploywindow/models"
+)
+
+type Manager interface {
+	Create(ctx context.Context, window *models.DeployWindow) (*models.DeployWindow, error)
+	Update(ctx context.Context, window *models.DeployWindow) (*models.DeployWindow, error)
+	Get(ctx context.Context, id uint) (*models.DeployWindow, error)
+	// List lists the deploy windows attached to the resources of resourceType
+	List(ctx context.Context, resourceType string, resourceIDs ...uint) ([]*models.DeployWindow, error)
+	Delete(ctx context.Context, id uint) error
+	DeleteByResource(ctx context.Context, resourceType string, resourceID uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, window *models.DeployWindow) (*models.DeployWindow, error) {
+	return m.dao.Create(ctx, window)
+}
+
+func (m *manager) Update(ctx context.Context, window *models.DeployWindow) (*models.DeployWindow, error) {
+	return m.dao.Update(ctx, window)
+}
+
+func (m *manager) Get(ctx context.Context, id uint) (*models.DeployWindow, error) {
+	return m.dao.Get(ctx, id)
+}
+
+func (m *manager) List(ctx context.Context, resourceType string,
+	resourceIDs ...uint) ([]*models.DeployWindow, error) {
+	return m.dao.List(ctx, resourceType, resourceIDs...)
+}
+
+func (m *manager) Delete(ctx context.Context, id uint) error {
+	return m.dao.Delete(ctx, id)
+}
+
+func (m *manager) DeleteByResource(ctx context.Context, resourceType string, resourceID uint) error {
+	return m.dao.DeleteByResource(ctx, resourceType, resourceID)
+}
@@ -0,0 +1,46 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+const (
+	// KindFreeze forbids deploying while the window is open
+	KindFreeze = "freeze"
+	// KindAllow only allows deploying while one of the windows is open
+	KindAllow = "allow"
+)
+
+// DeployWindow is a schedule attached to an environment, a group or an application,
+// which opens at each activation of Schedule in Timezone and closes after Duration minutes
+type DeployWindow struct {
+	global.Model
+
+	Name         string
+	Description  string
+	ResourceType string
+	ResourceID   uint
+	// Environment limits the window to clusters of the environment, empty means all the environments
+	Environment string
+	Kind        string
+	// Schedule is a standard cron expression with five fields
+	Schedule  string
+	Duration  uint
+	Timezone  string
+	CreatedBy uint
+	UpdatedBy uint
+}
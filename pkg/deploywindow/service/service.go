@@ -0,0 +1,259 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	// load the time zone database in case it's not installed in the system
+	_ "time/tzdata"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	deploywindowmanager "github.com/horizoncd/horizon/pkg/deploywindow/manager"
+	"github.com/horizoncd/horizon/pkg/deploywindow/models"
+	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// MaxDuration is the max duration of deploy windows in minutes
+const MaxDuration = 366 * 24 * 60
+
+type Service interface {
+	// ListBlocking lists the deploy windows which forbid deploying the cluster at t,
+	// the cluster is allowed to deploy if the result is empty
+	ListBlocking(ctx context.Context, application *appmodels.Application,
+		cluster *clustermodels.Cluster, t time.Time) ([]*models.DeployWindow, error)
+	// Check returns herrors.ErrDeployWindowClosed if the action on cluster is forbidden by deploy windows now.
+	// The owners of cluster can override it with a reason, and the override is recorded as an event.
+	Check(ctx context.Context, application *appmodels.Application,
+		cluster *clustermodels.Cluster, action, overrideReason string) error
+}
+
+type service struct {
+	deployWindowMgr deploywindowmanager.Manager
+	envMgr          envmanager.Manager
+	groupMgr        groupmanager.Manager
+	memberSvc       memberservice.Service
+	eventSvc        eventservice.Service
+}
+
+func NewService(manager *managerparam.Manager, memberSvc memberservice.Service,
+	eventSvc eventservice.Service) Service {
+	return &service{
+		deployWindowMgr: manager.DeployWindowMgr,
+		envMgr:          manager.EnvMgr,
+		groupMgr:        manager.GroupMgr,
+		memberSvc:       memberSvc,
+		eventSvc:        eventSvc,
+	}
+}
+
+// OverrideExtra is the extra of the event recorded when deploy windows are overridden
+type OverrideExtra struct {
+	Action  string        `json:"action"`
+	Reason  string        `json:"reason"`
+	Windows []WindowBrief `json:"windows"`
+}
+
+type WindowBrief struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// Validate checks the kind, schedule, duration and timezone of window
+func Validate(window *models.DeployWindow) error {
+	if window.Name == "" {
+		return perror.Wrap(herrors.ErrParamInvalid, "name of deploy window is empty")
+	}
+	if window.Kind != models.KindFreeze && window.Kind != models.KindAllow {
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid kind of deploy window: %s", window.Kind)
+	}
+	if window.Duration == 0 || window.Duration > MaxDuration {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"duration of deploy window should be between 1 and %d minutes", MaxDuration)
+	}
+	if _, err := cron.ParseStandard(window.Schedule); err != nil {
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid schedule %s: %s", window.Schedule, err)
+	}
+	if _, err := time.LoadLocation(window.Timezone); err != nil {
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid timezone %s: %s", window.Timezone, err)
+	}
+	return nil
+}
+
+// IsOpen returns whether window is open at t, that is,
+// there is an activation of the schedule in (t-duration, t]
+func IsOpen(window *models.DeployWindow, t time.Time) (bool, error) {
+	schedule, err := cron.ParseStandard(window.Schedule)
+	if err != nil {
+		return false, perror.Wrapf(herrors.ErrParamInvalid, "invalid schedule %s: %s", window.Schedule, err)
+	}
+	location, err := time.LoadLocation(window.Timezone)
+	if err != nil {
+		return false, perror.Wrapf(herrors.ErrParamInvalid, "invalid timezone %s: %s", window.Timezone, err)
+	}
+	duration := time.Duration(window.Duration) * time.Minute
+	start := schedule.Next(t.In(location).Add(-duration))
+	// zero time means there is no activation in years
+	return !start.IsZero() && !start.After(t), nil
+}
+
+// Blocking returns the windows forbidding deploying at t:
+// the open freeze windows, or all the allowed windows if none of them is open
+func Blocking(ctx context.Context, windows []*models.DeployWindow, t time.Time) []*models.DeployWindow {
+	var freezes, allows []*models.DeployWindow
+	allowOpen := false
+	for _, window := range windows {
+		open, err := IsOpen(window, t)
+		if err != nil {
+			// windows are validated when saved, skip the broken one
+			log.Errorf(ctx, "failed to check deploy window %d: %+v", window.ID, err)
+			continue
+		}
+		switch window.Kind {
+		case models.KindFreeze:
+			if open {
+				freezes = append(freezes, window)
+			}
+		case models.KindAllow:
+			allows = append(allows, window)
+			allowOpen = allowOpen || open
+		}
+	}
+	if len(freezes) > 0 {
+		return freezes
+	}
+	if len(allows) > 0 && !allowOpen {
+		return allows
+	}
+	return nil
+}
+
+func (s *service) ListBlocking(ctx context.Context, application *appmodels.Application,
+	cluster *clustermodels.Cluster, t time.Time) ([]*models.DeployWindow, error) {
+	windows, err := s.listWindows(ctx, application, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return Blocking(ctx, windows, t), nil
+}
+
+// listWindows lists the windows of the environment, the groups and the application of cluster
+func (s *service) listWindows(ctx context.Context, application *appmodels.Application,
+	cluster *clustermodels.Cluster) ([]*models.DeployWindow, error) {
+	var windows []*models.DeployWindow
+
+	env, err := s.envMgr.GetByName(ctx, cluster.EnvironmentName)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return nil, err
+		}
+	} else {
+		envWindows, err := s.deployWindowMgr.List(ctx, common.ResourceEnvironment, env.ID)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, envWindows...)
+	}
+
+	group, err := s.groupMgr.GetByID(ctx, application.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	groupWindows, err := s.deployWindowMgr.List(ctx, common.ResourceGroup,
+		groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs)...)
+	if err != nil {
+		return nil, err
+	}
+	windows = append(windows, groupWindows...)
+
+	appWindows, err := s.deployWindowMgr.List(ctx, common.ResourceApplication, application.ID)
+	if err != nil {
+		return nil, err
+	}
+	windows = append(windows, appWindows...)
+
+	result := make([]*models.DeployWindow, 0, len(windows))
+	for _, window := range windows {
+		if window.Environment == "" || window.Environment == cluster.EnvironmentName {
+			result = append(result, window)
+		}
+	}
+	return result, nil
+}
+
+func (s *service) Check(ctx context.Context, application *appmodels.Application,
+	cluster *clustermodels.Cluster, action, overrideReason string) error {
+	windows, err := s.ListBlocking(ctx, application, cluster, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(windows) == 0 {
+		return nil
+	}
+
+	briefs := make([]WindowBrief, 0, len(windows))
+	names := make([]string, 0, len(windows))
+	for _, window := range windows {
+		briefs = append(briefs, WindowBrief{ID: window.ID, Name: window.Name, Kind: window.Kind})
+		names = append(names, fmt.Sprintf("%s(%s)", window.Name, window.Kind))
+	}
+	overrideReason = strings.TrimSpace(overrideReason)
+	if overrideReason == "" {
+		return perror.Wrapf(herrors.ErrDeployWindowClosed,
+			"%s of cluster %s is forbidden by deploy windows %s, owners can override it with a reason",
+			action, cluster.Name, strings.Join(names, ", "))
+	}
+
+	if err := s.memberSvc.RequirePermissionEqualOrHigher(ctx, role.Owner,
+		common.ResourceCluster, cluster.ID); err != nil {
+		if perror.Cause(err) == herrors.ErrNoPrivilege {
+			return perror.Wrapf(herrors.ErrForbidden,
+				"only owners of cluster %s can override deploy windows %s", cluster.Name, strings.Join(names, ", "))
+		}
+		return err
+	}
+
+	extra, err := json.Marshal(OverrideExtra{
+		Action:  action,
+		Reason:  overrideReason,
+		Windows: briefs,
+	})
+	if err != nil {
+		return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	extraStr := string(extra)
+	log.Infof(ctx, "deploy windows of cluster %s are overridden, extra: %s", cluster.Name, extraStr)
+	s.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, cluster.ID,
+		eventmodels.ClusterWindowOverride, &extraStr)
+	return nil
+}
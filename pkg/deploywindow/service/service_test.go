@@ -0,0 +1,198 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	memberservicemock "github.com/horizoncd/horizon/mock/pkg/member/service"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/deploywindow/models"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+)
+
+func TestIsOpen(t *testing.T) {
+	// 2024-04-12 is a Friday
+	friday := time.Date(2024, 4, 12, 18, 30, 0, 0, time.UTC)
+	weekend := &models.DeployWindow{
+		Kind:     models.KindFreeze,
+		Schedule: "0 18 * * 5",
+		Duration: 2*24*60 + 6*60,
+		Timezone: "UTC",
+	}
+	cases := []struct {
+		t    time.Time
+		open bool
+	}{
+		{friday.Add(-time.Hour), false},
+		{friday, true},
+		{friday.Add(24 * time.Hour), true},
+		{friday.Add(2*24*time.Hour + 5*time.Hour), true},
+		{friday.Add(2*24*time.Hour + 6*time.Hour), false},
+	}
+	for _, c := range cases {
+		open, err := IsOpen(weekend, c.t)
+		assert.Nil(t, err)
+		assert.Equal(t, c.open, open, c.t.String())
+	}
+
+	// 18:00 in Shanghai is 10:00 in UTC
+	weekend.Timezone = "Asia/Shanghai"
+	open, err := IsOpen(weekend, time.Date(2024, 4, 12, 10, 30, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.True(t, open)
+	open, err = IsOpen(weekend, time.Date(2024, 4, 12, 9, 30, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.False(t, open)
+
+	weekend.Timezone = "Mars/Olympus"
+	_, err = IsOpen(weekend, friday)
+	assert.NotNil(t, err)
+}
+
+func TestBlocking(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 4, 12, 12, 0, 0, 0, time.UTC)
+	freeze := &models.DeployWindow{Name: "freeze", Kind: models.KindFreeze,
+		Schedule: "0 11 * * *", Duration: 120, Timezone: "UTC"}
+	closedFreeze := &models.DeployWindow{Name: "closed-freeze", Kind: models.KindFreeze,
+		Schedule: "0 20 * * *", Duration: 60, Timezone: "UTC"}
+	allow := &models.DeployWindow{Name: "allow", Kind: models.KindAllow,
+		Schedule: "0 10 * * 1-5", Duration: 180, Timezone: "UTC"}
+	closedAllow := &models.DeployWindow{Name: "closed-allow", Kind: models.KindAllow,
+		Schedule: "0 14 * * 1-5", Duration: 180, Timezone: "UTC"}
+	broken := &models.DeployWindow{Name: "broken", Kind: models.KindFreeze,
+		Schedule: "invalid", Duration: 60, Timezone: "UTC"}
+
+	assert.Empty(t, Blocking(ctx, nil, now))
+	assert.Empty(t, Blocking(ctx, []*models.DeployWindow{closedFreeze, broken}, now))
+	assert.Equal(t, []*models.DeployWindow{freeze},
+		Blocking(ctx, []*models.DeployWindow{freeze, closedFreeze, allow}, now))
+	assert.Empty(t, Blocking(ctx, []*models.DeployWindow{allow, closedAllow}, now))
+	assert.Equal(t, []*models.DeployWindow{closedAllow},
+		Blocking(ctx, []*models.DeployWindow{closedAllow, closedFreeze}, now))
+}
+
+func TestValidate(t *testing.T) {
+	window := &models.DeployWindow{Name: "freeze", Kind: models.KindFreeze,
+		Schedule: "0 18 * * 5", Duration: 60, Timezone: "Asia/Shanghai"}
+	assert.Nil(t, Validate(window))
+
+	for _, invalid := range []models.DeployWindow{
+		{Name: "", Kind: models.KindFreeze, Schedule: "0 18 * * 5", Duration: 60, Timezone: "UTC"},
+		{Name: "freeze", Kind: "deny", Schedule: "0 18 * * 5", Duration: 60, Timezone: "UTC"},
+		{Name: "freeze", Kind: models.KindFreeze, Schedule: "0 18 * *", Duration: 60, Timezone: "UTC"},
+		{Name: "freeze", Kind: models.KindFreeze, Schedule: "0 18 * * 5", Duration: 0, Timezone: "UTC"},
+		{Name: "freeze", Kind: models.KindFreeze, Schedule: "0 18 * * 5", Duration: MaxDuration + 1, Timezone: "UTC"},
+		{Name: "freeze", Kind: models.KindFreeze, Schedule: "0 18 * * 5", Duration: 60, Timezone: "Mars/Olympus"},
+	} {
+		invalid := invalid
+		err := Validate(&invalid)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err), invalid)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&models.DeployWindow{}, &envmodels.Environment{},
+		&groupmodels.Group{}, &eventmodels.Event{}))
+	manager := managerparam.InitManager(db)
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "Tony", ID: 1})
+
+	mockCtl := gomock.NewController(t)
+	memberSvc := memberservicemock.NewMockService(mockCtl)
+	s := NewService(manager, memberSvc, eventservice.New(manager))
+
+	env, err := manager.EnvMgr.CreateEnvironment(ctx, &envmodels.Environment{Name: "online"})
+	assert.Nil(t, err)
+	group := &groupmodels.Group{Name: "group", Path: "group"}
+	assert.Nil(t, db.Create(group).Error)
+	group.TraversalIDs = "1"
+	assert.Nil(t, db.Save(group).Error)
+	application := &appmodels.Application{Name: "app", GroupID: group.ID}
+	application.ID = 1
+	cluster := &clustermodels.Cluster{Name: "app-online", ApplicationID: application.ID,
+		EnvironmentName: env.Name}
+	cluster.ID = 1
+	testCluster := &clustermodels.Cluster{Name: "app-test", ApplicationID: application.ID,
+		EnvironmentName: "test"}
+	testCluster.ID = 2
+
+	assert.Nil(t, s.Check(ctx, application, cluster, "deploy", ""))
+
+	// a freeze window which is always open
+	_, err = manager.DeployWindowMgr.Create(ctx, &models.DeployWindow{
+		Name:         "freeze",
+		ResourceType: common.ResourceGroup,
+		ResourceID:   group.ID,
+		Environment:  env.Name,
+		Kind:         models.KindFreeze,
+		Schedule:     "0 * * * *",
+		Duration:     60,
+		Timezone:     "UTC",
+	})
+	assert.Nil(t, err)
+	// an allow window which is always open
+	_, err = manager.DeployWindowMgr.Create(ctx, &models.DeployWindow{
+		Name:         "allow",
+		ResourceType: common.ResourceEnvironment,
+		ResourceID:   env.ID,
+		Kind:         models.KindAllow,
+		Schedule:     "0 * * * *",
+		Duration:     60,
+		Timezone:     "UTC",
+	})
+	assert.Nil(t, err)
+
+	windows, err := s.ListBlocking(ctx, application, cluster, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(windows))
+	assert.Equal(t, "freeze", windows[0].Name)
+	assert.Nil(t, s.Check(ctx, application, testCluster, "deploy", ""))
+
+	err = s.Check(ctx, application, cluster, "deploy", "")
+	assert.Equal(t, herrors.ErrDeployWindowClosed, perror.Cause(err))
+
+	memberSvc.EXPECT().RequirePermissionEqualOrHigher(gomock.Any(), role.Owner,
+		common.ResourceCluster, cluster.ID).Return(herrors.ErrNoPrivilege).Times(1)
+	err = s.Check(ctx, application, cluster, "deploy", "hotfix")
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	memberSvc.EXPECT().RequirePermissionEqualOrHigher(gomock.Any(), role.Owner,
+		common.ResourceCluster, cluster.ID).Return(nil).Times(1)
+	assert.Nil(t, s.Check(ctx, application, cluster, "deploy", "hotfix"))
+
+	var events []*eventmodels.Event
+	assert.Nil(t, db.Where("event_type = ?", eventmodels.ClusterWindowOverride).Find(&events).Error)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, cluster.ID, events[0].ResourceID)
+	assert.Contains(t, *events[0].Extra, "hotfix")
+}
@@ -36,7 +36,10 @@ type DAO interface {
 	CreateOrUpdateCursor(ctx context.Context,
 		eventIndex *models.EventCursor) (*models.EventCursor, error)
 	GetCursor(ctx context.Context) (*models.EventCursor, error)
+	GetSinkCursor(ctx context.Context, name string) (*models.EventSinkCursor, error)
+	SaveSinkCursor(ctx context.Context, cursor *models.EventSinkCursor) error
 	GetEvent(ctx context.Context, id uint) (*models.Event, error)
+	GetLatestEventID(ctx context.Context) (uint, error)
 	DeleteEvents(ctx context.Context, id ...uint) (int64, error)
 }
 
@@ -77,6 +80,12 @@ func (d *dao) List(ctx context.Context, query *q.Query) ([]*models.Event, error)
 			statement = statement.Where("id <= ?", v)
 		case common.ReqID:
 			statement = statement.Where("req_id = ?", v)
+		case common.ParamResourceType:
+			statement = statement.Where("resource_type = ?", v)
+		case common.ParamResourceID:
+			statement = statement.Where("resource_id = ?", v)
+		case common.EventType:
+			statement = statement.Where("event_type in ?", v)
 		}
 	}
 
@@ -98,6 +107,15 @@ func (d *dao) GetEvent(ctx context.Context, id uint) (*models.Event, error) {
 	return event, nil
 }
 
+func (d *dao) GetLatestEventID(ctx context.Context) (uint, error) {
+	var id uint
+	result := d.db.WithContext(ctx).Model(&models.Event{}).Select("coalesce(max(id), 0)").Scan(&id)
+	if result.Error != nil {
+		return 0, herrors.NewErrGetFailed(herrors.EventInDB, result.Error.Error())
+	}
+	return id, nil
+}
+
 func (d *dao) CreateOrUpdateCursor(ctx context.Context,
 	eventCursor *models.EventCursor) (*models.EventCursor, error) {
 	if result := d.db.Clauses(clause.OnConflict{
@@ -126,6 +144,28 @@ func (d *dao) GetCursor(ctx context.Context) (*models.EventCursor, error) {
 	return &eventIndex, nil
 }
 
+func (d *dao) GetSinkCursor(ctx context.Context, name string) (*models.EventSinkCursor, error) {
+	var cursor models.EventSinkCursor
+	if result := d.db.WithContext(ctx).Where("name = ?", name).First(&cursor); result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.EventCursorInDB,
+				result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.EventCursorInDB, result.Error.Error())
+	}
+	return &cursor, nil
+}
+
+func (d *dao) SaveSinkCursor(ctx context.Context, cursor *models.EventSinkCursor) error {
+	if result := d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"position", "updated_at"}),
+	}).Create(cursor); result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.EventCursorInDB, result.Error.Error())
+	}
+	return nil
+}
+
 func (d *dao) DeleteEvents(ctx context.Context, ids ...uint) (int64, error) {
 	var events []*models.Event
 	tx := d.db.WithContext(ctx).Begin()
@@ -36,7 +36,10 @@ type Manager interface {
 	CreateOrUpdateCursor(ctx context.Context,
 		eventIndex *models.EventCursor) (*models.EventCursor, error)
 	GetCursor(ctx context.Context) (*models.EventCursor, error)
+	GetSinkCursor(ctx context.Context, name string) (*models.EventSinkCursor, error)
+	SaveSinkCursor(ctx context.Context, cursor *models.EventSinkCursor) error
 	GetEvent(ctx context.Context, id uint) (*models.Event, error)
+	GetLatestEventID(ctx context.Context) (uint, error)
 	ListSupportEvents() map[string]string
 	DeleteEvents(ctx context.Context, id ...uint) (int64, error)
 }
@@ -115,6 +118,24 @@ func (m *manager) GetEvent(ctx context.Context, id uint) (*models.Event, error)
 	return m.dao.GetEvent(ctx, id)
 }
 
+func (m *manager) GetSinkCursor(ctx context.Context, name string) (*models.EventSinkCursor, error) {
+	const op = "event manager: get sink cursor"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetSinkCursor(ctx, name)
+}
+
+func (m *manager) SaveSinkCursor(ctx context.Context, cursor *models.EventSinkCursor) error {
+	const op = "event manager: save sink cursor"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.SaveSinkCursor(ctx, cursor)
+}
+
+func (m *manager) GetLatestEventID(ctx context.Context) (uint, error) {
+	const op = "event manager: get latest event id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetLatestEventID(ctx)
+}
+
 func (m *manager) DeleteEvents(ctx context.Context, id ...uint) (int64, error) {
 	const op = "event manager: delete event"
 	defer wlog.Start(ctx, op).StopPrint()
@@ -135,6 +156,7 @@ var supportedEvents = map[string]string{
 	models.ClusterFreed:           "Cluster has been freed",
 	models.ClusterRestarted:       "Cluster has been restarted",
 	models.ClusterAction:          "Cluster has triggered an action",
+	models.ClusterWindowOverride:  "Deploy windows of cluster have been overridden by owner",
 	models.ClusterPodsRescheduled: "Pods has been deleted to reschedule",
 	models.ClusterKubernetesEvent: "Kubernetes event associated with cluster has been triggered",
 	models.MemberCreated:          "New member has been created",
@@ -143,6 +165,19 @@ var supportedEvents = map[string]string{
 	models.PipelinerunCreated:     "New pipelinerun has been created",
 	models.PipelinerunCancelled:   "Pipelinerun has been cancelled",
 	models.PipelinerunExecuted:    "Pipelinerun has been executed",
+	models.RoleCreated:            "New role has been created",
+	models.RoleUpdated:            "Role has been updated",
+	models.RoleDeleted:            "Role has been deleted",
+	models.GroupCreated:           "New group has been created",
+	models.GroupUpdated:           "Group has been updated",
+	models.GroupDeleted:           "Group has been deleted",
+	models.GroupTransferred:       "Group has been transferred to another group",
+	models.TemplateCreated:        "New template has been created",
+	models.TemplateUpdated:        "Template has been updated",
+	models.TemplateDeleted:        "Template has been deleted",
+	models.TemplateReleaseCreated: "New template release has been created",
+	models.TemplateReleaseUpdated: "Template release has been updated",
+	models.TemplateReleaseDeleted: "Template release has been deleted",
 }
 
 func (m *manager) ListSupportEvents() map[string]string {
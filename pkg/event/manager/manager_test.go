@@ -91,6 +91,24 @@ func Test(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 2, len(events))
 
+	events, err = m.ListEvents(ctx, &q.Query{Keywords: q.KeyWords{
+		common.ParamResourceType: common.ResourceCluster,
+		common.ParamResourceID:   1,
+		common.EventType:         []string{eventmodels.ClusterCreated, eventmodels.ClusterDeleted},
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(events))
+
+	events, err = m.ListEvents(ctx, &q.Query{Keywords: q.KeyWords{
+		common.EventType: []string{eventmodels.ClusterDeleted},
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(events))
+
+	latestID, err := m.GetLatestEventID(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(3), latestID)
+
 	ec, err := m.CreateOrUpdateCursor(ctx, &eventmodels.EventCursor{
 		Position: 1,
 	})
@@ -110,4 +128,8 @@ func Test(t *testing.T) {
 	events, err = m.ListEvents(ctx, &q.Query{Keywords: q.KeyWords{common.StartID: 0, common.Limit: 10}})
 	assert.Nil(t, err)
 	assert.Equal(t, 0, len(events))
+
+	latestID, err = m.GetLatestEventID(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(0), latestID)
 }
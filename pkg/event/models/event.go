@@ -38,13 +38,26 @@ const (
 	ClusterFreed           string = "clusters_freed"
 	ClusterKubernetesEvent string = "clusters_kubernetes_event"
 	ClusterAction                 = "clusters_action"
+	ClusterWindowOverride  string = "clusters_deploywindowoverridden"
 	MemberCreated          string = "members_created"
 	MemberUpdated          string = "members_updated"
 	MemberDeleted          string = "members_deleted"
 	PipelinerunCreated     string = "pipelineruns_created"
 	PipelinerunCancelled   string = "pipelineruns_cancelled"
 	PipelinerunExecuted    string = "pipelineruns_executed"
-	// TODO: add group events
+	RoleCreated            string = "roles_created"
+	RoleUpdated            string = "roles_updated"
+	RoleDeleted            string = "roles_deleted"
+	GroupCreated           string = "groups_created"
+	GroupUpdated           string = "groups_updated"
+	GroupDeleted           string = "groups_deleted"
+	GroupTransferred       string = "groups_transferred"
+	TemplateCreated        string = "templates_created"
+	TemplateUpdated        string = "templates_updated"
+	TemplateDeleted        string = "templates_deleted"
+	TemplateReleaseCreated string = "templatereleases_created"
+	TemplateReleaseUpdated string = "templatereleases_updated"
+	TemplateReleaseDeleted string = "templatereleases_deleted"
 )
 
 type EventSummary struct {
@@ -54,6 +67,20 @@ type EventSummary struct {
 	Extra        *string `gorm:"default:''"`
 }
 
+// ResourceExtra is the extra of events on groups, templates and template releases.
+// It records where the resource is, so that the event can still be associated with
+// the webhooks of ancestor groups after the resource has been deleted.
+type ResourceExtra struct {
+	Name string `json:"name"`
+	Path string `json:"path,omitempty"`
+	// GroupID is the parent group of group, or the group which the template belongs to
+	GroupID uint `json:"groupID"`
+	// TraversalIDs is the traversal ids of group
+	TraversalIDs string `json:"traversalIDs,omitempty"`
+	TemplateID   uint   `json:"templateID,omitempty"`
+	TemplateName string `json:"templateName,omitempty"`
+}
+
 type Event struct {
 	EventSummary
 	ID        uint
@@ -69,6 +96,15 @@ type EventCursor struct {
 	UpdatedAt time.Time
 }
 
+// EventSinkCursor records the position of events published by a sink
+type EventSinkCursor struct {
+	ID        uint
+	Name      string `gorm:"uniqueIndex:idx_name"`
+	Position  uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
 type ActionWithDescription struct {
 	Name        EventType `json:"name"`
 	Description string    `json:"description"`
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/pkg/event/manager"
@@ -20,6 +21,9 @@ type Service interface {
 	CreateEventsIgnoreError(ctx context.Context, events ...*models.Event) []*models.Event
 	// RecordMemberCreatedEvent records members_created event for the given resource that is created
 	RecordMemberCreatedEvent(ctx context.Context, resourceType string, resourceID uint) []*models.Event
+	// CreateResourceEventIgnoreError creates an event with the resource extra and ignore the error
+	CreateResourceEventIgnoreError(ctx context.Context, resourceType string,
+		resourceID uint, eventType string, extra *models.ResourceExtra) []*models.Event
 }
 
 type service struct {
@@ -51,6 +55,17 @@ func (s *service) CreateEventIgnoreError(ctx context.Context, resourceType strin
 	return events
 }
 
+func (s *service) CreateResourceEventIgnoreError(ctx context.Context, resourceType string,
+	resourceID uint, eventType string, extra *models.ResourceExtra) []*models.Event {
+	bts, err := json.Marshal(extra)
+	if err != nil {
+		log.Warningf(ctx, "failed to marshal event extra, err: %s", err.Error())
+		return nil
+	}
+	extraStr := string(bts)
+	return s.CreateEventIgnoreError(ctx, resourceType, resourceID, eventType, &extraStr)
+}
+
 func (s *service) CreateEventsIgnoreError(ctx context.Context, events ...*models.Event) []*models.Event {
 	events, err := s.eventMgr.CreateEvent(ctx, events...)
 	if err != nil {
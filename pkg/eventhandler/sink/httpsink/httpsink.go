@@ -0,0 +1,101 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	eventhandlerconfig "github.com/horizoncd/horizon/pkg/config/eventhandler"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/eventhandler/sink"
+)
+
+const (
+	Kind = "http"
+
+	ContentType = "application/cloudevents+json; charset=utf-8"
+	// _maxErrorBodySize limits the response body recorded in error
+	_maxErrorBodySize = 1024
+)
+
+func init() {
+	sink.Register(Kind, New)
+}
+
+// publisher posts each event to the endpoint in the structured content mode of CloudEvents
+type publisher struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func New(config *eventhandlerconfig.SinkConfig) (sink.Publisher, error) {
+	if config.HTTP == nil || config.HTTP.URL == "" {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "url of http event sink %s is empty", config.Name)
+	}
+	return &publisher{
+		url:     config.HTTP.URL,
+		headers: config.HTTP.Headers,
+		client:  &http.Client{Timeout: sink.Timeout(config)},
+	}, nil
+}
+
+func (p *publisher) Publish(ctx context.Context, events []*sink.CloudEvent) error {
+	for _, event := range events {
+		if err := p.publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *publisher) publish(ctx context.Context, event *sink.CloudEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", ContentType)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		respBody, _ := ioutil.ReadAll(io.LimitReader(resp.Body, _maxErrorBodySize))
+		return fmt.Errorf("failed to post event %s, status code: %d, body: %s",
+			event.ID, resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+func (p *publisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
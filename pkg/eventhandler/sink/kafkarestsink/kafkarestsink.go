@@ -0,0 +1,147 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkarestsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	eventhandlerconfig "github.com/horizoncd/horizon/pkg/config/eventhandler"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/eventhandler/sink"
+)
+
+const (
+	Kind = "kafka-rest"
+
+	// ContentType is the content type of json records of kafka REST proxy v2
+	ContentType = "application/vnd.kafka.json.v2+json"
+	Accept      = "application/vnd.kafka.v2+json"
+	// _maxErrorBodySize limits the response body recorded in error
+	_maxErrorBodySize = 1024
+)
+
+func init() {
+	sink.Register(Kind, New)
+}
+
+// publisher produces events to a topic through Confluent REST proxy v2, it doesn't speak the kafka protocol,
+// so the REST proxy is required to publish events to kafka.
+// Events are produced in the structured content mode of CloudEvents, and keyed by subject,
+// so that the events of a resource are kept in order in the same partition.
+type publisher struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+type record struct {
+	Key   string           `json:"key"`
+	Value *sink.CloudEvent `json:"value"`
+}
+
+type produceRequest struct {
+	Records []record `json:"records"`
+}
+
+type produceResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+func New(config *eventhandlerconfig.SinkConfig) (sink.Publisher, error) {
+	if config.KafkaREST == nil || config.KafkaREST.RESTProxyURL == "" || config.KafkaREST.Topic == "" {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"restProxyURL and topic of kafka-rest event sink %s are required", config.Name)
+	}
+	return &publisher{
+		url: fmt.Sprintf("%s/topics/%s", strings.TrimSuffix(config.KafkaREST.RESTProxyURL, "/"),
+			url.PathEscape(config.KafkaREST.Topic)),
+		headers: config.KafkaREST.Headers,
+		client:  &http.Client{Timeout: sink.Timeout(config)},
+	}, nil
+}
+
+func (p *publisher) Publish(ctx context.Context, events []*sink.CloudEvent) error {
+	request := produceRequest{Records: make([]record, 0, len(events))}
+	for _, event := range events {
+		request.Records = append(request.Records, record{Key: event.Subject, Value: event})
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", ContentType)
+	req.Header.Set("Accept", Accept)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to produce events to %s, status code: %d, body: %s",
+			p.url, resp.StatusCode, truncate(respBody))
+	}
+
+	// the proxy responds ok even if some records failed, check the offsets one by one
+	var response produceResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return fmt.Errorf("failed to unmarshal response of %s: %s, body: %s", p.url, err, truncate(respBody))
+	}
+	if len(response.Offsets) != len(events) {
+		return fmt.Errorf("%d events are produced while %d offsets returned by %s",
+			len(events), len(response.Offsets), p.url)
+	}
+	for i, offset := range response.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("failed to produce event %s to %s, error code: %d, error: %s",
+				events[i].ID, p.url, *offset.ErrorCode, offset.Error)
+		}
+	}
+	return nil
+}
+
+func (p *publisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
+
+func truncate(body []byte) []byte {
+	if len(body) > _maxErrorBodySize {
+		return body[:_maxErrorBodySize]
+	}
+	return body
+}
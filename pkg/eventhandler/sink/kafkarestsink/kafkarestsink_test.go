@@ -0,0 +1,72 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkarestsink
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	eventhandlerconfig "github.com/horizoncd/horizon/pkg/config/eventhandler"
+	"github.com/horizoncd/horizon/pkg/eventhandler/sink"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublish(t *testing.T) {
+	var (
+		request  produceRequest
+		response string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/topics/horizon-events", r.URL.Path)
+		assert.Equal(t, ContentType, r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&request))
+		w.Header().Set("Content-Type", Accept)
+		_, _ = w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	_, err := New(&eventhandlerconfig.SinkConfig{Name: "kafka", Kind: Kind})
+	assert.NotNil(t, err)
+
+	p, err := New(&eventhandlerconfig.SinkConfig{
+		Name: "kafka",
+		Kind: Kind,
+		KafkaREST: &eventhandlerconfig.KafkaRESTSinkConfig{
+			RESTProxyURL: server.URL + "/",
+			Topic:        "horizon-events",
+			Headers:      map[string]string{"Authorization": "secret"},
+		},
+	})
+	assert.Nil(t, err)
+	defer p.Close()
+
+	events := []*sink.CloudEvent{
+		{ID: "1", Subject: "clusters/1", Data: &sink.Data{ID: 1}},
+		{ID: "2", Subject: "clusters/2", Data: &sink.Data{ID: 2}},
+	}
+	response = `{"offsets":[{"partition":0,"offset":1},{"partition":1,"offset":1}]}`
+	assert.Nil(t, p.Publish(context.Background(), events))
+	assert.Equal(t, 2, len(request.Records))
+	assert.Equal(t, "clusters/2", request.Records[1].Key)
+	assert.Equal(t, "2", request.Records[1].Value.ID)
+
+	// failures of records are reported in offsets
+	response = `{"offsets":[{"partition":0,"offset":2},{"error_code":50003,"error":"timeout"}]}`
+	assert.NotNil(t, p.Publish(context.Background(), events))
+}
@@ -0,0 +1,138 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package natssink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	eventhandlerconfig "github.com/horizoncd/horizon/pkg/config/eventhandler"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/eventhandler/sink"
+)
+
+const (
+	Kind = "nats"
+
+	_clientName = "horizon-event-sink"
+)
+
+func init() {
+	sink.Register(Kind, New)
+}
+
+// publisher publishes events to NATS JetStream by the official client.
+// The events are published to the subject {subject}.{eventType} in the structured content mode of CloudEvents,
+// and considered accepted when the stream capturing the subject acknowledges them, so a stream must be
+// created for the subjects {subject}.> in advance. The id of event is sent as the message id,
+// so that the events published again after a failure are discarded by the duplicate window of the stream.
+type publisher struct {
+	sync.Mutex
+	config  *eventhandlerconfig.NATSSinkConfig
+	timeout time.Duration
+
+	conn *nats.Conn
+	js   nats.JetStreamContext
+}
+
+func New(config *eventhandlerconfig.SinkConfig) (sink.Publisher, error) {
+	if config.NATS == nil || config.NATS.URL == "" || config.NATS.Subject == "" {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"url and subject of nats event sink %s are required", config.Name)
+	}
+	return &publisher{
+		config:  config.NATS,
+		timeout: sink.Timeout(config),
+	}, nil
+}
+
+// Subject returns the subject to publish the event
+func Subject(prefix string, event *sink.CloudEvent) string {
+	return fmt.Sprintf("%s.%s", prefix, event.Data.EventType)
+}
+
+func (p *publisher) Publish(ctx context.Context, events []*sink.CloudEvent) error {
+	p.Lock()
+	defer p.Unlock()
+
+	js, err := p.connect()
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if err := p.publish(ctx, js, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *publisher) publish(ctx context.Context, js nats.JetStreamContext, event *sink.CloudEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	subject := Subject(p.config.Subject, event)
+	if _, err := js.Publish(subject, payload, nats.MsgId(event.ID), nats.Context(ctx)); err != nil {
+		return perror.Wrapf(err, "failed to publish event %s to %s", event.ID, subject)
+	}
+	return nil
+}
+
+// connect connects to the server if not connected, the client reconnects by itself after that
+func (p *publisher) connect() (nats.JetStreamContext, error) {
+	if p.conn != nil && !p.conn.IsClosed() {
+		return p.js, nil
+	}
+	options := []nats.Option{
+		nats.Name(_clientName),
+		nats.Timeout(p.timeout),
+	}
+	if p.config.Token != "" {
+		options = append(options, nats.Token(p.config.Token))
+	}
+	if p.config.Username != "" {
+		options = append(options, nats.UserInfo(p.config.Username, p.config.Password))
+	}
+	conn, err := nats.Connect(p.config.URL, options...)
+	if err != nil {
+		return nil, err
+	}
+	js, err := conn.JetStream(nats.MaxWait(p.timeout))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	p.conn, p.js = conn, js
+	return js, nil
+}
+
+func (p *publisher) Close() error {
+	p.Lock()
+	defer p.Unlock()
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn, p.js = nil, nil
+	}
+	return nil
+}
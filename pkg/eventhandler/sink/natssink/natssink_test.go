@@ -0,0 +1,162 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package natssink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	eventhandlerconfig "github.com/horizoncd/horizon/pkg/config/eventhandler"
+	"github.com/horizoncd/horizon/pkg/eventhandler/sink"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+)
+
+type connectOptions struct {
+	AuthToken string `json:"auth_token"`
+}
+
+type message struct {
+	subject string
+	msgID   string
+	event   sink.CloudEvent
+}
+
+// serve is a minimal NATS server with JetStream, which records published messages and acknowledges them,
+// except for the events of the type rejected
+func serve(t *testing.T, listener net.Listener, messages chan<- message, token, rejected string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	_, _ = conn.Write([]byte("INFO {\"server_id\":\"test\",\"auth_required\":true,\"headers\":true," +
+		"\"max_payload\":1048576}\r\n"))
+	// sids of the subscriptions of reply subjects
+	sids := map[string]string{}
+	reply := func(subject string, data string) {
+		for prefix, sid := range sids {
+			if strings.HasPrefix(subject, strings.TrimSuffix(prefix, "*")) {
+				_, _ = conn.Write([]byte(fmt.Sprintf("MSG %s %s %d\r\n%s\r\n", subject, sid, len(data), data)))
+			}
+		}
+	}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		args := strings.Fields(line)
+		switch args[0] {
+		case "CONNECT":
+			var options connectOptions
+			assert.Nil(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "CONNECT ")), &options))
+			if options.AuthToken != token {
+				_, _ = conn.Write([]byte("-ERR 'Authorization Violation'\r\n"))
+				return
+			}
+		case "SUB":
+			sids[args[1]] = args[len(args)-1]
+		case "PUB":
+			// the account info request of JetStream
+			size, _ := strconv.Atoi(args[len(args)-1])
+			if _, err := io.ReadFull(reader, make([]byte, size+2)); err != nil {
+				return
+			}
+			reply(args[2], "{}")
+		case "HPUB":
+			headerSize, _ := strconv.Atoi(args[3])
+			size, _ := strconv.Atoi(args[4])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			m := message{subject: args[1]}
+			for _, header := range strings.Split(string(payload[:headerSize]), "\r\n") {
+				if strings.HasPrefix(header, nats.MsgIdHdr+":") {
+					m.msgID = strings.TrimSpace(strings.TrimPrefix(header, nats.MsgIdHdr+":"))
+				}
+			}
+			assert.Nil(t, json.Unmarshal(payload[headerSize:size], &m.event))
+			if m.event.Data.EventType == rejected {
+				reply(args[2], `{"error":{"code":503,"description":"rejected"}}`)
+				continue
+			}
+			messages <- m
+			reply(args[2], fmt.Sprintf(`{"stream":"events","seq":%d}`, len(messages)))
+		case "PING":
+			_, _ = conn.Write([]byte("PONG\r\n"))
+		}
+	}
+}
+
+func TestPublish(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+	messages := make(chan message, 10)
+	go serve(t, listener, messages, "secret", "")
+
+	config := &eventhandlerconfig.SinkConfig{
+		Name: "nats",
+		Kind: Kind,
+		NATS: &eventhandlerconfig.NATSSinkConfig{
+			URL:     fmt.Sprintf("nats://%s", listener.Addr().String()),
+			Subject: "horizon.events",
+			Token:   "secret",
+		},
+	}
+	p, err := New(config)
+	assert.Nil(t, err)
+	defer p.Close()
+
+	events := []*sink.CloudEvent{
+		{ID: "1", Data: &sink.Data{ID: 1, EventType: "clusters_created"}},
+		{ID: "2", Data: &sink.Data{ID: 2, EventType: "clusters_deleted"}},
+	}
+	assert.Nil(t, p.Publish(context.Background(), events))
+	close(messages)
+	received := make([]message, 0)
+	for m := range messages {
+		received = append(received, m)
+	}
+	assert.Equal(t, 2, len(received))
+	assert.Equal(t, "horizon.events.clusters_created", received[0].subject)
+	assert.Equal(t, "horizon.events.clusters_deleted", received[1].subject)
+	assert.Equal(t, "2", received[1].event.ID)
+	assert.Equal(t, "2", received[1].msgID)
+
+	// the events not acknowledged by the stream fail to publish
+	go serve(t, listener, make(chan message, 10), "secret", "clusters_deleted")
+	p, err = New(config)
+	assert.Nil(t, err)
+	defer p.Close()
+	assert.NotNil(t, p.Publish(context.Background(), events))
+
+	// authorization violation
+	go serve(t, listener, make(chan message, 10), "another", "")
+	p, err = New(config)
+	assert.Nil(t, err)
+	defer p.Close()
+	assert.NotNil(t, p.Publish(context.Background(), events))
+}
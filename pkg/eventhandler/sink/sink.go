@@ -0,0 +1,264 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	eventhandlerconfig "github.com/horizoncd/horizon/pkg/config/eventhandler"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
+	"github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	CloudEventsSpecVersion = "1.0"
+	// CloudEventTypePrefix is the prefix of type attribute, the type of event is appended to it
+	CloudEventTypePrefix = "horizon.events."
+	DefaultSource        = "horizon"
+	DefaultBatchSize     = 100
+	DefaultTimeout       = 10 * time.Second
+	// DefaultIdleWaitInterval is the interval to wait when there is no new events
+	DefaultIdleWaitInterval = 3 * time.Second
+)
+
+// CloudEvent is an event in the CloudEvents JSON format, see https://github.com/cloudevents/spec
+type CloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	Data            *Data     `json:"data"`
+}
+
+// Data is the data of CloudEvent
+type Data struct {
+	ID           uint   `json:"id"`
+	ResourceType string `json:"resourceType"`
+	ResourceID   uint   `json:"resourceID"`
+	EventType    string `json:"eventType"`
+	Extra        string `json:"extra,omitempty"`
+	ReqID        string `json:"reqID,omitempty"`
+	CreatedBy    uint   `json:"createdBy"`
+}
+
+// NewCloudEvent converts an event to CloudEvent, the id is kept the same when published again,
+// so that consumers can deduplicate events by source and id
+func NewCloudEvent(source string, event *models.Event) *CloudEvent {
+	data := &Data{
+		ID:           event.ID,
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		EventType:    event.EventType,
+		ReqID:        event.ReqID,
+		CreatedBy:    event.CreatedBy,
+	}
+	if event.Extra != nil {
+		data.Extra = *event.Extra
+	}
+	return &CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              strconv.FormatUint(uint64(event.ID), 10),
+		Source:          source,
+		Type:            CloudEventTypePrefix + event.EventType,
+		Subject:         fmt.Sprintf("%s/%d", event.ResourceType, event.ResourceID),
+		Time:            event.CreatedAt,
+		DataContentType: "application/json",
+		Data:            data,
+	}
+}
+
+// Publisher publishes events to a message bus
+type Publisher interface {
+	// Publish returns nil only if all the events are accepted by the message bus
+	Publish(ctx context.Context, events []*CloudEvent) error
+	Close() error
+}
+
+type Constructor func(config *eventhandlerconfig.SinkConfig) (Publisher, error)
+
+var factory = make(map[string]Constructor)
+
+func Register(kind string, constructor Constructor) {
+	factory[kind] = constructor
+}
+
+func NewPublisher(config *eventhandlerconfig.SinkConfig) (Publisher, error) {
+	constructor, ok := factory[config.Kind]
+	if !ok {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"event sink initializes failed, kind = %v is not implement", config.Kind)
+	}
+	return constructor(config)
+}
+
+// Timeout returns the timeout of sink
+func Timeout(config *eventhandlerconfig.SinkConfig) time.Duration {
+	if config.Timeout == 0 {
+		return DefaultTimeout
+	}
+	return time.Duration(config.Timeout) * time.Second
+}
+
+// Handler publishes events by publisher.
+// It runs apart from the event handler service and tracks the position of events published
+// by its own cursor, so a slow or unavailable message bus never blocks webhooks or other sinks,
+// and the events failed to publish are published again in next round, which makes the delivery
+// at-least-once.
+type Handler struct {
+	name       string
+	source     string
+	eventTypes map[string]bool
+	batchSize  int
+	publisher  Publisher
+	eventMgr   eventmanager.Manager
+	cursor     *models.EventSinkCursor
+}
+
+func NewHandler(config *eventhandlerconfig.SinkConfig, publisher Publisher,
+	eventMgr eventmanager.Manager) (*Handler, error) {
+	if config.Name == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "name of event sink is empty")
+	}
+	h := &Handler{
+		name:       config.Name,
+		source:     config.Source,
+		eventTypes: make(map[string]bool, len(config.EventTypes)),
+		batchSize:  int(config.BatchSize),
+		publisher:  publisher,
+		eventMgr:   eventMgr,
+	}
+	if h.source == "" {
+		h.source = DefaultSource
+	}
+	if h.batchSize == 0 {
+		h.batchSize = DefaultBatchSize
+	}
+	supportedEvents := eventMgr.ListSupportEvents()
+	for _, eventType := range config.EventTypes {
+		if _, ok := supportedEvents[eventType]; !ok {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"unsupported event type %s of event sink %s", eventType, config.Name)
+		}
+		h.eventTypes[eventType] = true
+	}
+	return h, nil
+}
+
+// Name returns the name of sink
+func (h *Handler) Name() string {
+	return h.name
+}
+
+// Close closes the publisher
+func (h *Handler) Close() error {
+	return h.publisher.Close()
+}
+
+// Run publishes events until ctx is done, it waits for idleWaitInterval
+// when there is no new events or publishing failed
+func (h *Handler) Run(ctx context.Context, idleWaitInterval time.Duration) {
+	if idleWaitInterval <= 0 {
+		idleWaitInterval = DefaultIdleWaitInterval
+	}
+	for {
+		count, err := h.publishNext(ctx)
+		if err != nil {
+			log.Errorf(ctx, "failed to publish events by sink %s, error: %+v", h.name, err)
+		}
+		if err != nil || count == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(idleWaitInterval):
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
+
+// publishNext publishes the next batch of events after cursor,
+// and returns how many events the cursor moves forward
+func (h *Handler) publishNext(ctx context.Context) (int, error) {
+	if h.cursor == nil {
+		cursor, err := h.eventMgr.GetSinkCursor(ctx, h.name)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+				return 0, err
+			}
+			// publish the events created from now on at the first time
+			latest, err := h.eventMgr.GetLatestEventID(ctx)
+			if err != nil {
+				return 0, err
+			}
+			log.Infof(ctx, "cursor of event sink %s does not exist, start after event %d",
+				h.name, latest)
+			cursor = &models.EventSinkCursor{
+				Name:     h.name,
+				Position: latest,
+			}
+			if err := h.eventMgr.SaveSinkCursor(ctx, cursor); err != nil {
+				return 0, err
+			}
+		}
+		h.cursor = cursor
+	}
+
+	batch, err := h.eventMgr.ListEvents(ctx, &q.Query{Keywords: q.KeyWords{
+		common.StartID: h.cursor.Position,
+		common.Limit:   h.batchSize,
+	}})
+	if err != nil {
+		return 0, err
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+	cloudEvents := make([]*CloudEvent, 0, len(batch))
+	for _, event := range batch {
+		if len(h.eventTypes) > 0 && !h.eventTypes[event.EventType] {
+			continue
+		}
+		cloudEvents = append(cloudEvents, NewCloudEvent(h.source, event))
+	}
+	if len(cloudEvents) > 0 {
+		if err := h.publisher.Publish(ctx, cloudEvents); err != nil {
+			return 0, perror.Wrapf(err, "failed to publish events after %d by sink %s",
+				h.cursor.Position, h.name)
+		}
+	}
+	h.cursor.Position = batch[len(batch)-1].ID
+	if err := h.eventMgr.SaveSinkCursor(ctx, h.cursor); err != nil {
+		// the events will be published again if the cursor is lost
+		log.Errorf(ctx, "failed to save cursor(%d) of event sink %s, error: %+v",
+			h.cursor.Position, h.name, err)
+	}
+	return len(batch), nil
+}
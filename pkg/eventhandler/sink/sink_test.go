@@ -0,0 +1,144 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	eventhandlerconfig "github.com/horizoncd/horizon/pkg/config/eventhandler"
+	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
+	"github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePublisher struct {
+	fail   bool
+	events []*CloudEvent
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, events []*CloudEvent) error {
+	if p.fail {
+		return errors.New("message bus is unavailable")
+	}
+	p.events = append(p.events, events...)
+	return nil
+}
+
+func (p *fakePublisher) Close() error {
+	return nil
+}
+
+func createEvents(ctx context.Context, t *testing.T, mgr eventmanager.Manager, eventTypes ...string) []*models.Event {
+	events := make([]*models.Event, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		created, err := mgr.CreateEvent(ctx, &models.Event{
+			EventSummary: models.EventSummary{
+				ResourceType: common.ResourceCluster,
+				ResourceID:   1,
+				EventType:    eventType,
+			},
+			ReqID: "req",
+		})
+		assert.Nil(t, err)
+		events = append(events, created...)
+	}
+	return events
+}
+
+func TestHandler(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&models.Event{}, &models.EventSinkCursor{}); err != nil {
+		panic(err)
+	}
+	ctx := context.Background()
+	mgr := eventmanager.New(db)
+
+	_, err := NewHandler(&eventhandlerconfig.SinkConfig{
+		Name:       "kafka",
+		EventTypes: []string{"unknown"},
+	}, &fakePublisher{}, mgr)
+	assert.NotNil(t, err)
+
+	publisher := &fakePublisher{}
+	h, err := NewHandler(&eventhandlerconfig.SinkConfig{
+		Name:       "kafka",
+		Source:     "horizon-test",
+		EventTypes: []string{models.ClusterCreated, models.ClusterDeleted},
+		BatchSize:  1,
+	}, publisher, mgr)
+	assert.Nil(t, err)
+
+	// publish the events created after the sink is added
+	createEvents(ctx, t, mgr, models.ClusterCreated)
+	count, err := h.publishNext(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, 0, len(publisher.events))
+
+	events := createEvents(ctx, t, mgr, models.ClusterCreated, models.ClusterBuildDeployed)
+	count, err = h.publishNext(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 1, len(publisher.events))
+	event := publisher.events[0]
+	assert.Equal(t, "horizon-test", event.Source)
+	assert.Equal(t, CloudEventTypePrefix+models.ClusterCreated, event.Type)
+	assert.Equal(t, "clusters/1", event.Subject)
+	assert.Equal(t, events[0].ID, event.Data.ID)
+	// events not subscribed move the cursor only
+	count, err = h.publishNext(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 1, len(publisher.events))
+
+	cursor, err := mgr.GetSinkCursor(ctx, "kafka")
+	assert.Nil(t, err)
+	assert.Equal(t, events[1].ID, cursor.Position)
+
+	// events failed to publish are kept
+	publisher.fail = true
+	failed := createEvents(ctx, t, mgr, models.ClusterDeleted)
+	_, err = h.publishNext(ctx)
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, len(publisher.events))
+
+	// and published with the following events by a new handler
+	publisher.fail = false
+	h, err = NewHandler(&eventhandlerconfig.SinkConfig{
+		Name: "kafka",
+	}, publisher, mgr)
+	assert.Nil(t, err)
+	events = createEvents(ctx, t, mgr, models.ClusterCreated)
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		h.Run(runCtx, 10*time.Millisecond)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool {
+		cursor, err := mgr.GetSinkCursor(ctx, "kafka")
+		return err == nil && cursor.Position == events[0].ID
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+	assert.Equal(t, 3, len(publisher.events))
+	assert.Equal(t, failed[0].ID, publisher.events[1].Data.ID)
+	assert.Equal(t, events[0].ID, publisher.events[2].Data.ID)
+}
@@ -0,0 +1,151 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wlgenerator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	webhookmodels "github.com/horizoncd/horizon/pkg/webhook/models"
+)
+
+// _defaultTextTemplate renders the text of chat bot messages when the webhook has no template
+const _defaultTextTemplate = `[Horizon] {{.EventType}}: {{.ResourceType}} ` +
+	`{{if .ResourceName}}{{.ResourceName}}{{else}}#{{.ResourceID}}{{end}}` +
+	`{{with .Cluster}}{{if .Env}} ({{.Env}}){{end}}{{end}}` +
+	`{{with .Pipelinerun}}, {{.Action}}{{if .Title}} "{{.Title}}"{{end}}{{end}}` +
+	`{{with .User}} by {{.Name}}{{end}}` +
+	`{{with .Extra}}
+{{.}}{{end}}`
+
+var payloadTemplateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// PayloadData is the data to render payload templates of webhooks,
+// it exposes all the fields of MessageContent and the resource which the event happened on
+type PayloadData struct {
+	*MessageContent
+	ResourceType string
+	ResourceID   uint
+	ResourceName string
+	CreatedAt    time.Time
+}
+
+// NormalizePayloadFormat returns the payload format of webhook, empty means the default one
+func NormalizePayloadFormat(format string) string {
+	if format == "" {
+		return webhookmodels.PayloadFormatDefault
+	}
+	return format
+}
+
+// ValidatePayload checks whether the payload format is supported and the template can be parsed
+func ValidatePayload(format, text string) error {
+	switch NormalizePayloadFormat(format) {
+	case webhookmodels.PayloadFormatDefault:
+		if text != "" {
+			return fmt.Errorf("payload template is not supported by the default payload format")
+		}
+		return nil
+	case webhookmodels.PayloadFormatTemplate:
+		if text == "" {
+			return fmt.Errorf("payload template should not be empty")
+		}
+	case webhookmodels.PayloadFormatSlack, webhookmodels.PayloadFormatFeishu,
+		webhookmodels.PayloadFormatDingTalk, webhookmodels.PayloadFormatWeCom:
+	default:
+		return fmt.Errorf("unsupported payload format: %s", format)
+	}
+	if _, err := parsePayloadTemplate(text); err != nil {
+		return fmt.Errorf("invalid payload template: %v", err)
+	}
+	return nil
+}
+
+// RenderPayload renders the request body of the webhook according to its payload format.
+// The chat bot formats render the template of the webhook, or a default summary when it is empty,
+// as the text of the message.
+func RenderPayload(webhook *webhookmodels.Webhook, data *PayloadData) (string, error) {
+	format := NormalizePayloadFormat(webhook.PayloadFormat)
+	switch format {
+	case webhookmodels.PayloadFormatDefault:
+		body, err := json.Marshal(data.MessageContent)
+		if err != nil {
+			return "", err
+		}
+		return string(body), nil
+	case webhookmodels.PayloadFormatTemplate:
+		return executePayloadTemplate(webhook.PayloadTemplate, data)
+	}
+
+	text := webhook.PayloadTemplate
+	if text == "" {
+		text = _defaultTextTemplate
+	}
+	content, err := executePayloadTemplate(text, data)
+	if err != nil {
+		return "", err
+	}
+	content = strings.TrimSpace(content)
+
+	var message interface{}
+	switch format {
+	case webhookmodels.PayloadFormatSlack:
+		message = map[string]interface{}{
+			"text": content,
+		}
+	case webhookmodels.PayloadFormatFeishu:
+		message = map[string]interface{}{
+			"msg_type": "text",
+			"content":  map[string]string{"text": content},
+		}
+	case webhookmodels.PayloadFormatDingTalk, webhookmodels.PayloadFormatWeCom:
+		message = map[string]interface{}{
+			"msgtype": "text",
+			"text":    map[string]string{"content": content},
+		}
+	default:
+		return "", fmt.Errorf("unsupported payload format: %s", format)
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+func parsePayloadTemplate(text string) (*template.Template, error) {
+	return template.New("payload").Funcs(payloadTemplateFuncs).Parse(text)
+}
+
+func executePayloadTemplate(text string, data *PayloadData) (string, error) {
+	tmpl, err := parsePayloadTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,93 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wlgenerator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	webhookmodels "github.com/horizoncd/horizon/pkg/webhook/models"
+)
+
+func TestRenderPayload(t *testing.T) {
+	data := &PayloadData{
+		MessageContent: &MessageContent{
+			EventID:   1,
+			WebhookID: 2,
+			EventType: "clusters_deployed",
+			Cluster: &ClusterInfo{
+				ResourceCommonInfo: ResourceCommonInfo{ID: 3, Name: "demo-test"},
+				ApplicationName:    "demo",
+				Env:                "test",
+			},
+			User: &usermodels.UserBasic{Name: "tom"},
+		},
+		ResourceType: "clusters",
+		ResourceID:   3,
+		ResourceName: "demo-test",
+	}
+	const summary = "[Horizon] clusters_deployed: clusters demo-test (test) by tom"
+
+	body, err := RenderPayload(&webhookmodels.Webhook{}, data)
+	assert.Nil(t, err)
+	var message MessageContent
+	assert.Nil(t, json.Unmarshal([]byte(body), &message))
+	assert.Equal(t, "demo-test", message.Cluster.Name)
+
+	body, err = RenderPayload(&webhookmodels.Webhook{
+		PayloadFormat: webhookmodels.PayloadFormatSlack,
+	}, data)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"text":"`+summary+`"}`, body)
+
+	body, err = RenderPayload(&webhookmodels.Webhook{
+		PayloadFormat: webhookmodels.PayloadFormatFeishu,
+	}, data)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"msg_type":"text","content":{"text":"`+summary+`"}}`, body)
+
+	body, err = RenderPayload(&webhookmodels.Webhook{
+		PayloadFormat:   webhookmodels.PayloadFormatDingTalk,
+		PayloadTemplate: `{{.Cluster.Name}} is deployed`,
+	}, data)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"msgtype":"text","text":{"content":"demo-test is deployed"}}`, body)
+
+	body, err = RenderPayload(&webhookmodels.Webhook{
+		PayloadFormat:   webhookmodels.PayloadFormatTemplate,
+		PayloadTemplate: `{"cluster":{{json .Cluster.Name}},"operator":{{json .User.Name}}}`,
+	}, data)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"cluster":"demo-test","operator":"tom"}`, body)
+
+	_, err = RenderPayload(&webhookmodels.Webhook{
+		PayloadFormat:   webhookmodels.PayloadFormatTemplate,
+		PayloadTemplate: `{{.Application.Name}}`,
+	}, data)
+	assert.NotNil(t, err)
+}
+
+func TestValidatePayload(t *testing.T) {
+	assert.Nil(t, ValidatePayload("", ""))
+	assert.Nil(t, ValidatePayload(webhookmodels.PayloadFormatWeCom, ""))
+	assert.Nil(t, ValidatePayload(webhookmodels.PayloadFormatTemplate, `{{.EventType}}`))
+	assert.NotNil(t, ValidatePayload(webhookmodels.PayloadFormatDefault, `{{.EventType}}`))
+	assert.NotNil(t, ValidatePayload(webhookmodels.PayloadFormatTemplate, ""))
+	assert.NotNil(t, ValidatePayload(webhookmodels.PayloadFormatSlack, `{{.EventType`))
+	assert.NotNil(t, ValidatePayload("teams", ""))
+}
@@ -26,17 +26,19 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/horizoncd/horizon/core/common"
-	webhookctl "github.com/horizoncd/horizon/core/controller/webhook"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
 	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	applicationmodels "github.com/horizoncd/horizon/pkg/application/models"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
 	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
 	"github.com/horizoncd/horizon/pkg/event/models"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
+	templatemanager "github.com/horizoncd/horizon/pkg/template/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	"github.com/horizoncd/horizon/pkg/util/log"
@@ -45,9 +47,13 @@ import (
 )
 
 const (
+	// Deprecated: WebhookSecretHeader carries the raw secret unless webhook.disableSecretHeader is enabled,
+	// receivers should verify the signature headers instead
 	WebhookSecretHeader      = "X-Horizon-Webhook-Secret"
 	WebhookContentTypeHeader = "Content-Type"
 	WebhookContentType       = "application/json;charset=utf-8"
+	// WebhookFormatHeader tells the payload format of the request body
+	WebhookFormatHeader = "X-Horizon-Webhook-Format"
 )
 
 // MessageContent will be marshaled as webhook request body
@@ -59,6 +65,9 @@ type MessageContent struct {
 	Cluster     *ClusterInfo          `json:"cluster,omitempty"`
 	Pipelinerun *PipelinerunInfo      `json:"pipelinerun,omitempty"`
 	Member      *MemberInfo           `json:"member,omitempty"`
+	Group       *GroupInfo            `json:"group,omitempty"`
+	Template    *TemplateInfo         `json:"template,omitempty"`
+	Release     *TemplateReleaseInfo  `json:"templateRelease,omitempty"`
 	EventType   string                `json:"eventType,omitempty"`
 	User        *usermodels.UserBasic `json:"user,omitempty"`
 	Extra       *string               `json:"extra,omitempty"`
@@ -104,6 +113,26 @@ type MemberInfo struct {
 	MemberName   string                    `json:"memberName"`
 }
 
+// GroupInfo contains basic info of group
+type GroupInfo struct {
+	ResourceCommonInfo
+	Path     string `json:"path,omitempty"`
+	ParentID uint   `json:"parentID"`
+}
+
+// TemplateInfo contains basic info of template
+type TemplateInfo struct {
+	ResourceCommonInfo
+	GroupID uint `json:"groupID"`
+}
+
+// TemplateReleaseInfo contains basic info of template release
+type TemplateReleaseInfo struct {
+	ResourceCommonInfo
+	TemplateID   uint   `json:"templateID,omitempty"`
+	TemplateName string `json:"templateName,omitempty"`
+}
+
 // WebhookLogGenerator generates webhook logs by events
 type WebhookLogGenerator struct {
 	webhookMgr     webhookmanager.Manager
@@ -114,6 +143,7 @@ type WebhookLogGenerator struct {
 	prMgr          *prmanager.PRManager
 	memberMgr      membermanager.Manager
 	userMgr        usermanager.Manager
+	templateMgr    templatemanager.Manager
 }
 
 func NewWebhookLogGenerator(manager *managerparam.Manager) *WebhookLogGenerator {
@@ -126,6 +156,7 @@ func NewWebhookLogGenerator(manager *managerparam.Manager) *WebhookLogGenerator
 		prMgr:          manager.PRMgr,
 		memberMgr:      manager.MemberMgr,
 		userMgr:        manager.UserMgr,
+		templateMgr:    manager.TemplateMgr,
 	}
 }
 
@@ -137,6 +168,8 @@ type messageDependency struct {
 	pipelinerun *prmodels.Pipelinerun
 	member      *membermodels.Member
 	userBasic   *usermodels.UserBasic
+	// resourceExtra is the extra of group, template and template release events
+	resourceExtra *models.ResourceExtra
 }
 
 // listSystemResources lists root group(0) as system resource
@@ -220,13 +253,93 @@ func (w *WebhookLogGenerator) listAssociatedResourcesOfMember(ctx context.Contex
 		_, resources = w.listAssociatedResourcesOfApp(ctx, member.ResourceID)
 	case membermodels.TypeApplicationCluster:
 		_, _, resources = w.listAssociatedResourcesOfCluster(ctx, member.ResourceID)
+	case membermodels.TypeGroup:
+		resources = w.listAssociatedResourcesOfGroupID(ctx, member.ResourceID)
+	case membermodels.TypeTemplate:
+		template, err := w.templateMgr.GetByID(ctx, member.ResourceID)
+		if err != nil {
+			log.Warningf(ctx, "template %d of member %d is not exist", member.ResourceID, id)
+			return member, usermodels.ToUser(user), w.listSystemResources()
+		}
+		resources = w.listAssociatedResourcesOfGroupID(ctx, template.GroupID)
 	default:
-		// TODO: support member event of groups and templates
 		log.Warningf(ctx, "member event of resource type %s is unsupported yet", member.ResourceType)
 	}
 	return member, usermodels.ToUser(user), resources
 }
 
+// listAssociatedResourcesOfGroupID gets group by id and list the group and all its parent groups
+func (w *WebhookLogGenerator) listAssociatedResourcesOfGroupID(ctx context.Context, id uint) map[string][]uint {
+	resources := w.listSystemResources()
+	if id == 0 {
+		return resources
+	}
+	group, err := w.groupMgr.GetByID(ctx, id)
+	if err != nil {
+		log.Warningf(ctx, "group %d is not exist", id)
+		return resources
+	}
+	groupIDs := groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs)
+	resources[common.ResourceGroup] = append(resources[common.ResourceGroup], groupIDs...)
+	return resources
+}
+
+// listAssociatedResourcesOfGroup lists the group and all its parent groups of a group event.
+// The traversalIDs recorded in event are preferred, as the group may have been deleted.
+func (w *WebhookLogGenerator) listAssociatedResourcesOfGroup(ctx context.Context,
+	e *models.Event) (*models.ResourceExtra, map[string][]uint) {
+	extra := parseResourceExtra(ctx, e)
+	if extra == nil || extra.TraversalIDs == "" {
+		group, err := w.groupMgr.GetByID(ctx, e.ResourceID)
+		if err != nil {
+			log.Warningf(ctx, "group %d is not exist", e.ResourceID)
+			return nil, nil
+		}
+		extra = &models.ResourceExtra{
+			Name:         group.Name,
+			Path:         group.Path,
+			GroupID:      group.ParentID,
+			TraversalIDs: group.TraversalIDs,
+		}
+	}
+	resources := w.listSystemResources()
+	groupIDs := groupmanager.FormatIDsFromTraversalIDs(extra.TraversalIDs)
+	resources[common.ResourceGroup] = append(resources[common.ResourceGroup], groupIDs...)
+	return extra, resources
+}
+
+// listAssociatedResourcesOfTemplate lists all the groups which the template of event belongs to.
+// The group recorded in event is preferred, as templates are deleted permanently.
+func (w *WebhookLogGenerator) listAssociatedResourcesOfTemplate(ctx context.Context,
+	e *models.Event) (*models.ResourceExtra, map[string][]uint) {
+	extra := parseResourceExtra(ctx, e)
+	if extra == nil {
+		template, err := w.templateMgr.GetByID(ctx, e.ResourceID)
+		if err != nil {
+			log.Warningf(ctx, "template %d is not exist", e.ResourceID)
+			return nil, nil
+		}
+		extra = &models.ResourceExtra{
+			Name:    template.Name,
+			GroupID: template.GroupID,
+		}
+	}
+	return extra, w.listAssociatedResourcesOfGroupID(ctx, extra.GroupID)
+}
+
+// parseResourceExtra parses the extra of group, template and template release events
+func parseResourceExtra(ctx context.Context, e *models.Event) *models.ResourceExtra {
+	if e.Extra == nil || *e.Extra == "" {
+		return nil
+	}
+	extra := &models.ResourceExtra{}
+	if err := json.Unmarshal([]byte(*e.Extra), extra); err != nil {
+		log.Warningf(ctx, "failed to unmarshal extra of event %d, err: %s", e.ID, err.Error())
+		return nil
+	}
+	return extra
+}
+
 // listAssociatedResources list all the associated resources of event to find all the webhooks
 func (w *WebhookLogGenerator) listAssociatedResources(ctx context.Context,
 	e *models.Event) (*messageDependency, map[string][]uint) {
@@ -257,6 +370,16 @@ func (w *WebhookLogGenerator) listAssociatedResources(ctx context.Context,
 		member, userBasic, resources = w.listAssociatedResourcesOfMember(ctx, e.ResourceID)
 		dep.member = member
 		dep.userBasic = userBasic
+	case common.ResourceRole:
+		// roles are global, only system webhooks are notified
+		resources = w.listSystemResources()
+	case common.ResourceGroup:
+		dep.resourceExtra, resources = w.listAssociatedResourcesOfGroup(ctx, e)
+	case common.ResourceTemplate:
+		dep.resourceExtra, resources = w.listAssociatedResourcesOfTemplate(ctx, e)
+	case common.ResourceTemplateRelease:
+		// the extra of release event records the template it belongs to
+		dep.resourceExtra, resources = w.listAssociatedResourcesOfTemplate(ctx, e)
 	default:
 		log.Infof(ctx, "resource type %s is unsupported",
 			e.ResourceType)
@@ -265,10 +388,10 @@ func (w *WebhookLogGenerator) listAssociatedResources(ctx context.Context,
 }
 
 // makeRequestHeaders assemble headers of webhook request
-func (w *WebhookLogGenerator) makeRequestHeaders(secret string) (string, error) {
+func (w *WebhookLogGenerator) makeRequestHeaders(webhook *webhookmodels.Webhook) (string, error) {
 	header := http.Header{}
-	header.Add(WebhookSecretHeader, secret)
 	header.Add(WebhookContentTypeHeader, WebhookContentType)
+	header.Add(WebhookFormatHeader, NormalizePayloadFormat(webhook.PayloadFormat))
 	headerByte, err := yaml.Marshal(header)
 	if err != nil {
 		return "", err
@@ -276,8 +399,8 @@ func (w *WebhookLogGenerator) makeRequestHeaders(secret string) (string, error)
 	return string(headerByte), nil
 }
 
-// makeRequestHeaders assemble body of webhook request
-func (w *WebhookLogGenerator) makeRequestBody(ctx context.Context, dep *messageDependency) (string, error) {
+// makePayloadData assemble the message of event and the data to render payload templates
+func (w *WebhookLogGenerator) makePayloadData(ctx context.Context, dep *messageDependency) (*PayloadData, error) {
 	message := MessageContent{
 		EventID:   dep.event.ID,
 		WebhookID: dep.webhook.ID,
@@ -288,7 +411,7 @@ func (w *WebhookLogGenerator) makeRequestBody(ctx context.Context, dep *messageD
 	if dep.event.CreatedBy != 0 {
 		user, err := w.userMgr.GetUserByID(ctx, dep.event.CreatedBy)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		message.User = usermodels.ToUser(user)
 	}
@@ -351,12 +474,78 @@ func (w *WebhookLogGenerator) makeRequestBody(ctx context.Context, dep *messageD
 		}
 	}
 
-	reqBody, err := json.Marshal(message)
+	if dep.resourceExtra != nil {
+		commonInfo := ResourceCommonInfo{
+			ID:   dep.event.ResourceID,
+			Name: dep.resourceExtra.Name,
+		}
+		switch dep.event.ResourceType {
+		case common.ResourceGroup:
+			message.Group = &GroupInfo{
+				ResourceCommonInfo: commonInfo,
+				Path:               dep.resourceExtra.Path,
+				ParentID:           dep.resourceExtra.GroupID,
+			}
+		case common.ResourceTemplate:
+			message.Template = &TemplateInfo{
+				ResourceCommonInfo: commonInfo,
+				GroupID:            dep.resourceExtra.GroupID,
+			}
+		case common.ResourceTemplateRelease:
+			message.Release = &TemplateReleaseInfo{
+				ResourceCommonInfo: commonInfo,
+				TemplateID:         dep.resourceExtra.TemplateID,
+				TemplateName:       dep.resourceExtra.TemplateName,
+			}
+		}
+	}
+
+	data := &PayloadData{
+		MessageContent: &message,
+		ResourceType:   dep.event.ResourceType,
+		ResourceID:     dep.event.ResourceID,
+		CreatedAt:      dep.event.CreatedAt,
+	}
+	switch {
+	case message.Application != nil:
+		data.ResourceName = message.Application.Name
+	case message.Cluster != nil:
+		data.ResourceName = message.Cluster.Name
+	case message.Member != nil:
+		data.ResourceName = message.Member.MemberName
+	case dep.resourceExtra != nil:
+		data.ResourceName = dep.resourceExtra.Name
+	}
+	return data, nil
+}
+
+// Preview renders the request body of the webhook against a past event,
+// the event should happen on the resource of the webhook or its descendants
+func (w *WebhookLogGenerator) Preview(ctx context.Context, webhook *webhookmodels.Webhook,
+	event *models.Event) (string, error) {
+	dep, resources := w.listAssociatedResources(ctx, event)
+	associated := false
+	for _, id := range resources[webhook.ResourceType] {
+		if id == webhook.ResourceID {
+			associated = true
+			break
+		}
+	}
+	if !associated {
+		return "", perror.Wrapf(herrors.ErrParamInvalid,
+			"event %d is not associated with %s %d", event.ID, webhook.ResourceType, webhook.ResourceID)
+	}
+	dep.webhook = webhook
+	dep.event = event
+	data, err := w.makePayloadData(ctx, dep)
 	if err != nil {
-		log.Errorf(ctx, fmt.Sprintf("failed to marshal message, error: %+v", err))
 		return "", err
 	}
-	return string(reqBody), nil
+	body, err := RenderPayload(webhook, data)
+	if err != nil {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "failed to render payload: %s", err.Error())
+	}
+	return body, nil
 }
 
 // Process processes all the webhook logs that are in waiting status and send webhook requests
@@ -389,11 +578,7 @@ func (w *WebhookLogGenerator) Process(ctx context.Context, events []*models.Even
 		// 3. assemble webhook list of all events, prepare to create
 		for _, webhook := range webhooks {
 			// 3.1 if event does not match webhook trigger, skip
-			ok, err := webhookctl.CheckIfEventMatch(webhook, event)
-			if err != nil {
-				log.Errorf(ctx, "failed to match triggers %s, error: %+v", webhook.Triggers, err)
-				continue
-			} else if !ok {
+			if !webhook.MatchEventType(event.EventType) {
 				continue
 			}
 			log.Debugf(ctx, "event %d matches webhook %s", event.ID, webhook.URL)
@@ -402,13 +587,14 @@ func (w *WebhookLogGenerator) Process(ctx context.Context, events []*models.Even
 				conditionsToCreate[event.ID] = map[uint]messageDependency{}
 			}
 			conditionsToCreate[event.ID][webhook.ID] = messageDependency{
-				webhook:     webhook,
-				event:       event,
-				application: dependency.application,
-				cluster:     dependency.cluster,
-				pipelinerun: dependency.pipelinerun,
-				member:      dependency.member,
-				userBasic:   dependency.userBasic,
+				webhook:       webhook,
+				event:         event,
+				application:   dependency.application,
+				cluster:       dependency.cluster,
+				pipelinerun:   dependency.pipelinerun,
+				member:        dependency.member,
+				userBasic:     dependency.userBasic,
+				resourceExtra: dependency.resourceExtra,
 			}
 			conditionsToQuery[event.ID] = append(conditionsToQuery[event.ID], webhook.ID)
 		}
@@ -437,30 +623,45 @@ func (w *WebhookLogGenerator) Process(ctx context.Context, events []*models.Even
 	}
 	for _, dependencyMap := range conditionsToCreate {
 		for _, dependency := range dependencyMap {
-			headers, err := w.makeRequestHeaders(dependency.webhook.Secret)
+			headers, err := w.makeRequestHeaders(dependency.webhook)
 			if err != nil {
 				log.Errorf(ctx, fmt.Sprintf("failed to make headers, error: %+v", err))
 				continue
 			}
 
-			body, err := w.makeRequestBody(ctx, &dependency)
+			data, err := w.makePayloadData(ctx, &dependency)
 			if err != nil {
-				log.Errorf(ctx, fmt.Sprintf("failed to make headers, error: %+v", err))
+				log.Errorf(ctx, fmt.Sprintf("failed to make payload data of webhook %d, error: %+v",
+					dependency.webhook.ID, err))
 				continue
 			}
 
-			webhookLogs = append(webhookLogs, &webhookmodels.WebhookLog{
+			wl := &webhookmodels.WebhookLog{
 				EventID:        dependency.event.ID,
 				WebhookID:      dependency.webhook.ID,
 				URL:            dependency.webhook.URL,
 				RequestHeaders: headers,
-				RequestData:    body,
 				Status:         webhookmodels.StatusWaiting,
-			})
+			}
+			// retrying doesn't help with a broken payload template, so the log fails at once
+			// to let users know why the webhook is not sent
+			body, err := RenderPayload(dependency.webhook, data)
+			if err != nil {
+				log.Errorf(ctx, fmt.Sprintf("failed to render payload of webhook %d, error: %+v",
+					dependency.webhook.ID, err))
+				wl.Status = webhookmodels.StatusFailed
+				wl.ErrorMessage = fmt.Sprintf("failed to render payload, error: %s", err.Error())
+			} else {
+				wl.RequestData = body
+			}
+			webhookLogs = append(webhookLogs, wl)
 		}
 	}
 
 	// 6. batch create webhook logs
+	if len(webhookLogs) == 0 {
+		return nil
+	}
 	if _, err := w.webhookMgr.CreateWebhookLogs(ctx, webhookLogs); err != nil {
 		log.Errorf(ctx, "failed to create webhooks, error: %s", err.Error())
 		return err
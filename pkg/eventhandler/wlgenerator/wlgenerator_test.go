@@ -0,0 +1,172 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wlgenerator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	tmodels "github.com/horizoncd/horizon/pkg/template/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	webhookmodels "github.com/horizoncd/horizon/pkg/webhook/models"
+)
+
+func TestListAssociatedResourcesOfGroupAndTemplate(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&groupmodels.Group{}, &appmodels.Application{},
+		&tmodels.Template{}, &membermodels.Member{}, &usermodels.User{}))
+	mgr := managerparam.InitManager(db)
+	// nolint
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+
+	parent, err := mgr.GroupMgr.Create(ctx, &groupmodels.Group{Name: "parent", Path: "parent"})
+	assert.Nil(t, err)
+	child, err := mgr.GroupMgr.Create(ctx, &groupmodels.Group{Name: "child", Path: "child",
+		ParentID: parent.ID})
+	assert.Nil(t, err)
+	child, err = mgr.GroupMgr.GetByID(ctx, child.ID)
+	assert.Nil(t, err)
+
+	generator := NewWebhookLogGenerator(mgr)
+	newEvent := func(resourceType string, resourceID uint, eventType string,
+		extra *eventmodels.ResourceExtra) *eventmodels.Event {
+		bts, err := json.Marshal(extra)
+		assert.Nil(t, err)
+		extraStr := string(bts)
+		return &eventmodels.Event{
+			EventSummary: eventmodels.EventSummary{
+				ResourceType: resourceType,
+				ResourceID:   resourceID,
+				EventType:    eventType,
+				Extra:        &extraStr,
+			},
+		}
+	}
+
+	// the deleted group is identified by the traversalIDs in extra
+	event := newEvent(common.ResourceGroup, 100, eventmodels.GroupDeleted, &eventmodels.ResourceExtra{
+		Name:         "deleted",
+		Path:         "deleted",
+		GroupID:      child.ID,
+		TraversalIDs: child.TraversalIDs + ",100",
+	})
+	dep, resources := generator.listAssociatedResources(ctx, event)
+	assert.Equal(t, []uint{0, parent.ID, child.ID, 100}, resources[common.ResourceGroup])
+
+	dep.webhook = &webhookmodels.Webhook{}
+	dep.event = event
+	data, err := generator.makePayloadData(ctx, dep)
+	assert.Nil(t, err)
+	assert.Equal(t, "deleted", data.ResourceName)
+	assert.Equal(t, child.ID, data.Group.ParentID)
+
+	// group events without extra fall back to the group in db
+	event = &eventmodels.Event{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourceGroup,
+			ResourceID:   child.ID,
+			EventType:    eventmodels.GroupCreated,
+		},
+	}
+	_, resources = generator.listAssociatedResources(ctx, event)
+	assert.Equal(t, []uint{0, parent.ID, child.ID}, resources[common.ResourceGroup])
+
+	// template release events are associated with groups of the template
+	event = newEvent(common.ResourceTemplateRelease, 1, eventmodels.TemplateReleaseDeleted,
+		&eventmodels.ResourceExtra{
+			Name:         "v1.0.0",
+			GroupID:      child.ID,
+			TemplateID:   1,
+			TemplateName: "javaapp",
+		})
+	dep, resources = generator.listAssociatedResources(ctx, event)
+	assert.Equal(t, []uint{0, parent.ID, child.ID}, resources[common.ResourceGroup])
+	dep.webhook = &webhookmodels.Webhook{}
+	dep.event = event
+	data, err = generator.makePayloadData(ctx, dep)
+	assert.Nil(t, err)
+	assert.Equal(t, "javaapp", data.Release.TemplateName)
+
+	// templates in root group only notify system webhooks
+	event = newEvent(common.ResourceTemplate, 1, eventmodels.TemplateCreated,
+		&eventmodels.ResourceExtra{Name: "javaapp"})
+	_, resources = generator.listAssociatedResources(ctx, event)
+	assert.Equal(t, []uint{0}, resources[common.ResourceGroup])
+}
+
+func TestProcessRenderFailure(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&groupmodels.Group{}, &tmodels.Template{}, &usermodels.User{},
+		&webhookmodels.Webhook{}, &webhookmodels.WebhookLog{}))
+	mgr := managerparam.InitManager(db)
+	ctx := context.Background()
+
+	for _, webhook := range []*webhookmodels.Webhook{
+		{Enabled: true, URL: "http://broken", Triggers: eventmodels.Any,
+			PayloadFormat: webhookmodels.PayloadFormatTemplate, PayloadTemplate: "{{ .NoSuchField }}"},
+		{Enabled: true, URL: "http://default", Triggers: eventmodels.Any},
+	} {
+		webhook.ResourceType = common.ResourceGroup
+		_, err := mgr.WebhookMgr.CreateWebhook(ctx, webhook)
+		assert.Nil(t, err)
+	}
+
+	extra := `{"name":"javaapp"}`
+	event := &eventmodels.Event{
+		ID: 1,
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourceTemplate,
+			ResourceID:   1,
+			EventType:    eventmodels.TemplateCreated,
+			Extra:        &extra,
+		},
+		CreatedAt: time.Now().Add(time.Minute),
+	}
+	assert.Nil(t, NewWebhookLogGenerator(mgr).Process(ctx, []*eventmodels.Event{event}, false))
+
+	// the log whose payload failed to render is created as failed with the error
+	var logs []*webhookmodels.WebhookLog
+	assert.Nil(t, db.Order("url").Find(&logs).Error)
+	assert.Equal(t, 2, len(logs))
+	assert.Equal(t, "http://broken", logs[0].URL)
+	assert.Equal(t, webhookmodels.StatusFailed, logs[0].Status)
+	assert.Contains(t, logs[0].ErrorMessage, "failed to render payload")
+	assert.Equal(t, "", logs[0].RequestData)
+	assert.Equal(t, "http://default", logs[1].URL)
+	assert.Equal(t, webhookmodels.StatusWaiting, logs[1].Status)
+	assert.Contains(t, logs[1].RequestData, "javaapp")
+
+	// and it can't be resent or redelivered
+	_, err := mgr.WebhookMgr.ResendWebhook(ctx, logs[0].ID)
+	assert.NotNil(t, err)
+	count, err := mgr.WebhookMgr.RedeliverWebhookLogs(ctx, logs[0].WebhookID, time.Now().Add(-time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+}
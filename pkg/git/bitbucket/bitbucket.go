@@ -0,0 +1,275 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bitbucket implements git.Helper for Bitbucket Server (Data Center).
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	gitconfig "github.com/horizoncd/horizon/pkg/config/git"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/git"
+)
+
+const Kind = "bitbucket"
+
+func init() {
+	git.Register(Kind, New)
+}
+
+type Helper struct {
+	client *http.Client
+	url    string
+	token  string
+}
+
+type commit struct {
+	ID        string `json:"id"`
+	DisplayID string `json:"displayId"`
+	Message   string `json:"message"`
+}
+
+type ref struct {
+	ID           string `json:"id"`
+	DisplayID    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+}
+
+type refPage struct {
+	Values        []ref `json:"values"`
+	IsLastPage    bool  `json:"isLastPage"`
+	NextPageStart int   `json:"nextPageStart"`
+}
+
+func New(ctx context.Context, config *gitconfig.Repo) (git.Helper, error) {
+	return &Helper{
+		client: http.DefaultClient,
+		url:    strings.TrimSuffix(config.URL, "/"),
+		token:  config.Token,
+	}, nil
+}
+
+func (h Helper) GetTagArchive(ctx context.Context, gitURL, tagName string) (*git.Tag, error) {
+	project, repo, err := extractProjectAndRepo(gitURL)
+	if err != nil {
+		return nil, err
+	}
+	t, err := h.findRef(ctx, project, repo, "tags", tagName)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("at", t.ID)
+	query.Set("format", "tar.gz")
+	resp, err := h.do(ctx, repoPath(project, repo)+"/archive", query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	archiveData, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to read archive: err = %v", err)
+	}
+
+	shortID := t.LatestCommit
+	if len(shortID) > 11 {
+		shortID = shortID[:11]
+	}
+	return &git.Tag{
+		ShortID:     shortID,
+		Name:        tagName,
+		ArchiveData: archiveData,
+	}, nil
+}
+
+func (h Helper) GetCommit(ctx context.Context, gitURL string, refType string, refName string) (*git.Commit, error) {
+	project, repo, err := extractProjectAndRepo(gitURL)
+	if err != nil {
+		return nil, err
+	}
+	var commitID string
+	switch refType {
+	case git.GitRefTypeCommit:
+		commitID = refName
+	case git.GitRefTypeTag:
+		t, err := h.findRef(ctx, project, repo, "tags", refName)
+		if err != nil {
+			return nil, err
+		}
+		commitID = t.LatestCommit
+	case git.GitRefTypeBranch:
+		b, err := h.findRef(ctx, project, repo, "branches", refName)
+		if err != nil {
+			return nil, err
+		}
+		commitID = b.LatestCommit
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "git ref type %s is invalid", refType)
+	}
+
+	c := &commit{}
+	if err := h.get(ctx, fmt.Sprintf("%s/commits/%s", repoPath(project, repo),
+		url.PathEscape(commitID)), nil, c); err != nil {
+		return nil, err
+	}
+	return &git.Commit{
+		ID:      c.ID,
+		Message: c.Message,
+	}, nil
+}
+
+func (h Helper) ListBranch(ctx context.Context, gitURL string, params *git.SearchParams) ([]string, error) {
+	return h.listRefs(ctx, gitURL, "branches", params)
+}
+
+func (h Helper) ListTag(ctx context.Context, gitURL string, params *git.SearchParams) ([]string, error) {
+	return h.listRefs(ctx, gitURL, "tags", params)
+}
+
+func (h Helper) GetHTTPLink(gitURL string) (string, error) {
+	project, repo, err := extractProjectAndRepo(gitURL)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/projects/%s/repos/%s/browse", h.url, strings.ToUpper(project), repo), nil
+}
+
+func (h Helper) GetCommitHistoryLink(gitURL string, commit string) (string, error) {
+	project, repo, err := extractProjectAndRepo(gitURL)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/projects/%s/repos/%s/commits/%s", h.url, strings.ToUpper(project), repo, commit), nil
+}
+
+func (h Helper) listRefs(ctx context.Context, gitURL, kind string, params *git.SearchParams) ([]string, error) {
+	project, repo, err := extractProjectAndRepo(gitURL)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	if params.Filter != "" {
+		query.Set("filterText", params.Filter)
+	}
+	if params.PageSize > 0 {
+		query.Set("limit", strconv.Itoa(params.PageSize))
+		if params.PageNumber > 1 {
+			query.Set("start", strconv.Itoa((params.PageNumber-1)*params.PageSize))
+		}
+	}
+	page := &refPage{}
+	if err := h.get(ctx, fmt.Sprintf("%s/%s", repoPath(project, repo), kind), query, page); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(page.Values))
+	for _, r := range page.Values {
+		names = append(names, r.DisplayID)
+	}
+	return names, nil
+}
+
+// findRef looks up a branch or tag by its exact name, bitbucket only supports fuzzy filtering
+func (h Helper) findRef(ctx context.Context, project, repo, kind, name string) (*ref, error) {
+	query := url.Values{}
+	query.Set("filterText", name)
+	for {
+		page := &refPage{}
+		if err := h.get(ctx, fmt.Sprintf("%s/%s", repoPath(project, repo), kind), query, page); err != nil {
+			return nil, err
+		}
+		for i := range page.Values {
+			if page.Values[i].DisplayID == name {
+				return &page.Values[i], nil
+			}
+		}
+		if page.IsLastPage || len(page.Values) == 0 {
+			break
+		}
+		query.Set("start", strconv.Itoa(page.NextPageStart))
+	}
+	return nil, herrors.NewErrNotFound(herrors.BitbucketResource,
+		fmt.Sprintf("%s %s not found in %s/%s", kind, name, project, repo))
+}
+
+func (h Helper) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	resp, err := h.do(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "failed to decode bitbucket response: err = %v", err)
+	}
+	return nil
+}
+
+func (h Helper) do(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	link := fmt.Sprintf("%s/rest/api/1.0%s", h.url, path)
+	if len(query) > 0 {
+		link = fmt.Sprintf("%s?%s", link, query.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to create request: err = %v", err)
+	}
+	if h.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", h.token))
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to request bitbucket: err = %v", err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		return nil, herrors.NewErrNotFound(herrors.BitbucketResource,
+			fmt.Sprintf("bitbucket resource not found: path = %s", path))
+	}
+	return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+}
+
+func repoPath(project, repo string) string {
+	return fmt.Sprintf("/projects/%s/repos/%s", url.PathEscape(project), url.PathEscape(repo))
+}
+
+// extractProjectAndRepo extracts project key and repo slug from a bitbucket clone url, such as
+// https://bitbucket.example.com/scm/proj/repo.git or ssh://git@bitbucket.example.com:7999/proj/repo.git
+func extractProjectAndRepo(gitURL string) (string, string, error) {
+	pid, err := git.ExtractProjectPathFromURL(gitURL)
+	if err != nil {
+		return "", "", err
+	}
+	paths := strings.Split(strings.TrimPrefix(pid, "scm/"), "/")
+	if len(paths) != 2 {
+		return "", "", perror.Wrapf(herrors.ErrParamInvalid, "git url is incorrect: git url = %s", gitURL)
+	}
+	return paths[0], paths[1], nil
+}
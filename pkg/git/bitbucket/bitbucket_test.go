@@ -0,0 +1,133 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	gitconfig "github.com/horizoncd/horizon/pkg/config/git"
+	"github.com/horizoncd/horizon/pkg/git"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	token     = "bitbucket-token"
+	commitSHA = "0123456789abcdef0123456789abcdef01234567"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	refs := map[string][]ref{
+		"branches": {
+			{ID: "refs/heads/master-fix", DisplayID: "master-fix", LatestCommit: "fff"},
+			{ID: "refs/heads/master-dev", DisplayID: "master-dev", LatestCommit: "eee"},
+			{ID: "refs/heads/master", DisplayID: "master", LatestCommit: commitSHA},
+		},
+		"tags": {
+			{ID: "refs/tags/v1.0.0", DisplayID: "v1.0.0", LatestCommit: commitSHA},
+		},
+	}
+	writeJSON := func(w http.ResponseWriter, v interface{}) {
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	prefix := "/rest/api/1.0/projects/proj/repos/app"
+	mux := http.NewServeMux()
+	for kind := range refs {
+		values := refs[kind]
+		mux.HandleFunc(prefix+"/"+kind, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer "+token, r.Header.Get("Authorization"))
+			filtered := make([]ref, 0)
+			for _, v := range values {
+				if strings.Contains(v.DisplayID, r.URL.Query().Get("filterText")) {
+					filtered = append(filtered, v)
+				}
+			}
+			// page the refs by start and limit, the default limit is small to test paging
+			start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+			limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+			if err != nil {
+				limit = 1
+			}
+			if start > len(filtered) {
+				start = len(filtered)
+			}
+			end := start + limit
+			if end > len(filtered) {
+				end = len(filtered)
+			}
+			writeJSON(w, refPage{Values: filtered[start:end], IsLastPage: end == len(filtered), NextPageStart: end})
+		})
+	}
+	mux.HandleFunc(prefix+"/commits/"+commitSHA, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, commit{ID: commitSHA, DisplayID: commitSHA[:11], Message: "init"})
+	})
+	mux.HandleFunc(prefix+"/archive", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "refs/tags/v1.0.0", r.URL.Query().Get("at"))
+		_, _ = w.Write([]byte("archive"))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestHelper(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	helper, err := git.NewHelper(ctx, &gitconfig.Repo{Kind: Kind, URL: s.URL, Token: token})
+	assert.Nil(t, err)
+
+	gitURL := s.URL + "/scm/proj/app.git"
+	branches, err := helper.ListBranch(ctx, gitURL, &git.SearchParams{Filter: "master", PageNumber: 1, PageSize: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"master-fix", "master-dev", "master"}, branches)
+
+	branches, err = helper.ListBranch(ctx, gitURL, &git.SearchParams{Filter: "master", PageNumber: 2, PageSize: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"master"}, branches)
+
+	tags, err := helper.ListTag(ctx, gitURL, &git.SearchParams{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"v1.0.0"}, tags)
+
+	// the exact match is on the last page of the fuzzy filtered branches
+	commit, err := helper.GetCommit(ctx, gitURL, git.GitRefTypeBranch, "master")
+	assert.Nil(t, err)
+	assert.Equal(t, &git.Commit{ID: commitSHA, Message: "init"}, commit)
+
+	commit, err = helper.GetCommit(ctx, "ssh://git@bitbucket.example.com:7999/proj/app.git",
+		git.GitRefTypeTag, "v1.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, &git.Commit{ID: commitSHA, Message: "init"}, commit)
+
+	_, err = helper.GetCommit(ctx, gitURL, git.GitRefTypeTag, "v2.0.0")
+	assert.NotNil(t, err)
+
+	tag, err := helper.GetTagArchive(ctx, gitURL, "v1.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, &git.Tag{ShortID: commitSHA[:11], Name: "v1.0.0", ArchiveData: []byte("archive")}, tag)
+
+	link, err := helper.GetHTTPLink(gitURL)
+	assert.Nil(t, err)
+	assert.Equal(t, s.URL+"/projects/PROJ/repos/app/browse", link)
+
+	link, err = helper.GetCommitHistoryLink("ssh://git@bitbucket.example.com:7999/proj/app.git", commitSHA)
+	assert.Nil(t, err)
+	assert.Equal(t, s.URL+"/projects/PROJ/repos/app/commits/"+commitSHA, link)
+}
@@ -0,0 +1,292 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	gitconfig "github.com/horizoncd/horizon/pkg/config/git"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/git"
+)
+
+const Kind = "gitea"
+
+// _listAllPageSize is the default max page size of gitea api
+const _listAllPageSize = 50
+
+func init() {
+	git.Register(Kind, New)
+}
+
+type Helper struct {
+	client *http.Client
+	url    string
+	token  string
+}
+
+type commit struct {
+	ID      string `json:"id"`
+	SHA     string `json:"sha"`
+	Message string `json:"message"`
+	Commit  *struct {
+		Message string `json:"message"`
+	} `json:"commit"`
+}
+
+type branch struct {
+	Name   string `json:"name"`
+	Commit commit `json:"commit"`
+}
+
+// namedRef is a branch or tag, of which only the name is needed when listing
+type namedRef struct {
+	Name string `json:"name"`
+}
+
+type tag struct {
+	Name   string `json:"name"`
+	ID     string `json:"id"`
+	Commit commit `json:"commit"`
+}
+
+func New(ctx context.Context, config *gitconfig.Repo) (git.Helper, error) {
+	return &Helper{
+		client: http.DefaultClient,
+		url:    strings.TrimSuffix(config.URL, "/"),
+		token:  config.Token,
+	}, nil
+}
+
+func (h Helper) GetTagArchive(ctx context.Context, gitURL, tagName string) (*git.Tag, error) {
+	pid, err := git.ExtractProjectPathFromURL(gitURL)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &tag{}
+	if err := h.get(ctx, fmt.Sprintf("/repos/%s/tags/%s", pid, url.PathEscape(tagName)), nil, t); err != nil {
+		return nil, err
+	}
+
+	resp, err := h.do(ctx, fmt.Sprintf("/repos/%s/archive/%s.tar.gz", pid, url.PathEscape(tagName)), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	archiveData, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to read archive: err = %v", err)
+	}
+
+	return &git.Tag{
+		ShortID:     shortID(t.Commit.SHA),
+		Name:        tagName,
+		ArchiveData: archiveData,
+	}, nil
+}
+
+func (h Helper) GetCommit(ctx context.Context, gitURL string, refType string, ref string) (*git.Commit, error) {
+	pid, err := git.ExtractProjectPathFromURL(gitURL)
+	if err != nil {
+		return nil, err
+	}
+	switch refType {
+	case git.GitRefTypeCommit:
+		return h.getCommit(ctx, pid, ref)
+	case git.GitRefTypeTag:
+		t := &tag{}
+		if err := h.get(ctx, fmt.Sprintf("/repos/%s/tags/%s", pid, url.PathEscape(ref)), nil, t); err != nil {
+			return nil, err
+		}
+		return h.getCommit(ctx, pid, t.Commit.SHA)
+	case git.GitRefTypeBranch:
+		b := &branch{}
+		if err := h.get(ctx, fmt.Sprintf("/repos/%s/branches/%s", pid, url.PathEscape(ref)), nil, b); err != nil {
+			return nil, err
+		}
+		return &git.Commit{
+			ID:      b.Commit.ID,
+			Message: b.Commit.Message,
+		}, nil
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "git ref type %s is invalid", refType)
+	}
+}
+
+func (h Helper) ListBranch(ctx context.Context, gitURL string, params *git.SearchParams) ([]string, error) {
+	pid, err := git.ExtractProjectPathFromURL(gitURL)
+	if err != nil {
+		return nil, err
+	}
+	return h.listRefNames(ctx, fmt.Sprintf("/repos/%s/branches", pid), params)
+}
+
+func (h Helper) ListTag(ctx context.Context, gitURL string, params *git.SearchParams) ([]string, error) {
+	pid, err := git.ExtractProjectPathFromURL(gitURL)
+	if err != nil {
+		return nil, err
+	}
+	return h.listRefNames(ctx, fmt.Sprintf("/repos/%s/tags", pid), params)
+}
+
+// listRefNames lists the names of branches or tags. Gitea does not support searching them,
+// so all of the refs are listed and filtered before paging when the filter is set.
+func (h Helper) listRefNames(ctx context.Context, path string, params *git.SearchParams) ([]string, error) {
+	if params.Filter == "" {
+		refs := make([]namedRef, 0)
+		if err := h.get(ctx, path, pageQuery(params.PageNumber, params.PageSize), &refs); err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(refs))
+		for _, ref := range refs {
+			names = append(names, ref.Name)
+		}
+		return names, nil
+	}
+
+	names := make([]string, 0)
+	for page := 1; ; page++ {
+		refs := make([]namedRef, 0)
+		if err := h.get(ctx, path, pageQuery(page, _listAllPageSize), &refs); err != nil {
+			return nil, err
+		}
+		for _, ref := range refs {
+			if strings.Contains(ref.Name, params.Filter) {
+				names = append(names, ref.Name)
+			}
+		}
+		if len(refs) < _listAllPageSize {
+			break
+		}
+	}
+	if params.PageSize <= 0 {
+		return names, nil
+	}
+	pageNumber := params.PageNumber
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+	start := (pageNumber - 1) * params.PageSize
+	if start >= len(names) {
+		return []string{}, nil
+	}
+	end := start + params.PageSize
+	if end > len(names) {
+		end = len(names)
+	}
+	return names[start:end], nil
+}
+
+func (h Helper) GetHTTPLink(gitURL string) (string, error) {
+	pid, err := git.ExtractProjectPathFromURL(gitURL)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/%s", h.url, pid), nil
+}
+
+func (h Helper) GetCommitHistoryLink(gitURL string, commit string) (string, error) {
+	httpLink, err := h.GetHTTPLink(gitURL)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/commit/%s", httpLink, commit), nil
+}
+
+func (h Helper) getCommit(ctx context.Context, pid, sha string) (*git.Commit, error) {
+	c := &commit{}
+	if err := h.get(ctx, fmt.Sprintf("/repos/%s/git/commits/%s", pid, url.PathEscape(sha)), nil, c); err != nil {
+		return nil, err
+	}
+	message := c.Message
+	if c.Commit != nil {
+		message = c.Commit.Message
+	}
+	return &git.Commit{
+		ID:      c.SHA,
+		Message: message,
+	}, nil
+}
+
+func (h Helper) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	resp, err := h.do(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "failed to decode gitea response: err = %v", err)
+	}
+	return nil
+}
+
+func (h Helper) do(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	link := fmt.Sprintf("%s/api/v1%s", h.url, path)
+	if len(query) > 0 {
+		link = fmt.Sprintf("%s?%s", link, query.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to create request: err = %v", err)
+	}
+	if h.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("token %s", h.token))
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to request gitea: err = %v", err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		return nil, herrors.NewErrNotFound(herrors.GiteaResource,
+			fmt.Sprintf("gitea resource not found: path = %s", path))
+	}
+	return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+}
+
+func pageQuery(pageNumber, pageSize int) url.Values {
+	query := url.Values{}
+	if pageNumber > 0 {
+		query.Set("page", strconv.Itoa(pageNumber))
+	}
+	if pageSize > 0 {
+		query.Set("limit", strconv.Itoa(pageSize))
+	}
+	return query
+}
+
+func shortID(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
@@ -0,0 +1,139 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	gitconfig "github.com/horizoncd/horizon/pkg/config/git"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/git"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	token     = "gitea-token"
+	commitSHA = "0123456789abcdef0123456789abcdef01234567"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	writeJSON := func(w http.ResponseWriter, v interface{}) {
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/repos/demo/app/branches", func(w http.ResponseWriter, r *http.Request) {
+		// 51 branches: main, feature-0 ... feature-49
+		branches := []map[string]interface{}{{"name": "main"}}
+		for i := 0; i < 50; i++ {
+			branches = append(branches, map[string]interface{}{"name": fmt.Sprintf("feature-%d", i)})
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		start, end := (page-1)*limit, page*limit
+		if start > len(branches) {
+			start = len(branches)
+		}
+		if end > len(branches) {
+			end = len(branches)
+		}
+		writeJSON(w, branches[start:end])
+	})
+	mux.HandleFunc("/api/v1/repos/demo/app/branches/main", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"name":   "main",
+			"commit": map[string]interface{}{"id": commitSHA, "message": "init"},
+		})
+	})
+	mux.HandleFunc("/api/v1/repos/demo/app/tags", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]interface{}{{"name": "v1.0.0"}, {"name": "v1.1.0"}})
+	})
+	mux.HandleFunc("/api/v1/repos/demo/app/tags/v1.0.0", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"name":   "v1.0.0",
+			"commit": map[string]interface{}{"sha": commitSHA},
+		})
+	})
+	mux.HandleFunc("/api/v1/repos/demo/app/git/commits/"+commitSHA, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token "+token, r.Header.Get("Authorization"))
+		writeJSON(w, map[string]interface{}{
+			"sha":    commitSHA,
+			"commit": map[string]interface{}{"message": "init"},
+		})
+	})
+	mux.HandleFunc("/api/v1/repos/demo/app/archive/v1.0.0.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("archive"))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestHelper(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	helper, err := git.NewHelper(ctx, &gitconfig.Repo{Kind: Kind, URL: s.URL, Token: token})
+	assert.Nil(t, err)
+
+	gitURL := s.URL + "/demo/app.git"
+	branches, err := helper.ListBranch(ctx, gitURL, &git.SearchParams{PageNumber: 2, PageSize: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"feature-1", "feature-2"}, branches)
+
+	// the filter is applied before paging, across the pages of gitea api
+	branches, err = helper.ListBranch(ctx, gitURL, &git.SearchParams{Filter: "feature-4", PageNumber: 2, PageSize: 5})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"feature-44", "feature-45", "feature-46", "feature-47", "feature-48"}, branches)
+	branches, err = helper.ListBranch(ctx, gitURL, &git.SearchParams{Filter: "feature-4", PageNumber: 3, PageSize: 5})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"feature-49"}, branches)
+
+	tags, err := helper.ListTag(ctx, gitURL, &git.SearchParams{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"v1.0.0", "v1.1.0"}, tags)
+
+	for _, refType := range []string{git.GitRefTypeBranch, git.GitRefTypeTag, git.GitRefTypeCommit} {
+		ref := map[string]string{
+			git.GitRefTypeBranch: "main",
+			git.GitRefTypeTag:    "v1.0.0",
+			git.GitRefTypeCommit: commitSHA,
+		}[refType]
+		commit, err := helper.GetCommit(ctx, gitURL, refType, ref)
+		assert.Nil(t, err)
+		assert.Equal(t, &git.Commit{ID: commitSHA, Message: "init"}, commit)
+	}
+
+	_, err = helper.GetCommit(ctx, gitURL, git.GitRefTypeBranch, "not-exist")
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	tag, err := helper.GetTagArchive(ctx, gitURL, "v1.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, &git.Tag{ShortID: commitSHA[:8], Name: "v1.0.0", ArchiveData: []byte("archive")}, tag)
+
+	link, err := helper.GetHTTPLink(gitURL)
+	assert.Nil(t, err)
+	assert.Equal(t, s.URL+"/demo/app", link)
+
+	link, err = helper.GetCommitHistoryLink(gitURL, commitSHA)
+	assert.Nil(t, err)
+	assert.Equal(t, s.URL+"/demo/app/commit/"+commitSHA, link)
+}
@@ -0,0 +1,118 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"context"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/horizoncd/horizon/core/common"
+	prctl "github.com/horizoncd/horizon/core/controller/pipelinerun"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/q"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/config/approval"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_defaultJobInterval = time.Minute
+	_defaultBatchSize   = 100
+)
+
+// Run cancels the stale pending approval check runs periodically
+func Run(ctx context.Context, jobConfig *approval.Config, userMgr usermanager.Manager,
+	prCtl prctl.Controller) {
+	if jobConfig.Expiry <= 0 {
+		log.Infof(ctx, "approvals never expire, skip the approval expiry job")
+		return
+	}
+	if jobConfig.JobInterval <= 0 {
+		jobConfig.JobInterval = _defaultJobInterval
+	}
+	if jobConfig.BatchSize <= 0 {
+		jobConfig.BatchSize = _defaultBatchSize
+	}
+
+	// verify account
+	user, err := userMgr.GetUserByID(ctx, jobConfig.AccountID)
+	if err != nil {
+		log.Errorf(ctx, "failed to verify operator of approval expiry job, err: %v", err.Error())
+		return
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     user.Name,
+		FullName: user.FullName,
+		ID:       user.ID,
+		Email:    user.Email,
+		Admin:    user.Admin,
+	})
+
+	// start job
+	log.Infof(ctx, "Starting expiring approvals pending for %v every %v", jobConfig.Expiry, jobConfig.JobInterval)
+	defer log.Infof(ctx, "Stopping expiring approvals")
+	ticker := time.NewTicker(jobConfig.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			log.Infof(ctx, "approval expiry job starts to execute, rid: %v", rid)
+			process(ctx, jobConfig, prCtl)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func process(ctx context.Context, jobConfig *approval.Config, prCtl prctl.Controller) {
+	op := "job: approval expiry"
+	before := time.Now().Add(-jobConfig.Expiry)
+	query := &q.Query{
+		PageNumber: common.DefaultPageNumber,
+		PageSize:   jobConfig.BatchSize,
+		Keywords:   make(map[string]interface{}),
+	}
+	for {
+		// 1. fetch a batch of stale approval check runs
+		checkRuns, err := prCtl.ListStaleApprovalCheckRuns(ctx, before, query)
+		if err != nil {
+			log.WithFiled(ctx, "op", op).
+				Errorf("failed to list stale approval check runs, err: %v", err.Error())
+			return
+		}
+
+		// 2. cancel the check runs and their pipelineruns
+		for _, checkRun := range checkRuns {
+			if err := prCtl.ExpireApprovalCheckRun(ctx, checkRun.ID); err != nil {
+				log.WithFiled(ctx, "op", op).Errorf("failed to expire approval check run: %v, err: %v",
+					checkRun.ID, err.Error())
+				continue
+			}
+			log.WithFiled(ctx, "op", op).Infof("approval check run %v of pipelinerun %v expired",
+				checkRun.ID, checkRun.PipelineRunID)
+		}
+		if len(checkRuns) < query.PageSize {
+			break
+		}
+		query.Keywords[common.IDThan] = checkRuns[len(checkRuns)-1].ID
+		time.Sleep(jobConfig.BatchInterval)
+	}
+}
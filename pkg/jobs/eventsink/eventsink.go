@@ -0,0 +1,69 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventsink
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	eventhandlerconfig "github.com/horizoncd/horizon/pkg/config/eventhandler"
+	"github.com/horizoncd/horizon/pkg/eventhandler/sink"
+	"github.com/horizoncd/horizon/pkg/jobs"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	hlog "github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// New creates the configured event sinks, each of them publishes events in its own goroutine
+func New(ctx context.Context, config eventhandlerconfig.Config, mgrs *managerparam.Manager) jobs.Job {
+	handlers := make([]*sink.Handler, 0, len(config.Sinks))
+	for i := range config.Sinks {
+		sinkConfig := &config.Sinks[i]
+		publisher, err := sink.NewPublisher(sinkConfig)
+		if err != nil {
+			log.Printf("failed to create publisher of event sink %s, error: %s", sinkConfig.Name, err.Error())
+			panic(err)
+		}
+		handler, err := sink.NewHandler(sinkConfig, publisher, mgrs.EventMgr)
+		if err != nil {
+			log.Printf("failed to create event sink %s, error: %s", sinkConfig.Name, err.Error())
+			panic(err)
+		}
+		handlers = append(handlers, handler)
+	}
+	idleWaitInterval := time.Second * time.Duration(config.IdleWaitInterval)
+
+	return func(ctx context.Context) {
+		var wg sync.WaitGroup
+		for _, handler := range handlers {
+			wg.Add(1)
+			go func(handler *sink.Handler) {
+				defer wg.Done()
+				defer func() {
+					if err := recover(); err != nil {
+						hlog.Errorf(ctx, "event sink %s panic: %s", handler.Name(), string(debug.Stack()))
+					}
+				}()
+				handler.Run(ctx, idleWaitInterval)
+				if err := handler.Close(); err != nil {
+					log.Printf("failed to close event sink %s, error: %s", handler.Name(), err.Error())
+				}
+			}(handler)
+		}
+		wg.Wait()
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package membergrant
+
+import (
+	"context"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/horizoncd/horizon/core/common"
+	grantctl "github.com/horizoncd/horizon/core/controller/membergrant"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/q"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/config/membergrant"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_defaultJobInterval = time.Minute
+	_defaultBatchSize   = 100
+)
+
+// Run removes the members of expired grants periodically
+func Run(ctx context.Context, jobConfig *membergrant.Config, userMgr usermanager.Manager,
+	grantCtl grantctl.Controller) {
+	if jobConfig.JobInterval <= 0 {
+		jobConfig.JobInterval = _defaultJobInterval
+	}
+	if jobConfig.BatchSize <= 0 {
+		jobConfig.BatchSize = _defaultBatchSize
+	}
+
+	// verify account, expired members are still filtered out when listing members without the job
+	user, err := userMgr.GetUserByID(ctx, jobConfig.AccountID)
+	if err != nil {
+		log.Errorf(ctx, "failed to verify operator of member grant job, err: %v", err.Error())
+		return
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     user.Name,
+		FullName: user.FullName,
+		ID:       user.ID,
+		Email:    user.Email,
+		Admin:    user.Admin,
+	})
+
+	// start job
+	log.Infof(ctx, "Starting removing expired members every %v", jobConfig.JobInterval)
+	defer log.Infof(ctx, "Stopping removing expired members")
+	ticker := time.NewTicker(jobConfig.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			log.Infof(ctx, "member grant job starts to execute, rid: %v", rid)
+			process(ctx, jobConfig, grantCtl)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func process(ctx context.Context, jobConfig *membergrant.Config, grantCtl grantctl.Controller) {
+	op := "job: member grant expiry"
+	query := &q.Query{
+		PageNumber: common.DefaultPageNumber,
+		PageSize:   jobConfig.BatchSize,
+		Keywords:   make(map[string]interface{}),
+	}
+	for {
+		// 1. fetch a batch of expired grants
+		grants, err := grantCtl.ListExpiredMemberGrants(ctx, query)
+		if err != nil {
+			log.WithFiled(ctx, "op", op).
+				Errorf("failed to list expired member grants, err: %v", err.Error())
+			return
+		}
+
+		// 2. remove the members and mark grants expired
+		for _, grant := range grants {
+			if err := grantCtl.ExpireMemberGrant(ctx, grant.ID); err != nil {
+				log.WithFiled(ctx, "op", op).Errorf("failed to expire member grant: %v, err: %v",
+					grant.ID, err.Error())
+				continue
+			}
+			log.WithFiled(ctx, "op", op).Infof("member grant %v expired, member %v of %v/%v removed",
+				grant.ID, grant.MemberID, grant.ResourceType, grant.ResourceID)
+		}
+		if len(grants) < query.PageSize {
+			break
+		}
+		query.Keywords[common.IDThan] = grants[len(grants)-1].ID
+		time.Sleep(jobConfig.BatchInterval)
+	}
+}
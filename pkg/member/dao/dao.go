@@ -60,8 +60,16 @@ func NewDAO(db *gorm.DB) DAO {
 type dao struct{ db *gorm.DB }
 
 func (d *dao) Create(ctx context.Context, member *models.Member) (*models.Member, error) {
-	result := d.db.WithContext(ctx).Create(member)
-	return member, result.Error
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the temporary member which has expired but not been removed yet conflicts with the new one
+		now := time.Now()
+		if result := tx.Exec(common.MemberExpiredDelete, now.Unix(), member.ResourceType, member.ResourceID,
+			member.MemberType, member.MemberNameID, now); result.Error != nil {
+			return result.Error
+		}
+		return tx.Create(member).Error
+	})
+	return member, err
 }
 
 func (d *dao) Get(ctx context.Context, resourceType models.ResourceType, resourceID uint,
@@ -72,7 +80,7 @@ func (d *dao) Get(ctx context.Context, resourceType models.ResourceType, resourc
 	if result.Error != nil {
 		return nil, result.Error
 	}
-	if result.RowsAffected == 0 {
+	if result.RowsAffected == 0 || member.Expired(time.Now()) {
 		return nil, nil
 	}
 	return &member, nil
@@ -163,7 +171,7 @@ func (d *dao) ListDirectMember(ctx context.Context, resourceType models.Resource
 	if result.Error != nil {
 		return nil, result.Error
 	}
-	return members, nil
+	return withoutExpired(members), nil
 }
 
 func (d *dao) ListDirectMemberOnCondition(ctx context.Context, resourceType models.ResourceType,
@@ -175,7 +183,19 @@ func (d *dao) ListDirectMemberOnCondition(ctx context.Context, resourceType mode
 			return nil, result.Error
 		}
 	}
-	return members, nil
+	return withoutExpired(members), nil
+}
+
+// withoutExpired filters out the temporary members which have expired but not been removed yet
+func withoutExpired(members []models.Member) []models.Member {
+	now := time.Now()
+	retMembers := members[:0]
+	for _, member := range members {
+		if !member.Expired(now) {
+			retMembers = append(retMembers, member)
+		}
+	}
+	return retMembers
 }
 
 func (d *dao) ListResourceOfMemberInfo(ctx context.Context,
@@ -16,6 +16,7 @@ package models
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/pkg/server/global"
@@ -76,9 +77,17 @@ type Member struct {
 	// TODO(tom): change go user
 	GrantedBy uint `gorm:"column:granted_by"`
 	CreatedBy uint `gorm:"column:created_by"`
+
+	// ExpiredAt the time when a temporary member expires, nil means the member is permanent
+	ExpiredAt *time.Time `gorm:"column:expired_at"`
 }
 
 func (m *Member) BaseInfo() string {
 	return fmt.Sprintf("resource(%s/%d)-memberInfo(%d/%d)-ruleID(%d)",
 		m.ResourceType, m.ResourceID, m.MemberType, m.MemberNameID, m.ID)
 }
+
+// Expired returns true if the member is temporary and has expired
+func (m *Member) Expired(now time.Time) bool {
+	return m.ExpiredAt != nil && !m.ExpiredAt.After(now)
+}
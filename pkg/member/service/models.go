@@ -40,6 +40,15 @@ type PostMember struct {
 	Role string
 }
 
+// Resource is a resource whose members are inherited by its descendants
+type Resource struct {
+	// ResourceType group/application/cluster/...
+	ResourceType string
+
+	// ResourceID group id;application id ...
+	ResourceID uint
+}
+
 type Member struct {
 	// ID the uniq id of the member entry
 	ID uint
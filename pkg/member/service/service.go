@@ -61,6 +61,9 @@ type Service interface {
 	GetMemberOfResource(ctx context.Context, resourceType string, resourceID string) (*models.Member, error)
 	// RequirePermissionEqualOrHigher helps to check if your permission is higher than specified member
 	RequirePermissionEqualOrHigher(ctx context.Context, role, resourceType string, resourceID uint) error
+	// ListResourceChain lists the resources whose members are inherited by the resource,
+	// from the root group down to the resource which GetMemberOfResource looks up members from
+	ListResourceChain(ctx context.Context, resourceType string, resourceID string) ([]Resource, error)
 }
 
 type service struct {
@@ -284,6 +287,122 @@ func (s *service) GetMemberOfResource(ctx context.Context,
 	return memberInfo, nil
 }
 
+func (s *service) ListResourceChain(ctx context.Context,
+	resourceType string, resourceIDStr string) ([]Resource, error) {
+	if resourceType == common.ResourceOauthApps {
+		app, err := s.oauthManager.GetOAuthApp(ctx, resourceIDStr)
+		if err != nil {
+			return nil, err
+		}
+		if !app.IsGroupOwnerType() {
+			return nil, herror.ErrOAuthNotGroupOwnerType
+		}
+		return s.listResourceChain(ctx, common.ResourceGroup, app.OwnerID)
+	}
+	resourceID, _ := strconv.Atoi(resourceIDStr)
+	return s.listResourceChain(ctx, resourceType, uint(resourceID))
+}
+
+func (s *service) listResourceChain(ctx context.Context,
+	resourceType string, resourceID uint) ([]Resource, error) {
+	switch resourceType {
+	case common.ResourceGroup:
+		chain := []Resource{{ResourceType: common.ResourceGroup, ResourceID: groupmodels.RootGroupID}}
+		if s.groupManager.IsRootGroup(resourceID) {
+			return chain, nil
+		}
+		groupInfo, err := s.groupManager.GetByID(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range groupmanager.FormatIDsFromTraversalIDs(groupInfo.TraversalIDs) {
+			chain = append(chain, Resource{ResourceType: common.ResourceGroup, ResourceID: id})
+		}
+		return chain, nil
+	case common.ResourceApplication:
+		applicationInfo, err := s.applicationManager.GetByID(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		chain, err := s.listResourceChain(ctx, common.ResourceGroup, applicationInfo.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		return append(chain, Resource{ResourceType: resourceType, ResourceID: resourceID}), nil
+	case common.ResourceCluster:
+		clusterInfo, err := s.applicationClusterManager.GetByID(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		chain, err := s.listResourceChain(ctx, common.ResourceApplication, clusterInfo.ApplicationID)
+		if err != nil {
+			return nil, err
+		}
+		return append(chain, Resource{ResourceType: resourceType, ResourceID: resourceID}), nil
+	case common.ResourceTemplate:
+		templateInfo, err := s.templateManager.GetByID(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		chain, err := s.listResourceChain(ctx, common.ResourceGroup, templateInfo.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		return append(chain, Resource{ResourceType: resourceType, ResourceID: resourceID}), nil
+	case common.ResourceTemplateRelease:
+		tr, err := s.templateReleaseManager.GetByID(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		return s.listResourceChain(ctx, common.ResourceTemplate, tr.Template)
+	case common.ResourcePipelinerun:
+		pipeline, err := s.prMgr.PipelineRun.GetByID(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		if pipeline == nil {
+			msg := fmt.Sprintf("pipeline do not found, pipelineID = %d", resourceID)
+			return nil, herror.NewErrNotFound(herror.MemberInfoInDB, msg)
+		}
+		return s.listResourceChain(ctx, common.ResourceCluster, pipeline.ClusterID)
+	case common.ResourceCheckrun:
+		checkrun, err := s.prMgr.Check.GetCheckRunByID(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		if checkrun == nil {
+			msg := fmt.Sprintf("checkrun does not found, checkrunID = %d", resourceID)
+			return nil, herror.NewErrNotFound(herror.MemberInfoInDB, msg)
+		}
+		return s.listResourceChain(ctx, common.ResourcePipelinerun, checkrun.PipelineRunID)
+	case common.ResourceWebhook:
+		if resourceID == 0 {
+			return nil, nil
+		}
+		webhook, err := s.webhookManager.GetWebhook(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		switch webhook.ResourceType {
+		case common.ResourceGroup, common.ResourceApplication, common.ResourceCluster:
+			return s.listResourceChain(ctx, webhook.ResourceType, webhook.ResourceID)
+		default:
+			return nil, nil
+		}
+	case common.ResourceWebhookLog:
+		if resourceID == 0 {
+			return nil, nil
+		}
+		webhookLog, err := s.webhookManager.GetWebhookLog(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		return s.listResourceChain(ctx, common.ResourceWebhook, webhookLog.WebhookID)
+	default:
+		return nil, errors.New("unsupported resourceType")
+	}
+}
+
 func (s *service) GetMember(ctx context.Context, memberID uint) (*models.Member, error) {
 	return s.memberManager.GetByID(ctx, memberID)
 }
@@ -0,0 +1,149 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/membergrant/models"
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	// Create a member grant
+	Create(ctx context.Context, grant *models.MemberGrant) (*models.MemberGrant, error)
+	// GetByID get by id
+	GetByID(ctx context.Context, id uint) (*models.MemberGrant, error)
+	// List list member grants filtered by the keywords of query
+	List(ctx context.Context, query *q.Query) ([]*models.MemberGrant, int64, error)
+	// ListExpired list approved grants expired before the time
+	ListExpired(ctx context.Context, before time.Time, query *q.Query) ([]*models.MemberGrant, error)
+	// UpdateStatusByID update the status of a grant, it fails if the status has been changed by others
+	UpdateStatusByID(ctx context.Context, id uint, fromStatus string, grant *models.MemberGrant) error
+}
+
+type dao struct{ db *gorm.DB }
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, grant *models.MemberGrant) (*models.MemberGrant, error) {
+	result := d.db.WithContext(ctx).Create(grant)
+
+	if result.Error != nil {
+		return nil, herrors.NewErrCreateFailed(herrors.MemberGrantInDB, result.Error.Error())
+	}
+
+	return grant, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.MemberGrant, error) {
+	var grant models.MemberGrant
+	result := d.db.WithContext(ctx).Where("id = ?", id).First(&grant)
+
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.MemberGrantInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.MemberGrantInDB, result.Error.Error())
+	}
+
+	return &grant, nil
+}
+
+func (d *dao) List(ctx context.Context, query *q.Query) ([]*models.MemberGrant, int64, error) {
+	var (
+		grants []*models.MemberGrant
+		count  int64
+	)
+
+	statement := d.db.WithContext(ctx).Model(&models.MemberGrant{})
+	if query != nil {
+		for k, v := range query.Keywords {
+			switch k {
+			case common.MemberGrantQueryByResourceType:
+				statement = statement.Where("resource_type = ?", v)
+			case common.MemberGrantQueryByResourceID:
+				statement = statement.Where("resource_id = ?", v)
+			case common.MemberGrantQueryByUserID:
+				statement = statement.Where("user_id = ?", v)
+			case common.MemberGrantQueryByStatus:
+				statement = statement.Where("status = ?", v)
+			case common.MemberGrantQueryVisibleTo:
+				if visibility, ok := v.(*models.Visibility); ok {
+					visible := d.db.Where("user_id = ?", visibility.UserID)
+					for _, resource := range visibility.Resources {
+						visible = visible.Or("resource_type = ? AND resource_id = ?", resource.Type, resource.ID)
+					}
+					statement = statement.Where(visible)
+				}
+			}
+		}
+	}
+
+	if result := statement.Count(&count); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.MemberGrantInDB, result.Error.Error())
+	}
+	if query != nil {
+		statement = statement.Limit(query.Limit()).Offset(query.Offset())
+	}
+	if result := statement.Order("id desc").Find(&grants); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.MemberGrantInDB, result.Error.Error())
+	}
+
+	return grants, count, nil
+}
+
+func (d *dao) ListExpired(ctx context.Context, before time.Time,
+	query *q.Query) ([]*models.MemberGrant, error) {
+	var grants []*models.MemberGrant
+
+	statement := d.db.WithContext(ctx).
+		Where("status = ?", models.StatusApproved).
+		Where("expired_at <= ?", before)
+	if query != nil {
+		if v, ok := query.Keywords[common.IDThan]; ok {
+			statement = statement.Where("id > ?", v)
+		}
+		statement = statement.Limit(query.Limit())
+	}
+	if result := statement.Order("id asc").Find(&grants); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.MemberGrantInDB, result.Error.Error())
+	}
+
+	return grants, nil
+}
+
+func (d *dao) UpdateStatusByID(ctx context.Context, id uint, fromStatus string,
+	grant *models.MemberGrant) error {
+	result := d.db.WithContext(ctx).Model(&models.MemberGrant{}).
+		Where("id = ?", id).Where("status = ?", fromStatus).
+		Select("Status", "ReviewedBy", "ReviewedAt", "ReviewComment", "MemberID", "ExpiredAt").
+		Updates(grant)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.MemberGrantInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.MemberGrantInDB,
+			"member grant not found or its status has been changed")
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/membergrant/dao"
+	"github.com/horizoncd/horizon/pkg/membergrant/models"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	// Create a member grant
+	Create(ctx context.Context, grant *models.MemberGrant) (*models.MemberGrant, error)
+	// GetByID get by id
+	GetByID(ctx context.Context, id uint) (*models.MemberGrant, error)
+	// List list member grants filtered by the keywords of query
+	List(ctx context.Context, query *q.Query) ([]*models.MemberGrant, int64, error)
+	// ListExpired list approved grants expired before the time
+	ListExpired(ctx context.Context, before time.Time, query *q.Query) ([]*models.MemberGrant, error)
+	// UpdateStatusByID update the status of a grant, it fails if the status has been changed by others
+	UpdateStatusByID(ctx context.Context, id uint, fromStatus string, grant *models.MemberGrant) error
+}
+
+type manager struct {
+	grantDAO dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		grantDAO: dao.NewDAO(db),
+	}
+}
+
+func (m manager) Create(ctx context.Context, grant *models.MemberGrant) (*models.MemberGrant, error) {
+	return m.grantDAO.Create(ctx, grant)
+}
+
+func (m manager) GetByID(ctx context.Context, id uint) (*models.MemberGrant, error) {
+	return m.grantDAO.GetByID(ctx, id)
+}
+
+func (m manager) List(ctx context.Context, query *q.Query) ([]*models.MemberGrant, int64, error) {
+	return m.grantDAO.List(ctx, query)
+}
+
+func (m manager) ListExpired(ctx context.Context, before time.Time,
+	query *q.Query) ([]*models.MemberGrant, error) {
+	return m.grantDAO.ListExpired(ctx, before, query)
+}
+
+func (m manager) UpdateStatusByID(ctx context.Context, id uint, fromStatus string,
+	grant *models.MemberGrant) error {
+	return m.grantDAO.UpdateStatusByID(ctx, id, fromStatus, grant)
+}
@@ -0,0 +1,67 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+	StatusExpired  = "expired"
+)
+
+// MemberGrant is a request of a user for a temporary role on a resource,
+// a member expiring after the duration is created once it's approved
+type MemberGrant struct {
+	global.Model
+
+	// ResourceType groups/applications/clusters
+	ResourceType string
+	ResourceID   uint
+	Role         string
+	// UserID the user who requests the role
+	UserID uint
+	// DurationSeconds how long the member lasts after approved
+	DurationSeconds uint
+	Reason          string
+	Status          string
+
+	ReviewedBy    uint
+	ReviewedAt    *time.Time
+	ReviewComment string
+
+	// MemberID the member created when approved
+	MemberID  uint
+	ExpiredAt *time.Time
+
+	CreatedBy uint
+	UpdatedBy uint
+}
+
+// Visibility limits member grants to the ones requested by the user or on the resources
+type Visibility struct {
+	UserID    uint
+	Resources []Resource
+}
+
+type Resource struct {
+	Type string
+	ID   uint
+}
@@ -15,10 +15,17 @@
 package scope
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/horizoncd/horizon/pkg/config/oauth"
 	"github.com/horizoncd/horizon/pkg/rbac/types"
 )
 
+// ConstraintSeparator separates the name of scope and the environment/region it's constrained to,
+// such as clusters:read-write@test and clusters:read-write@test/hz
+const ConstraintSeparator = "@"
+
 type Service interface {
 	GetRulesByScope([]string) []types.Role
 	GetAllScopeNames() []string
@@ -63,15 +70,56 @@ func (f *fileScopeService) GetRulesByScope(scopes []string) []types.Role {
 		return append(roles, f.DefaultRoles...)
 	}
 	for _, scope := range scopes {
+		name, environment, region, err := ParseScope(scope)
+		if err != nil {
+			continue
+		}
 		for _, role := range f.Roles {
-			if role.Name == scope {
-				roles = append(roles, role)
+			if role.Name != name {
+				continue
+			}
+			if environment != "" {
+				role = constrainRole(scope, role, environment, region)
 			}
+			roles = append(roles, role)
 		}
 	}
 	return roles
 }
 
+// ParseScope parses the scope into its name and the environment/region it's constrained to
+func ParseScope(scope string) (name, environment, region string, err error) {
+	parts := strings.SplitN(scope, ConstraintSeparator, 2)
+	if len(parts) == 1 {
+		return scope, "", "", nil
+	}
+	name = parts[0]
+	envRegion := strings.Split(parts[1], "/")
+	if name == "" || len(envRegion) > 2 || envRegion[0] == "" ||
+		(len(envRegion) == 2 && envRegion[1] == "") {
+		return "", "", "", fmt.Errorf("invalid scope %s, should be in format of "+
+			"{scope}[@{environment}[/{region}]]", scope)
+	}
+	environment = envRegion[0]
+	if len(envRegion) == 2 {
+		region = envRegion[1]
+	}
+	return name, environment, region, nil
+}
+
+// constrainRole constrains all the rules of role to the environment and region
+func constrainRole(scope string, role types.Role, environment, region string) types.Role {
+	rules := make([]types.PolicyRule, 0, len(role.PolicyRules))
+	for _, rule := range role.PolicyRules {
+		if constrained, ok := rule.ConstrainTo(environment, region); ok {
+			rules = append(rules, constrained)
+		}
+	}
+	role.Name = scope
+	role.PolicyRules = rules
+	return role
+}
+
 func (f *fileScopeService) GetAllScopeNames() []string {
 	var scopeNames = make([]string, 0)
 	for _, role := range f.Roles {
@@ -0,0 +1,84 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scope
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/config/oauth"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+)
+
+func TestParseScope(t *testing.T) {
+	cases := []struct {
+		scope       string
+		name        string
+		environment string
+		region      string
+		valid       bool
+	}{
+		{scope: "clusters:read-write", name: "clusters:read-write", valid: true},
+		{scope: "clusters:read-write@test", name: "clusters:read-write", environment: "test", valid: true},
+		{scope: "clusters:read-write@test/hz", name: "clusters:read-write", environment: "test",
+			region: "hz", valid: true},
+		{scope: "clusters:read-write@"},
+		{scope: "clusters:read-write@test/"},
+		{scope: "clusters:read-write@test/hz/a"},
+		{scope: "@test"},
+	}
+	for _, c := range cases {
+		name, environment, region, err := ParseScope(c.scope)
+		if !c.valid {
+			assert.NotNil(t, err, c.scope)
+			continue
+		}
+		assert.Nil(t, err, c.scope)
+		assert.Equal(t, c.name, name)
+		assert.Equal(t, c.environment, environment)
+		assert.Equal(t, c.region, region)
+	}
+}
+
+func TestGetRulesByScope(t *testing.T) {
+	svc, err := NewFileScopeService(oauth.Scopes{
+		DefaultScopes: []string{"clusters:read-write"},
+		Roles: []types.Role{{
+			Name: "clusters:read-write",
+			PolicyRules: []types.PolicyRule{
+				{Verbs: []string{"*"}, APIGroups: []string{"core"}, Resources: []string{"clusters"}},
+				{Verbs: []string{"*"}, APIGroups: []string{"core"}, Resources: []string{"clusters/builddeploy"},
+					Environments: []string{"online"}},
+			},
+		}},
+	})
+	assert.Nil(t, err)
+
+	roles := svc.GetRulesByScope([]string{"clusters:read-write"})
+	assert.Equal(t, 1, len(roles))
+	assert.Equal(t, 2, len(roles[0].PolicyRules))
+
+	roles = svc.GetRulesByScope([]string{"clusters:read-write@test/hz"})
+	assert.Equal(t, 1, len(roles))
+	assert.Equal(t, "clusters:read-write@test/hz", roles[0].Name)
+	assert.Equal(t, []types.PolicyRule{{
+		Verbs: []string{"*"}, APIGroups: []string{"core"}, Resources: []string{"clusters"},
+		Environments: []string{"test"}, Regions: []string{"hz"},
+	}}, roles[0].PolicyRules)
+
+	roles = svc.GetRulesByScope([]string{"clusters:read-write@"})
+	assert.Equal(t, 0, len(roles))
+}
@@ -21,17 +21,21 @@ import (
 	collectionmanager "github.com/horizoncd/horizon/pkg/collection/manager"
 
 	accesstokenmanager "github.com/horizoncd/horizon/pkg/accesstoken/manager"
+	admissionpolicymanager "github.com/horizoncd/horizon/pkg/admissionpolicy/manager"
 	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	applicationregionmanager "github.com/horizoncd/horizon/pkg/applicationregion/manager"
 	badgemanager "github.com/horizoncd/horizon/pkg/badge/manager"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	deploywindowmanager "github.com/horizoncd/horizon/pkg/deploywindow/manager"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	environmentregionmanager "github.com/horizoncd/horizon/pkg/environmentregion/manager"
 	eventManager "github.com/horizoncd/horizon/pkg/event/manager"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	idpmanager "github.com/horizoncd/horizon/pkg/idp/manager"
+	membergrantmanager "github.com/horizoncd/horizon/pkg/membergrant/manager"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	pipelinemanager "github.com/horizoncd/horizon/pkg/pr/pipeline/manager"
+	rolemanager "github.com/horizoncd/horizon/pkg/rbac/role/manager"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	registrymanager "github.com/horizoncd/horizon/pkg/registry/manager"
 	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
@@ -72,6 +76,10 @@ type Manager struct {
 	EventMgr             eventManager.Manager
 	TokenMgr             tokenmanager.Manager
 	BadgeMgr             badgemanager.Manager
+	DeployWindowMgr      deploywindowmanager.Manager
+	AdmissionPolicyMgr   admissionpolicymanager.Manager
+	RoleMgr              rolemanager.Manager
+	MemberGrantMgr       membergrantmanager.Manager
 }
 
 func InitManager(db *gorm.DB) *Manager {
@@ -102,5 +110,9 @@ func InitManager(db *gorm.DB) *Manager {
 		EventMgr:             eventManager.New(db),
 		TokenMgr:             tokenmanager.New(db),
 		BadgeMgr:             badgemanager.New(db),
+		DeployWindowMgr:      deploywindowmanager.New(db),
+		AdmissionPolicyMgr:   admissionpolicymanager.New(db),
+		RoleMgr:              rolemanager.New(db),
+		MemberGrantMgr:       membergrantmanager.New(db),
 	}
 }
@@ -22,6 +22,7 @@ import (
 	clustergitrepo "github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	clusterservice "github.com/horizoncd/horizon/pkg/cluster/service"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
+	deploywindowservice "github.com/horizoncd/horizon/pkg/deploywindow/service"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	"github.com/horizoncd/horizon/pkg/grafana"
@@ -59,6 +60,8 @@ type Param struct {
 	PRService      prservice.Service
 	ScopeService   scope.Service
 	GrafanaService grafana.Service
+	// DeployWindowSvc checks deploy windows before pipelineruns are created
+	DeployWindowSvc deploywindowservice.Service
 
 	// others
 	Hook                 hook.Hook
@@ -0,0 +1,109 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+type ApprovalDAO interface {
+	// Create creates an approval
+	Create(ctx context.Context, approval *models.Approval) (*models.Approval, error)
+	// ListByCheckRunIDs lists approvals of check runs order by id asc
+	ListByCheckRunIDs(ctx context.Context, checkRunIDs ...uint) ([]*models.Approval, error)
+	// Decide creates the approval on a pending check run and updates the check run by decide
+	// with all the approvals of it in a transaction, the check run is locked during it.
+	// A nil check run is returned if the check run is not pending
+	// or the creator of approval has already made a decision on it.
+	Decide(ctx context.Context, approval *models.Approval,
+		decide func(run *models.CheckRun, approvals []*models.Approval)) (*models.CheckRun, error)
+}
+
+type approvalDAO struct{ db *gorm.DB }
+
+func NewApprovalDAO(db *gorm.DB) ApprovalDAO {
+	return &approvalDAO{db: db}
+}
+
+func (d *approvalDAO) Create(ctx context.Context, approval *models.Approval) (*models.Approval, error) {
+	result := d.db.WithContext(ctx).Create(approval)
+
+	if result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.ApprovalInDB, result.Error.Error())
+	}
+
+	return approval, nil
+}
+
+func (d *approvalDAO) ListByCheckRunIDs(ctx context.Context, checkRunIDs ...uint) ([]*models.Approval, error) {
+	var approvals []*models.Approval
+	if len(checkRunIDs) == 0 {
+		return approvals, nil
+	}
+	result := d.db.WithContext(ctx).Where("checkrun_id in ?", checkRunIDs).Order("id asc").Find(&approvals)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ApprovalInDB, result.Error.Error())
+	}
+	return approvals, nil
+}
+
+func (d *approvalDAO) Decide(ctx context.Context, approval *models.Approval,
+	decide func(run *models.CheckRun, approvals []*models.Approval)) (*models.CheckRun, error) {
+	var (
+		run     models.CheckRun
+		decided bool
+	)
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", approval.CheckRunID).Find(&run)
+		if result.Error != nil {
+			return herrors.NewErrGetFailed(herrors.CheckRunInDB, result.Error.Error())
+		}
+		if result.RowsAffected == 0 {
+			return herrors.NewErrNotFound(herrors.CheckRunInDB, "check run not found")
+		}
+		if run.Status != models.CheckStatusPending {
+			return nil
+		}
+
+		var count int64
+		result = tx.Model(&models.Approval{}).Where("checkrun_id = ?", run.ID).
+			Where("created_by = ?", approval.CreatedBy).Count(&count)
+		if result.Error != nil {
+			return herrors.NewErrGetFailed(herrors.ApprovalInDB, result.Error.Error())
+		}
+		if count > 0 {
+			return nil
+		}
+		if result := tx.Create(approval); result.Error != nil {
+			return herrors.NewErrInsertFailed(herrors.ApprovalInDB, result.Error.Error())
+		}
+
+		// count again with the approval created, the others are kept out by the lock
+		var approvals []*models.Approval
+		if result := tx.Where("checkrun_id = ?", run.ID).Order("id asc").Find(&approvals); result.Error != nil {
+			return herrors.NewErrGetFailed(herrors.ApprovalInDB, result.Error.Error())
+		}
+		decide(&run, approvals)
+		result = tx.Model(&models.CheckRun{}).Where("id = ?", run.ID).Updates(&models.CheckRun{
+			Status:  run.Status,
+			Message: run.Message,
+		})
+		if result.Error != nil {
+			return herrors.NewErrUpdateFailed(herrors.CheckRunInDB, result.Error.Error())
+		}
+		decided = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !decided {
+		return nil, nil
+	}
+	return &run, nil
+}
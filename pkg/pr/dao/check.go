@@ -2,17 +2,25 @@ package dao
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
 	"github.com/horizoncd/horizon/pkg/pr/models"
 )
 
 type CheckDAO interface {
 	// Create create a check
 	Create(ctx context.Context, check *models.Check) (*models.Check, error)
+	// Get gets a check by id
+	Get(ctx context.Context, id uint) (*models.Check, error)
+	// Update updates the kind, environment and approval policy of a check
+	Update(ctx context.Context, check *models.Check) (*models.Check, error)
+	// Delete deletes a check by id
+	Delete(ctx context.Context, id uint) error
 	// Update check run
 	UpdateByID(ctx context.Context, checkRunID uint, newCheckRun *models.CheckRun) error
 	// GetByResource get checks by resource
@@ -20,6 +28,8 @@ type CheckDAO interface {
 	GetCheckRunByID(ctx context.Context, checkRunID uint) (*models.CheckRun, error)
 	ListCheckRuns(ctx context.Context, pipelinerunID uint) ([]*models.CheckRun, error)
 	CreateCheckRun(ctx context.Context, run *models.CheckRun) (*models.CheckRun, error)
+	// ListPendingApprovalCheckRuns lists pending check runs of approval checks created before the time
+	ListPendingApprovalCheckRuns(ctx context.Context, before time.Time, query *q.Query) ([]*models.CheckRun, error)
 }
 
 type checkDAO struct{ db *gorm.DB }
@@ -38,6 +48,38 @@ func (d *checkDAO) Create(ctx context.Context, check *models.Check) (*models.Che
 	return check, result.Error
 }
 
+func (d *checkDAO) Get(ctx context.Context, id uint) (*models.Check, error) {
+	var check models.Check
+	result := d.db.WithContext(ctx).Where("id = ?", id).First(&check)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.CheckInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.CheckInDB, result.Error.Error())
+	}
+	return &check, nil
+}
+
+func (d *checkDAO) Update(ctx context.Context, check *models.Check) (*models.Check, error) {
+	result := d.db.WithContext(ctx).Model(&models.Check{}).Where("id = ?", check.ID).
+		Select("Kind", "Environment", "Approval").Updates(check)
+	if result.Error != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.CheckInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.CheckInDB, "check not found")
+	}
+	return d.Get(ctx, check.ID)
+}
+
+func (d *checkDAO) Delete(ctx context.Context, id uint) error {
+	result := d.db.WithContext(ctx).Delete(&models.Check{}, id)
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.CheckInDB, result.Error.Error())
+	}
+	return nil
+}
+
 func (d *checkDAO) UpdateByID(ctx context.Context, checkRunID uint, newCheckRun *models.CheckRun) error {
 	result := d.db.WithContext(ctx).Model(&models.CheckRun{}).Debug().Where("id = ?", checkRunID).Updates(newCheckRun)
 
@@ -106,3 +148,25 @@ func (d *checkDAO) GetCheckRunByID(ctx context.Context, checkRunID uint) (*model
 	}
 	return &checkRun, nil
 }
+
+func (d *checkDAO) ListPendingApprovalCheckRuns(ctx context.Context, before time.Time,
+	query *q.Query) ([]*models.CheckRun, error) {
+	var checkRuns []*models.CheckRun
+
+	statement := d.db.WithContext(ctx).Table("tb_checkrun").Select("tb_checkrun.*").
+		Joins("join tb_check on tb_check.id = tb_checkrun.check_id").
+		Where("tb_check.kind = ?", models.CheckKindApproval).
+		Where("tb_checkrun.status = ?", models.CheckStatusPending).
+		Where("tb_checkrun.created_at <= ?", before).
+		Where("tb_checkrun.deleted_ts = 0")
+	if query != nil {
+		if v, ok := query.Keywords[common.IDThan]; ok {
+			statement = statement.Where("tb_checkrun.id > ?", v)
+		}
+		statement = statement.Limit(query.Limit())
+	}
+	if result := statement.Order("tb_checkrun.id asc").Find(&checkRuns); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.CheckRunInDB, result.Error.Error())
+	}
+	return checkRuns, nil
+}
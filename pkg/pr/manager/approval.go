@@ -0,0 +1,47 @@
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/pr/dao"
+	"github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+type ApprovalManager interface {
+	// Create creates an approval
+	Create(ctx context.Context, approval *models.Approval) (*models.Approval, error)
+	// ListByCheckRunIDs lists approvals of check runs order by id asc
+	ListByCheckRunIDs(ctx context.Context, checkRunIDs ...uint) ([]*models.Approval, error)
+	// Decide creates the approval on a pending check run and updates the check run by decide
+	// with all the approvals of it, the check run is locked until it's updated.
+	// A nil check run is returned if the check run is not pending
+	// or the creator of approval has already made a decision on it.
+	Decide(ctx context.Context, approval *models.Approval,
+		decide func(run *models.CheckRun, approvals []*models.Approval)) (*models.CheckRun, error)
+}
+
+type approvalManager struct {
+	dao dao.ApprovalDAO
+}
+
+func NewApprovalManager(db *gorm.DB) ApprovalManager {
+	return &approvalManager{
+		dao: dao.NewApprovalDAO(db),
+	}
+}
+
+func (m *approvalManager) Create(ctx context.Context, approval *models.Approval) (*models.Approval, error) {
+	return m.dao.Create(ctx, approval)
+}
+
+func (m *approvalManager) ListByCheckRunIDs(ctx context.Context,
+	checkRunIDs ...uint) ([]*models.Approval, error) {
+	return m.dao.ListByCheckRunIDs(ctx, checkRunIDs...)
+}
+
+func (m *approvalManager) Decide(ctx context.Context, approval *models.Approval,
+	decide func(run *models.CheckRun, approvals []*models.Approval)) (*models.CheckRun, error) {
+	return m.dao.Decide(ctx, approval, decide)
+}
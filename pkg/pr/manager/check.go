@@ -2,10 +2,12 @@ package manager
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 
 	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/q"
 	"github.com/horizoncd/horizon/pkg/pr/dao"
 	"github.com/horizoncd/horizon/pkg/pr/models"
 )
@@ -16,6 +18,12 @@ import (
 type CheckManager interface {
 	// Create create a check
 	Create(ctx context.Context, check *models.Check) (*models.Check, error)
+	// Get gets a check by id
+	Get(ctx context.Context, id uint) (*models.Check, error)
+	// Update updates the kind, environment and approval policy of a check
+	Update(ctx context.Context, check *models.Check) (*models.Check, error)
+	// Delete deletes a check by id
+	Delete(ctx context.Context, id uint) error
 	// Update check run
 	UpdateByID(ctx context.Context, checkRunID uint, newCheckRun *models.CheckRun) error
 	// GetByResource get checks by resource
@@ -23,6 +31,8 @@ type CheckManager interface {
 	GetCheckRunByID(ctx context.Context, checkRunID uint) (*models.CheckRun, error)
 	ListCheckRuns(ctx context.Context, pipelinerunID uint) ([]*models.CheckRun, error)
 	CreateCheckRun(ctx context.Context, checkRun *models.CheckRun) (*models.CheckRun, error)
+	// ListPendingApprovalCheckRuns lists pending check runs of approval checks created before the time
+	ListPendingApprovalCheckRuns(ctx context.Context, before time.Time, query *q.Query) ([]*models.CheckRun, error)
 }
 
 type checkManager struct {
@@ -39,6 +49,18 @@ func (m *checkManager) Create(ctx context.Context, check *models.Check) (*models
 	return m.dao.Create(ctx, check)
 }
 
+func (m *checkManager) Get(ctx context.Context, id uint) (*models.Check, error) {
+	return m.dao.Get(ctx, id)
+}
+
+func (m *checkManager) Update(ctx context.Context, check *models.Check) (*models.Check, error) {
+	return m.dao.Update(ctx, check)
+}
+
+func (m *checkManager) Delete(ctx context.Context, id uint) error {
+	return m.dao.Delete(ctx, id)
+}
+
 func (m *checkManager) UpdateByID(ctx context.Context, checkRunID uint, newCheckRun *models.CheckRun) error {
 	return m.dao.UpdateByID(ctx, checkRunID, newCheckRun)
 }
@@ -58,3 +80,8 @@ func (m *checkManager) ListCheckRuns(ctx context.Context, pipelinerunID uint) ([
 func (m *checkManager) CreateCheckRun(ctx context.Context, checkRun *models.CheckRun) (*models.CheckRun, error) {
 	return m.dao.CreateCheckRun(ctx, checkRun)
 }
+
+func (m *checkManager) ListPendingApprovalCheckRuns(ctx context.Context, before time.Time,
+	query *q.Query) ([]*models.CheckRun, error) {
+	return m.dao.ListPendingApprovalCheckRuns(ctx, before, query)
+}
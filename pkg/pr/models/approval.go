@@ -0,0 +1,24 @@
+package models
+
+import (
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+const (
+	ApprovalDecisionApproved = "approved"
+	ApprovalDecisionRejected = "rejected"
+)
+
+// Approval is a decision made by an approver on a check run of an approval check
+type Approval struct {
+	global.Model
+	CheckRunID    uint `gorm:"column:checkrun_id"`
+	PipelineRunID uint `gorm:"column:pipeline_run_id"`
+	Decision      string
+	Comment       string
+	CreatedBy     uint
+}
+
+func (Approval) TableName() string {
+	return "tb_pr_approval"
+}
@@ -32,9 +32,31 @@ func String2CheckRunStatus(s string) CheckRunStatus {
 	return CheckStatusPending
 }
 
+const (
+	// CheckKindApproval is the kind of built-in approval checks,
+	// the check runs of other checks are reported by external systems
+	CheckKindApproval = "approval"
+)
+
 type Check struct {
 	global.Model
 	common.Resource `json:",inline"`
+	// Kind is empty for checks of external systems, or approval
+	Kind string `json:"kind"`
+	// Environment limits the check to clusters of the environment, empty means all the environments
+	Environment string `json:"environment"`
+	// Approval is the ApprovalPolicy in json, only for approval checks
+	Approval string `json:"-"`
+}
+
+// ApprovalPolicy decides who can approve pipelineruns and how many approvals are required
+type ApprovalPolicy struct {
+	// Required is the number of approvals required, that is, N of N-of-M
+	Required uint `json:"required"`
+	// Roles are the roles of cluster members who can approve
+	Roles []string `json:"roles,omitempty"`
+	// Users are the ids of users who can approve
+	Users []uint `json:"users,omitempty"`
 }
 
 type CheckRun struct {
@@ -2,12 +2,16 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/core/middleware/requestid"
 	amodels "github.com/horizoncd/horizon/pkg/application/models"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
 	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	gmodels "github.com/horizoncd/horizon/pkg/group/models"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	"github.com/horizoncd/horizon/pkg/pr/models"
@@ -19,8 +23,12 @@ type Service interface {
 		firstCanRollbackPipelinerun *models.Pipelinerun) (*models.PipelineBasic, error)
 	OfPipelineBasics(ctx context.Context, prs []*models.Pipelinerun,
 		firstCanRollbackPipelinerun *models.Pipelinerun) ([]*models.PipelineBasic, error)
+	// GetCheckByResource gets the checks of the resource and its ancestors,
+	// checks of other environments are filtered out for clusters
 	GetCheckByResource(ctx context.Context, resourceID uint,
 		resourceType string) ([]*models.Check, error)
+	// CreateApprovalCheckRuns creates pending check runs of the approval checks for the pipelinerun
+	CreateApprovalCheckRuns(ctx context.Context, prID uint, checks []*models.Check) error
 	// CreateUserMessage creates a user message on pipeline run
 	CreateUserMessage(ctx context.Context, prID uint, content string) (*models.PRMessage, error)
 	// CreateSystemMessageAsync creates a system message on pipeline run
@@ -152,7 +160,54 @@ func (s *service) GetCheckByResource(ctx context.Context, resourceID uint,
 		Type:       common.ResourceGroup,
 	})
 
-	return s.manager.PRMgr.Check.GetByResource(ctx, resources...)
+	checks, err := s.manager.PRMgr.Check.GetByResource(ctx, resources...)
+	if err != nil || cluster == nil {
+		return checks, err
+	}
+	result := make([]*models.Check, 0, len(checks))
+	for _, check := range checks {
+		if check.Environment == "" || check.Environment == cluster.EnvironmentName {
+			result = append(result, check)
+		}
+	}
+	return result, nil
+}
+
+// ParseApprovalPolicy parses the approval policy of an approval check
+func ParseApprovalPolicy(check *models.Check) (*models.ApprovalPolicy, error) {
+	var policy models.ApprovalPolicy
+	if err := json.Unmarshal([]byte(check.Approval), &policy); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"invalid approval policy of check %d: %s", check.ID, err)
+	}
+	return &policy, nil
+}
+
+// ApprovalMessage is the message of check runs of approval checks
+func ApprovalMessage(approved int, policy *models.ApprovalPolicy) string {
+	return fmt.Sprintf("%d/%d approvals", approved, policy.Required)
+}
+
+func (s *service) CreateApprovalCheckRuns(ctx context.Context, prID uint, checks []*models.Check) error {
+	for _, check := range checks {
+		if check.Kind != models.CheckKindApproval {
+			continue
+		}
+		policy, err := ParseApprovalPolicy(check)
+		if err != nil {
+			return err
+		}
+		if _, err := s.manager.PRMgr.Check.CreateCheckRun(ctx, &models.CheckRun{
+			Name:          models.CheckKindApproval,
+			CheckID:       check.ID,
+			Status:        models.CheckStatusPending,
+			Message:       ApprovalMessage(0, policy),
+			PipelineRunID: prID,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *service) CreateUserMessage(ctx context.Context, prID uint,
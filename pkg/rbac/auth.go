@@ -29,12 +29,37 @@ import (
 
 // Authorizer use the basic rbac rules to check if the user
 // have the permissions
+//
+//go:generate mockgen -source=$GOFILE -destination=../../mock/pkg/rbac/auth_mock.go -package=mock_rbac
 type Authorizer interface {
 	Authorize(ctx context.Context, attributes auth.Attributes) (auth.Decision, string, error)
+	// Explain explains how the decision of Authorize is made
+	Explain(ctx context.Context, attributes auth.Attributes) (*Explanation, error)
 }
 
 type VisitorFunc func(fmt.Stringer, *types.PolicyRule, error) bool
 
+// Explanation records the details of an authorization
+type Explanation struct {
+	Decision auth.Decision
+	Reason   string
+	// Member is the member of user found by member service, the default role is used when it's nil
+	Member *models.Member
+	// Chain is the resources which the member is inherited from, from the root group down to the resource
+	Chain []memberservice.Resource
+	Role  *types.Role
+	// RuleIndex is the index of the rule matched in role, -1 means no rule matched
+	RuleIndex int
+}
+
+// Rule returns the rule matched
+func (e *Explanation) Rule() *types.PolicyRule {
+	if e.Role == nil || e.RuleIndex < 0 || e.RuleIndex >= len(e.Role.PolicyRules) {
+		return nil
+	}
+	return &e.Role.PolicyRules[e.RuleIndex]
+}
+
 func NewAuthorizer(roleservice role.Service, memberservice memberservice.Service) Authorizer {
 	return &authorizer{
 		roleService:   roleservice,
@@ -59,23 +84,59 @@ const (
 
 func (a *authorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.Decision,
 	string, error) {
+	explanation, err := a.authorize(ctx, attr)
+	return explanation.Decision, explanation.Reason, err
+}
+
+func (a *authorizer) Explain(ctx context.Context, attr auth.Attributes) (*Explanation, error) {
+	explanation, err := a.authorize(ctx, attr)
+	if err != nil {
+		return nil, err
+	}
+	if explanation.Member == nil && explanation.Role == nil {
+		// not decided by members
+		return explanation, nil
+	}
+
+	chain, err := a.memberService.ListResourceChain(ctx, attr.GetResource(), attr.GetName())
+	if err != nil {
+		return nil, err
+	}
+	explanation.Chain = chain
+	return explanation, nil
+}
+
+func (a *authorizer) authorize(ctx context.Context, attr auth.Attributes) (*Explanation, error) {
+	explanation := &Explanation{
+		Decision:  auth.DecisionDeny,
+		RuleIndex: -1,
+	}
+	decide := func(decision auth.Decision, reason string) *Explanation {
+		explanation.Decision = decision
+		explanation.Reason = reason
+		return explanation
+	}
+
 	// 0. check (admin allows everything, and some are not checked)
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
-		return auth.DecisionDeny, AnonymousUser, nil
+		return decide(auth.DecisionDeny, AnonymousUser), nil
 	}
 	if currentUser.IsAdmin() {
-		return auth.DecisionAllow, AdminAllow, nil
+		return decide(auth.DecisionAllow, AdminAllow), nil
 	}
 
 	// TODO(tom): members, users, accesstokens and environments need to add to auth check
+	// member grants are checked by the controller, any user can request a temporary role,
+	// while only the requester and owners of the resource can see it, and only owners can review it
 	if attr.IsResourceRequest() && (attr.GetResource() == "members" ||
+		attr.GetResource() == "membergrants" || attr.GetSubResource() == "membergrants" ||
 		attr.GetResource() == "environments" || attr.GetResource() == "users" ||
 		attr.GetResource() == "personalaccesstokens" ||
 		(attr.GetResource() == "accesstokens" && attr.GetVerb() == "delete")) {
 		log.Warning(ctx,
 			"members|environments|access tokens are not authed yet")
-		return auth.DecisionAllow, NotChecked, nil
+		return decide(auth.DecisionAllow, NotChecked), nil
 	}
 
 	// 1. get the member
@@ -84,8 +145,9 @@ func (a *authorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.
 	if err != nil {
 		log.Warningf(ctx, "GetMemberOfResource error, resourceType = %s, resourceID = %s, user = %s\n",
 			attr.GetResource(), attr.GetName(), attr.GetUser().String())
-		return auth.DecisionDeny, InternalError, err
+		return decide(auth.DecisionDeny, InternalError), err
 	}
+	explanation.Member = member
 
 	// 2. get the role
 	var role *types.Role
@@ -96,7 +158,7 @@ func (a *authorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.
 		if defaultRole == nil {
 			log.Warningf(ctx, " user %s member and role not found of resourceType = %s, resourceID = %s",
 				attr.GetUser().String(), attr.GetResource(), attr.GetName())
-			return auth.DecisionDeny, MemberNotExist, nil
+			return decide(auth.DecisionDeny, MemberNotExist), nil
 		}
 		log.WithFiled(ctx, "user",
 			attr.GetUser().String()).Debugf(" use the default role %s", defaultRole.Name)
@@ -106,18 +168,22 @@ func (a *authorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.
 		role, err = a.roleService.GetRole(ctx, member.Role)
 		if err != nil {
 			log.Errorf(ctx, "get role for role(%s), err = %+v", member.Role, err)
-			return auth.DecisionDeny, InternalError, err
+			return decide(auth.DecisionDeny, InternalError), err
 		}
 	}
 	if role == nil {
-		return auth.DecisionDeny, RoleNotExist, nil
+		return decide(auth.DecisionDeny, RoleNotExist), nil
 	}
+	explanation.Role = role
 
 	// 3. check the permission
-	return VisitRoles(member, role, attr)
+	explanation.RuleIndex, _ = MatchRule(role, attr)
+	decision, reason, err := VisitRoles(member, explanation.RuleIndex, attr)
+	return decide(decision, reason), err
 }
 
-func VisitRoles(member *models.Member, role *types.Role,
+// VisitRoles decides by the index of rule matched by MatchRule
+func VisitRoles(member *models.Member, ruleIndex int,
 	attr auth.Attributes) (_ auth.Decision, reason string, err error) {
 	var memberInfo string
 	if member != nil {
@@ -125,13 +191,22 @@ func VisitRoles(member *models.Member, role *types.Role,
 	} else {
 		memberInfo = "null"
 	}
-	for i, rule := range role.PolicyRules {
-		if types.RuleAllow(attr, &rule) {
-			reason = fmt.Sprintf("user %s allowed by member(%s) by rule[%d]",
-				attr.GetUser().String(), memberInfo, i)
-			return auth.DecisionAllow, reason, nil
-		}
+	if ruleIndex >= 0 {
+		reason = fmt.Sprintf("user %s allowed by member(%s) by rule[%d]",
+			attr.GetUser().String(), memberInfo, ruleIndex)
+		return auth.DecisionAllow, reason, nil
 	}
 	reason = fmt.Sprintf("user %s denied by member(%s)", attr.GetUser().String(), memberInfo)
 	return auth.DecisionDeny, reason, nil
 }
+
+// MatchRule returns the first rule of role allowing the request and its index,
+// index is -1 if no rule matched
+func MatchRule(role *types.Role, attr auth.Attributes) (int, *types.PolicyRule) {
+	for i := range role.PolicyRules {
+		if types.RuleAllow(attr, &role.PolicyRules[i]) {
+			return i, &role.PolicyRules[i]
+		}
+	}
+	return -1, nil
+}
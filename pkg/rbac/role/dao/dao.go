@@ -0,0 +1,129 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/rbac/role/models"
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	// Create a role
+	Create(ctx context.Context, role *models.Role) (*models.Role, error)
+	// UpdateByID update a role
+	UpdateByID(ctx context.Context, id uint, role *models.Role) error
+	// DeleteByID delete a role by id
+	DeleteByID(ctx context.Context, id uint) error
+	// GetByID get by id
+	GetByID(ctx context.Context, id uint) (*models.Role, error)
+	// GetByName get by name
+	GetByName(ctx context.Context, name string) (*models.Role, error)
+	// ListAll list all roles ordered by priority desc
+	ListAll(ctx context.Context) ([]*models.Role, error)
+}
+
+type dao struct{ db *gorm.DB }
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, role *models.Role) (*models.Role, error) {
+	result := d.db.WithContext(ctx).Create(role)
+
+	if result.Error != nil {
+		return nil, herrors.NewErrCreateFailed(herrors.RoleInDB, result.Error.Error())
+	}
+
+	return role, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.Role, error) {
+	var role models.Role
+	result := d.db.WithContext(ctx).Where("id = ?", id).First(&role)
+
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.RoleInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.RoleInDB, result.Error.Error())
+	}
+
+	return &role, nil
+}
+
+func (d *dao) GetByName(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	result := d.db.WithContext(ctx).Where("name = ?", name).First(&role)
+
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.RoleInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.RoleInDB, result.Error.Error())
+	}
+
+	return &role, nil
+}
+
+func (d *dao) ListAll(ctx context.Context) ([]*models.Role, error) {
+	var roles []*models.Role
+	result := d.db.WithContext(ctx).Order("priority desc").Order("id asc").Find(&roles)
+
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.RoleInDB, result.Error.Error())
+	}
+
+	return roles, nil
+}
+
+func (d *dao) UpdateByID(ctx context.Context, id uint, role *models.Role) error {
+	result := d.db.WithContext(ctx).Where("id = ?", id).
+		Select("Description", "Priority", "Rules").Updates(role)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.RoleInDB, result.Error.Error())
+	}
+
+	return nil
+}
+
+func (d *dao) DeleteByID(ctx context.Context, id uint) error {
+	role, err := d.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// check if any member is bound to the role
+	var count int64
+	result := d.db.WithContext(ctx).Model(&membermodels.Member{}).
+		Where("role = ?", role.Name).Where("deleted_ts = 0").Count(&count)
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.RoleInDB, result.Error.Error())
+	}
+	if count > 0 {
+		return herrors.ErrRoleUsedByMembers
+	}
+
+	result = d.db.WithContext(ctx).Delete(&models.Role{}, id)
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.RoleInDB, result.Error.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/rbac/role/manager"
+	"github.com/horizoncd/horizon/pkg/rbac/role/models"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// _builtinPriorityStep is the gap between priorities of adjacent built-in roles,
+// so that custom roles can be ranked between them
+const _builtinPriorityStep = 100
+
+// dbRoleService serves the built-in roles from config together with the custom roles in db,
+// built-in roles are read-only and take precedence over custom roles with the same name
+type dbRoleService struct {
+	builtin    *fileRoleService
+	priorities map[string]int
+	roleMgr    manager.Manager
+}
+
+func NewDBRole(ctx context.Context, config roleconfig.Config, roleMgr manager.Manager) (Service, error) {
+	fRole, err := NewFileRoleFrom2(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return &dbRoleService{
+		builtin:    fRole.(*fileRoleService),
+		priorities: BuiltinPriorities(config.RolePriorityRankDesc),
+		roleMgr:    roleMgr,
+	}, nil
+}
+
+// BuiltinPriorities returns priorities of the built-in roles by their rank
+func BuiltinPriorities(rankDesc []string) map[string]int {
+	priorities := make(map[string]int, len(rankDesc))
+	for i, roleName := range rankDesc {
+		priorities[roleName] = (len(rankDesc) - i) * _builtinPriorityStep
+	}
+	return priorities
+}
+
+func (s *dbRoleService) ListRole(ctx context.Context) ([]types.Role, error) {
+	roles, err := s.builtin.ListRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+	priorities := make([]int, 0, len(roles))
+	for _, r := range roles {
+		priorities = append(priorities, s.priorities[r.Name])
+	}
+
+	customRoles, err := s.roleMgr.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, customRole := range customRoles {
+		if _, ok := s.priorities[customRole.Name]; ok {
+			log.Warningf(ctx, "custom role %s is shadowed by built-in role", customRole.Name)
+			continue
+		}
+		r, err := OfModel(customRole)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, *r)
+		priorities = append(priorities, customRole.Priority)
+	}
+
+	indexes := make([]int, len(roles))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return priorities[indexes[i]] > priorities[indexes[j]]
+	})
+	sorted := make([]types.Role, 0, len(roles))
+	for _, i := range indexes {
+		sorted = append(sorted, roles[i])
+	}
+	return sorted, nil
+}
+
+func (s *dbRoleService) GetRole(ctx context.Context, roleName string) (*types.Role, error) {
+	if r, err := s.builtin.GetRole(ctx, roleName); err == nil {
+		return r, nil
+	}
+	customRole, err := s.getCustomRole(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+	return OfModel(customRole)
+}
+
+func (s *dbRoleService) RoleCompare(ctx context.Context, role1, role2 string) (CompResult, error) {
+	priority1, err := s.priority(ctx, role1)
+	if err != nil {
+		log.Errorf(ctx, "role %s cannot found", role1)
+		return RoleCanNotCompare, err
+	}
+	priority2, err := s.priority(ctx, role2)
+	if err != nil {
+		log.Errorf(ctx, "role %s cannot found", role2)
+		return RoleCanNotCompare, err
+	}
+	if priority1 > priority2 {
+		return RoleBigger, nil
+	} else if priority1 < priority2 {
+		return RoleSmaller, nil
+	}
+	return RoleEqual, nil
+}
+
+func (s *dbRoleService) GetDefaultRole(ctx context.Context) *types.Role {
+	return s.builtin.GetDefaultRole(ctx)
+}
+
+func (s *dbRoleService) priority(ctx context.Context, roleName string) (int, error) {
+	if priority, ok := s.priorities[roleName]; ok {
+		return priority, nil
+	}
+	customRole, err := s.getCustomRole(ctx, roleName)
+	if err != nil {
+		return 0, err
+	}
+	return customRole.Priority, nil
+}
+
+func (s *dbRoleService) getCustomRole(ctx context.Context, roleName string) (*models.Role, error) {
+	customRole, err := s.roleMgr.GetByName(ctx, roleName)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil, ErrorRoleNotFound
+		}
+		return nil, err
+	}
+	return customRole, nil
+}
+
+// OfModel converts custom role in db to role
+func OfModel(m *models.Role) (*types.Role, error) {
+	var rules []types.PolicyRule
+	if m.Rules != "" {
+		if err := json.Unmarshal([]byte(m.Rules), &rules); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"failed to unmarshal rules of role %s: %v", m.Name, err)
+		}
+	}
+	return &types.Role{
+		Name:        m.Name,
+		Desc:        m.Description,
+		PolicyRules: rules,
+	}, nil
+}
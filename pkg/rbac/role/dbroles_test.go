@@ -0,0 +1,116 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/rbac/role/manager"
+	"github.com/horizoncd/horizon/pkg/rbac/role/models"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+	"github.com/horizoncd/horizon/pkg/server/route"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBRole(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&models.Role{}); err != nil {
+		panic(err)
+	}
+	roleMgr := manager.New(db)
+
+	config := roleconfig.Config{
+		RolePriorityRankDesc: []string{Owner, Guest},
+		DefaultRole:          Guest,
+		Roles:                []types.Role{{Name: Owner}, {Name: Guest}},
+	}
+	s, err := NewDBRole(ctx, config, roleMgr)
+	assert.Nil(t, err)
+
+	_, err = roleMgr.Create(ctx, &models.Role{
+		Name:     "deployer",
+		Priority: 150,
+		Rules:    `[{"verbs":["create"],"apiGroups":["core"],"resources":["clusters/deploy"]}]`,
+	})
+	assert.Nil(t, err)
+
+	roles, err := s.ListRole(ctx)
+	assert.Nil(t, err)
+	names := make([]string, 0, len(roles))
+	for _, r := range roles {
+		names = append(names, r.Name)
+	}
+	assert.Equal(t, []string{Owner, "deployer", Guest}, names)
+
+	r, err := s.GetRole(ctx, "deployer")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"clusters/deploy"}, r.PolicyRules[0].Resources)
+
+	_, err = s.GetRole(ctx, "unknown")
+	assert.Equal(t, ErrorRoleNotFound, err)
+
+	ret, err := s.RoleCompare(ctx, Owner, "deployer")
+	assert.Nil(t, err)
+	assert.Equal(t, RoleBigger, ret)
+	ret, err = s.RoleCompare(ctx, Guest, "deployer")
+	assert.Nil(t, err)
+	assert.Equal(t, RoleSmaller, ret)
+	ret, err = s.RoleCompare(ctx, "deployer", "unknown")
+	assert.Equal(t, ErrorRoleNotFound, err)
+	assert.Equal(t, RoleCanNotCompare, ret)
+
+	assert.Equal(t, Guest, s.GetDefaultRole(ctx).Name)
+}
+
+func TestValidateRules(t *testing.T) {
+	engine := gin.New()
+	route.RegisterRoutes(engine.Group("/apis/core/v2"), route.Routes{
+		{Method: http.MethodGet, Pattern: "/clusters/:clusterID"},
+		{Method: http.MethodPost, Pattern: "/clusters/:clusterID/deploy"},
+		{Method: http.MethodGet, Pattern: "/:resourceType/:resourceID/members"},
+	})
+
+	valid := [][]types.PolicyRule{
+		{{Verbs: []string{"create"}, APIGroups: []string{"core"}, Resources: []string{"clusters/deploy"}}},
+		{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*/deploy"}}},
+		{{Verbs: []string{"list"}, APIGroups: []string{"core"}, Resources: []string{"clusters/members"}}},
+		{{Verbs: []string{"get"}, NonResourceURLs: []string{"/apis/front/*"}}},
+		{{Verbs: []string{"create"}, APIGroups: []string{"core"}, Resources: []string{"clusters/deploy"},
+			Environments: []string{"test"}, Regions: []string{"hz"}}},
+	}
+	for _, rules := range valid {
+		assert.Nil(t, ValidateRules(rules))
+	}
+
+	invalid := [][]types.PolicyRule{
+		{{Verbs: []string{"deploy"}, APIGroups: []string{"core"}, Resources: []string{"clusters"}}},
+		{{Verbs: []string{"get"}, APIGroups: []string{"unknown"}, Resources: []string{"clusters"}}},
+		{{Verbs: []string{"get"}, APIGroups: []string{"core"}, Resources: []string{"unknown"}}},
+		{{Verbs: []string{"get"}, APIGroups: []string{"core"}, Resources: []string{"clusters/unknown"}}},
+		{{Verbs: []string{"get"}, APIGroups: []string{"core"}}},
+		{{Verbs: []string{"get"}, APIGroups: []string{"core"}, Resources: []string{"clusters"},
+			Environments: []string{"test/hz"}}},
+	}
+	for _, rules := range invalid {
+		err := ValidateRules(rules)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/rbac/role/dao"
+	"github.com/horizoncd/horizon/pkg/rbac/role/models"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	// Create a role
+	Create(ctx context.Context, role *models.Role) (*models.Role, error)
+	// UpdateByID update a role
+	UpdateByID(ctx context.Context, id uint, role *models.Role) error
+	// DeleteByID delete a role by id
+	DeleteByID(ctx context.Context, id uint) error
+	// GetByID get by id
+	GetByID(ctx context.Context, id uint) (*models.Role, error)
+	// GetByName get by name
+	GetByName(ctx context.Context, name string) (*models.Role, error)
+	// ListAll list all roles ordered by priority desc
+	ListAll(ctx context.Context) ([]*models.Role, error)
+}
+
+type manager struct {
+	roleDAO dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		roleDAO: dao.NewDAO(db),
+	}
+}
+
+func (m manager) Create(ctx context.Context, role *models.Role) (*models.Role, error) {
+	return m.roleDAO.Create(ctx, role)
+}
+
+func (m manager) UpdateByID(ctx context.Context, id uint, role *models.Role) error {
+	return m.roleDAO.UpdateByID(ctx, id, role)
+}
+
+func (m manager) DeleteByID(ctx context.Context, id uint) error {
+	return m.roleDAO.DeleteByID(ctx, id)
+}
+
+func (m manager) GetByID(ctx context.Context, id uint) (*models.Role, error) {
+	return m.roleDAO.GetByID(ctx, id)
+}
+
+func (m manager) GetByName(ctx context.Context, name string) (*models.Role, error) {
+	return m.roleDAO.GetByName(ctx, name)
+}
+
+func (m manager) ListAll(ctx context.Context) ([]*models.Role, error) {
+	return m.roleDAO.ListAll(ctx)
+}
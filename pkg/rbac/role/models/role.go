@@ -0,0 +1,32 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+// Role is a custom role defined by admins, it's stored with rules encoded in json
+type Role struct {
+	global.Model
+
+	Name        string
+	Description string
+	// Priority decides the rank of the role, the bigger the higher
+	Priority  int
+	Rules     string
+	CreatedBy uint
+	UpdatedBy uint
+}
@@ -0,0 +1,102 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+	"github.com/horizoncd/horizon/pkg/server/route"
+	"github.com/horizoncd/horizon/pkg/util/sets"
+)
+
+// verbs are the verbs resolved from http methods by the request info resolver
+var verbs = sets.NewString(types.VerbAll, "get", "list", "create", "update", "patch", "delete")
+
+// ValidateRules checks that the verbs, api groups and resources referenced by rules exist,
+// resources are looked up in the registered routes
+func ValidateRules(rules []types.PolicyRule) error {
+	resources := route.Resources()
+	for i, rule := range rules {
+		if len(rule.Verbs) == 0 {
+			return perror.Wrapf(herrors.ErrParamInvalid, "rule %d: verbs cannot be empty", i)
+		}
+		for _, verb := range rule.Verbs {
+			if !verbs.Has(verb) {
+				return perror.Wrapf(herrors.ErrParamInvalid, "rule %d: verb %s is not supported", i, verb)
+			}
+		}
+
+		if len(rule.Resources) == 0 && len(rule.NonResourceURLs) == 0 {
+			return perror.Wrapf(herrors.ErrParamInvalid,
+				"rule %d: either resources or nonResourceURLs should be specified", i)
+		}
+		if len(rule.Resources) > 0 && len(rule.APIGroups) == 0 {
+			return perror.Wrapf(herrors.ErrParamInvalid, "rule %d: apiGroups cannot be empty", i)
+		}
+
+		served := sets.NewString()
+		for _, group := range rule.APIGroups {
+			if group == types.APIGroupAll {
+				for _, rs := range resources {
+					served = served.Union(rs)
+				}
+				continue
+			}
+			rs, ok := resources[group]
+			if !ok {
+				return perror.Wrapf(herrors.ErrParamInvalid, "rule %d: api group %s does not exist", i, group)
+			}
+			served = served.Union(rs)
+		}
+		for _, resource := range rule.Resources {
+			if !resourceServed(served, resource) {
+				return perror.Wrapf(herrors.ErrParamInvalid, "rule %d: resource %s does not exist", i, resource)
+			}
+		}
+
+		for _, v := range append(append([]string{}, rule.Environments...), rule.Regions...) {
+			if v == "" || strings.Contains(v, "/") {
+				return perror.Wrapf(herrors.ErrParamInvalid, "rule %d: invalid environment or region %q", i, v)
+			}
+		}
+	}
+	return nil
+}
+
+func resourceServed(served sets.String, resource string) bool {
+	if resource == types.ResourceAll || served.Has(resource) {
+		return true
+	}
+	parts := strings.SplitN(resource, "/", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	parent, sub := parts[0], parts[1]
+	// subresources of routes whose resource is a path param are recorded as */subresource
+	if served.Has(types.ResourceAll + "/" + sub) {
+		return parent == types.ResourceAll || served.Has(parent)
+	}
+	if parent == types.ResourceAll {
+		for _, r := range served.UnsortedList() {
+			if strings.HasSuffix(r, "/"+sub) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -93,6 +93,48 @@ func ScopeMatches(rule *PolicyRule, requestScope string) bool {
 	return false
 }
 
+// EnvironmentRegionMatches checks the environment and region in scope with the constraints of rule,
+// scope is in format of {environment}/{region}
+func EnvironmentRegionMatches(rule *PolicyRule, requestScope string) bool {
+	if requestScope == "" || (len(rule.Environments) == 0 && len(rule.Regions) == 0) {
+		return true
+	}
+	parts := strings.SplitN(requestScope, "/", 2)
+	environment, region := parts[0], ""
+	if len(parts) == 2 {
+		region = parts[1]
+	}
+	return valueMatches(rule.Environments, environment) && valueMatches(rule.Regions, region)
+}
+
+func valueMatches(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, v := range allowed {
+		if v == ScopeAll || v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ConstrainTo returns a copy of the rule constrained to the environment and region,
+// region is optional, false is returned if the rule can't be applied to them at all
+func (rule PolicyRule) ConstrainTo(environment, region string) (PolicyRule, bool) {
+	if !valueMatches(rule.Environments, environment) {
+		return rule, false
+	}
+	rule.Environments = []string{environment}
+	if region != "" {
+		if !valueMatches(rule.Regions, region) {
+			return rule, false
+		}
+		rule.Regions = []string{region}
+	}
+	return rule, true
+}
+
 func NonResourceURLMatches(rule *PolicyRule, requestedURL string) bool {
 	for _, ruleURL := range rule.NonResourceURLs {
 		if ruleURL == NonResourceAll {
@@ -118,7 +160,8 @@ func RuleAllow(attribute auth.Attributes, rule *PolicyRule) bool {
 		return VerbMatches(rule, attribute.GetVerb()) &&
 			APIGroupMatches(rule, attribute.GetAPIGroup()) &&
 			ResourceMatches(rule, combinedResource, attribute.GetSubResource()) &&
-			ScopeMatches(rule, attribute.GetScope())
+			ScopeMatches(rule, attribute.GetScope()) &&
+			EnvironmentRegionMatches(rule, attribute.GetScope())
 	}
 	return VerbMatches(rule, attribute.GetVerb()) &&
 		NonResourceURLMatches(rule, attribute.GetPath())
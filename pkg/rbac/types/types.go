@@ -37,4 +37,8 @@ type PolicyRule struct {
 	Resources       []string `yaml:"resources" json:"resources"`
 	Scopes          []string `yaml:"scopes" json:"scopes"`
 	NonResourceURLs []string `yaml:"nonResourceURLs" json:"nonResourceURLs"`
+	// Environments and Regions constrain the rule to requests targeting clusters in them,
+	// empty means not constrained, requests without scope are not constrained either
+	Environments []string `yaml:"environments,omitempty" json:"environments,omitempty"`
+	Regions      []string `yaml:"regions,omitempty" json:"regions,omitempty"`
 }
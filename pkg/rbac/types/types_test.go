@@ -262,6 +262,77 @@ func TestRuleAllow(t *testing.T) {
 				NonResourceURLs: []string{"/apis/front/*"},
 			},
 			allowed: false,
+		}, {
+			// case environment constraint allow
+			attr: auth.AttributesRecord{
+				Verb:            "create",
+				APIGroup:        "core",
+				Resource:        "clusters",
+				SubResource:     "builddeploy",
+				Scope:           "test/hz",
+				ResourceRequest: true,
+			},
+			policy: PolicyRule{
+				Verbs:        []string{"*"},
+				APIGroups:    []string{"core"},
+				Resources:    []string{"clusters/builddeploy"},
+				Scopes:       []string{"*"},
+				Environments: []string{"test"},
+			},
+			allowed: true,
+		}, {
+			// case environment constraint deny
+			attr: auth.AttributesRecord{
+				Verb:            "create",
+				APIGroup:        "core",
+				Resource:        "clusters",
+				SubResource:     "builddeploy",
+				Scope:           "online/hz",
+				ResourceRequest: true,
+			},
+			policy: PolicyRule{
+				Verbs:        []string{"*"},
+				APIGroups:    []string{"core"},
+				Resources:    []string{"clusters/builddeploy"},
+				Scopes:       []string{"*"},
+				Environments: []string{"test"},
+			},
+			allowed: false,
+		}, {
+			// case region constraint deny
+			attr: auth.AttributesRecord{
+				Verb:            "create",
+				APIGroup:        "core",
+				Resource:        "clusters",
+				SubResource:     "builddeploy",
+				Scope:           "test/hz",
+				ResourceRequest: true,
+			},
+			policy: PolicyRule{
+				Verbs:        []string{"*"},
+				APIGroups:    []string{"core"},
+				Resources:    []string{"clusters/builddeploy"},
+				Scopes:       []string{"*"},
+				Environments: []string{"test"},
+				Regions:      []string{"jd"},
+			},
+			allowed: false,
+		}, {
+			// case environment constraint allow (request not targeting a cluster)
+			attr: auth.AttributesRecord{
+				Verb:            "get",
+				APIGroup:        "core",
+				Resource:        "applications",
+				ResourceRequest: true,
+			},
+			policy: PolicyRule{
+				Verbs:        []string{"get"},
+				APIGroups:    []string{"core"},
+				Resources:    []string{"applications"},
+				Scopes:       []string{"*"},
+				Environments: []string{"test"},
+			},
+			allowed: true,
 		},
 	}
 
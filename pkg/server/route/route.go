@@ -16,10 +16,16 @@ package route
 
 import (
 	"net/http"
+	"path"
+	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
+	"github.com/horizoncd/horizon/pkg/util/sets"
 )
 
+const _apiPrefix = "apis"
+
 // Route is the information for every URI.
 type Route struct {
 	// Method is the string for the HTTP method. ex) GET, POST etc..
@@ -33,6 +39,13 @@ type Route struct {
 // Routes is the list of the generated Route.
 type Routes []Route
 
+var (
+	resourcesLock sync.RWMutex
+	// resources records the resources of each api group served by the routes,
+	// subresources are recorded as resource/subresource
+	resources = make(map[string]sets.String)
+)
+
 // RegisterRoutes register every route to routerGroup
 func RegisterRoutes(api *gin.RouterGroup, routes Routes) {
 	for _, route := range routes {
@@ -48,5 +61,48 @@ func RegisterRoutes(api *gin.RouterGroup, routes Routes) {
 		case http.MethodDelete:
 			api.DELETE(route.Pattern, route.HandlerFunc)
 		}
+		recordResource(path.Join(api.BasePath(), route.Pattern))
+	}
+}
+
+// recordResource parses path like the request info resolver does,
+// path params in the place of resource are recorded as *
+func recordResource(fullPath string) {
+	parts := strings.Split(strings.Trim(fullPath, "/"), "/")
+	if len(parts) < 4 || parts[0] != _apiPrefix {
+		return
+	}
+	group, parts := parts[1], parts[3:]
+
+	resource := parts[0]
+	if isParam(resource) {
+		resource = "*"
+	}
+
+	resourcesLock.Lock()
+	defer resourcesLock.Unlock()
+	if _, ok := resources[group]; !ok {
+		resources[group] = sets.NewString()
+	}
+	if resource != "*" {
+		resources[group].Insert(resource)
+	}
+	if len(parts) >= 3 && !isParam(parts[2]) {
+		resources[group].Insert(resource + "/" + parts[2])
+	}
+}
+
+func isParam(part string) bool {
+	return strings.HasPrefix(part, ":") || strings.HasPrefix(part, "*")
+}
+
+// Resources returns the resources served by the registered routes, keyed by api group
+func Resources() map[string]sets.String {
+	resourcesLock.RLock()
+	defer resourcesLock.RUnlock()
+	ret := make(map[string]sets.String, len(resources))
+	for group, rs := range resources {
+		ret[group] = sets.NewString(rs.UnsortedList()...)
 	}
+	return ret
 }
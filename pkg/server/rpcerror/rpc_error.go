@@ -76,4 +76,10 @@ var (
 		HTTPCode:  http.StatusConflict,
 		ErrorCode: "Conflict",
 	}
+	// DeployWindowClosedError is returned when deploying is forbidden by deploy windows,
+	// clients can retry with an override reason
+	DeployWindowClosedError = RPCError{
+		HTTPCode:  http.StatusForbidden,
+		ErrorCode: "DeployWindowClosed",
+	}
 )
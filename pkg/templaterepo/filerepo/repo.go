@@ -0,0 +1,291 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package filerepo stores packaged charts and an index.yaml in a local directory or a s3 bucket,
+// the layout is the same as a helm chart repository, so it works without a chart server.
+// Charts of both kinds are served by horizon itself under ServePath, so that argocd or flux can fetch them
+// without access to the directory or the bucket.
+// The index is only guarded by a lock in process, so the repo should not be written by multiple
+// horizon instances at the same time.
+package filerepo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/s3"
+	config "github.com/horizoncd/horizon/pkg/config/templaterepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	kindLocal = "local"
+	kindS3    = "s3"
+
+	indexFileName = "index.yaml"
+	chartFileExt  = ".tgz"
+
+	// ServePath is the path under which horizon serves the files of the template repo
+	ServePath = "/apis/front/v2/templaterepo"
+)
+
+// indexFile is in the same format as index.yaml of helm chart repository
+type indexFile struct {
+	APIVersion string                     `json:"apiVersion"`
+	Generated  time.Time                  `json:"generated"`
+	Entries    map[string][]*chartVersion `json:"entries"`
+}
+
+type chartVersion struct {
+	*chart.Metadata
+	URLs    []string  `json:"urls"`
+	Created time.Time `json:"created"`
+	Digest  string    `json:"digest"`
+}
+
+func newIndexFile() *indexFile {
+	return &indexFile{
+		APIVersion: chart.APIVersionV1,
+		Entries:    map[string][]*chartVersion{},
+	}
+}
+
+func init() {
+	templaterepo.Register(kindLocal, NewRepo)
+	templaterepo.Register(kindS3, NewRepo)
+}
+
+type Repo struct {
+	loc     string
+	storage storage
+	// m guards the read-modify-write of index
+	m sync.Mutex
+}
+
+func NewRepo(config config.Repo) (templaterepo.TemplateRepo, error) {
+	// the location is used as the repository of chart dependencies in gitops repos,
+	// so it must be an url of horizon accessible by argocd or flux rather than a local path or a bucket
+	if config.Host == "" {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"host is required for %s template repo to serve charts", config.Kind)
+	}
+	loc := strings.TrimSuffix(config.Host, "/") + ServePath
+
+	switch config.Kind {
+	case kindLocal:
+		if config.Path == "" {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, "path is required for local template repo")
+		}
+		dir, err := filepath.Abs(config.Path)
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "path is incorrect: %v", err)
+		}
+		s, err := newLocalStorage(dir)
+		if err != nil {
+			return nil, err
+		}
+		return &Repo{loc: loc, storage: s}, nil
+	case kindS3:
+		if config.S3 == nil {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, "s3 is required for s3 template repo")
+		}
+		driver, err := s3.NewDriver(s3.Params{
+			AccessKey:        config.S3.AccessKey,
+			SecretKey:        config.S3.SecretKey,
+			Region:           config.S3.Region,
+			Endpoint:         config.S3.Endpoint,
+			Bucket:           config.S3.Bucket,
+			Prefix:           config.S3.Prefix,
+			DisableSSL:       config.S3.DisableSSL,
+			SkipVerify:       config.S3.SkipVerify,
+			S3ForcePathStyle: config.S3.S3ForcePathStyle,
+			ContentType:      "application/octet-stream",
+		})
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to create s3 driver: %v", err)
+		}
+		return &Repo{loc: loc, storage: &s3Storage{driver: driver}}, nil
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "kind (%s) is not supported", config.Kind)
+	}
+}
+
+func (r *Repo) GetLoc() string {
+	return r.loc
+}
+
+func (r *Repo) UploadChart(chartPkg *chart.Chart) error {
+	var buf bytes.Buffer
+	if err := templaterepo.ChartSerialize(chartPkg, &buf); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	name, version := chartPkg.Metadata.Name, chartPkg.Metadata.Version
+	fileName := chartFileName(name, version)
+	if err := r.storage.Put(ctx, fileName, buf.Bytes()); err != nil {
+		return err
+	}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+	index, err := r.loadIndex(ctx)
+	if err != nil {
+		return err
+	}
+	removeEntry(index, name, version)
+	index.Entries[name] = append(index.Entries[name], &chartVersion{
+		Metadata: chartPkg.Metadata,
+		URLs:     []string{fileName},
+		Created:  time.Now(),
+		Digest:   fmt.Sprintf("%x", sha256.Sum256(buf.Bytes())),
+	})
+	return r.saveIndex(ctx, index)
+}
+
+func (r *Repo) DeleteChart(name string, version string) error {
+	ctx := context.Background()
+
+	r.m.Lock()
+	defer r.m.Unlock()
+	index, err := r.loadIndex(ctx)
+	if err != nil {
+		return err
+	}
+	if !removeEntry(index, name, version) {
+		return herrors.NewErrNotFound(herrors.TemplateReleaseInRepo,
+			fmt.Sprintf("chart %s-%s not found", name, version))
+	}
+	if err := r.saveIndex(ctx, index); err != nil {
+		return err
+	}
+	return r.storage.Delete(ctx, chartFileName(name, version))
+}
+
+func (r *Repo) ExistChart(name string, version string) (bool, error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	index, err := r.loadIndex(context.Background())
+	if err != nil {
+		return false, err
+	}
+	return getEntry(index, name, version) != nil, nil
+}
+
+func (r *Repo) GetChart(name string, version string, lastSyncAt time.Time) (*chart.Chart, error) {
+	content, err := r.storage.Get(context.Background(), chartFileName(name, version))
+	if err != nil {
+		return nil, err
+	}
+	chartPackage, err := loader.LoadArchive(bytes.NewReader(content))
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrLoadChartArchive,
+			fmt.Sprintf("failed to load archive: %v", err))
+	}
+	return chartPackage, nil
+}
+
+// ServeHTTP serves index.yaml and packaged charts, just like a static helm chart repository
+func (r *Repo) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	name := path.Base(req.URL.Path)
+	if name != indexFileName && (!strings.HasSuffix(name, chartFileExt) || strings.HasPrefix(name, ".")) {
+		http.NotFound(w, req)
+		return
+	}
+	content, err := r.storage.Get(req.Context(), name)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			http.NotFound(w, req)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.ServeContent(w, req, name, time.Time{}, bytes.NewReader(content))
+}
+
+func (r *Repo) loadIndex(ctx context.Context) (*indexFile, error) {
+	content, err := r.storage.Get(ctx, indexFileName)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return newIndexFile(), nil
+		}
+		return nil, err
+	}
+	index := newIndexFile()
+	if err := yaml.Unmarshal(content, index); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to unmarshal index: %v", err)
+	}
+	if index.Entries == nil {
+		index.Entries = map[string][]*chartVersion{}
+	}
+	return index, nil
+}
+
+func (r *Repo) saveIndex(ctx context.Context, index *indexFile) error {
+	// newest first, like helm does
+	for _, versions := range index.Entries {
+		sort.SliceStable(versions, func(i, j int) bool {
+			return versions[i].Created.After(versions[j].Created)
+		})
+	}
+	index.Generated = time.Now()
+	content, err := yaml.Marshal(index)
+	if err != nil {
+		return perror.Wrapf(herrors.ErrParamInvalid, "failed to marshal index: %v", err)
+	}
+	return r.storage.Put(ctx, indexFileName, content)
+}
+
+// getEntry matches version exactly, as versions of templates are not always semver
+func getEntry(index *indexFile, name, version string) *chartVersion {
+	for _, v := range index.Entries[name] {
+		if v.Version == version {
+			return v
+		}
+	}
+	return nil
+}
+
+func removeEntry(index *indexFile, name, version string) bool {
+	versions := index.Entries[name]
+	for i, v := range versions {
+		if v.Version == version {
+			index.Entries[name] = append(versions[:i], versions[i+1:]...)
+			if len(index.Entries[name]) == 0 {
+				delete(index.Entries, name)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func chartFileName(name, version string) string {
+	return fmt.Sprintf("%s-%s%s", name, version, chartFileExt)
+}
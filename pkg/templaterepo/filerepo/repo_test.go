@@ -0,0 +1,154 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filerepo
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	config "github.com/horizoncd/horizon/pkg/config/templaterepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"sigs.k8s.io/yaml"
+)
+
+func TestLocal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filerepo")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	repo, err := templaterepo.NewRepo(config.Repo{Kind: kindLocal, Path: dir, Host: "http://horizon.example.com/"})
+	assert.Nil(t, err)
+	assert.Equal(t, "http://horizon.example.com"+ServePath, repo.GetLoc())
+	testRepo(t, repo)
+
+	// index.yaml is readable as a helm chart repository index
+	content, err := ioutil.ReadFile(filepath.Join(dir, indexFileName))
+	assert.Nil(t, err)
+	index := newIndexFile()
+	assert.Nil(t, yaml.Unmarshal(content, index))
+	assert.Equal(t, 1, len(index.Entries["javaapp"]))
+	assert.Equal(t, "v1.0.0", index.Entries["javaapp"][0].Version)
+	assert.Equal(t, []string{"javaapp-v1.0.0.tgz"}, index.Entries["javaapp"][0].URLs)
+
+	// the index and charts are served over http
+	handler := repo.(*templaterepo.RepoWithCache).TemplateRepo.(http.Handler)
+	for name, code := range map[string]int{
+		indexFileName:        http.StatusOK,
+		"javaapp-v1.0.0.tgz": http.StatusOK,
+		"javaapp-v2.0.0.tgz": http.StatusNotFound,
+		"other.yaml":         http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, ServePath+"/"+name, nil))
+		assert.Equal(t, code, w.Code, name)
+		if code == http.StatusOK {
+			expected, err := ioutil.ReadFile(filepath.Join(dir, name))
+			assert.Nil(t, err)
+			assert.Equal(t, expected, w.Body.Bytes())
+		}
+	}
+
+	_, err = templaterepo.NewRepo(config.Repo{Kind: kindLocal})
+	assert.NotNil(t, err)
+	_, err = templaterepo.NewRepo(config.Repo{Kind: kindLocal, Path: dir})
+	assert.NotNil(t, err)
+}
+
+func TestS3(t *testing.T) {
+	backend := s3mem.New()
+	_ = backend.CreateBucket("bucket")
+	ts := httptest.NewServer(gofakes3.New(backend).Server())
+	defer ts.Close()
+
+	repo, err := templaterepo.NewRepo(config.Repo{
+		Kind: kindS3,
+		Host: "http://horizon.example.com",
+		S3: &config.S3{
+			AccessKey:        "accessKey",
+			SecretKey:        "secretKey",
+			Region:           "us-east-1",
+			Endpoint:         ts.URL,
+			Bucket:           "bucket",
+			Prefix:           "charts",
+			S3ForcePathStyle: true,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "http://horizon.example.com"+ServePath, repo.GetLoc())
+	testRepo(t, repo)
+
+	// the charts in the bucket are served over http as well
+	handler := repo.(*templaterepo.RepoWithCache).TemplateRepo.(http.Handler)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, ServePath+"/javaapp-v1.0.0.tgz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	_, err = templaterepo.NewRepo(config.Repo{Kind: kindS3, S3: &config.S3{Bucket: "bucket"}})
+	assert.NotNil(t, err)
+}
+
+func testRepo(t *testing.T, repo templaterepo.TemplateRepo) {
+	exist, err := repo.ExistChart("javaapp", "v1.0.0")
+	assert.Nil(t, err)
+	assert.False(t, exist)
+
+	for _, version := range []string{"v1.0.0", "v1.0.1"} {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "javaapp", Version: version, APIVersion: chart.APIVersionV2},
+			Files:    []*chart.File{{Name: "test", Data: []byte(version)}},
+		}
+		assert.Nil(t, repo.UploadChart(c))
+	}
+	// upload again to overwrite
+	assert.Nil(t, repo.UploadChart(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "javaapp", Version: "v1.0.1", APIVersion: chart.APIVersionV2},
+		Files:    []*chart.File{{Name: "test", Data: []byte("overwritten")}},
+	}))
+
+	exist, err = repo.ExistChart("javaapp", "v1.0.1")
+	assert.Nil(t, err)
+	assert.True(t, exist)
+
+	tm := time.Now()
+	c, err := repo.GetChart("javaapp", "v1.0.1", tm)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("overwritten"), c.Files[0].Data)
+	// use cache
+	c, err = repo.GetChart("javaapp", "v1.0.1", tm)
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.0.1", c.Metadata.Version)
+
+	assert.Nil(t, repo.DeleteChart("javaapp", "v1.0.1"))
+	exist, err = repo.ExistChart("javaapp", "v1.0.1")
+	assert.Nil(t, err)
+	assert.False(t, exist)
+	_, err = repo.GetChart("javaapp", "v1.0.1", time.Now())
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	err = repo.DeleteChart("javaapp", "v1.0.1")
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
@@ -0,0 +1,119 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filerepo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/s3"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// storage stores files by name, Get returns HorizonErrNotFound if the file does not exist
+type storage interface {
+	Get(ctx context.Context, name string) ([]byte, error)
+	Put(ctx context.Context, name string, content []byte) error
+	Delete(ctx context.Context, name string) error
+}
+
+type localStorage struct {
+	dir string
+}
+
+func newLocalStorage(dir string) (storage, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, perror.Wrapf(herrors.ErrWriteFailed, "failed to create directory %s: %v", dir, err)
+	}
+	return &localStorage{dir: dir}, nil
+}
+
+func (l *localStorage) Get(ctx context.Context, name string) ([]byte, error) {
+	content, err := ioutil.ReadFile(filepath.Join(l.dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, herrors.NewErrNotFound(herrors.TemplateReleaseInRepo,
+				fmt.Sprintf("file %s not found", name))
+		}
+		return nil, perror.Wrapf(herrors.ErrReadFailed, "failed to read file %s: %v", name, err)
+	}
+	return content, nil
+}
+
+// Put writes to a temporary file first and renames it, so readers never see a partial file
+func (l *localStorage) Put(ctx context.Context, name string, content []byte) error {
+	tmp, err := ioutil.TempFile(l.dir, "."+name)
+	if err != nil {
+		return perror.Wrapf(herrors.ErrWriteFailed, "failed to create file %s: %v", name, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return perror.Wrapf(herrors.ErrWriteFailed, "failed to write file %s: %v", name, err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(l.dir, name)); err != nil {
+		return perror.Wrapf(herrors.ErrWriteFailed, "failed to write file %s: %v", name, err)
+	}
+	return nil
+}
+
+func (l *localStorage) Delete(ctx context.Context, name string) error {
+	if err := os.Remove(filepath.Join(l.dir, name)); err != nil && !os.IsNotExist(err) {
+		return herrors.NewErrDeleteFailed(herrors.TemplateReleaseInRepo,
+			fmt.Sprintf("failed to delete file %s: %v", name, err))
+	}
+	return nil
+}
+
+type s3Storage struct {
+	driver s3.Interface
+}
+
+func (s *s3Storage) Get(ctx context.Context, name string) ([]byte, error) {
+	content, err := s.driver.GetObject(ctx, name)
+	if err != nil {
+		if e, ok := err.(awserr.Error); ok && e.Code() == awss3.ErrCodeNoSuchKey {
+			return nil, herrors.NewErrNotFound(herrors.TemplateReleaseInRepo,
+				fmt.Sprintf("object %s not found", name))
+		}
+		return nil, perror.Wrapf(herrors.ErrReadFailed, "failed to get object %s: %v", name, err)
+	}
+	return content, nil
+}
+
+func (s *s3Storage) Put(ctx context.Context, name string, content []byte) error {
+	if err := s.driver.PutObject(ctx, name, bytes.NewReader(content), nil); err != nil {
+		return perror.Wrapf(herrors.ErrWriteFailed, "failed to put object %s: %v", name, err)
+	}
+	return nil
+}
+
+func (s *s3Storage) Delete(ctx context.Context, name string) error {
+	if err := s.driver.DeleteObjects(ctx, name); err != nil {
+		return herrors.NewErrDeleteFailed(herrors.TemplateReleaseInRepo,
+			fmt.Sprintf("failed to delete object %s: %v", name, err))
+	}
+	return nil
+}
@@ -0,0 +1,447 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oci stores charts as OCI artifacts in the same layout as `helm push`,
+// so that the charts can be pulled by helm and argo cd with oci://<host>/<repoName>.
+// The registry client of helm is internal in the helm version we use,
+// so charts are pushed and pulled with the distribution api directly.
+package oci
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	config "github.com/horizoncd/horizon/pkg/config/templaterepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"k8s.io/helm/pkg/tlsutil"
+)
+
+const kind = "oci"
+
+const (
+	configMediaType       = "application/vnd.cncf.helm.config.v1+json"
+	chartLayerMediaType   = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+	legacyChartLayerMedia = "application/tar+gzip"
+	manifestMediaType     = "application/vnd.oci.image.manifest.v1+json"
+)
+
+var _challengeParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+func init() {
+	templaterepo.Register(kind, NewRepo)
+}
+
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+type manifest struct {
+	SchemaVersion int           `json:"schemaVersion"`
+	MediaType     string        `json:"mediaType,omitempty"`
+	Config        descriptor    `json:"config"`
+	Layers        []*descriptor `json:"layers"`
+}
+
+type Repo struct {
+	host     *url.URL
+	username string
+	password string
+	repoName string
+	client   *http.Client
+
+	// bearerTokens caches bearer tokens issued by the token server, keyed by scope
+	bearerTokens sync.Map
+}
+
+func NewRepo(config config.Repo) (templaterepo.TemplateRepo, error) {
+	host, err := url.Parse(config.Host)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid,
+			fmt.Sprintf("url is incorrect: %v", err))
+	}
+	if host.Scheme == "" || host.Host == "" {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"url should be in format of scheme://host, but got %s", config.Host)
+	}
+
+	tlsConf, err := tlsutil.NewClientTLS(config.CertFile, config.KeyFile, config.CAFile)
+	if err != nil {
+		return nil, perror.Wrap(herrors.NewErrCreateFailed(herrors.TLS, err.Error()),
+			"failed to create TLS: %v")
+	}
+	tlsConf.InsecureSkipVerify = config.Insecure
+
+	return &Repo{
+		host:     host,
+		username: config.Username,
+		password: config.Password,
+		repoName: strings.Trim(config.RepoName, "/"),
+		client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: tlsConf,
+			},
+		},
+	}, nil
+}
+
+func (h *Repo) GetLoc() string {
+	return fmt.Sprintf("oci://%s", path.Join(h.host.Host, h.repoName))
+}
+
+func (h *Repo) UploadChart(chartPkg *chart.Chart) error {
+	var buf bytes.Buffer
+	if err := templaterepo.ChartSerialize(chartPkg, &buf); err != nil {
+		return err
+	}
+	configData, err := json.Marshal(chartPkg.Metadata)
+	if err != nil {
+		return perror.Wrap(herrors.ErrParamInvalid,
+			fmt.Sprintf("failed to marshal chart metadata: %v", err))
+	}
+
+	repository := h.repository(chartPkg.Metadata.Name)
+	configDesc, err := h.pushBlob(repository, configMediaType, configData)
+	if err != nil {
+		return err
+	}
+	layerDesc, err := h.pushBlob(repository, chartLayerMediaType, buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	manifestData, err := json.Marshal(&manifest{
+		SchemaVersion: 2,
+		MediaType:     manifestMediaType,
+		Config:        *configDesc,
+		Layers:        []*descriptor{layerDesc},
+	})
+	if err != nil {
+		return perror.Wrap(herrors.ErrParamInvalid,
+			fmt.Sprintf("failed to marshal manifest: %v", err))
+	}
+	resp, err := h.do(http.MethodPut, repository, "push",
+		h.manifestLink(repository, tagOf(chartPkg.Metadata.Version)), manifestData,
+		http.Header{"Content-Type": []string{manifestMediaType}})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusCreated {
+		return respError(resp)
+	}
+	return nil
+}
+
+func (h *Repo) DeleteChart(name string, version string) error {
+	repository := h.repository(name)
+	digest, err := h.getDigest(repository, version)
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.do(http.MethodDelete, repository, "delete", h.manifestLink(repository, digest), nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return respError(resp)
+	}
+	return nil
+}
+
+func (h *Repo) ExistChart(name string, version string) (bool, error) {
+	_, err := h.getDigest(h.repository(name), version)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (h *Repo) GetChart(name string, version string, lastSyncAt time.Time) (*chart.Chart, error) {
+	repository := h.repository(name)
+	resp, err := h.do(http.MethodGet, repository, "pull", h.manifestLink(repository, tagOf(version)), nil,
+		http.Header{"Accept": []string{manifestMediaType}})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, herrors.NewErrNotFound(herrors.TemplateReleaseInRepo,
+			fmt.Sprintf("chart %s-%s not found", name, version))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, respError(resp)
+	}
+
+	var m manifest
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected,
+			fmt.Sprintf("failed to decode manifest: %v", err))
+	}
+	var layer *descriptor
+	for _, l := range m.Layers {
+		if l.MediaType == chartLayerMediaType || l.MediaType == legacyChartLayerMedia {
+			layer = l
+			break
+		}
+	}
+	if layer == nil {
+		return nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"no chart layer found in %s:%s", repository, version)
+	}
+
+	data, err := h.pullBlob(repository, layer.Digest)
+	if err != nil {
+		return nil, err
+	}
+	chartPackage, err := loader.LoadArchive(bytes.NewReader(data))
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrLoadChartArchive,
+			fmt.Sprintf("failed to load archive: %v", err))
+	}
+	return chartPackage, nil
+}
+
+func (h *Repo) getDigest(repository, version string) (string, error) {
+	resp, err := h.do(http.MethodHead, repository, "pull", h.manifestLink(repository, tagOf(version)), nil,
+		http.Header{"Accept": []string{manifestMediaType}})
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Header.Get("Docker-Content-Digest"), nil
+	case http.StatusNotFound:
+		return "", herrors.NewErrNotFound(herrors.TemplateReleaseInRepo,
+			fmt.Sprintf("chart %s:%s not found", repository, version))
+	default:
+		return "", perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"failed to get digest of %s:%s, status = %s", repository, version, resp.Status)
+	}
+}
+
+// pushBlob uploads the blob in a single request if it does not exist in the repository
+func (h *Repo) pushBlob(repository, mediaType string, data []byte) (*descriptor, error) {
+	desc := &descriptor{
+		MediaType: mediaType,
+		Digest:    fmt.Sprintf("sha256:%x", sha256.Sum256(data)),
+		Size:      int64(len(data)),
+	}
+
+	resp, err := h.do(http.MethodHead, repository, "push", h.blobLink(repository, desc.Digest), nil)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return desc, nil
+	}
+
+	resp, err = h.do(http.MethodPost, repository, "push",
+		fmt.Sprintf("%s/v2/%s/blobs/uploads/", h.linkWithSchemeAndHost(), repository), nil)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return nil, respError(resp)
+	}
+	location, err := resp.Location()
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected,
+			fmt.Sprintf("failed to get upload location: %v", err))
+	}
+	query := location.Query()
+	query.Set("digest", desc.Digest)
+	location.RawQuery = query.Encode()
+
+	resp, err = h.do(http.MethodPut, repository, "push", location.String(), data,
+		http.Header{"Content-Type": []string{"application/octet-stream"}})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusCreated {
+		return nil, respError(resp)
+	}
+	return desc, nil
+}
+
+func (h *Repo) pullBlob(repository, digest string) ([]byte, error) {
+	resp, err := h.do(http.MethodGet, repository, "pull", h.blobLink(repository, digest), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, respError(resp)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrReadFailed,
+			fmt.Sprintf("failed to read response: %v", err))
+	}
+	return data, nil
+}
+
+// do sends request with basic auth, and retries with a bearer token
+// if the registry requires token authentication
+func (h *Repo) do(method, repository, action, link string, body []byte,
+	headers ...http.Header) (*http.Response, error) {
+	scope := fmt.Sprintf("repository:%s:pull", repository)
+	if action != "pull" {
+		scope = fmt.Sprintf("repository:%s:pull,push,delete", repository)
+	}
+	token, _ := h.bearerTokens.Load(scope)
+	bearer, _ := token.(string)
+
+	resp, err := h.send(method, link, bearer, body, headers...)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	_ = resp.Body.Close()
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"unauthorized to %s %s, challenge = %s", method, link, challenge)
+	}
+	bearer, err = h.fetchBearerToken(challenge, scope)
+	if err != nil {
+		return nil, err
+	}
+	h.bearerTokens.Store(scope, bearer)
+	return h.send(method, link, bearer, body, headers...)
+}
+
+func (h *Repo) send(method, link, bearer string, body []byte, headers ...http.Header) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, link, reader)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRequestFailed,
+			fmt.Sprintf("failed to create request: %v", err))
+	}
+	for _, header := range headers {
+		for k, values := range header {
+			for _, v := range values {
+				req.Header.Add(k, v)
+			}
+		}
+	}
+	if bearer != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", bearer))
+	} else if h.username != "" {
+		req.SetBasicAuth(h.username, h.password)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRequestFailed,
+			fmt.Sprintf("failed to create send request: %v", err))
+	}
+	return resp, nil
+}
+
+// fetchBearerToken gets a token from the token server in the challenge,
+// see https://docs.docker.com/registry/spec/auth/token/ for more information
+func (h *Repo) fetchBearerToken(challenge, scope string) (string, error) {
+	params := map[string]string{}
+	for _, match := range _challengeParamRegex.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+	realm, ok := params["realm"]
+	if !ok {
+		return "", perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "no realm in challenge: %s", challenge)
+	}
+	query := url.Values{}
+	if service, ok := params["service"]; ok {
+		query.Set("service", service)
+	}
+	query.Set("scope", scope)
+
+	resp, err := h.send(http.MethodGet, fmt.Sprintf("%s?%s", realm, query.Encode()), "", nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", respError(resp)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", perror.Wrap(herrors.ErrHTTPRespNotAsExpected, err.Error())
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	return token.Token, nil
+}
+
+func (h *Repo) linkWithSchemeAndHost() string {
+	return fmt.Sprintf("%s://%s", h.host.Scheme, h.host.Host)
+}
+
+func (h *Repo) repository(name string) string {
+	return path.Join(h.repoName, name)
+}
+
+func (h *Repo) manifestLink(repository, reference string) string {
+	return fmt.Sprintf("%s/v2/%s/manifests/%s", h.linkWithSchemeAndHost(), repository, url.PathEscape(reference))
+}
+
+func (h *Repo) blobLink(repository, digest string) string {
+	return fmt.Sprintf("%s/v2/%s/blobs/%s", h.linkWithSchemeAndHost(), repository, digest)
+}
+
+// tagOf converts chart version to oci tag, '+' is not allowed in tags, helm replaces it with '_'
+func tagOf(version string) string {
+	return strings.ReplaceAll(version, "+", "_")
+}
+
+func respError(resp *http.Response) error {
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return perror.Wrap(herrors.ErrReadFailed,
+			fmt.Sprintf("failed to read response: %v", err))
+	}
+	return perror.Wrap(herrors.ErrHTTPRespNotAsExpected,
+		fmt.Sprintf("%s: %s", resp.Status, string(b)))
+}
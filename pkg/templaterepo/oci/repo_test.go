@@ -0,0 +1,80 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/cluster/registry/distribution/mockserver"
+	config "github.com/horizoncd/horizon/pkg/config/templaterepo"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestRepo(t *testing.T) {
+	for _, tokenAuth := range []bool{false, true} {
+		server := mockserver.NewDistributionServer()
+		server.TokenAuth = tokenAuth
+		s := httptest.NewServer(http.HandlerFunc(server.R.ServeHTTP))
+
+		repo, err := templaterepo.NewRepo(config.Repo{
+			Kind:     kind,
+			Host:     s.URL,
+			Username: "admin",
+			Password: "password",
+			RepoName: "horizon-template",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "oci://"+s.Listener.Addr().String()+"/horizon-template", repo.GetLoc())
+
+		name, version := "javaapp", "v1.0.0+build.1"
+		exist, err := repo.ExistChart(name, version)
+		assert.Nil(t, err)
+		assert.False(t, exist)
+
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{Name: name, Version: version, APIVersion: chart.APIVersionV2},
+			Files:    []*chart.File{{Name: "test", Data: []byte("hello, world")}},
+		}
+		assert.Nil(t, repo.UploadChart(c))
+		// uploading again reuses the existing blobs
+		assert.Nil(t, repo.UploadChart(c))
+		assert.NotNil(t, server.Repositories["horizon-template/javaapp"]["v1.0.0_build.1"])
+
+		exist, err = repo.ExistChart(name, version)
+		assert.Nil(t, err)
+		assert.True(t, exist)
+
+		c, err = repo.GetChart(name, version, time.Now())
+		assert.Nil(t, err)
+		assert.Equal(t, name, c.Metadata.Name)
+		assert.Equal(t, version, c.Metadata.Version)
+		assert.Equal(t, []byte("hello, world"), c.Files[0].Data)
+
+		assert.Nil(t, repo.DeleteChart(name, version))
+		exist, err = repo.ExistChart(name, version)
+		assert.Nil(t, err)
+		assert.False(t, exist)
+		_, err = repo.GetChart(name, "v2.0.0", time.Now())
+		assert.NotNil(t, err)
+		assert.NotNil(t, repo.DeleteChart(name, version))
+
+		s.Close()
+	}
+}
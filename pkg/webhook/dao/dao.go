@@ -17,6 +17,7 @@ package dao
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -41,6 +42,7 @@ type DAO interface {
 		resources map[string][]uint) ([]*models.WebhookLogWithEventInfo, int64, error)
 	ListWebhookLogsByStatus(ctx context.Context, wID uint,
 		status string) ([]*models.WebhookLog, error)
+	ListWebhookLogsToSend(ctx context.Context, wID uint, now time.Time) ([]*models.WebhookLog, error)
 	ListWebhookLogsByMap(ctx context.Context,
 		webhookEventMap map[uint][]uint) ([]*models.WebhookLog, error)
 	UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error)
@@ -48,6 +50,7 @@ type DAO interface {
 	GetWebhookLogByEventID(ctx context.Context, webhookID, eventID uint) (*models.WebhookLog, error)
 	GetMaxEventIDOfLog(ctx context.Context) (uint, error)
 	DeleteWebhookLogs(ctx context.Context, id ...uint) (int64, error)
+	RedeliverWebhookLogs(ctx context.Context, wID uint, since time.Time) (int64, error)
 }
 
 type dao struct{ db *gorm.DB }
@@ -130,7 +133,8 @@ func (d *dao) ListWebhookOfResources(ctx context.Context,
 func (d *dao) UpdateWebhook(ctx context.Context, id uint,
 	w *models.Webhook) (*models.Webhook, error) {
 	if result := d.db.WithContext(ctx).Where("id = ?", id).
-		Select("enabled", "url", "enable_ssl_verify", "description", "secret", "triggers").
+		Select("enabled", "url", "enable_ssl_verify", "description", "secret", "secondary_secret", "triggers",
+			"max_attempts", "retry_backoff_seconds", "payload_format", "payload_template").
 		Updates(w); result.Error != nil {
 		return nil, herrors.NewErrUpdateFailed(herrors.WebhookInDB, result.Error.Error())
 	}
@@ -268,10 +272,22 @@ func (d *dao) ListWebhookLogsByStatus(ctx context.Context, wID uint,
 	return ws, nil
 }
 
+func (d *dao) ListWebhookLogsToSend(ctx context.Context, wID uint,
+	now time.Time) ([]*models.WebhookLog, error) {
+	var ws []*models.WebhookLog
+	if result := d.db.WithContext(ctx).Where("webhook_id = ?", wID).
+		Where(d.db.Where("status = ?", models.StatusWaiting).
+			Or("status = ? and next_retry_at <= ?", models.StatusRetrying, now)).
+		Find(&ws); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.WebhookLogInDB, result.Error.Error())
+	}
+	return ws, nil
+}
+
 func (d *dao) UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error) {
 	if result := d.db.WithContext(ctx).Where("id = ?", wl.ID).
 		Select("status", "response_headers", "response_body",
-			"status", "error_message").
+			"status", "error_message", "attempts", "next_retry_at").
 		Updates(wl); result.Error != nil {
 		return nil, herrors.NewErrUpdateFailed(herrors.WebhookLogInDB, result.Error.Error())
 	}
@@ -322,3 +338,21 @@ func (d *dao) GetMaxEventIDOfLog(ctx context.Context) (uint, error) {
 	}
 	return maxID, nil
 }
+
+func (d *dao) RedeliverWebhookLogs(ctx context.Context, wID uint, since time.Time) (int64, error) {
+	result := d.db.WithContext(ctx).Model(&models.WebhookLog{}).
+		Where("webhook_id = ?", wID).
+		Where("status in ?", []string{models.StatusFailed, models.StatusDeadLetter}).
+		Where("created_at >= ?", since).
+		Where("NOT (" + models.NothingToSendCondition + ")").
+		Updates(map[string]interface{}{
+			"status":        models.StatusWaiting,
+			"attempts":      0,
+			"next_retry_at": nil,
+			"error_message": "",
+		})
+	if result.Error != nil {
+		return 0, herrors.NewErrUpdateFailed(herrors.WebhookLogInDB, result.Error.Error())
+	}
+	return result.RowsAffected, nil
+}
@@ -16,10 +16,13 @@ package manager
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 	"github.com/horizoncd/horizon/pkg/webhook/dao"
 	models "github.com/horizoncd/horizon/pkg/webhook/models"
@@ -41,12 +44,16 @@ type Manager interface {
 		webhookEventMap map[uint][]uint) ([]*models.WebhookLog, error)
 	ListWebhookLogsByStatus(ctx context.Context, wID uint,
 		status string) ([]*models.WebhookLog, error)
+	ListWebhookLogsToSend(ctx context.Context, wID uint, now time.Time) ([]*models.WebhookLog, error)
 	UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error)
 	GetWebhookLog(ctx context.Context, id uint) (*models.WebhookLog, error)
 	ResendWebhook(ctx context.Context, id uint) (*models.WebhookLog, error)
 	GetWebhookLogByEventID(ctx context.Context, webhookID, eventID uint) (*models.WebhookLog, error)
 	GetMaxEventIDOfLog(ctx context.Context) (uint, error)
 	DeleteWebhookLogs(ctx context.Context, id ...uint) (int64, error)
+	// RedeliverWebhookLogs resets failed and dead-lettered logs of a webhook created since the given time
+	// to waiting, so that they will be sent again with a fresh attempt counter
+	RedeliverWebhookLogs(ctx context.Context, wID uint, since time.Time) (int64, error)
 }
 
 type manager struct {
@@ -130,6 +137,11 @@ func (m *manager) ListWebhookLogsByStatus(ctx context.Context, wID uint,
 	return m.dao.ListWebhookLogsByStatus(ctx, wID, status)
 }
 
+func (m *manager) ListWebhookLogsToSend(ctx context.Context, wID uint,
+	now time.Time) ([]*models.WebhookLog, error) {
+	return m.dao.ListWebhookLogsToSend(ctx, wID, now)
+}
+
 func (m *manager) UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error) {
 	const op = "webhook manager: update  webhook log"
 	defer wlog.Start(ctx, op).StopPrint()
@@ -155,6 +167,13 @@ func (m *manager) DeleteWebhookLogs(ctx context.Context, id ...uint) (int64, err
 	return m.dao.DeleteWebhookLogs(ctx, id...)
 }
 
+func (m *manager) RedeliverWebhookLogs(ctx context.Context, wID uint, since time.Time) (int64, error) {
+	const op = "webhook manager: redeliver webhook logs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return m.dao.RedeliverWebhookLogs(ctx, wID, since)
+}
+
 func (m *manager) ResendWebhook(ctx context.Context, id uint) (*models.WebhookLog, error) {
 	const op = "webhook manager: resend"
 	defer wlog.Start(ctx, op).StopPrint()
@@ -164,6 +183,10 @@ func (m *manager) ResendWebhook(ctx context.Context, id uint) (*models.WebhookLo
 	if err != nil {
 		return nil, err
 	}
+	if wl.HasNothingToSend() {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"webhook log %d has nothing to send: %s", id, wl.ErrorMessage)
+	}
 
 	// 2. make a copy with waiting status
 	wlCopy := models.WebhookLog{
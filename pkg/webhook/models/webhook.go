@@ -14,28 +14,90 @@
 
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+)
+
+// MaxRetryBackoff caps the delay between two attempts of a webhook log
+const MaxRetryBackoff = time.Hour
+
+// TriggerSeparator separates the triggers of a webhook stored in db
+const TriggerSeparator = ","
+
+const (
+	// PayloadFormatDefault sends the horizon message as json
+	PayloadFormatDefault = "default"
+	// PayloadFormatTemplate sends the payload rendered by the go template of the webhook
+	PayloadFormatTemplate = "template"
+	// PayloadFormatSlack, PayloadFormatFeishu, PayloadFormatDingTalk and PayloadFormatWeCom send
+	// a text message accepted by the incoming webhook of the chat bot
+	PayloadFormatSlack    = "slack"
+	PayloadFormatFeishu   = "feishu"
+	PayloadFormatDingTalk = "dingtalk"
+	PayloadFormatWeCom    = "wecom"
+)
 
 const (
 	StatusWaiting = "waiting"
 	StatusSuccess = "success"
 	StatusFailed  = "failed"
+	// StatusRetrying means the last attempt failed with a retryable error
+	// and the log will be sent again after NextRetryAt
+	StatusRetrying = "retrying"
+	// StatusDeadLetter means the log failed after exhausting the max attempts of its webhook
+	StatusDeadLetter = "deadletter"
 )
 
 type Webhook struct {
-	ID               uint
-	Enabled          bool
-	URL              string
-	SSLVerifyEnabled bool
-	Description      string
-	Secret           string
-	Triggers         string
-	ResourceType     string
-	ResourceID       uint
-	CreatedAt        time.Time
-	CreatedBy        uint
-	UpdatedAt        time.Time
-	UpdatedBy        uint
+	ID                  uint
+	Enabled             bool
+	URL                 string
+	SSLVerifyEnabled    bool
+	Description         string
+	Secret              string
+	SecondarySecret     string
+	Triggers            string
+	MaxAttempts         uint
+	RetryBackoffSeconds uint
+	PayloadFormat       string
+	PayloadTemplate     string
+	ResourceType        string
+	ResourceID          uint
+	CreatedAt           time.Time
+	CreatedBy           uint
+	UpdatedAt           time.Time
+	UpdatedBy           uint
+}
+
+// MatchEventType returns whether the event type is one of the triggers of the webhook
+func (w *Webhook) MatchEventType(eventType string) bool {
+	for _, trigger := range strings.Split(w.Triggers, TriggerSeparator) {
+		if trigger == eventmodels.Any || trigger == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryEnabled returns whether failed logs of the webhook should be sent again
+func (w *Webhook) RetryEnabled() bool {
+	return w.MaxAttempts > 1
+}
+
+// RetryDelay returns the exponential backoff to wait after the given number of attempts,
+// which is RetryBackoffSeconds * 2^(attempts-1) and no more than MaxRetryBackoff
+func (w *Webhook) RetryDelay(attempts uint) time.Duration {
+	delay := time.Duration(w.RetryBackoffSeconds) * time.Second
+	for i := uint(1); i < attempts && delay < MaxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > MaxRetryBackoff {
+		delay = MaxRetryBackoff
+	}
+	return delay
 }
 
 type WebhookLog struct {
@@ -49,11 +111,22 @@ type WebhookLog struct {
 	ResponseBody    string
 	Status          string
 	ErrorMessage    string
+	Attempts        uint
+	NextRetryAt     *time.Time
 	CreatedAt       time.Time
 	CreatedBy       uint
 	UpdatedAt       time.Time
 }
 
+// NothingToSendCondition is the sql condition of the logs HasNothingToSend returns true for
+const NothingToSendCondition = "status = '" + StatusFailed + "' AND attempts = 0 AND request_data = ''"
+
+// HasNothingToSend returns whether the log failed before it's ever sent without a request,
+// such as failing to render payload, so it can't be sent again
+func (wl *WebhookLog) HasNothingToSend() bool {
+	return wl.Status == StatusFailed && wl.Attempts == 0 && wl.RequestData == ""
+}
+
 type WebhookLogWithEventInfo struct {
 	WebhookLog
 	EventType    string
@@ -39,11 +39,13 @@ import (
 	webhookmanager "github.com/horizoncd/horizon/pkg/webhook/manager"
 	"github.com/horizoncd/horizon/pkg/webhook/models"
 	webhookmodels "github.com/horizoncd/horizon/pkg/webhook/models"
+	"github.com/horizoncd/horizon/pkg/webhook/signature"
 )
 
 type worker struct {
 	idleWaitInterval         uint
 	responseBodyTruncateSize uint
+	disableSecretHeader      bool
 
 	ctx            context.Context
 	insecureClient http.Client
@@ -132,7 +134,7 @@ func (s *service) reconcileWorkers() {
 			// 2.2 create workers
 			s.workers[id] = newWebhookWorker(s.webhookManager, s.eventManager,
 				s.userManager, webhook, s.config.IdleWaitInterval,
-				s.config.ClientTimeout, s.config.ResponseBodyTruncateSize)
+				s.config.ClientTimeout, s.config.ResponseBodyTruncateSize, s.config.DisableSecretHeader)
 		}
 		reconciled[id] = true
 	}
@@ -182,10 +184,11 @@ func (s *service) StopAndWait() {
 func newWebhookWorker(webhookMgr webhookmanager.Manager,
 	eventMgr eventmanager.Manager, userMgr usermanager.Manager,
 	webhook *models.Webhook, idleWaitInterval uint,
-	clientTimeout, responseBodyTruncateSize uint) *worker {
+	clientTimeout, responseBodyTruncateSize uint, disableSecretHeader bool) *worker {
 	ww := &worker{
 		idleWaitInterval:         idleWaitInterval,
 		responseBodyTruncateSize: responseBodyTruncateSize,
+		disableSecretHeader:      disableSecretHeader,
 		ctx:                      context.Background(),
 		quit:                     make(chan bool, 1),
 		insecureClient: http.Client{
@@ -208,39 +211,56 @@ func newWebhookWorker(webhookMgr webhookmanager.Manager,
 	return ww
 }
 
-func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models.WebhookLog {
-	// 1. make request and set body
-	reqBody, err := addWebhookLogID([]byte(wl.RequestData), wl.ID)
-	if err != nil {
-		wl.ErrorMessage = fmt.Sprintf("failed to add id, error: %+v", err)
+// sendWebhook sends the webhook log once and records the error message if it fails,
+// the returned bool tells whether the failure is transient and worth retrying
+func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, bool) {
+	wl.ErrorMessage = ""
+	wl.Attempts++
+
+	// 1. parse headers
+	headers := http.Header{}
+	if err := yaml.Unmarshal([]byte(wl.RequestHeaders), &headers); err != nil {
+		wl.ErrorMessage = fmt.Sprintf("failed to unmarshal header, error: %+v", err)
 		log.Errorf(ctx, wl.ErrorMessage)
-		return wl
+		return wl, false
+	}
+
+	// 2. make request and set body, only the default payload carries the id of log
+	reqBody := []byte(wl.RequestData)
+	if wlgenerator.NormalizePayloadFormat(headers.Get(wlgenerator.WebhookFormatHeader)) ==
+		webhookmodels.PayloadFormatDefault {
+		var err error
+		reqBody, err = addWebhookLogID(reqBody, wl.ID)
+		if err != nil {
+			wl.ErrorMessage = fmt.Sprintf("failed to add id, error: %+v", err)
+			log.Errorf(ctx, wl.ErrorMessage)
+			return wl, false
+		}
 	}
 	req, err := http.NewRequest(http.MethodPost, wl.URL,
 		bytes.NewBuffer(reqBody))
 	if err != nil {
 		wl.ErrorMessage = fmt.Sprintf("failed to new request, error: %+v", err)
 		log.Errorf(ctx, wl.ErrorMessage)
-		return wl
-	}
-
-	// 2. set headers
-	headers := http.Header{}
-	if err := yaml.Unmarshal([]byte(wl.RequestHeaders), &headers); err != nil {
-		wl.ErrorMessage = fmt.Sprintf("failed to unmarshal header, error: %+v", err)
-		log.Errorf(ctx, wl.ErrorMessage)
-		return wl
+		return wl, false
 	}
 	req.Header = headers
 
-	// 3. send request
+	// 3. sign and send request
 	cli := w.secureClient
 	webhook, err := w.getWebhook()
 	if err != nil {
 		log.Error(ctx, err)
 		wl.ErrorMessage = err.Error()
-		return wl
+		return wl, false
 	}
+	// logs created by old versions may carry the raw secret, which may have been rotated
+	req.Header.Del(wlgenerator.WebhookSecretHeader)
+	if !w.disableSecretHeader {
+		req.Header.Set(wlgenerator.WebhookSecretHeader, webhook.Secret)
+	}
+	signature.SignRequestHeaders(req.Header, []string{webhook.Secret, webhook.SecondarySecret},
+		time.Now(), reqBody)
 
 	if !webhook.SSLVerifyEnabled {
 		cli = w.insecureClient
@@ -249,7 +269,7 @@ func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models
 	if err != nil {
 		wl.ErrorMessage = fmt.Sprintf("failed to send req, error: %+v", err)
 		log.Errorf(ctx, wl.ErrorMessage)
-		return wl
+		return wl, true
 	}
 
 	// 4. update response body
@@ -260,7 +280,7 @@ func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models
 		wl.ErrorMessage = fmt.Sprintf("failed to read response body, error: %+v", err)
 		log.Errorf(ctx, wl.ErrorMessage)
 		resp.Body.Close()
-		return wl
+		return wl, true
 	}
 	wl.ResponseBody = string(respBody)
 
@@ -270,14 +290,39 @@ func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models
 		wl.ErrorMessage = fmt.Sprintf("failed to marshal, error: %+v", err)
 		log.Errorf(ctx, wl.ErrorMessage)
 		resp.Body.Close()
-		return wl
+		return wl, false
 	}
 	if resp.StatusCode >= http.StatusBadRequest || resp.StatusCode < http.StatusOK {
 		wl.ErrorMessage = fmt.Sprintf("unexpected response code: %d", resp.StatusCode)
 	}
 	wl.ResponseHeaders = string(respHeader)
 	resp.Body.Close()
-	return wl
+	return wl, isRetryableStatusCode(resp.StatusCode)
+}
+
+// isRetryableStatusCode returns true for the response codes
+// which usually mean the receiver is temporarily unavailable
+func isRetryableStatusCode(code int) bool {
+	return code >= http.StatusInternalServerError ||
+		code == http.StatusRequestTimeout ||
+		code == http.StatusTooManyRequests
+}
+
+// setDeliveryResult sets the status of the webhook log according to the result of the last attempt
+func setDeliveryResult(webhook *models.Webhook, wl *models.WebhookLog, retryable bool, now time.Time) {
+	wl.NextRetryAt = nil
+	switch {
+	case wl.ErrorMessage == "":
+		wl.Status = webhookmodels.StatusSuccess
+	case !retryable || !webhook.RetryEnabled():
+		wl.Status = webhookmodels.StatusFailed
+	case wl.Attempts >= webhook.MaxAttempts:
+		wl.Status = webhookmodels.StatusDeadLetter
+	default:
+		nextRetryAt := now.Add(webhook.RetryDelay(wl.Attempts))
+		wl.Status = webhookmodels.StatusRetrying
+		wl.NextRetryAt = &nextRetryAt
+	}
 }
 
 // start webhook worker and begin to send process webhook logs
@@ -301,8 +346,7 @@ L:
 				log.Error(ctx, err)
 				continue
 			}
-			wls, err := w.webhookManager.ListWebhookLogsByStatus(ctx, webhook.ID,
-				webhookmodels.StatusWaiting)
+			wls, err := w.webhookManager.ListWebhookLogsToSend(ctx, webhook.ID, time.Now())
 			if err != nil {
 				log.Errorf(ctx, "failed to list webhook logs of %d, error: %s", webhook.ID, err.Error())
 				continue
@@ -312,20 +356,15 @@ L:
 				continue
 			}
 			for _, wl := range wls {
-				saveResult := func() {
-					if wl.ErrorMessage != "" {
-						wl.Status = webhookmodels.StatusFailed
-					} else {
-						wl.Status = webhookmodels.StatusSuccess
-					}
+				saveResult := func(wl *models.WebhookLog, retryable bool) {
+					setDeliveryResult(webhook, wl, retryable, time.Now())
 					_, err := w.webhookManager.UpdateWebhookLog(ctx, wl)
 					if err != nil {
 						log.Errorf(ctx, "failed to update webhook log %d, error: %s", wl.ID, err.Error())
 					}
 				}
 
-				wl = w.sendWebhook(ctx, wl)
-				saveResult()
+				saveResult(w.sendWebhook(ctx, wl))
 			}
 		}
 	}
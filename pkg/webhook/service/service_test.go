@@ -0,0 +1,122 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/horizoncd/horizon/pkg/eventhandler/wlgenerator"
+	"github.com/horizoncd/horizon/pkg/webhook/models"
+	"github.com/horizoncd/horizon/pkg/webhook/signature"
+)
+
+func TestRetryDelay(t *testing.T) {
+	webhook := &models.Webhook{MaxAttempts: 5, RetryBackoffSeconds: 10}
+	assert.Equal(t, 10*time.Second, webhook.RetryDelay(1))
+	assert.Equal(t, 20*time.Second, webhook.RetryDelay(2))
+	assert.Equal(t, 80*time.Second, webhook.RetryDelay(4))
+	assert.Equal(t, models.MaxRetryBackoff, webhook.RetryDelay(100))
+}
+
+func TestSetDeliveryResult(t *testing.T) {
+	now := time.Now()
+	webhook := &models.Webhook{MaxAttempts: 3, RetryBackoffSeconds: 10}
+
+	wl := &models.WebhookLog{Attempts: 1}
+	setDeliveryResult(webhook, wl, false, now)
+	assert.Equal(t, models.StatusSuccess, wl.Status)
+	assert.Nil(t, wl.NextRetryAt)
+
+	wl = &models.WebhookLog{Attempts: 1, ErrorMessage: "unexpected response code: 400"}
+	setDeliveryResult(webhook, wl, false, now)
+	assert.Equal(t, models.StatusFailed, wl.Status)
+
+	wl = &models.WebhookLog{Attempts: 2, ErrorMessage: "unexpected response code: 503"}
+	setDeliveryResult(webhook, wl, true, now)
+	assert.Equal(t, models.StatusRetrying, wl.Status)
+	assert.Equal(t, now.Add(20*time.Second), *wl.NextRetryAt)
+
+	wl.Attempts = 3
+	setDeliveryResult(webhook, wl, true, now)
+	assert.Equal(t, models.StatusDeadLetter, wl.Status)
+	assert.Nil(t, wl.NextRetryAt)
+
+	// webhooks created before retries were supported keep failing at once
+	wl = &models.WebhookLog{Attempts: 1, ErrorMessage: "unexpected response code: 503"}
+	setDeliveryResult(&models.Webhook{MaxAttempts: 1}, wl, true, now)
+	assert.Equal(t, models.StatusFailed, wl.Status)
+
+	assert.True(t, isRetryableStatusCode(502))
+	assert.True(t, isRetryableStatusCode(429))
+	assert.False(t, isRetryableStatusCode(404))
+}
+
+func TestSendSignedWebhook(t *testing.T) {
+	var (
+		verifyErr    error
+		secretHeader string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		secretHeader = r.Header.Get(wlgenerator.WebhookSecretHeader)
+		_, verifyErr = signature.VerifyRequest(r, "old-secret", signature.DefaultTolerance)
+		if verifyErr != nil {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	w := &worker{responseBodyTruncateSize: 1024}
+	w.setWebhook(&models.Webhook{
+		ID:               1,
+		Secret:           "new-secret",
+		SecondarySecret:  "old-secret",
+		SSLVerifyEnabled: true,
+	})
+	// the raw secret stored in logs by old versions is not sent
+	headers, err := yaml.Marshal(http.Header{
+		"Content-Type":                  []string{"application/json"},
+		wlgenerator.WebhookSecretHeader: []string{"stale-secret"},
+	})
+	assert.Nil(t, err)
+
+	wl, retryable := w.sendWebhook(context.Background(), &models.WebhookLog{
+		ID:             1,
+		URL:            server.URL,
+		RequestHeaders: string(headers),
+		RequestData:    `{"eventType":"clusters_deployed"}`,
+	})
+	assert.Nil(t, verifyErr)
+	// the current raw secret is still sent by default
+	assert.Equal(t, "new-secret", secretHeader)
+	assert.Equal(t, "", wl.ErrorMessage)
+	assert.False(t, retryable)
+	assert.Equal(t, uint(1), wl.Attempts)
+
+	// the raw secret is not sent once it's disabled
+	w.disableSecretHeader = true
+	_, _ = w.sendWebhook(context.Background(), wl)
+	assert.Nil(t, verifyErr)
+	assert.Equal(t, "", secretHeader)
+}
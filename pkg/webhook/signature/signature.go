@@ -0,0 +1,137 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signature signs webhook requests sent by horizon and verifies them on the receiver side.
+//
+// The signature is the hex encoded HMAC-SHA256 of "{timestamp}.{body}" keyed by the webhook secret.
+// The request carries the unix timestamp in the X-Horizon-Webhook-Timestamp header and the signatures
+// in the X-Horizon-Webhook-Signature header, e.g. "v1=5257a8...,v1=9a1f0c...". There is one signature for
+// each active secret of the webhook, so receivers keep working while secrets are rotated.
+//
+// Receivers should only depend on this package:
+//
+//	body, err := signature.VerifyRequest(r, secret, signature.DefaultTolerance)
+//	if err != nil {
+//		w.WriteHeader(http.StatusUnauthorized)
+//		return
+//	}
+package signature
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TimestampHeader = "X-Horizon-Webhook-Timestamp"
+	SignatureHeader = "X-Horizon-Webhook-Signature"
+
+	// Version is the scheme prefix of signatures
+	Version = "v1"
+
+	// DefaultTolerance is the max age of a request accepted by receivers
+	DefaultTolerance = 5 * time.Minute
+)
+
+var (
+	ErrMissingTimestamp  = errors.New("missing webhook timestamp")
+	ErrInvalidTimestamp  = errors.New("invalid webhook timestamp")
+	ErrTimestampExpired  = errors.New("webhook timestamp is out of tolerance")
+	ErrMissingSignature  = errors.New("missing webhook signature")
+	ErrSignatureMismatch = errors.New("webhook signature mismatch")
+)
+
+// Sign returns the hex encoded signature of body at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequestHeaders sets the timestamp and signature headers, one signature for each non-empty secret
+func SignRequestHeaders(header http.Header, secrets []string, timestamp time.Time, body []byte) {
+	ts := timestamp.Unix()
+	signatures := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		signatures = append(signatures, fmt.Sprintf("%s=%s", Version, Sign(secret, ts, body)))
+	}
+	if len(signatures) == 0 {
+		return
+	}
+	header.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+	header.Set(SignatureHeader, strings.Join(signatures, ","))
+}
+
+// Verify checks the headers against body, the request is accepted when any of the signatures
+// matches the secret and the timestamp is within tolerance of now. A zero tolerance skips the timestamp check.
+func Verify(header http.Header, body []byte, secret string, tolerance time.Duration, now time.Time) error {
+	tsStr := header.Get(TimestampHeader)
+	if tsStr == "" {
+		return ErrMissingTimestamp
+	}
+	ts, err := strconv.ParseInt(tsStr, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	if tolerance > 0 {
+		age := now.Sub(time.Unix(ts, 0))
+		if age > tolerance || age < -tolerance {
+			return ErrTimestampExpired
+		}
+	}
+
+	signatures := header.Get(SignatureHeader)
+	if signatures == "" {
+		return ErrMissingSignature
+	}
+	expected := []byte(Sign(secret, ts, body))
+	for _, signature := range strings.Split(signatures, ",") {
+		parts := strings.SplitN(strings.TrimSpace(signature), "=", 2)
+		if len(parts) != 2 || parts[0] != Version {
+			continue
+		}
+		if hmac.Equal([]byte(parts[1]), expected) {
+			return nil
+		}
+	}
+	return ErrSignatureMismatch
+}
+
+// VerifyRequest reads and verifies the body of request, the body is restored so that it can be read again
+func VerifyRequest(r *http.Request, secret string, tolerance time.Duration) ([]byte, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err := Verify(r.Header, body, secret, tolerance, time.Now()); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
@@ -0,0 +1,71 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"eventType":"clusters_deployed"}`)
+	now := time.Now()
+
+	header := http.Header{}
+	SignRequestHeaders(header, []string{"new-secret", "old-secret"}, now, body)
+	assert.NotEmpty(t, header.Get(TimestampHeader))
+
+	// both secrets are accepted during rotation
+	assert.Nil(t, Verify(header, body, "new-secret", DefaultTolerance, now))
+	assert.Nil(t, Verify(header, body, "old-secret", DefaultTolerance, now))
+	assert.Equal(t, ErrSignatureMismatch, Verify(header, body, "other-secret", DefaultTolerance, now))
+	assert.Equal(t, ErrSignatureMismatch,
+		Verify(header, []byte(`{"eventType":"clusters_deleted"}`), "new-secret", DefaultTolerance, now))
+
+	// replayed requests are rejected
+	assert.Equal(t, ErrTimestampExpired,
+		Verify(header, body, "new-secret", DefaultTolerance, now.Add(DefaultTolerance+time.Minute)))
+	assert.Nil(t, Verify(header, body, "new-secret", 0, now.Add(time.Hour)))
+
+	assert.Equal(t, ErrMissingTimestamp, Verify(http.Header{}, body, "new-secret", DefaultTolerance, now))
+	header.Del(SignatureHeader)
+	assert.Equal(t, ErrMissingSignature, Verify(header, body, "new-secret", DefaultTolerance, now))
+
+	// nothing is signed without secrets
+	header = http.Header{}
+	SignRequestHeaders(header, []string{""}, now, body)
+	assert.Empty(t, header.Get(SignatureHeader))
+}
+
+func TestVerifyRequest(t *testing.T) {
+	body := []byte(`{"eventType":"clusters_deployed"}`)
+	req, err := http.NewRequest(http.MethodPost, "http://localhost", bytes.NewReader(body))
+	assert.Nil(t, err)
+	SignRequestHeaders(req.Header, []string{"secret"}, time.Now(), body)
+
+	got, err := VerifyRequest(req, "secret", DefaultTolerance)
+	assert.Nil(t, err)
+	assert.Equal(t, body, got)
+
+	// body can be read again after verification
+	got, err = ioutil.ReadAll(req.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, body, got)
+}
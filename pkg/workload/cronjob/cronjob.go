@@ -0,0 +1,233 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronjob
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/workload"
+	"github.com/horizoncd/horizon/pkg/workload/job"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+)
+
+const (
+	ActionTrigger = "trigger"
+	ActionSuspend = "suspend"
+	ActionResume  = "resume"
+
+	// _instantiateAnnotation is the same as the annotation added by 'kubectl create job --from'
+	_instantiateAnnotation = "cronjob.kubernetes.io/instantiate"
+	// _maxNameLength keeps names of jobs and their pods within the limit of label value
+	_maxNameLength = 45
+)
+
+var (
+	// GVRCronJob cronjobs are served by batch/v1 since kubernetes 1.21, and by batch/v1beta1 before 1.25
+	GVRCronJob = schema.GroupVersionResource{
+		Group:    "batch",
+		Version:  "v1",
+		Resource: "cronjobs",
+	}
+	GVRCronJobV1beta1 = schema.GroupVersionResource{
+		Group:    "batch",
+		Version:  "v1beta1",
+		Resource: "cronjobs",
+	}
+	GVRPod = schema.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "pods",
+	}
+)
+
+func init() {
+	workload.Register(ability, GVRCronJob, GVRCronJobV1beta1, job.GVRJob, GVRPod)
+}
+
+// please refer to github.com/horizoncd/horizon/pkg/cluster/cd/workload/workload.go
+var ability = &cronjob{}
+
+type cronjob struct{}
+
+func (*cronjob) MatchGK(gk schema.GroupKind) bool {
+	return gk.Group == "batch" && gk.Kind == "CronJob"
+}
+
+func gvrOfNode(node *v1alpha1.ResourceNode) schema.GroupVersionResource {
+	if node.Version == GVRCronJobV1beta1.Version {
+		return GVRCronJobV1beta1
+	}
+	return GVRCronJob
+}
+
+// getCronJob returns the cronjob from informers,
+// batch/v1beta1 is compatible with batch/v1 in the fields of cronjob used by horizon
+func (*cronjob) getCronJob(node *v1alpha1.ResourceNode,
+	factory dynamicinformer.DynamicSharedInformerFactory) (*batchv1beta1.CronJob, error) {
+	obj, err := factory.ForResource(gvrOfNode(node)).Lister().ByNamespace(node.Namespace).Get(node.Name)
+	if err != nil {
+		return nil,
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				fmt.Sprintf("failed to get cronjob in k8s: cronjob = %s, ns = %v, err = %v",
+					node.Name, node.Namespace, err),
+			)
+	}
+	instance := &batchv1beta1.CronJob{}
+	if err := workload.ObjUnmarshal(obj, instance); err != nil {
+		return nil,
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				fmt.Sprintf("failed to convert obj into cronjob: name = %s, ns = %v, err = %v",
+					node.Name, node.Namespace, err),
+			)
+	}
+	return instance, nil
+}
+
+func (*cronjob) getCronJobByNode(node *v1alpha1.ResourceNode, client *kube.Client) (*batchv1beta1.CronJob, error) {
+	un, err := client.Dynamic.Resource(gvrOfNode(node)).Namespace(node.Namespace).
+		Get(context.TODO(), node.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, perror.Wrapf(
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				"failed to get cronjob in k8s"),
+			"failed to get cronjob in k8s: cronjob = %s, ns = %v, err = %v", node.Name, node.Namespace, err)
+	}
+	instance := &batchv1beta1.CronJob{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(un.Object, instance); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// IsHealthy returns true once cronjob exists, the jobs created by cronjob report their own health
+func (c *cronjob) IsHealthy(node *v1alpha1.ResourceNode,
+	client *kube.Client) (bool, error) {
+	if _, err := c.getCronJobByNode(node, client); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// ListPods lists pods of the jobs created by cronjob
+func (c *cronjob) ListPods(node *v1alpha1.ResourceNode,
+	factory dynamicinformer.DynamicSharedInformerFactory) ([]corev1.Pod, error) {
+	instance, err := c.getCronJob(node, factory)
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err := factory.ForResource(job.GVRJob).Lister().ByNamespace(node.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	pods := make([]corev1.Pod, 0)
+	for _, obj := range objs {
+		instanceJob := &batchv1.Job{}
+		if err := workload.ObjUnmarshal(obj, instanceJob); err != nil {
+			continue
+		}
+		if !metav1.IsControlledBy(instanceJob, instance) {
+			continue
+		}
+		podsOfJob, err := job.ListPodsOfJob(instanceJob, factory)
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, podsOfJob...)
+	}
+	return pods, nil
+}
+
+func (*cronjob) Action(actionName string, un *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	switch actionName {
+	case ActionSuspend:
+		if err := unstructured.SetNestedField(un.Object, true, "spec", "suspend"); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to suspend cronjob: %v", err)
+		}
+	case ActionResume:
+		if err := unstructured.SetNestedField(un.Object, false, "spec", "suspend"); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to resume cronjob: %v", err)
+		}
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported action: %v", actionName)
+	}
+	return un, nil
+}
+
+// CreateByAction creates a job from the job template of cronjob when it is triggered,
+// just like 'kubectl create job --from=cronjob/<name>'
+func (*cronjob) CreateByAction(actionName string,
+	un *unstructured.Unstructured) (schema.GroupVersionResource, *unstructured.Unstructured, bool, error) {
+	if actionName != ActionTrigger {
+		return schema.GroupVersionResource{}, nil, false, nil
+	}
+
+	instance := &batchv1beta1.CronJob{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(un.Object, instance); err != nil {
+		return schema.GroupVersionResource{}, nil, false,
+			perror.Wrapf(herrors.ErrParamInvalid, "convert to cronjob failed: %v", err)
+	}
+
+	name := instance.Name
+	if len(name) > _maxNameLength {
+		name = name[:_maxNameLength]
+	}
+	annotations := map[string]string{_instantiateAnnotation: "manual"}
+	for k, v := range instance.Spec.JobTemplate.Annotations {
+		annotations[k] = v
+	}
+	controller := true
+	created := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-manual-%s", name, rand.String(5)),
+			Namespace:   instance.Namespace,
+			Labels:      instance.Spec.JobTemplate.Labels,
+			Annotations: annotations,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: un.GetAPIVersion(),
+				Kind:       un.GetKind(),
+				Name:       instance.Name,
+				UID:        instance.UID,
+				Controller: &controller,
+			}},
+		},
+		Spec: instance.Spec.JobTemplate.Spec,
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(created)
+	if err != nil {
+		return schema.GroupVersionResource{}, nil, false,
+			perror.Wrapf(herrors.ErrParamInvalid, "convert job to unstructured failed: %v", err)
+	}
+	return job.GVRJob, &unstructured.Unstructured{Object: obj}, true, nil
+}
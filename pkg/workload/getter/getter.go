@@ -49,6 +49,21 @@ func (w *Helper) GetSteps(node *v1alpha1.ResourceNode, client *kube.Client) (*wo
 	return steps, nil
 }
 
+func (w *Helper) ListAnalysisRuns(node *v1alpha1.ResourceNode,
+	client *kube.Client) ([]workload.AnalysisRun, error) {
+	lister, ok := w.inner.(workload.AnalysisRunsLister)
+	if !ok {
+		return nil, perror.Wrapf(herrors.ErrMethodNotImplemented,
+			"workload %v not support list analysis runs", reflect.TypeOf(w.inner))
+	}
+	runs, err := lister.ListAnalysisRuns(node, client)
+	if err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ResourceInK8S,
+			fmt.Sprintf("failed to list analysis runs: resource name = %v, err = %v", node.Name, err))
+	}
+	return runs, nil
+}
+
 // TODO: remove this after using informer
 func (w *Helper) ListPods(node *v1alpha1.ResourceNode,
 	factory dynamicinformer.DynamicSharedInformerFactory) ([]corev1.Pod, error) {
@@ -0,0 +1,141 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/workload"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+)
+
+var (
+	GVRJob = schema.GroupVersionResource{
+		Group:    "batch",
+		Version:  "v1",
+		Resource: "jobs",
+	}
+	GVRPod = schema.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "pods",
+	}
+)
+
+func init() {
+	workload.Register(ability, GVRJob, GVRPod)
+}
+
+// please refer to github.com/horizoncd/horizon/pkg/cluster/cd/workload/workload.go
+var ability = &job{}
+
+type job struct{}
+
+func (*job) MatchGK(gk schema.GroupKind) bool {
+	return gk.Group == "batch" && gk.Kind == "Job"
+}
+
+func (*job) getJob(node *v1alpha1.ResourceNode,
+	factory dynamicinformer.DynamicSharedInformerFactory) (*batchv1.Job, error) {
+	obj, err := factory.ForResource(GVRJob).Lister().ByNamespace(node.Namespace).Get(node.Name)
+	if err != nil {
+		return nil,
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				fmt.Sprintf("failed to get job in k8s: job = %s, ns = %v, err = %v",
+					node.Name, node.Namespace, err),
+			)
+	}
+	instance := &batchv1.Job{}
+	if err := workload.ObjUnmarshal(obj, instance); err != nil {
+		return nil,
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				fmt.Sprintf("failed to convert obj into job: name = %s, ns = %v, err = %v",
+					node.Name, node.Namespace, err),
+			)
+	}
+	return instance, nil
+}
+
+func (*job) getJobByNode(node *v1alpha1.ResourceNode, client *kube.Client) (*batchv1.Job, error) {
+	instance, err := client.Basic.BatchV1().Jobs(node.Namespace).Get(context.TODO(), node.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, perror.Wrapf(
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				"failed to get job in k8s"),
+			"failed to get job in k8s: job = %s, ns = %v, err = %v", node.Name, node.Namespace, err)
+	}
+	return instance, nil
+}
+
+// IsHealthy returns true if the job has completed
+func (j *job) IsHealthy(node *v1alpha1.ResourceNode,
+	client *kube.Client) (bool, error) {
+	instance, err := j.getJobByNode(node, client)
+	if err != nil {
+		return true, err
+	}
+	return IsCompleted(instance), nil
+}
+
+func (j *job) ListPods(node *v1alpha1.ResourceNode,
+	factory dynamicinformer.DynamicSharedInformerFactory) ([]corev1.Pod, error) {
+	instance, err := j.getJob(node, factory)
+	if err != nil {
+		return nil, err
+	}
+	return ListPodsOfJob(instance, factory)
+}
+
+func (*job) Action(actionName string, un *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return un, nil
+}
+
+// IsCompleted returns true if the job has finished successfully
+func IsCompleted(instance *batchv1.Job) bool {
+	for _, condition := range instance.Status.Conditions {
+		if condition.Type == batchv1.JobComplete && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// ListPodsOfJob lists pods created by the job from informers
+func ListPodsOfJob(instance *batchv1.Job,
+	factory dynamicinformer.DynamicSharedInformerFactory) ([]corev1.Pod, error) {
+	selector, err := metav1.LabelSelectorAsSelector(instance.Spec.Selector)
+	if err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ResourceInK8S,
+			fmt.Sprintf("failed to get selectors for object %s/%s", instance.Namespace, instance.Name))
+	}
+	objs, err := factory.ForResource(GVRPod).Lister().ByNamespace(instance.Namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	pods := workload.ObjIntoPod(objs...)
+
+	return pods, nil
+}
@@ -0,0 +1,161 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollout
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	rolloutsv1alpha1 "github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/workload"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var GVRAnalysisRun = schema.GroupVersionResource{
+	Group:    "argoproj.io",
+	Version:  "v1alpha1",
+	Resource: "analysisruns",
+}
+
+// ListAnalysisRuns lists analysis runs of the current step of rollout,
+// including background analysis and blue-green pre/post promotion analysis of the current version.
+func (r *rollout) ListAnalysisRuns(node *v1alpha1.ResourceNode,
+	client *kube.Client) ([]workload.AnalysisRun, error) {
+	gvr := schema.GroupVersionResource{
+		Group:    "argoproj.io",
+		Version:  node.Version,
+		Resource: "rollouts",
+	}
+	un, err := client.Dynamic.Resource(gvr).Namespace(node.Namespace).
+		Get(context.TODO(), node.Name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return []workload.AnalysisRun{}, nil
+		}
+		return nil, perror.Wrapf(
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				"failed to get rollout in k8s"),
+			"failed to get rollout in k8s: rollout = %s, err = %v", node.Name, err)
+	}
+	var instance *rolloutsv1alpha1.Rollout
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(un.UnstructuredContent(), &instance); err != nil {
+		return nil, err
+	}
+	if instance.Status.CurrentPodHash == "" {
+		return []workload.AnalysisRun{}, nil
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{
+		rolloutsv1alpha1.DefaultRolloutUniqueLabelKey: instance.Status.CurrentPodHash,
+	})
+	list, err := client.Dynamic.Resource(GVRAnalysisRun).Namespace(node.Namespace).
+		List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, perror.Wrapf(
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				"failed to list analysis runs in k8s"),
+			"failed to list analysis runs in k8s: rollout = %s, err = %v", node.Name, err)
+	}
+
+	runs := make([]workload.AnalysisRun, 0, len(list.Items))
+	for i := range list.Items {
+		if !metav1.IsControlledBy(&list.Items[i], instance) {
+			continue
+		}
+		var run *rolloutsv1alpha1.AnalysisRun
+		if err := runtime.DefaultUnstructuredConverter.
+			FromUnstructured(list.Items[i].UnstructuredContent(), &run); err != nil {
+			return nil, err
+		}
+		if !isCurrentAnalysisRun(instance, run) {
+			continue
+		}
+		runs = append(runs, ofAnalysisRun(run))
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		if runs[i].StartedAt == nil || runs[j].StartedAt == nil {
+			return runs[j].StartedAt == nil && runs[i].StartedAt != nil
+		}
+		return runs[i].StartedAt.Before(*runs[j].StartedAt)
+	})
+	return runs, nil
+}
+
+// isCurrentAnalysisRun filters out analysis runs created by the previous steps
+func isCurrentAnalysisRun(instance *rolloutsv1alpha1.Rollout, run *rolloutsv1alpha1.AnalysisRun) bool {
+	if run.Labels[rolloutsv1alpha1.RolloutTypeLabel] != rolloutsv1alpha1.RolloutTypeStepLabel {
+		return true
+	}
+	if instance.Status.CurrentStepIndex == nil {
+		return false
+	}
+	return run.Labels[rolloutsv1alpha1.RolloutCanaryStepIndexLabel] ==
+		strconv.Itoa(int(*instance.Status.CurrentStepIndex))
+}
+
+func ofAnalysisRun(run *rolloutsv1alpha1.AnalysisRun) workload.AnalysisRun {
+	result := workload.AnalysisRun{
+		Name:      run.Name,
+		Type:      run.Labels[rolloutsv1alpha1.RolloutTypeLabel],
+		Phase:     string(run.Status.Phase),
+		Message:   run.Status.Message,
+		StartedAt: ofTime(run.Status.StartedAt),
+		Metrics:   make([]workload.AnalysisMetric, 0, len(run.Status.MetricResults)),
+	}
+	if index, err := strconv.Atoi(run.Labels[rolloutsv1alpha1.RolloutCanaryStepIndexLabel]); err == nil {
+		result.StepIndex = &index
+	}
+	for _, metric := range run.Status.MetricResults {
+		measurements := make([]workload.AnalysisMeasurement, 0, len(metric.Measurements))
+		for _, measurement := range metric.Measurements {
+			measurements = append(measurements, workload.AnalysisMeasurement{
+				Phase:      string(measurement.Phase),
+				Message:    measurement.Message,
+				Value:      measurement.Value,
+				StartedAt:  ofTime(measurement.StartedAt),
+				FinishedAt: ofTime(measurement.FinishedAt),
+			})
+		}
+		result.Metrics = append(result.Metrics, workload.AnalysisMetric{
+			Name:         metric.Name,
+			Phase:        string(metric.Phase),
+			Message:      metric.Message,
+			Count:        int(metric.Count),
+			Successful:   int(metric.Successful),
+			Failed:       int(metric.Failed),
+			Inconclusive: int(metric.Inconclusive),
+			Error:        int(metric.Error),
+			Measurements: measurements,
+		})
+	}
+	return result
+}
+
+func ofTime(t *metav1.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	return &t.Time
+}
@@ -221,6 +221,7 @@ func (r *rollout) GetSteps(node *v1alpha1.ResourceNode, client *kube.Client) (*w
 			Index:    0,
 			Total:    1,
 			Replicas: []int{replicasTotal},
+			Aborted:  instance.Status.Abort,
 		}, nil
 	}
 
@@ -273,6 +274,7 @@ func (r *rollout) GetSteps(node *v1alpha1.ResourceNode, client *kube.Client) (*w
 		Replicas:     incrementReplicasList,
 		ManualPaused: instance.Spec.Paused,
 		AutoPromote:  autoPromote,
+		Aborted:      instance.Status.Abort,
 		Extra:        &extra,
 	}, nil
 }
@@ -311,6 +313,15 @@ func (r *rollout) Action(actionName string, un *unstructured.Unstructured) (*uns
 		spec["paused"] = false
 	case "cancel-auto-promote":
 		delete(status, "autoPromote")
+	case "abort":
+		// stops progressing the rollout and reverts to the stable version
+		status["abort"] = true
+	case "retry":
+		// retries the aborted rollout from the first step
+		if !instance.Status.Abort {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "rollout %v is not aborted", un.GetName())
+		}
+		status["abort"] = false
 	default:
 		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported action: %v", actionName)
 	}
@@ -15,6 +15,8 @@
 package workload
 
 import (
+	"time"
+
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -24,9 +26,42 @@ type Step struct {
 	Replicas     []int
 	ManualPaused bool
 	AutoPromote  bool
+	Aborted      bool
 	Extra        *string
 }
 
+// AnalysisRun is the analysis run of the current step in progressive delivery
+type AnalysisRun struct {
+	Name string
+	// Type indicates why the analysis run is created, such as Step, Background, PrePromotion and PostPromotion
+	Type      string
+	StepIndex *int
+	Phase     string
+	Message   string
+	StartedAt *time.Time
+	Metrics   []AnalysisMetric
+}
+
+type AnalysisMetric struct {
+	Name         string
+	Phase        string
+	Message      string
+	Count        int
+	Successful   int
+	Failed       int
+	Inconclusive int
+	Error        int
+	Measurements []AnalysisMeasurement
+}
+
+type AnalysisMeasurement struct {
+	Phase      string
+	Message    string
+	Value      string
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+}
+
 type Revision struct {
 	Name string
 	Pods []v1.Pod
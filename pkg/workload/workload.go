@@ -69,11 +69,25 @@ type Workload interface {
 	Action(aName string, un *unstructured.Unstructured) (*unstructured.Unstructured, error)
 }
 
+// ActionCreator is implemented by workloads which have actions creating a new resource
+// instead of updating the workload itself, such as triggering a cronjob.
+type ActionCreator interface {
+	Workload
+	// CreateByAction returns the resource to create, and false if the action does not create any resource
+	CreateByAction(aName string, un *unstructured.Unstructured) (schema.GroupVersionResource,
+		*unstructured.Unstructured, bool, error)
+}
+
 type GreyscaleReleaser interface {
 	Workload
 	GetSteps(node *v1alpha1.ResourceNode, client *kube.Client) (*Step, error)
 }
 
+type AnalysisRunsLister interface {
+	Workload
+	ListAnalysisRuns(node *v1alpha1.ResourceNode, client *kube.Client) ([]AnalysisRun, error)
+}
+
 type PodsLister interface {
 	Workload
 	ListPods(node *v1alpha1.ResourceNode,